@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lsy88/uptime-chopper/internal/agent"
+	"github.com/lsy88/uptime-chopper/internal/config"
+	"github.com/lsy88/uptime-chopper/internal/docker"
+	"github.com/lsy88/uptime-chopper/internal/kube"
+)
+
+// runAgent implements "uptime-chopper agent": it checks the monitors
+// assigned to this agent on the server and reports their results. Docker
+// hosts and kubeconfig come from the usual config file and environment.
+func runAgent(logger *zap.Logger, args []string) {
+	fs := flag.NewFlagSet("agent", flag.ExitOnError)
+	server := fs.String("server", os.Getenv("UPTIME_CHOPPER_AGENT_SERVER"), "base URL of the uptime-chopper server")
+	token := fs.String("token", os.Getenv("UPTIME_CHOPPER_AGENT_TOKEN"), "agent token configured on the server")
+	dataFile := fs.String("data", "data/agent.json", "file holding the assigned monitors and local history")
+	syncInterval := fs.Duration("sync-interval", 30*time.Second, "how often assignments are fetched from the server")
+	_ = fs.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("load config", zap.Error(err))
+	}
+
+	dockerClient, err := docker.NewClient()
+	if err != nil && !errors.Is(err, docker.ErrDockerUnavailable) {
+		logger.Fatal("init docker", zap.Error(err))
+	}
+	dockerHosts, err := docker.NewHosts(dockerClient, cfg.DockerHosts)
+	if err != nil {
+		logger.Fatal("init docker hosts", zap.Error(err))
+	}
+	kubeClient, err := kube.NewClient(cfg.Kubeconfig, cfg.KubeContext)
+	if err != nil {
+		if !errors.Is(err, kube.ErrNotConfigured) {
+			logger.Warn("kubernetes monitors disabled", zap.Error(err))
+		}
+		kubeClient = nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	err = agent.Run(ctx, agent.Options{
+		Server:       *server,
+		Token:        *token,
+		DataFile:     *dataFile,
+		SyncInterval: *syncInterval,
+		Logger:       logger,
+		Docker:       dockerHosts,
+		Kube:         kubeClient,
+		MaxLogBytes:  cfg.MaxDockerLogBytes,
+		DefaultSince: cfg.DefaultDockerLogSince,
+
+		AllowHostCommands: cfg.AllowHostCommands,
+	})
+	if err != nil {
+		logger.Fatal("agent", zap.Error(err))
+	}
+}
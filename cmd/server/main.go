@@ -2,62 +2,203 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"flag"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/lsy88/uptime-chopper/internal/api"
+	"github.com/lsy88/uptime-chopper/internal/auth"
 	"github.com/lsy88/uptime-chopper/internal/config"
 	"github.com/lsy88/uptime-chopper/internal/docker"
+	"github.com/lsy88/uptime-chopper/internal/kube"
 	"github.com/lsy88/uptime-chopper/internal/monitor"
 	"github.com/lsy88/uptime-chopper/internal/notify"
+	"github.com/lsy88/uptime-chopper/internal/provision"
+	"github.com/lsy88/uptime-chopper/internal/redact"
+	"github.com/lsy88/uptime-chopper/internal/secrets"
 	"github.com/lsy88/uptime-chopper/internal/store"
 
 	"go.uber.org/zap"
 )
 
 func main() {
-	logger, _ := zap.NewProduction()
+	if len(os.Args) > 1 && os.Args[1] == "agent" {
+		logger, _ := zap.NewProduction(zap.WrapCore(redact.Core))
+		defer logger.Sync()
+		runAgent(logger, os.Args[2:])
+		return
+	}
+
+	simulate := flag.Bool("simulate", false, "run the scheduler against synthetic monitors and report capacity")
+	simMonitors := flag.Int("simulate-monitors", 1000, "number of synthetic monitors in simulation mode")
+	simInterval := flag.Duration("simulate-interval", 30*time.Second, "check interval of synthetic monitors")
+	simDuration := flag.Duration("simulate-duration", time.Minute, "how long the simulation runs")
+	simMinLatency := flag.Duration("simulate-min-latency", 5*time.Millisecond, "minimum latency of stub checks")
+	simMaxLatency := flag.Duration("simulate-max-latency", 50*time.Millisecond, "maximum latency of stub checks")
+	simFailureRate := flag.Float64("simulate-failure-rate", 0.01, "fraction of stub checks that report down")
+	migrateTo := flag.Int("migrate-to", -1, "migrate the SQLite schema up or down to this version and exit, e.g. before downgrading")
+	flag.Parse()
+
+	logger, _ := zap.NewProduction(zap.WrapCore(redact.Core))
 	defer logger.Sync()
 
+	if *simulate {
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		report, err := monitor.Simulate(ctx, zap.NewNop(), monitor.SimulateOptions{
+			Monitors:    *simMonitors,
+			Interval:    *simInterval,
+			Duration:    *simDuration,
+			MinLatency:  *simMinLatency,
+			MaxLatency:  *simMaxLatency,
+			FailureRate: *simFailureRate,
+		})
+		if err != nil {
+			logger.Fatal("simulate", zap.Error(err))
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+		return
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		logger.Fatal("load config", zap.Error(err))
 	}
 
-	st, err := store.NewSQLiteStore(cfg.DataFilePath)
-	if err != nil {
-		logger.Fatal("open store", zap.Error(err))
+	var st store.Store
+	switch cfg.StoreBackend {
+	case "json":
+		if *migrateTo >= 0 {
+			logger.Fatal("-migrate-to requires the sqlite store backend")
+		}
+		js, err := store.NewJSONStore(cfg.JSONDataFilePath)
+		if err != nil {
+			logger.Fatal("open store", zap.Error(err))
+		}
+		st = js
+	case "sqlite":
+		sq, err := store.NewSQLiteStore(cfg.DataFilePath)
+		if err != nil {
+			logger.Fatal("open store", zap.Error(err))
+		}
+		if *migrateTo >= 0 {
+			if err := sq.MigrateTo(*migrateTo); err != nil {
+				logger.Fatal("migrate schema", zap.Error(err))
+			}
+			logger.Info("schema migrated", zap.Int("version", *migrateTo))
+			_ = sq.Close()
+			return
+		}
+		if err := sq.MigrateFromJSON(cfg.JSONDataFilePath); err != nil {
+			logger.Fatal("migrate json store", zap.Error(err))
+		}
+		st = sq
+	default:
+		logger.Fatal("unknown store backend", zap.String("store_backend", cfg.StoreBackend))
+	}
+	logger.Info("store opened", zap.String("backend", cfg.StoreBackend))
+
+	if created, err := auth.BootstrapAdmin(st, cfg.AdminUsername, cfg.AdminPassword); err != nil {
+		logger.Fatal("bootstrap admin", zap.Error(err))
+	} else if created {
+		logger.Info("created admin user", zap.String("username", cfg.AdminUsername))
 	}
 
 	dockerClient, err := docker.NewClient()
 	if err != nil && !errors.Is(err, docker.ErrDockerUnavailable) {
 		logger.Fatal("init docker", zap.Error(err))
 	}
+	dockerHosts, err := docker.NewHosts(dockerClient, cfg.DockerHosts)
+	if err != nil {
+		logger.Fatal("init docker hosts", zap.Error(err))
+	}
+
+	kubeClient, err := kube.NewClient(cfg.Kubeconfig, cfg.KubeContext)
+	if err != nil {
+		if !errors.Is(err, kube.ErrNotConfigured) {
+			logger.Warn("kubernetes monitors disabled", zap.Error(err))
+		}
+		kubeClient = nil
+	}
+
+	box, err := secrets.NewBox(cfg.SecretKey)
+	if err != nil {
+		logger.Fatal("init secrets", zap.Error(err))
+	}
+	resolver := secrets.NewResolver(box, st)
 
 	notifier := notify.NewDispatcher(cfg.Notifications)
 
+	settings := cfg.Settings()
+	if saved, ok := st.GetSettings(); ok {
+		settings = saved
+	}
+
 	engine := monitor.NewEngine(monitor.EngineDeps{
 		Logger:       logger,
 		Store:        st,
-		Docker:       dockerClient,
+		Docker:       dockerHosts,
+		Kube:         kubeClient,
 		Notifier:     notifier,
-		MaxLogBytes:  cfg.MaxDockerLogBytes,
 		DefaultSince: cfg.DefaultDockerLogSince,
+		Settings:     settings,
+		Secrets:      resolver,
+
+		LatencyBucketsMs:  cfg.LatencyBucketsMs,
+		PersistHistograms: cfg.PersistHistograms,
+		DigestWindow:      cfg.AlertDigestWindow,
+
+		ImageUpdateInterval: cfg.ImageUpdateInterval,
+		DockerPingInterval:  cfg.DockerPingInterval,
+		AllowHostCommands:   cfg.AllowHostCommands,
+		CheckJitter:         cfg.CheckJitter,
+		DrainTimeout:        cfg.DrainTimeout,
+
+		PruneInterval:        cfg.PruneInterval,
+		VacuumInterval:       cfg.VacuumInterval,
+		HistoryFlushInterval: cfg.HistoryFlushInterval,
+		HistoryBatchSize:     cfg.HistoryBatchSize,
 	})
+	corsOrigin := api.NewCORSOrigin(cfg.AllowedCORSOrigin)
+	apiDeps := api.Deps{
+		Logger:     logger,
+		Store:      st,
+		Docker:     dockerHosts,
+		Engine:     engine,
+		Config:     cfg,
+		CORSOrigin: corsOrigin,
+		Secrets:    resolver,
+	}
+	reconcile := func() {
+		if cfg.ProvisioningFile == "" {
+			return
+		}
+		declared, err := provision.Load(cfg.ProvisioningFile)
+		if err == nil {
+			var res provision.Result
+			if res, err = provision.Reconcile(st, declared, apiDeps.PrepareMonitor); err == nil {
+				logger.Info("provisioned monitors", zap.String("file", cfg.ProvisioningFile),
+					zap.Int("created", res.Created), zap.Int("updated", res.Updated),
+					zap.Int("deleted", res.Deleted), zap.Int("unchanged", res.Unchanged))
+				return
+			}
+		}
+		logger.Error("provisioning failed; monitors left unchanged", zap.String("file", cfg.ProvisioningFile), zap.Error(err))
+	}
+	reconcile()
+
 	engine.Start()
 	defer engine.Stop()
 
-	r := api.NewRouter(api.Deps{
-		Logger: logger,
-		Store:  st,
-		Docker: dockerClient,
-		Engine: engine,
-		Config: cfg,
-	})
+	r := api.NewRouter(apiDeps)
 
 	srv := &http.Server{
 		Addr:              cfg.HTTPAddr,
@@ -65,16 +206,56 @@ func main() {
 		ReadHeaderTimeout: 10 * time.Second,
 	}
 
+	serve, err := serveFunc(srv, cfg, logger)
+	if err != nil {
+		logger.Fatal("tls config", zap.Error(err))
+	}
 	go func() {
-		logger.Info("http listening", zap.String("addr", cfg.HTTPAddr))
-		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Info("http listening", zap.String("addr", cfg.HTTPAddr), zap.Bool("tls", cfg.TLSCertFile != "" || len(cfg.ACMEDomains) > 0))
+		if err := serve(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			logger.Fatal("listen", zap.Error(err))
 		}
 	}()
 
+	// reload applies the options of a changed config file that take effect
+	// without a restart: webhooks, the CORS origin and the log limits. The
+	// log limits and retention only follow the file until settings are
+	// saved through the API.
+	var reloadMu sync.Mutex
+	reload := func(next *config.Config) {
+		reloadMu.Lock()
+		defer reloadMu.Unlock()
+		engine.SetNotifier(notify.NewDispatcher(next.Notifications))
+		corsOrigin.Set(next.AllowedCORSOrigin)
+		if _, saved := st.GetSettings(); !saved {
+			engine.ApplySettings(next.Settings())
+		}
+		logger.Info("config reloaded", zap.Int("webhooks", len(next.Notifications)), zap.String("cors_origin", next.AllowedCORSOrigin))
+	}
+	reloadFailed := func(err error) {
+		logger.Error("config reload failed; previous config left in effect", zap.Error(err))
+	}
+	if config.Watch(reload, reloadFailed) {
+		logger.Info("watching config file for changes")
+	}
+
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
-	<-stop
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for running := true; running; {
+		select {
+		case <-hup:
+			if next, err := config.Load(); err != nil {
+				reloadFailed(err)
+			} else {
+				reload(next)
+			}
+			reconcile()
+		case <-stop:
+			running = false
+		}
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/lsy88/uptime-chopper/internal/config"
+)
+
+// serveFunc returns how srv is served according to the TLS options of cfg:
+// plain HTTP, HTTPS with a certificate from files, or HTTPS with
+// certificates obtained over ACME.
+func serveFunc(srv *http.Server, cfg *config.Config, logger *zap.Logger) (func() error, error) {
+	files := cfg.TLSCertFile != "" || cfg.TLSKeyFile != ""
+	switch {
+	case files && len(cfg.ACMEDomains) > 0:
+		return nil, errors.New("tls_cert_file/tls_key_file and acme_domains are mutually exclusive")
+	case files:
+		if cfg.TLSCertFile == "" || cfg.TLSKeyFile == "" {
+			return nil, errors.New("tls_cert_file and tls_key_file must be set together")
+		}
+		return func() error { return srv.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile) }, nil
+	case len(cfg.ACMEDomains) > 0:
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.ACMEDomains...),
+			Cache:      autocert.DirCache(cfg.ACMECacheDir),
+			Email:      cfg.ACMEEmail,
+		}
+		if cfg.ACMEDirectoryURL != "" {
+			m.Client = &acme.Client{DirectoryURL: cfg.ACMEDirectoryURL}
+		}
+		srv.TLSConfig = m.TLSConfig()
+		return func() error {
+			if cfg.ACMEHTTPAddr != "" {
+				challenge := &http.Server{
+					Addr:              cfg.ACMEHTTPAddr,
+					Handler:           m.HTTPHandler(nil),
+					ReadHeaderTimeout: 10 * time.Second,
+				}
+				go func() {
+					logger.Info("acme http listening", zap.String("addr", cfg.ACMEHTTPAddr))
+					if err := challenge.ListenAndServe(); err != nil {
+						logger.Error("acme http listener", zap.Error(err))
+					}
+				}()
+			}
+			return srv.ListenAndServeTLS("", "")
+		}, nil
+	default:
+		return srv.ListenAndServe, nil
+	}
+}
@@ -0,0 +1,261 @@
+// Package agent runs checks on behalf of a remote uptime-chopper server. The
+// agent fetches the monitors assigned to it, checks them with a local engine
+// (so container monitors see the agent's Docker daemon) and reports the
+// results back over HTTP. Results are buffered while the server is
+// unreachable and sent once it is back.
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lsy88/uptime-chopper/internal/docker"
+	"github.com/lsy88/uptime-chopper/internal/kube"
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/monitor"
+	"github.com/lsy88/uptime-chopper/internal/notify"
+	"github.com/lsy88/uptime-chopper/internal/store"
+)
+
+// maxBuffered caps the results kept while the server is unreachable; the
+// oldest are dropped first.
+const maxBuffered = 1000
+
+type Options struct {
+	// Server is the base URL of the server, e.g. https://uptime.example.com.
+	Server string
+	Token  string
+	// DataFile keeps the assigned monitors and local history, so checks
+	// continue across restarts while the server is down.
+	DataFile string
+	// SyncInterval is how often assignments are fetched; default 30s.
+	SyncInterval time.Duration
+	// FlushInterval is how often buffered results are sent; default 5s.
+	FlushInterval time.Duration
+
+	Logger       *zap.Logger
+	Docker       *docker.Hosts
+	Kube         *kube.Client
+	MaxLogBytes  int
+	DefaultSince time.Duration
+	// AllowHostCommands lets exec monitors and script remediation run on
+	// this agent.
+	AllowHostCommands bool
+}
+
+type agent struct {
+	opts   Options
+	client *http.Client
+	store  *store.JSONStore
+
+	registered bool
+
+	mu      sync.Mutex
+	pending []model.AgentResult
+}
+
+// Run registers with the server and checks the assigned monitors until ctx
+// is cancelled.
+func Run(ctx context.Context, opts Options) error {
+	if opts.Server == "" || opts.Token == "" {
+		return fmt.Errorf("agent: server and token are required")
+	}
+	opts.Server = strings.TrimRight(opts.Server, "/")
+	if opts.DataFile == "" {
+		opts.DataFile = "data/agent.json"
+	}
+	if opts.SyncInterval <= 0 {
+		opts.SyncInterval = 30 * time.Second
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+	if err := os.MkdirAll(filepath.Dir(opts.DataFile), 0o755); err != nil {
+		return err
+	}
+	st, err := store.NewJSONStore(opts.DataFile)
+	if err != nil {
+		return err
+	}
+	a := &agent{opts: opts, client: &http.Client{Timeout: 15 * time.Second}, store: st}
+
+	a.sync(ctx)
+
+	engine := monitor.NewEngine(monitor.EngineDeps{
+		Logger:       opts.Logger,
+		Store:        st,
+		Docker:       opts.Docker,
+		Kube:         opts.Kube,
+		Notifier:     notify.NewDispatcher(nil),
+		DefaultSince: opts.DefaultSince,
+		Settings:     model.Settings{MaxDockerLogBytes: opts.MaxLogBytes},
+		OnResult:     a.queue,
+
+		AllowHostCommands: opts.AllowHostCommands,
+	})
+	engine.Start()
+
+	syncTicker := time.NewTicker(opts.SyncInterval)
+	defer syncTicker.Stop()
+	flushTicker := time.NewTicker(opts.FlushInterval)
+	defer flushTicker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// Let running checks finish, then send what is left with a
+			// short deadline.
+			engine.Stop()
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			a.flush(flushCtx)
+			cancel()
+			return nil
+		case <-syncTicker.C:
+			a.sync(ctx)
+		case <-flushTicker.C:
+			a.flush(ctx)
+		}
+	}
+}
+
+func (a *agent) register(ctx context.Context) error {
+	hostname, _ := os.Hostname()
+	var out struct {
+		Name string `json:"name"`
+	}
+	if err := a.do(ctx, http.MethodPost, "/api/agent/register", map[string]string{"hostname": hostname}, &out); err != nil {
+		return err
+	}
+	a.opts.Logger.Info("agent registered", zap.String("name", out.Name), zap.String("server", a.opts.Server))
+	return nil
+}
+
+// sync replaces the local monitors with the ones assigned by the server.
+// Until the agent has registered, and whenever the server cannot be
+// reached, the previous assignments keep running.
+func (a *agent) sync(ctx context.Context) {
+	if !a.registered {
+		if err := a.register(ctx); err != nil {
+			a.opts.Logger.Warn("agent registration failed", zap.Error(err))
+			return
+		}
+		a.registered = true
+	}
+	var monitors []model.Monitor
+	if err := a.do(ctx, http.MethodGet, "/api/agent/monitors", nil, &monitors); err != nil {
+		a.opts.Logger.Warn("agent sync failed", zap.Error(err))
+		return
+	}
+
+	local := map[string]model.Monitor{}
+	for _, m := range a.store.GetState().Monitors {
+		local[m.ID] = m
+	}
+	for _, m := range monitors {
+		// Checked here, not handed on again; the server combines regions.
+		m.Agent, m.Regions = "", nil
+		if old, ok := local[m.ID]; ok && sameMonitor(old, m) {
+			delete(local, m.ID)
+			continue
+		}
+		delete(local, m.ID)
+		if _, err := a.store.UpsertMonitor(m); err != nil {
+			a.opts.Logger.Error("agent store monitor", zap.String("monitor_id", m.ID), zap.Error(err))
+		}
+	}
+	for id := range local {
+		if err := a.store.DeleteMonitor(id); err != nil {
+			a.opts.Logger.Error("agent delete monitor", zap.String("monitor_id", id), zap.Error(err))
+		}
+	}
+}
+
+// sameMonitor compares monitors ignoring the timestamps set by the store.
+func sameMonitor(a, b model.Monitor) bool {
+	a.CreatedAt, a.UpdatedAt = time.Time{}, time.Time{}
+	b.CreatedAt, b.UpdatedAt = time.Time{}, time.Time{}
+	return reflect.DeepEqual(a, b)
+}
+
+func (a *agent) queue(m model.Monitor, res model.CheckResult, logs *notify.DockerLogsAttachment) {
+	r := model.AgentResult{Result: res}
+	if logs != nil {
+		r.Logs, r.LogsTruncated = logs.Content, logs.Truncated
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pending = append(a.pending, r)
+	if n := len(a.pending) - maxBuffered; n > 0 {
+		a.pending = append([]model.AgentResult(nil), a.pending[n:]...)
+	}
+}
+
+// flush sends the buffered results and keeps them if the server cannot be
+// reached.
+func (a *agent) flush(ctx context.Context) {
+	a.mu.Lock()
+	batch := a.pending
+	a.pending = nil
+	a.mu.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+	if err := a.do(ctx, http.MethodPost, "/api/agent/results", batch, nil); err != nil {
+		a.opts.Logger.Warn("agent report failed", zap.Int("results", len(batch)), zap.Error(err))
+		a.mu.Lock()
+		a.pending = append(batch, a.pending...)
+		if n := len(a.pending) - maxBuffered; n > 0 {
+			a.pending = a.pending[n:]
+		}
+		a.mu.Unlock()
+	}
+}
+
+func (a *agent) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, a.opts.Server+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.opts.Token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&e)
+		if e.Error == "" {
+			e.Error = resp.Status
+		}
+		return fmt.Errorf("%s %s: %s", method, path, e.Error)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/monitor"
+)
+
+type agentKey struct{}
+
+// agentRouter serves remote agents. Agents authenticate with their own
+// token from the agents config rather than a user session, so the routes
+// are exempt from user authentication by default.
+func agentRouter(deps Deps) http.Handler {
+	r := chi.NewRouter()
+	r.Use(deps.authenticateAgent)
+
+	r.Post("/register", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Hostname string `json:"hostname"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		name := agentFrom(r)
+		deps.Engine.AgentSeen(name, body.Hostname, r.RemoteAddr)
+		writeJSON(w, http.StatusOK, map[string]any{"name": name})
+	})
+
+	r.Get("/monitors", func(w http.ResponseWriter, r *http.Request) {
+		name := agentFrom(r)
+		deps.Engine.AgentSeen(name, "", r.RemoteAddr)
+		writeJSON(w, http.StatusOK, deps.Engine.AgentMonitors(name))
+	})
+
+	r.Post("/results", func(w http.ResponseWriter, r *http.Request) {
+		var results []model.AgentResult
+		if err := json.NewDecoder(r.Body).Decode(&results); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		name := agentFrom(r)
+		deps.Engine.AgentSeen(name, "", r.RemoteAddr)
+		accepted := 0
+		for _, res := range results {
+			err := deps.Engine.IngestAgentResult(name, res)
+			if errors.Is(err, monitor.ErrNotAssigned) {
+				// The monitor was deleted or reassigned since the agent
+				// last synced; drop the result.
+				continue
+			}
+			if err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+				return
+			}
+			accepted++
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "accepted": accepted})
+	})
+
+	return r
+}
+
+// handleAgents lists the configured agents and when they last reported.
+func (d Deps) handleAgents(w http.ResponseWriter, r *http.Request) {
+	names := make([]string, 0, len(d.Config.Agents))
+	for _, a := range d.Config.Agents {
+		names = append(names, a.Name)
+	}
+	writeJSON(w, http.StatusOK, d.Engine.AgentStatuses(names))
+}
+
+func (d Deps) authenticateAgent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := requestToken(r)
+		name := ""
+		for _, a := range d.Config.Agents {
+			if a.Name != "" && a.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.Token)) == 1 {
+				name = a.Name
+			}
+		}
+		if name == "" {
+			writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "unknown agent token"})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), agentKey{}, name)))
+	})
+}
+
+func agentFrom(r *http.Request) string {
+	name, _ := r.Context().Value(agentKey{}).(string)
+	return name
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/lsy88/uptime-chopper/internal/auth"
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+const sessionCookie = "uptime_chopper_session"
+
+// principal identifies who made a request. APIKey is set for requests
+// authenticated with a configured API key rather than a user session.
+type principal struct {
+	User   *model.User
+	APIKey bool
+}
+
+type principalKey struct{}
+
+func principalFrom(ctx context.Context) *principal {
+	p, _ := ctx.Value(principalKey{}).(*principal)
+	return p
+}
+
+// authenticate accepts either a configured API key or a user session token,
+// sent as "Authorization: Bearer <token>", in the X-API-Key header, or in the
+// session cookie. Authentication is only enforced once API keys are
+// configured or at least one user account exists.
+func (d Deps) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions || pathExempt(r.URL.Path, d.Config.AuthExemptPaths) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		p := d.resolvePrincipal(r)
+		if p == nil {
+			if len(d.Config.APIKeys) == 0 && len(d.Store.GetUsers()) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="uptime-chopper"`)
+			writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "unauthorized"})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, p)))
+	})
+}
+
+// role returns the caller's effective role. API keys grant admin.
+func (p *principal) role() model.Role {
+	if p.APIKey {
+		return model.RoleAdmin
+	}
+	return p.User.EffectiveRole()
+}
+
+// requireRole rejects authenticated callers whose role is below min.
+// Requests without a principal only get here when authentication is
+// disabled or the path is exempt, so they are let through.
+func requireRole(min model.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if p := principalFrom(r.Context()); p != nil && !p.role().Allows(min) {
+				writeJSON(w, http.StatusForbidden, map[string]any{"error": "forbidden: requires " + string(min) + " role"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// authorize applies the default permission model by HTTP method: reads need
+// viewer, changes need operator, and deletes need admin.
+func authorize(next http.Handler) http.Handler {
+	viewer := requireRole(model.RoleViewer)(next)
+	operator := requireRole(model.RoleOperator)(next)
+	admin := requireRole(model.RoleAdmin)(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			viewer.ServeHTTP(w, r)
+		case http.MethodDelete:
+			admin.ServeHTTP(w, r)
+		default:
+			operator.ServeHTTP(w, r)
+		}
+	})
+}
+
+// guardReveal only lets admins ask for unmasked credentials with
+// ?reveal=true; everyone else gets 403.
+func guardReveal(next http.Handler) http.Handler {
+	admin := requireRole(model.RoleAdmin)(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if revealing(r) {
+			admin.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// revealing reports whether a response behind guardReveal carries
+// credentials unmasked.
+func revealing(r *http.Request) bool {
+	return r.URL.Query().Get("reveal") == "true"
+}
+
+func (d Deps) resolvePrincipal(r *http.Request) *principal {
+	token := requestToken(r)
+	if token == "" {
+		return nil
+	}
+	if validAPIKey(token, d.Config.APIKeys) {
+		return &principal{APIKey: true}
+	}
+	sess, ok := d.Store.GetSession(auth.HashToken(token))
+	if !ok {
+		return nil
+	}
+	for _, u := range d.Store.GetUsers() {
+		if u.ID == sess.UserID {
+			v := u
+			return &principal{User: &v}
+		}
+	}
+	return nil
+}
+
+func requestToken(r *http.Request) string {
+	if v := r.Header.Get("X-API-Key"); v != "" {
+		return v
+	}
+	h := r.Header.Get("Authorization")
+	if len(h) > 7 && strings.EqualFold(h[:7], "bearer ") {
+		return strings.TrimSpace(h[7:])
+	}
+	if c, err := r.Cookie(sessionCookie); err == nil {
+		return c.Value
+	}
+	return ""
+}
+
+func validAPIKey(got string, keys []string) bool {
+	if got == "" {
+		return false
+	}
+	ok := false
+	for _, k := range keys {
+		if k != "" && subtle.ConstantTimeCompare([]byte(got), []byte(k)) == 1 {
+			ok = true
+		}
+	}
+	return ok
+}
+
+func pathExempt(path string, exempt []string) bool {
+	for _, p := range exempt {
+		if prefix, ok := strings.CutSuffix(p, "/*"); ok {
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if path == p {
+			return true
+		}
+	}
+	return false
+}
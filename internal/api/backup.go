@@ -0,0 +1,320 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/monitor"
+)
+
+// handleBackup returns every monitor, notification channel, routing policy,
+// status page and maintenance window and the runtime settings as a single
+// JSON document, plus the persisted history of each monitor with
+// ?history=true.
+func (deps Deps) handleBackup(w http.ResponseWriter, r *http.Request) {
+	st := deps.Store.GetState()
+	set := deps.Engine.Settings()
+	b := model.Backup{
+		Version:            model.BackupVersion,
+		CreatedAt:          time.Now().UTC(),
+		Monitors:           st.Monitors,
+		Notifications:      deps.Store.GetNotifications(),
+		RoutingPolicies:    deps.Store.GetRoutingPolicies(),
+		StatusPages:        deps.Store.GetStatusPages(),
+		MaintenanceWindows: deps.Store.GetMaintenanceWindows(),
+		Settings:           &set,
+	}
+	if r.URL.Query().Get("history") == "true" {
+		b.History = map[string][]model.MonitorHistoryEntry{}
+		for _, m := range st.Monitors {
+			entries := []model.MonitorHistoryEntry{}
+			err := deps.Store.ExportMonitorHistory(m.ID, time.Time{}, time.Time{}, func(e model.MonitorHistoryEntry) error {
+				entries = append(entries, e)
+				return nil
+			})
+			if err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+				return
+			}
+			b.History[m.ID] = entries
+		}
+	}
+	filename := fmt.Sprintf("uptime-chopper-backup-%s.json", b.CreatedAt.Format("20060102-150405"))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	writeJSON(w, http.StatusOK, b)
+}
+
+// backupDocument is a model.Backup whose items are kept raw, so that merge
+// can tell which fields the backup actually sets.
+type backupDocument struct {
+	Version            int                                    `json:"version"`
+	Monitors           []json.RawMessage                      `json:"monitors"`
+	Notifications      []json.RawMessage                      `json:"notifications"`
+	RoutingPolicies    []json.RawMessage                      `json:"routingPolicies"`
+	StatusPages        []json.RawMessage                      `json:"statusPages"`
+	MaintenanceWindows []json.RawMessage                      `json:"maintenanceWindows"`
+	Settings           json.RawMessage                        `json:"settings"`
+	History            map[string][]model.MonitorHistoryEntry `json:"history"`
+}
+
+// handleRestore imports a backup produced by handleBackup. Items are matched
+// by ID and conflicts are resolved with ?strategy=skip (default), overwrite
+// or merge; saved settings count as existing. Settings are restored first
+// so that monitors get their defaults, then notification channels and
+// routing policies so that monitors can reference them, and maintenance
+// windows last so that their monitors exist. Restored items do not send
+// lifecycle notifications.
+func (deps Deps) handleRestore(w http.ResponseWriter, r *http.Request) {
+	strategy := model.RestoreStrategy(r.URL.Query().Get("strategy"))
+	if strategy == "" {
+		strategy = model.RestoreSkip
+	}
+	if strategy != model.RestoreSkip && strategy != model.RestoreOverwrite && strategy != model.RestoreMerge {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "strategy must be one of skip, overwrite, merge"})
+		return
+	}
+	var doc backupDocument
+	if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	}
+	if doc.Version < 1 || doc.Version > model.BackupVersion {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": fmt.Sprintf("unsupported backup version %d", doc.Version)})
+		return
+	}
+
+	res := model.RestoreResult{Strategy: strategy, Errors: []string{}}
+	rs := restorer{strategy: strategy, res: &res}
+
+	if len(doc.Settings) > 0 && string(doc.Settings) != "null" {
+		_, exists := deps.Store.GetSettings()
+		if rs.begin(&res.Settings, "settings", "settings", exists) {
+			set := deps.Config.Settings()
+			if rs.merging(exists) {
+				set = deps.Engine.Settings()
+			}
+			err := json.Unmarshal(doc.Settings, &set)
+			if err == nil {
+				err = validateSettings(deps, set)
+			}
+			if err == nil {
+				set, err = deps.Store.SaveSettings(set)
+			}
+			if err == nil {
+				deps.Engine.ApplySettings(set)
+			}
+			rs.end(&res.Settings, "settings", "settings", exists, err)
+		}
+	}
+
+	notifications := map[string]model.Notification{}
+	for _, n := range deps.Store.GetNotifications() {
+		notifications[n.ID] = n
+	}
+	for _, raw := range doc.Notifications {
+		id := backupItemID(raw)
+		cur, exists := notifications[id]
+		if !rs.begin(&res.Notifications, "notification", id, exists) {
+			continue
+		}
+		var n model.Notification
+		if rs.merging(exists) {
+			n = cur
+		}
+		if err := json.Unmarshal(raw, &n); err != nil {
+			rs.fail(&res.Notifications, "notification", id, err)
+			continue
+		}
+		n.ID = id
+		err := validateNotification(n)
+		if err == nil {
+			_, err = deps.Store.UpsertNotification(n)
+		}
+		rs.end(&res.Notifications, "notification", id, exists, err)
+	}
+
+	policies := map[string]model.RoutingPolicy{}
+	for _, p := range deps.Store.GetRoutingPolicies() {
+		policies[p.ID] = p
+	}
+	for _, raw := range doc.RoutingPolicies {
+		id := backupItemID(raw)
+		cur, exists := policies[id]
+		if !rs.begin(&res.RoutingPolicies, "routing policy", id, exists) {
+			continue
+		}
+		var p model.RoutingPolicy
+		if rs.merging(exists) {
+			p = cur
+		}
+		if err := json.Unmarshal(raw, &p); err != nil {
+			rs.fail(&res.RoutingPolicies, "routing policy", id, err)
+			continue
+		}
+		p.ID = id
+		_, err := deps.Store.UpsertRoutingPolicy(p)
+		rs.end(&res.RoutingPolicies, "routing policy", id, exists, err)
+	}
+
+	skippedMonitors := map[string]bool{}
+	for _, raw := range doc.Monitors {
+		id := backupItemID(raw)
+		cur := findMonitor(deps, id)
+		exists := cur != nil
+		if !rs.begin(&res.Monitors, "monitor", id, exists) {
+			skippedMonitors[id] = true
+			continue
+		}
+		var m model.Monitor
+		if rs.merging(exists) {
+			m = *cur
+		}
+		if err := json.Unmarshal(raw, &m); err != nil {
+			rs.fail(&res.Monitors, "monitor", id, err)
+			skippedMonitors[id] = true
+			continue
+		}
+		m.ID = id
+		m.Provisioned = false
+		m = normalizeMonitor(m, deps.Engine.Settings())
+		err := validateMonitor(deps, m)
+		if err == nil && exists && cur.Provisioned {
+			err = errProvisioned
+		}
+		if err == nil {
+			_, err = deps.Store.UpsertMonitor(m)
+		}
+		if err != nil {
+			skippedMonitors[id] = true
+		}
+		rs.end(&res.Monitors, "monitor", id, exists, err)
+	}
+
+	for _, raw := range doc.StatusPages {
+		id := backupItemID(raw)
+		cur := findStatusPage(deps, func(sp model.StatusPage) bool { return sp.ID == id })
+		exists := cur != nil
+		if !rs.begin(&res.StatusPages, "status page", id, exists) {
+			continue
+		}
+		var p model.StatusPage
+		if rs.merging(exists) {
+			p = *cur
+		}
+		if err := json.Unmarshal(raw, &p); err != nil {
+			rs.fail(&res.StatusPages, "status page", id, err)
+			continue
+		}
+		p.ID = id
+		err := validateStatusPage(deps, p)
+		if err == nil {
+			if p.Secret == "" {
+				p.Secret = monitor.NewID()
+			}
+			_, err = deps.Store.UpsertStatusPage(p)
+		}
+		rs.end(&res.StatusPages, "status page", id, exists, err)
+	}
+
+	windows := map[string]model.MaintenanceWindow{}
+	for _, mw := range deps.Store.GetMaintenanceWindows() {
+		windows[mw.ID] = mw
+	}
+	for _, raw := range doc.MaintenanceWindows {
+		id := backupItemID(raw)
+		cur, exists := windows[id]
+		if !rs.begin(&res.MaintenanceWindows, "maintenance window", id, exists) {
+			continue
+		}
+		var mw model.MaintenanceWindow
+		if rs.merging(exists) {
+			mw = cur
+		}
+		if err := json.Unmarshal(raw, &mw); err != nil {
+			rs.fail(&res.MaintenanceWindows, "maintenance window", id, err)
+			continue
+		}
+		mw.ID = id
+		err := mw.Validate()
+		for _, mid := range mw.MonitorIDs {
+			if err == nil && findMonitor(deps, mid) == nil {
+				err = errors.New("monitor not found: " + mid)
+			}
+		}
+		if err == nil {
+			_, err = deps.Store.UpsertMaintenanceWindow(mw)
+		}
+		rs.end(&res.MaintenanceWindows, "maintenance window", id, exists, err)
+	}
+
+	// History is only imported for monitors restored above; imports skip
+	// entries that already exist, so restoring twice is harmless.
+	for id, entries := range doc.History {
+		m := findMonitor(deps, id)
+		if m == nil || skippedMonitors[id] {
+			continue
+		}
+		out, err := deps.Store.ImportMonitorHistory(id, entries, m.HistoryRetention(deps.Engine.Settings().HistoryRetentionDays))
+		if err != nil {
+			res.Errors = append(res.Errors, fmt.Sprintf("history of monitor %s: %v", id, err))
+			continue
+		}
+		res.HistoryImported += out.Imported
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+// restorer applies the conflict strategy and keeps the counts of a restore.
+type restorer struct {
+	strategy model.RestoreStrategy
+	res      *model.RestoreResult
+}
+
+// begin reports whether an item should be restored, counting it as skipped
+// or failed otherwise.
+func (rs restorer) begin(c *model.RestoreCounts, kind, id string, exists bool) bool {
+	if id == "" {
+		rs.fail(c, kind, id, errors.New("missing id"))
+		return false
+	}
+	if exists && rs.strategy == model.RestoreSkip {
+		c.Skipped++
+		return false
+	}
+	return true
+}
+
+// merging reports whether an existing item is the base the backed-up one
+// is decoded onto.
+func (rs restorer) merging(exists bool) bool {
+	return exists && rs.strategy == model.RestoreMerge
+}
+
+func (rs restorer) end(c *model.RestoreCounts, kind, id string, exists bool, err error) {
+	switch {
+	case err != nil:
+		rs.fail(c, kind, id, err)
+	case exists:
+		c.Updated++
+	default:
+		c.Created++
+	}
+}
+
+func (rs restorer) fail(c *model.RestoreCounts, kind, id string, err error) {
+	c.Failed++
+	rs.res.Errors = append(rs.res.Errors, fmt.Sprintf("%s %q: %v", kind, id, err))
+}
+
+func backupItemID(raw json.RawMessage) string {
+	var item struct {
+		ID string `json:"id"`
+	}
+	_ = json.Unmarshal(raw, &item)
+	return item.ID
+}
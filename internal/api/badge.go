@@ -0,0 +1,135 @@
+package api
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+const (
+	badgeGreen  = "#4c1"
+	badgeYellow = "#dfb317"
+	badgeOrange = "#fe7d37"
+	badgeRed    = "#e05d44"
+	badgeGrey   = "#9f9f9f"
+)
+
+// Badges are served as SVG by default, or in the shields.io endpoint JSON
+// schema with ?format=shields so they can be wrapped by img.shields.io/endpoint.
+func badgeRouter(deps Deps) http.Handler {
+	r := chi.NewRouter()
+	r.Get("/{monitorId}/status", func(w http.ResponseWriter, r *http.Request) {
+		m := findMonitor(deps, chi.URLParam(r, "monitorId"))
+		if m == nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "monitor not found"})
+			return
+		}
+		status := model.StatusUnknown
+		if info, ok := deps.Engine.StatusSnapshot()[m.ID]; ok {
+			status = info.Status
+		}
+		if m.IsPaused {
+			status = model.StatusPaused
+		}
+		color := badgeGrey
+		switch status {
+		case model.StatusUp:
+			color = badgeGreen
+		case model.StatusDegraded:
+			color = badgeYellow
+		case model.StatusDown, model.StatusDockerUnreachable, model.StatusCrashLoop:
+			color = badgeRed
+		}
+		writeBadge(w, r, badgeLabel(r, "status"), string(status), color)
+	})
+	r.Get("/{monitorId}/uptime", func(w http.ResponseWriter, r *http.Request) {
+		m := findMonitor(deps, chi.URLParam(r, "monitorId"))
+		if m == nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "monitor not found"})
+			return
+		}
+		window := r.URL.Query().Get("window")
+		if window == "" {
+			window = "24h"
+		}
+		d, ok := statsWindows[window]
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "window must be one of 24h, 7d, 30d"})
+			return
+		}
+		stats, err := deps.Store.GetMonitorStats(m.ID, time.Now().UTC().Add(-d))
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		message, color := "no data", badgeGrey
+		if stats.TotalChecks > 0 {
+			message = strconv.FormatFloat(stats.UptimePercent, 'f', 2, 64) + "%"
+			switch {
+			case stats.UptimePercent >= 99:
+				color = badgeGreen
+			case stats.UptimePercent >= 95:
+				color = badgeYellow
+			case stats.UptimePercent >= 90:
+				color = badgeOrange
+			default:
+				color = badgeRed
+			}
+		}
+		writeBadge(w, r, badgeLabel(r, "uptime "+window), message, color)
+	})
+	return r
+}
+
+func badgeLabel(r *http.Request, def string) string {
+	if v := r.URL.Query().Get("label"); v != "" {
+		return v
+	}
+	return def
+}
+
+func writeBadge(w http.ResponseWriter, r *http.Request, label, message, color string) {
+	w.Header().Set("Cache-Control", "no-cache, max-age=0")
+	if r.URL.Query().Get("format") == "shields" {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"schemaVersion": 1,
+			"label":         label,
+			"message":       message,
+			"color":         color,
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "image/svg+xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(renderBadge(label, message, color)))
+}
+
+// renderBadge draws a flat shields-style badge. Text width is approximated
+// from the character count, which is close enough for Verdana 11px.
+func renderBadge(label, message, color string) string {
+	lw := badgeTextWidth(label)
+	mw := badgeTextWidth(message)
+	total := lw + mw
+	label = html.EscapeString(label)
+	message = html.EscapeString(message)
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[2]s: %[3]s">`+
+		`<title>%[2]s: %[3]s</title>`+
+		`<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`+
+		`<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>`+
+		`<g clip-path="url(#r)"><rect width="%[4]d" height="20" fill="#555"/><rect x="%[4]d" width="%[5]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>`+
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+
+		`<text x="%[7]d" y="15" fill="#010101" fill-opacity=".3">%[2]s</text><text x="%[7]d" y="14">%[2]s</text>`+
+		`<text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[3]s</text><text x="%[8]d" y="14">%[3]s</text>`+
+		`</g></svg>`,
+		total, label, message, lw, mw, color, lw/2, lw+mw/2)
+}
+
+func badgeTextWidth(s string) int {
+	return len([]rune(s))*7 + 10
+}
@@ -1,32 +1,162 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
 
+	"github.com/lsy88/uptime-chopper/internal/docker"
 	"github.com/lsy88/uptime-chopper/internal/model"
 )
 
 func containersRouter(deps Deps) http.Handler {
 	r := chi.NewRouter()
 
+	// The list is filtered by the daemon with ?state= (comma-separated),
+	// ?health=, ?image=, ?label= (repeatable, key or key=value) and ?name=,
+	// then sorted with ?sort= and paged with ?limit= and ?offset=. The
+	// X-Total-Count header holds the number of matches before paging.
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
-		cs, err := deps.Docker.ListContainers(ctx)
+		q := r.URL.Query()
+		less, ok := containerOrder(q.Get("sort"))
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "sort must be one of name, image, state, created, restarts, optionally prefixed with -"})
+			return
+		}
+		dc, ok := dockerClient(w, r, deps)
+		if !ok {
+			return
+		}
+		f := docker.ContainerFilter{
+			Health: q.Get("health"),
+			Image:  q.Get("image"),
+			Labels: q["label"],
+			Name:   q.Get("name"),
+		}
+		for _, st := range strings.Split(q.Get("state"), ",") {
+			if st = strings.TrimSpace(st); st != "" {
+				f.States = append(f.States, st)
+			}
+		}
+		cs, err := dc.FindContainers(r.Context(), f)
 		if err != nil {
 			writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": err.Error()})
 			return
 		}
+		dc.FillRestartCounts(r.Context(), cs)
+		if less != nil {
+			sort.SliceStable(cs, func(i, j int) bool { return less(cs[i], cs[j]) })
+		}
+		w.Header().Set("X-Total-Count", strconv.Itoa(len(cs)))
+		cs = page(cs, q)
+		host := q.Get("host")
+		stats := deps.Engine.ContainerStats(host)
+		updates := deps.Engine.ImageUpdates(host)
+		for i := range cs {
+			if s, ok := stats[cs[i].ID]; ok {
+				cs[i].Stats = &s
+			}
+			if u, ok := updates[cs[i].ID]; ok {
+				cs[i].Update = &u
+			}
+		}
 		writeJSON(w, http.StatusOK, cs)
 	})
 
+	r.Get("/hosts", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, deps.Docker.List())
+	})
+
+	r.Get("/updates", func(w http.ResponseWriter, r *http.Request) {
+		updates := deps.Engine.ImageUpdates(r.URL.Query().Get("host"))
+		out := make([]docker.ImageUpdate, 0, len(updates))
+		for _, u := range updates {
+			out = append(out, u)
+		}
+		sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+		writeJSON(w, http.StatusOK, out)
+	})
+
+	r.Post("/{id}/update", func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		to := bodyTimeout(r)
+		dc, ok := dockerClient(w, r, deps)
+		if !ok {
+			return
+		}
+		newID, err := dc.UpdateImage(r.Context(), id, to)
+		if err != nil {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": err.Error()})
+			return
+		}
+		deps.Engine.ForgetImageUpdate(r.URL.Query().Get("host"), id)
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "containerId": newID})
+	})
+
+	r.Get("/projects", func(w http.ResponseWriter, r *http.Request) {
+		dc, ok := dockerClient(w, r, deps)
+		if !ok {
+			return
+		}
+		cs, err := dc.ListContainers(r.Context())
+		if err != nil {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, docker.GroupByProject(cs))
+	})
+
+	r.Post("/projects/{project}/start", func(w http.ResponseWriter, r *http.Request) {
+		projectAction(w, r, deps, func(ctx context.Context, dc *docker.Client, id string) error {
+			return dc.Start(ctx, id)
+		})
+	})
+
+	r.Post("/projects/{project}/stop", func(w http.ResponseWriter, r *http.Request) {
+		to := bodyTimeout(r)
+		projectAction(w, r, deps, func(ctx context.Context, dc *docker.Client, id string) error {
+			return dc.Stop(ctx, id, to)
+		})
+	})
+
+	r.Post("/projects/{project}/restart", func(w http.ResponseWriter, r *http.Request) {
+		to := bodyTimeout(r)
+		projectAction(w, r, deps, func(ctx context.Context, dc *docker.Client, id string) error {
+			return dc.Restart(ctx, id, to)
+		})
+	})
+
+	r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		dc, ok := dockerClient(w, r, deps)
+		if !ok {
+			return
+		}
+		d, err := dc.Inspect(r.Context(), chi.URLParam(r, "id"))
+		if errors.Is(err, docker.ErrContainerNotFound) {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, d)
+	})
+
 	r.Get("/{id}/logs", func(w http.ResponseWriter, r *http.Request) {
 		id := chi.URLParam(r, "id")
 		tail := r.URL.Query().Get("tail")
@@ -41,26 +171,116 @@ func containersRouter(deps Deps) http.Handler {
 		}
 		since := time.Now().Add(-time.Duration(sinceSec) * time.Second)
 
-		rc, err := deps.Docker.Logs(r.Context(), id, tail, since)
+		filter := logFilter{grep: r.URL.Query().Get("grep")}
+		if v := r.URL.Query().Get("regex"); v != "" {
+			re, err := regexp.Compile(v)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid regex: " + err.Error()})
+				return
+			}
+			filter.regex = re
+		}
+		if v := r.URL.Query().Get("level"); v != "" {
+			filter.minLevel = parseLogLevel(v)
+			if filter.minLevel == 0 {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"error": "unknown log level: " + v})
+				return
+			}
+		}
+
+		dc, ok := dockerClient(w, r, deps)
+		if !ok {
+			return
+		}
+		rc, err := dc.Logs(r.Context(), id, docker.LogsOptions{
+			Tail:       tail,
+			Since:      since,
+			Stdout:     r.URL.Query().Get("stdout") != "false",
+			Stderr:     r.URL.Query().Get("stderr") != "false",
+			Timestamps: r.URL.Query().Get("timestamps") != "false",
+		})
 		if err != nil {
 			writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": err.Error()})
 			return
 		}
 		defer rc.Close()
 
+		maxBytes := deps.Engine.Settings().MaxDockerLogBytes
+		if filter.active() {
+			pr, pw := io.Pipe()
+			go func() {
+				_, err := stdcopy.StdCopy(pw, pw, rc)
+				_ = pw.CloseWithError(err)
+			}()
+			res := filterLogLines(pr, filter, maxBytes)
+			_ = pr.Close()
+			writeJSON(w, http.StatusOK, res)
+			return
+		}
+
 		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-		_, _ = writeDockerLogsAtMost(w, rc, deps.Config.MaxDockerLogBytes, stdcopy.StdCopy)
+		_, _ = writeDockerLogsAtMost(w, rc, maxBytes, stdcopy.StdCopy)
 	})
 
 	r.Post("/{id}/start", func(w http.ResponseWriter, r *http.Request) {
 		id := chi.URLParam(r, "id")
-		if err := deps.Docker.Start(r.Context(), id); err != nil {
+		dc, ok := dockerClient(w, r, deps)
+		if !ok {
+			return
+		}
+		if err := dc.Start(r.Context(), id); err != nil {
 			writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": err.Error()})
 			return
 		}
 		writeJSON(w, http.StatusOK, map[string]any{"ok": true})
 	})
 
+	// exec runs one of the commands allowed by container_exec_commands for
+	// diagnostics, e.g. `nginx -t`, and returns its exit code and the tail
+	// of its output.
+	r.Post("/{id}/exec", func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		var body struct {
+			Command        []string `json:"command"`
+			TimeoutSeconds int      `json:"timeoutSeconds"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		allowed := deps.Config.ContainerExecCommands
+		if len(allowed) == 0 {
+			writeJSON(w, http.StatusForbidden, map[string]any{"error": "container exec is disabled; list the allowed commands in container_exec_commands"})
+			return
+		}
+		if !execAllowed(allowed, body.Command) {
+			writeJSON(w, http.StatusForbidden, map[string]any{"error": "command is not in container_exec_commands"})
+			return
+		}
+		to := 10 * time.Second
+		if body.TimeoutSeconds > 0 {
+			to = time.Duration(min(body.TimeoutSeconds, 60)) * time.Second
+		}
+		dc, ok := dockerClient(w, r, deps)
+		if !ok {
+			return
+		}
+		deps.Logger.Info("container exec", zap.String("container", id), zap.Strings("command", body.Command), zap.String("by", actorName(r)))
+		ctx, cancel := context.WithTimeout(r.Context(), to)
+		defer cancel()
+		res, err := dc.Exec(ctx, id, body.Command)
+		switch {
+		case errors.Is(err, docker.ErrContainerNotFound):
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+		case errors.Is(err, context.DeadlineExceeded):
+			writeJSON(w, http.StatusGatewayTimeout, map[string]any{"error": "command timed out after " + to.String(), "output": res.Output})
+		case err != nil:
+			writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": err.Error()})
+		default:
+			writeJSON(w, http.StatusOK, res)
+		}
+	})
+
 	r.Post("/{id}/stop", func(w http.ResponseWriter, r *http.Request) {
 		id := chi.URLParam(r, "id")
 		var body struct {
@@ -68,7 +288,11 @@ func containersRouter(deps Deps) http.Handler {
 		}
 		_ = json.NewDecoder(r.Body).Decode(&body)
 		to := time.Duration(maxInt(1, body.TimeoutSeconds)) * time.Second
-		if err := deps.Docker.Stop(r.Context(), id, to); err != nil {
+		dc, ok := dockerClient(w, r, deps)
+		if !ok {
+			return
+		}
+		if err := dc.Stop(r.Context(), id, to); err != nil {
 			writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": err.Error()})
 			return
 		}
@@ -82,7 +306,11 @@ func containersRouter(deps Deps) http.Handler {
 		}
 		_ = json.NewDecoder(r.Body).Decode(&body)
 		to := time.Duration(maxInt(1, body.TimeoutSeconds)) * time.Second
-		if err := deps.Docker.Restart(r.Context(), id, to); err != nil {
+		dc, ok := dockerClient(w, r, deps)
+		if !ok {
+			return
+		}
+		if err := dc.Restart(r.Context(), id, to); err != nil {
 			writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": err.Error()})
 			return
 		}
@@ -96,7 +324,11 @@ func containersRouter(deps Deps) http.Handler {
 			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
 			return
 		}
-		if err := deps.Docker.UpdateRestartPolicy(r.Context(), id, container.RestartPolicy{
+		dc, ok := dockerClient(w, r, deps)
+		if !ok {
+			return
+		}
+		if err := dc.UpdateRestartPolicy(r.Context(), id, container.RestartPolicy{
 			Name:              container.RestartPolicyMode(body.Name),
 			MaximumRetryCount: body.MaximumRetryCount,
 		}); err != nil {
@@ -109,6 +341,68 @@ func containersRouter(deps Deps) http.Handler {
 	return r
 }
 
+// dockerClient returns the client for the ?host= query parameter, the local
+// daemon when it is absent.
+func dockerClient(w http.ResponseWriter, r *http.Request, deps Deps) (*docker.Client, bool) {
+	dc, err := deps.Docker.Get(r.URL.Query().Get("host"))
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+		return nil, false
+	}
+	return dc, true
+}
+
+// projectAction applies fn to every container of the {project} URL
+// parameter. It carries on past failures and reports them per container.
+func projectAction(w http.ResponseWriter, r *http.Request, deps Deps, fn func(context.Context, *docker.Client, string) error) {
+	project := chi.URLParam(r, "project")
+	dc, ok := dockerClient(w, r, deps)
+	if !ok {
+		return
+	}
+	cs, err := dc.ProjectContainers(r.Context(), project)
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": err.Error()})
+		return
+	}
+	if len(cs) == 0 {
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": "compose project not found"})
+		return
+	}
+	failed := map[string]string{}
+	for _, c := range cs {
+		if err := fn(r.Context(), dc, c.ID); err != nil {
+			failed[c.Name] = err.Error()
+		}
+	}
+	if len(failed) > 0 {
+		writeJSON(w, http.StatusBadGateway, map[string]any{"error": "some containers failed", "containers": len(cs), "failed": failed})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "containers": len(cs)})
+}
+
+// execAllowed reports whether cmd equals one of the allowed command lines
+// split on whitespace.
+func execAllowed(allowed, cmd []string) bool {
+	for _, a := range allowed {
+		if f := strings.Fields(a); len(f) > 0 && slices.Equal(f, cmd) {
+			return true
+		}
+	}
+	return false
+}
+
+// bodyTimeout reads the optional {"timeoutSeconds": n} body of stop and
+// restart requests.
+func bodyTimeout(r *http.Request) time.Duration {
+	var body struct {
+		TimeoutSeconds int `json:"timeoutSeconds"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	return time.Duration(maxInt(1, body.TimeoutSeconds)) * time.Second
+}
+
 type stdCopyFn func(dstout io.Writer, dsterr io.Writer, src io.Reader) (written int64, err error)
 
 func writeDockerLogsAtMost(w io.Writer, src io.Reader, maxBytes int, stdCopy stdCopyFn) (int64, bool) {
@@ -117,3 +411,47 @@ func writeDockerLogsAtMost(w io.Writer, src io.Reader, maxBytes int, stdCopy std
 	_, _ = w.Write(lw.Bytes())
 	return int64(len(lw.Bytes())), lw.Truncated()
 }
+
+// containerOrder returns the comparison for a ?sort= value, nil to keep the
+// daemon's order (newest first), and false for an unknown key. A leading
+// "-" reverses the order.
+func containerOrder(key string) (func(a, b docker.ContainerSummary) bool, bool) {
+	desc := strings.HasPrefix(key, "-")
+	var less func(a, b docker.ContainerSummary) bool
+	switch strings.TrimPrefix(key, "-") {
+	case "":
+		return nil, true
+	case "name":
+		less = func(a, b docker.ContainerSummary) bool { return a.Name < b.Name }
+	case "image":
+		less = func(a, b docker.ContainerSummary) bool { return a.Image < b.Image }
+	case "state":
+		less = func(a, b docker.ContainerSummary) bool { return a.State < b.State }
+	case "created":
+		less = func(a, b docker.ContainerSummary) bool { return a.Created.Before(b.Created) }
+	case "restarts":
+		less = func(a, b docker.ContainerSummary) bool { return a.RestartCount < b.RestartCount }
+	default:
+		return nil, false
+	}
+	if desc {
+		return func(a, b docker.ContainerSummary) bool { return less(b, a) }, true
+	}
+	return less, true
+}
+
+// page applies ?offset= and ?limit= to items; without a limit it returns
+// everything after the offset.
+func page[T any](items []T, q url.Values) []T {
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	if offset > 0 {
+		if offset >= len(items) {
+			return items[:0]
+		}
+		items = items[offset:]
+	}
+	if limit, err := strconv.Atoi(q.Get("limit")); err == nil && limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
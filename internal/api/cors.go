@@ -2,19 +2,41 @@ package api
 
 import (
 	"net/http"
+	"sync/atomic"
 )
 
-func cors(allowedOrigin string) func(http.Handler) http.Handler {
+// CORSOrigin is the origin allowed to call the API from a browser, "*" for
+// any. It can be changed while the server runs.
+type CORSOrigin struct {
+	v atomic.Value
+}
+
+func NewCORSOrigin(origin string) *CORSOrigin {
+	c := &CORSOrigin{}
+	c.Set(origin)
+	return c
+}
+
+func (c *CORSOrigin) Set(origin string) {
+	c.v.Store(origin)
+}
+
+func (c *CORSOrigin) Get() string {
+	return c.v.Load().(string)
+}
+
+func cors(origins *CORSOrigin) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			origin := r.Header.Get("Origin")
+			allowedOrigin := origins.Get()
 			if allowedOrigin == "*" {
 				w.Header().Set("Access-Control-Allow-Origin", "*")
 			} else if origin != "" && origin == allowedOrigin {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
 				w.Header().Set("Vary", "Origin")
 			}
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
 			w.Header().Set("Access-Control-Allow-Methods", "GET,POST,PUT,DELETE,OPTIONS")
 
 			if r.Method == http.MethodOptions {
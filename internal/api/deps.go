@@ -2,24 +2,69 @@ package api
 
 import (
 	"net/http"
+	"sort"
 
 	"go.uber.org/zap"
 
 	"github.com/lsy88/uptime-chopper/internal/config"
 	"github.com/lsy88/uptime-chopper/internal/docker"
+	"github.com/lsy88/uptime-chopper/internal/model"
 	"github.com/lsy88/uptime-chopper/internal/monitor"
+	"github.com/lsy88/uptime-chopper/internal/secrets"
 	"github.com/lsy88/uptime-chopper/internal/store"
 )
 
 type Deps struct {
 	Logger *zap.Logger
 	Store  store.Store
-	Docker *docker.Client
+	Docker *docker.Hosts
 	Engine *monitor.Engine
 	Config *config.Config
+	// CORSOrigin overrides Config.AllowedCORSOrigin so that it can be
+	// reloaded; optional.
+	CORSOrigin *CORSOrigin
+	// Secrets encrypts secrets saved through the API; optional.
+	Secrets *secrets.Resolver
 }
 
+// handleStatus returns the live status of each monitor together with the
+// counts by status, the same counts per tag and the newest event of each
+// monitor, so that a dashboard header needs a single request.
 func (d Deps) handleStatus(w http.ResponseWriter, r *http.Request) {
 	status := d.Engine.StatusSnapshot()
-	writeJSON(w, http.StatusOK, map[string]any{"status": status})
+	events, err := d.Store.LatestEvents()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+		return
+	}
+	var summary model.StatusCounts
+	tags := map[string]*model.TagStatus{}
+	lastEvents := map[string]model.Event{}
+	for _, m := range d.Store.GetState().Monitors {
+		s := status[m.ID].Status
+		if m.IsPaused {
+			s = model.StatusPaused
+		}
+		summary.Add(s)
+		for _, t := range m.Tags {
+			if tags[t] == nil {
+				tags[t] = &model.TagStatus{Tag: t}
+			}
+			tags[t].Add(s)
+		}
+		if ev, ok := events[m.ID]; ok {
+			lastEvents[m.ID] = ev
+		}
+	}
+	groups := make([]model.TagStatus, 0, len(tags))
+	for _, g := range tags {
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Tag < groups[j].Tag })
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":     status,
+		"summary":    summary,
+		"groups":     groups,
+		"lastEvents": lastEvents,
+	})
 }
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/lsy88/uptime-chopper/internal/docker"
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+// dockerRouter serves operations on a Docker daemon as a whole, selected
+// with ?host= like the container API.
+func dockerRouter(deps Deps) http.Handler {
+	r := chi.NewRouter()
+	// info reports the daemon's version, object counts, storage driver and
+	// `docker system df` totals.
+	r.Get("/info", func(w http.ResponseWriter, r *http.Request) {
+		dc, ok := dockerClient(w, r, deps)
+		if !ok {
+			return
+		}
+		info, err := dc.Info(r.Context())
+		if err != nil {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, info)
+	})
+	// daemons reports the availability of every configured daemon as
+	// tracked by the engine's periodic ping.
+	r.Get("/daemons", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, deps.Engine.DaemonStatuses())
+	})
+	// prune removes stopped containers, dangling images and unused
+	// networks, plus unused volumes when asked for. An empty body selects
+	// containers, images and networks; ?dryRun=true only reports what
+	// would be removed and how much space that frees.
+	r.With(requireRole(model.RoleAdmin)).Post("/prune", func(w http.ResponseWriter, r *http.Request) {
+		opts := docker.PruneOptions{Containers: true, Images: true, Networks: true}
+		if r.ContentLength != 0 {
+			opts = docker.PruneOptions{}
+			if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+				return
+			}
+		}
+		if r.URL.Query().Get("dryRun") == "true" {
+			opts.DryRun = true
+		}
+		if !opts.Containers && !opts.Images && !opts.Networks && !opts.Volumes && !opts.AllVolumes {
+			var errs validationErrors
+			errs.add("containers", "select at least one of containers, images, networks, volumes")
+			writeInvalid(w, errs)
+			return
+		}
+		dc, ok := dockerClient(w, r, deps)
+		if !ok {
+			return
+		}
+		rep, err := dc.Prune(r.Context(), opts)
+		if err != nil {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": err.Error()})
+			return
+		}
+		if !opts.DryRun {
+			deps.Logger.Info("docker prune", zap.String("host", r.URL.Query().Get("host")), zap.Int("containers", len(rep.Containers)),
+				zap.Int("images", len(rep.Images)), zap.Int("networks", len(rep.Networks)), zap.Int("volumes", len(rep.Volumes)),
+				zap.Uint64("space_reclaimed", rep.SpaceReclaimed), zap.String("by", actorName(r)))
+		}
+		writeJSON(w, http.StatusOK, rep)
+	})
+	return r
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+// monitorETag is a strong validator derived from the stored monitor, so it
+// changes with every update.
+func monitorETag(m model.Monitor) string {
+	b, _ := json.Marshal(m)
+	sum := sha256.Sum256(b)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// writeMonitor responds with m, redacted unless revealing, and its ETag;
+// created responses also carry the monitor's Location. The ETag is that of
+// the stored monitor, so it does not depend on ?reveal.
+func writeMonitor(w http.ResponseWriter, r *http.Request, status int, m model.Monitor) {
+	w.Header().Set("ETag", monitorETag(m))
+	if status == http.StatusCreated {
+		w.Header().Set("Location", "/api/monitors/"+m.ID)
+	}
+	writeJSON(w, status, clientMonitor(r, m))
+}
+
+// clientMonitor returns m as it is sent to the client: redacted unless the
+// request is revealing.
+func clientMonitor(r *http.Request, m model.Monitor) model.Monitor {
+	if revealing(r) {
+		return m
+	}
+	return m.Redacted()
+}
+
+// clientMonitors applies clientMonitor to each of ms.
+func clientMonitors(r *http.Request, ms []model.Monitor) []model.Monitor {
+	out := make([]model.Monitor, len(ms))
+	for i, m := range ms {
+		out[i] = clientMonitor(r, m)
+	}
+	return out
+}
+
+// preconditionFailed evaluates If-Match against the current monitor, nil
+// when it does not exist, and writes 412 when it does not hold.
+func preconditionFailed(w http.ResponseWriter, r *http.Request, cur *model.Monitor) bool {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return false
+	}
+	if cur != nil && etagMatches(header, monitorETag(*cur)) {
+		return false
+	}
+	writeJSON(w, http.StatusPreconditionFailed, map[string]any{"error": "monitor was modified; fetch it again"})
+	return true
+}
+
+// etagMatches reports whether a comma-separated If-Match or If-None-Match
+// header lists etag or is "*".
+func etagMatches(header, etag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v == "*" || v == etag {
+			return true
+		}
+	}
+	return false
+}
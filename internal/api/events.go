@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/store"
+)
+
+// handleEvents pages through the event log, newest first, filtered by
+// monitorId, type and a [from, to) time range.
+func (deps Deps) handleEvents(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := store.EventFilter{
+		MonitorID: q.Get("monitorId"),
+		Type:      model.EventType(q.Get("type")),
+		Limit:     queryLimit(r, 100),
+	}
+	var err error
+	if f.From, err = parseTimeParam(q.Get("from")); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "from: " + err.Error()})
+		return
+	}
+	if f.To, err = parseTimeParam(q.Get("to")); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "to: " + err.Error()})
+		return
+	}
+	if v := q.Get("offset"); v != "" {
+		if f.Offset, err = strconv.Atoi(v); err != nil || f.Offset < 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "offset must be a non-negative integer"})
+			return
+		}
+	}
+	events, total, err := deps.Store.GetEvents(f)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"events": events,
+		"total":  total,
+		"limit":  f.Limit,
+		"offset": f.Offset,
+	})
+}
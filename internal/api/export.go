@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+var historyCSVHeader = []string{"checked_at", "status", "latency_ms", "message", "transient", "conn_mode", "weight", "cpu_percent", "mem_percent", "regions"}
+
+// handleHistoryExport streams a monitor's persisted history as CSV or as a
+// JSON array, oldest first. from and to accept RFC 3339 timestamps or
+// dates and bound the export to [from, to).
+func (deps Deps) handleHistoryExport(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	m := findMonitor(deps, id)
+	if m == nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": "monitor not found"})
+		return
+	}
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "format must be csv or json"})
+		return
+	}
+	from, err := parseTimeParam(q.Get("from"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "from: " + err.Error()})
+		return
+	}
+	to, err := parseTimeParam(q.Get("to"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "to: " + err.Error()})
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && !to.After(from) {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "to must be after from"})
+		return
+	}
+
+	filename := fmt.Sprintf("%s-history.%s", id, format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	// Once the first entry is written the status is sent, so later errors
+	// can only cut the stream short.
+	switch format {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		cw := csv.NewWriter(w)
+		_ = cw.Write(historyCSVHeader)
+		err = deps.Store.ExportMonitorHistory(id, from, to, func(e model.MonitorHistoryEntry) error {
+			return cw.Write(historyCSVRecord(e))
+		})
+		cw.Flush()
+	case "json":
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		enc := json.NewEncoder(w)
+		sep := "["
+		err = deps.Store.ExportMonitorHistory(id, from, to, func(e model.MonitorHistoryEntry) error {
+			if _, err := w.Write([]byte(sep)); err != nil {
+				return err
+			}
+			sep = ","
+			return enc.Encode(e)
+		})
+		if sep == "[" {
+			_, _ = w.Write([]byte(sep))
+		}
+		_, _ = w.Write([]byte("]\n"))
+	}
+	if err != nil {
+		deps.Logger.Warn("history export interrupted", zap.String("monitor_id", id), zap.Error(err))
+	}
+}
+
+func historyCSVRecord(e model.MonitorHistoryEntry) []string {
+	optional := func(v *float64) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'f', -1, 64)
+	}
+	regions := make([]string, 0, len(e.Regions))
+	for _, rr := range e.Regions {
+		regions = append(regions, rr.Region+"="+string(rr.Status))
+	}
+	return []string{
+		e.CheckedAt.UTC().Format(time.RFC3339),
+		string(e.Status),
+		strconv.Itoa(e.LatencyMs),
+		e.Message,
+		strconv.FormatBool(e.Transient),
+		string(e.ConnMode),
+		strconv.Itoa(e.Checks()),
+		optional(e.CPUPercent),
+		optional(e.MemoryPercent),
+		strings.Join(regions, ";"),
+	}
+}
+
+// parseTimeParam accepts an RFC 3339 timestamp or a UTC date; empty yields
+// the zero time.
+func parseTimeParam(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("want RFC 3339 timestamp or YYYY-MM-DD, got %q", v)
+	}
+	return t, nil
+}
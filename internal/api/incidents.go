@@ -0,0 +1,140 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/monitor"
+)
+
+type incidentNote struct {
+	Note string `json:"note"`
+	Text string `json:"text"`
+}
+
+func incidentsRouter(deps Deps) http.Handler {
+	r := chi.NewRouter()
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		writeIncidents(w, deps, q.Get("monitorId"), q.Get("open") == "true", queryLimit(r, 100))
+	})
+
+	r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		inc, ok := deps.Store.GetIncident(chi.URLParam(r, "id"))
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "incident not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, withLiveDuration(inc, time.Now().UTC()))
+	})
+
+	r.Post("/{id}/ack", func(w http.ResponseWriter, r *http.Request) {
+		var body incidentNote
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+				return
+			}
+		}
+		updateIncident(w, r, deps, func(inc *model.Incident, actor string, now time.Time) {
+			acknowledge(inc, actor, body.Note, now)
+		})
+	})
+
+	r.Post("/{id}/comments", func(w http.ResponseWriter, r *http.Request) {
+		var body incidentNote
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		text := strings.TrimSpace(body.Text)
+		if text == "" {
+			var errs validationErrors
+			errs.add("text", "is required")
+			writeInvalid(w, errs)
+			return
+		}
+		updateIncident(w, r, deps, func(inc *model.Incident, actor string, now time.Time) {
+			inc.Comments = append(inc.Comments, model.IncidentComment{Author: actor, Text: text, At: now})
+		})
+	})
+
+	return r
+}
+
+// acknowledge records who is handling the incident; an optional note is
+// added as a comment. While the incident stays open, reminders and
+// escalations for it are muted.
+func acknowledge(inc *model.Incident, actor, note string, now time.Time) {
+	if inc.AckedAt == nil {
+		inc.AckedBy = actor
+		inc.AckedAt = &now
+	}
+	if note = strings.TrimSpace(note); note != "" {
+		inc.Comments = append(inc.Comments, model.IncidentComment{Author: actor, Text: note, At: now})
+	}
+}
+
+func updateIncident(w http.ResponseWriter, r *http.Request, deps Deps, apply func(inc *model.Incident, actor string, now time.Time)) {
+	now := time.Now().UTC()
+	actor := actorName(r)
+	out, err := deps.Engine.UpdateIncident(chi.URLParam(r, "id"), func(inc *model.Incident) {
+		apply(inc, actor, now)
+	})
+	writeIncidentUpdate(w, out, err, now)
+}
+
+// writeIncidentUpdate responds with the incident saved by the engine.
+func writeIncidentUpdate(w http.ResponseWriter, inc model.Incident, err error, now time.Time) {
+	switch {
+	case errors.Is(err, monitor.ErrIncidentNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+	case errors.Is(err, monitor.ErrNoOpenIncident):
+		writeJSON(w, http.StatusConflict, map[string]any{"error": err.Error()})
+	case err != nil:
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+	default:
+		writeJSON(w, http.StatusOK, withLiveDuration(inc, now))
+	}
+}
+
+func writeIncidents(w http.ResponseWriter, deps Deps, monitorID string, openOnly bool, limit int) {
+	incidents, err := deps.Store.GetIncidents(monitorID, openOnly, limit)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+		return
+	}
+	now := time.Now().UTC()
+	for i := range incidents {
+		incidents[i] = withLiveDuration(incidents[i], now)
+	}
+	writeJSON(w, http.StatusOK, incidents)
+}
+
+// withLiveDuration fills in the running duration of an open incident.
+func withLiveDuration(inc model.Incident, now time.Time) model.Incident {
+	if inc.Open() {
+		inc.DurationSec = int64(inc.Duration(now) / time.Second)
+	}
+	return inc
+}
+
+// actorName identifies the caller for audit fields: the username of a
+// session, "api-key" for API key requests, or empty when auth is disabled.
+func actorName(r *http.Request) string {
+	p := principalFrom(r.Context())
+	switch {
+	case p == nil:
+		return ""
+	case p.APIKey:
+		return "api-key"
+	default:
+		return p.User.Username
+	}
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+type logFilter struct {
+	grep     string
+	regex    *regexp.Regexp
+	minLevel int
+}
+
+type logFilterResult struct {
+	Lines     []string `json:"lines"`
+	Matched   int      `json:"matched"`
+	Scanned   int      `json:"scanned"`
+	Truncated bool     `json:"truncated"`
+}
+
+var logLevels = []struct {
+	rank  int
+	names []string
+}{
+	{1, []string{"trace", "debug", "dbug"}},
+	{2, []string{"info", "notice"}},
+	{3, []string{"warn", "warning"}},
+	{4, []string{"error", "err", "eror"}},
+	{5, []string{"fatal", "panic", "crit", "critical"}},
+}
+
+var logLevelTokenRe = regexp.MustCompile(`[A-Za-z]+`)
+
+func parseLogLevel(s string) int {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, l := range logLevels {
+		for _, n := range l.names {
+			if s == n {
+				return l.rank
+			}
+		}
+	}
+	return 0
+}
+
+// detectLogLevel returns the rank of the first level keyword found in line,
+// or 0 when the line carries no recognizable level.
+func detectLogLevel(line string) int {
+	for _, tok := range logLevelTokenRe.FindAllString(line, -1) {
+		if rank := parseLogLevel(tok); rank > 0 {
+			return rank
+		}
+	}
+	return 0
+}
+
+func (f logFilter) active() bool {
+	return f.grep != "" || f.regex != nil || f.minLevel > 0
+}
+
+func (f logFilter) match(line string) bool {
+	if f.grep != "" && !strings.Contains(strings.ToLower(line), strings.ToLower(f.grep)) {
+		return false
+	}
+	if f.regex != nil && !f.regex.MatchString(line) {
+		return false
+	}
+	if f.minLevel > 0 && detectLogLevel(line) < f.minLevel {
+		return false
+	}
+	return true
+}
+
+// filterLogLines scans src line by line and keeps the lines accepted by f,
+// up to maxBytes of output.
+func filterLogLines(src io.Reader, f logFilter, maxBytes int) logFilterResult {
+	if maxBytes <= 0 {
+		maxBytes = 64 * 1024
+	}
+	res := logFilterResult{Lines: []string{}}
+	size := 0
+	sc := bufio.NewScanner(src)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		res.Scanned++
+		if !f.match(line) {
+			continue
+		}
+		res.Matched++
+		if size+len(line) > maxBytes {
+			res.Truncated = true
+			continue
+		}
+		size += len(line)
+		res.Lines = append(res.Lines, line)
+	}
+	return res
+}
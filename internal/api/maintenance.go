@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/lsy88/uptime-chopper/internal/cron"
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/monitor"
+)
+
+func maintenanceRouter(deps Deps) http.Handler {
+	r := chi.NewRouter()
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		type MaintenanceResponse struct {
+			model.MaintenanceWindow
+			Active bool `json:"active"`
+		}
+		now := time.Now()
+		resp := []MaintenanceResponse{}
+		for _, mw := range deps.Store.GetMaintenanceWindows() {
+			resp = append(resp, MaintenanceResponse{MaintenanceWindow: mw, Active: mw.Active(now)})
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+
+	r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+		var mw model.MaintenanceWindow
+		if err := json.NewDecoder(r.Body).Decode(&mw); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		if mw.ID == "" {
+			mw.ID = monitor.NewID()
+		}
+		saveMaintenanceWindow(w, deps, mw)
+	})
+
+	r.Put("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var mw model.MaintenanceWindow
+		if err := json.NewDecoder(r.Body).Decode(&mw); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		mw.ID = chi.URLParam(r, "id")
+		saveMaintenanceWindow(w, deps, mw)
+	})
+
+	r.Delete("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if err := deps.Store.DeleteMaintenanceWindow(id); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+	})
+
+	return r
+}
+
+func saveMaintenanceWindow(w http.ResponseWriter, deps Deps, mw model.MaintenanceWindow) {
+	if err := validateMaintenanceWindow(deps, mw); err != nil {
+		writeInvalid(w, err)
+		return
+	}
+	out, err := deps.Store.UpsertMaintenanceWindow(mw)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// validateMaintenanceWindow applies the rules of model.MaintenanceWindow's
+// Validate field by field and checks that every monitor exists.
+func validateMaintenanceWindow(deps Deps, mw model.MaintenanceWindow) error {
+	var errs validationErrors
+	if mw.Cron == "" {
+		switch {
+		case mw.StartAt == nil:
+			errs.add("startAt", "required for a one-off window")
+		case mw.EndAt == nil:
+			errs.add("endAt", "required for a one-off window")
+		case !mw.EndAt.After(*mw.StartAt):
+			errs.add("endAt", "must be after startAt")
+		}
+	} else {
+		if _, err := cron.Parse(mw.Cron); err != nil {
+			errs.add("cron", err.Error())
+		}
+		if mw.DurationMinutes <= 0 {
+			errs.add("durationMinutes", "required for a recurring window")
+		}
+	}
+	if mw.Timezone != "" {
+		if _, err := time.LoadLocation(mw.Timezone); err != nil {
+			errs.add("timezone", "unknown timezone "+mw.Timezone)
+		}
+	}
+	for i, id := range mw.MonitorIDs {
+		if findMonitor(deps, id) == nil {
+			errs.add(fmt.Sprintf("monitorIds[%d]", i), "monitor not found: "+id)
+		}
+	}
+	return errs.err()
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+// handleMetrics renders monitor state in the Prometheus text exposition format.
+func (d Deps) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	st := d.Store.GetState()
+	status := d.Engine.StatusSnapshot()
+	hists := d.Engine.LatencyHistograms()
+
+	monitors := st.Monitors
+	sort.Slice(monitors, func(i, j int) bool { return monitors[i].ID < monitors[j].ID })
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	fmt.Fprintln(w, "# HELP uptime_chopper_monitor_up Whether the monitor's last check succeeded (1) or not (0).")
+	fmt.Fprintln(w, "# TYPE uptime_chopper_monitor_up gauge")
+	for _, m := range monitors {
+		v := 0
+		if status[m.ID].Status.Available() {
+			v = 1
+		}
+		fmt.Fprintf(w, "uptime_chopper_monitor_up{%s} %d\n", monitorLabels(m), v)
+	}
+
+	fmt.Fprintln(w, "# HELP uptime_chopper_check_latency_seconds Latency of successful checks.")
+	fmt.Fprintln(w, "# TYPE uptime_chopper_check_latency_seconds histogram")
+	for _, m := range monitors {
+		h, ok := hists[m.ID]
+		if !ok {
+			continue
+		}
+		writeHistogram(w, "uptime_chopper_check_latency_seconds", monitorLabels(m), h)
+	}
+}
+
+func writeHistogram(w io.Writer, name, labels string, h model.LatencyHistogram) {
+	var cum uint64
+	for i, b := range h.BucketsMs {
+		cum += h.Counts[i]
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", name, labels, msToSeconds(int64(b)), cum)
+	}
+	fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.Count)
+	fmt.Fprintf(w, "%s_sum{%s} %s\n", name, labels, msToSeconds(h.SumMs))
+	fmt.Fprintf(w, "%s_count{%s} %d\n", name, labels, h.Count)
+}
+
+func monitorLabels(m model.Monitor) string {
+	return fmt.Sprintf(`monitor_id="%s",monitor_name="%s",type="%s"`,
+		escapeLabel(m.ID), escapeLabel(m.Name), escapeLabel(string(m.Type)))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func msToSeconds(ms int64) string {
+	return strconv.FormatFloat(float64(ms)/1000, 'f', -1, 64)
+}
@@ -1,20 +1,39 @@
 package api
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/lsy88/uptime-chopper/internal/docker"
+	"github.com/lsy88/uptime-chopper/internal/jsonpath"
 	"github.com/lsy88/uptime-chopper/internal/model"
 	"github.com/lsy88/uptime-chopper/internal/monitor"
+	"github.com/lsy88/uptime-chopper/internal/netdial"
+	"github.com/lsy88/uptime-chopper/internal/redact"
+	"github.com/lsy88/uptime-chopper/internal/secrets"
 )
 
 func monitorsRouter(deps Deps) http.Handler {
 	r := chi.NewRouter()
+	r.Use(guardReveal)
+	// The list can be filtered with ?q= (a case-insensitive search of the
+	// name, ID, target and tags), ?type=, ?status= (the live status) and
+	// ?tag=; the last three take comma-separated alternatives.
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		st := deps.Store.GetState()
-		writeJSON(w, http.StatusOK, st.Monitors)
+		writeJSON(w, http.StatusOK, clientMonitors(r, filterMonitors(deps, r.URL.Query(), st.Monitors)))
 	})
 	r.Post("/", func(w http.ResponseWriter, r *http.Request) {
 		var m model.Monitor
@@ -25,13 +44,183 @@ func monitorsRouter(deps Deps) http.Handler {
 		if m.ID == "" {
 			m.ID = monitor.NewID()
 		}
-		m = normalizeMonitor(m)
+		existing := findMonitor(deps, m.ID)
+		if existing != nil && existing.Provisioned {
+			writeJSON(w, http.StatusConflict, map[string]any{"error": errProvisioned.Error()})
+			return
+		}
+		m.Provisioned = false
+		set := deps.Engine.Settings()
+		if existing == nil {
+			m = applyNotifyDefaults(m, set)
+		} else {
+			m = m.Unredact(*existing)
+		}
+		m = normalizeMonitor(m, set)
+		if !allowHostCommands(w, r, m, existing) || !allowMonitorSecretRefs(w, r, m, existing) {
+			return
+		}
+		if err := validateMonitor(deps, m); err != nil {
+			writeInvalid(w, err)
+			return
+		}
 		out, err := deps.Store.UpsertMonitor(m)
 		if err != nil {
 			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
 			return
 		}
-		writeJSON(w, http.StatusOK, out)
+		if existing == nil {
+			deps.Engine.NotifyLifecycle(out, model.EventMonitorCreated)
+			writeMonitor(w, r, http.StatusCreated, out)
+			return
+		}
+		deps.Engine.NotifyLifecycle(out, model.EventMonitorUpdated)
+		writeMonitor(w, r, http.StatusOK, out)
+	})
+	// bulk creates or updates many monitors at once. Every monitor is
+	// validated first and nothing is saved unless all of them pass.
+	r.Post("/bulk", func(w http.ResponseWriter, r *http.Request) {
+		var ms []model.Monitor
+		if err := json.NewDecoder(r.Body).Decode(&ms); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		existing := map[string]bool{}
+		provisioned := map[string]bool{}
+		stored := map[string]model.Monitor{}
+		for _, m := range deps.Store.GetState().Monitors {
+			existing[m.ID] = true
+			provisioned[m.ID] = m.Provisioned
+			stored[m.ID] = m
+		}
+		seen := map[string]bool{}
+		set := deps.Engine.Settings()
+		var errs validationErrors
+		for i := range ms {
+			if ms[i].ID == "" {
+				ms[i].ID = monitor.NewID()
+			}
+			ms[i].Provisioned = false
+			if !existing[ms[i].ID] {
+				ms[i] = applyNotifyDefaults(ms[i], set)
+			} else {
+				ms[i] = ms[i].Unredact(stored[ms[i].ID])
+			}
+			ms[i] = normalizeMonitor(ms[i], set)
+			var prev *model.Monitor
+			if existing[ms[i].ID] {
+				v := stored[ms[i].ID]
+				prev = &v
+			}
+			if !allowHostCommands(w, r, ms[i], prev) || !allowMonitorSecretRefs(w, r, ms[i], prev) {
+				return
+			}
+			prefix := fmt.Sprintf("[%d]", i)
+			var invalid validationErrors
+			if errors.As(validateMonitor(deps, ms[i]), &invalid) {
+				errs = append(errs, invalid.prefixed(prefix)...)
+			}
+			if seen[ms[i].ID] {
+				errs.add(prefix+".id", "duplicate id "+ms[i].ID)
+			}
+			seen[ms[i].ID] = true
+			if provisioned[ms[i].ID] {
+				writeJSON(w, http.StatusConflict, map[string]any{"error": fmt.Sprintf("%s: %v", ms[i].ID, errProvisioned)})
+				return
+			}
+		}
+		if len(errs) > 0 {
+			writeInvalid(w, errs)
+			return
+		}
+		out, err := deps.Store.UpsertMonitors(ms)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		for _, m := range out {
+			if existing[m.ID] {
+				deps.Engine.NotifyLifecycle(m, model.EventMonitorUpdated)
+			} else {
+				deps.Engine.NotifyLifecycle(m, model.EventMonitorCreated)
+			}
+		}
+		writeJSON(w, http.StatusOK, clientMonitors(r, out))
+	})
+	// export downloads monitor definitions in the format bulk accepts,
+	// optionally only those listed in ?ids=a,b. Credentials are masked
+	// unless an admin adds ?reveal=true; bulk keeps the stored values of
+	// masked fields when the export is imported back.
+	r.Get("/export", func(w http.ResponseWriter, r *http.Request) {
+		out := []model.Monitor{}
+		var want map[string]bool
+		if ids := r.URL.Query().Get("ids"); ids != "" {
+			want = map[string]bool{}
+			for _, id := range strings.Split(ids, ",") {
+				want[strings.TrimSpace(id)] = true
+			}
+		}
+		for _, m := range deps.Store.GetState().Monitors {
+			if want == nil || want[m.ID] {
+				out = append(out, m)
+			}
+		}
+		w.Header().Set("Content-Disposition", `attachment; filename="monitors.json"`)
+		writeJSON(w, http.StatusOK, clientMonitors(r, out))
+	})
+	// pause and resume act on every monitor, or on those tagged ?tag=, for
+	// instance around planned maintenance.
+	r.Post("/pause", func(w http.ResponseWriter, r *http.Request) {
+		setMonitorsPaused(deps, w, r, true)
+	})
+	r.Post("/resume", func(w http.ResponseWriter, r *http.Request) {
+		setMonitorsPaused(deps, w, r, false)
+	})
+	// validate dry-runs an unsaved monitor definition: nothing is stored,
+	// recorded or notified, and the check runs on the server even for
+	// monitors assigned to agents.
+	r.Post("/validate", func(w http.ResponseWriter, r *http.Request) {
+		var m model.Monitor
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		cur := findMonitor(deps, m.ID)
+		if cur != nil {
+			m = m.Unredact(*cur)
+		}
+		m = normalizeMonitor(m, deps.Engine.Settings())
+		// The dry run executes the commands, so it needs the same role as
+		// saving them.
+		if !allowHostCommands(w, r, m, cur) || !allowMonitorSecretRefs(w, r, m, cur) {
+			return
+		}
+		if err := validateMonitor(deps, m); err != nil {
+			writeJSON(w, http.StatusOK, map[string]any{"valid": false, "errors": err})
+			return
+		}
+		var res model.CheckResult
+		if m.Type == model.MonitorTypePush {
+			// There is no heartbeat to judge before the monitor exists.
+			res = model.CheckResult{MonitorID: m.ID, Status: model.StatusUnknown, CheckedAt: time.Now().UTC(), Message: "push monitors are checked when heartbeats arrive"}
+		} else {
+			res = deps.Engine.DebugCheck(r.Context(), m).Result
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"valid": true, "errors": validationErrors{}, "result": res})
+	})
+	r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		found := findMonitor(deps, chi.URLParam(r, "id"))
+		if found == nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "monitor not found"})
+			return
+		}
+		etag := monitorETag(*found)
+		if inm := r.Header.Get("If-None-Match"); inm != "" && etagMatches(inm, etag) {
+			w.Header().Set("ETag", etag)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		writeMonitor(w, r, http.StatusOK, *found)
 	})
 	r.Put("/{id}", func(w http.ResponseWriter, r *http.Request) {
 		id := chi.URLParam(r, "id")
@@ -41,95 +230,881 @@ func monitorsRouter(deps Deps) http.Handler {
 			return
 		}
 		m.ID = id
-		m = normalizeMonitor(m)
+		existing := findMonitor(deps, id)
+		if existing == nil && r.URL.Query().Get("create") != "true" {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "monitor not found"})
+			return
+		}
+		if preconditionFailed(w, r, existing) {
+			return
+		}
+		if existing != nil && existing.Provisioned {
+			writeJSON(w, http.StatusConflict, map[string]any{"error": errProvisioned.Error()})
+			return
+		}
+		m.Provisioned = false
+		set := deps.Engine.Settings()
+		if existing == nil {
+			m = applyNotifyDefaults(m, set)
+		} else {
+			m = m.Unredact(*existing)
+		}
+		m = normalizeMonitor(m, set)
+		if !allowHostCommands(w, r, m, existing) || !allowMonitorSecretRefs(w, r, m, existing) {
+			return
+		}
+		if err := validateMonitor(deps, m); err != nil {
+			writeInvalid(w, err)
+			return
+		}
 		out, err := deps.Store.UpsertMonitor(m)
 		if err != nil {
 			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
 			return
 		}
-		writeJSON(w, http.StatusOK, out)
+		if existing == nil {
+			deps.Engine.NotifyLifecycle(out, model.EventMonitorCreated)
+			writeMonitor(w, r, http.StatusCreated, out)
+			return
+		}
+		deps.Engine.NotifyLifecycle(out, model.EventMonitorUpdated)
+		writeMonitor(w, r, http.StatusOK, out)
 	})
 	r.Delete("/{id}", func(w http.ResponseWriter, r *http.Request) {
 		id := chi.URLParam(r, "id")
+		existing := findMonitor(deps, id)
+		if existing == nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "monitor not found"})
+			return
+		}
+		if preconditionFailed(w, r, existing) {
+			return
+		}
+		if existing.Provisioned {
+			writeJSON(w, http.StatusConflict, map[string]any{"error": errProvisioned.Error()})
+			return
+		}
 		if err := deps.Store.DeleteMonitor(id); err != nil {
 			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
 			return
 		}
+		deps.Engine.NotifyLifecycle(*existing, model.EventMonitorDeleted)
 		writeJSON(w, http.StatusOK, map[string]any{"ok": true})
 	})
 
 	r.Post("/{id}/pause", func(w http.ResponseWriter, r *http.Request) {
 		id := chi.URLParam(r, "id")
-		st := deps.Store.GetState()
-		var found *model.Monitor
-		for _, m := range st.Monitors {
-			if m.ID == id {
-				v := m
-				found = &v
-				break
-			}
-		}
+		found := findMonitor(deps, id)
 		if found == nil {
 			writeJSON(w, http.StatusNotFound, map[string]any{"error": "monitor not found"})
 			return
 		}
 		found.IsPaused = true
+		found.PausedReason = ""
 		out, err := deps.Store.UpsertMonitor(*found)
 		if err != nil {
 			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
 			return
 		}
-		writeJSON(w, http.StatusOK, out)
+		deps.Engine.NotifyLifecycle(out, model.EventMonitorPaused)
+		writeMonitor(w, r, http.StatusOK, out)
 	})
 
 	r.Post("/{id}/resume", func(w http.ResponseWriter, r *http.Request) {
 		id := chi.URLParam(r, "id")
-		st := deps.Store.GetState()
-		var found *model.Monitor
-		for _, m := range st.Monitors {
-			if m.ID == id {
-				v := m
-				found = &v
-				break
-			}
-		}
+		found := findMonitor(deps, id)
 		if found == nil {
 			writeJSON(w, http.StatusNotFound, map[string]any{"error": "monitor not found"})
 			return
 		}
 		found.IsPaused = false
+		found.PausedReason = ""
 		out, err := deps.Store.UpsertMonitor(*found)
 		if err != nil {
 			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
 			return
 		}
-		writeJSON(w, http.StatusOK, out)
+		deps.Engine.NotifyLifecycle(out, model.EventMonitorResumed)
+		writeMonitor(w, r, http.StatusOK, out)
+	})
+
+	// clone copies a monitor under a new ID. The name defaults to
+	// "<name> (copy)"; push monitors get a fresh token.
+	r.Post("/{id}/clone", func(w http.ResponseWriter, r *http.Request) {
+		found := findMonitor(deps, chi.URLParam(r, "id"))
+		if found == nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "monitor not found"})
+			return
+		}
+		var body struct {
+			Name string `json:"name"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+				return
+			}
+		}
+		m := *found
+		m.ID = monitor.NewID()
+		m.Provisioned = false
+		m.Name = body.Name
+		if m.Name == "" {
+			m.Name = found.Name + " (copy)"
+		}
+		m.CreatedAt, m.UpdatedAt = time.Time{}, time.Time{}
+		if m.Push != nil {
+			push := *m.Push
+			push.Token = ""
+			m.Push = &push
+		}
+		m = normalizeMonitor(m, deps.Engine.Settings())
+		if err := validateMonitor(deps, m); err != nil {
+			writeInvalid(w, err)
+			return
+		}
+		out, err := deps.Store.UpsertMonitor(m)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		deps.Engine.NotifyLifecycle(out, model.EventMonitorCreated)
+		writeMonitor(w, r, http.StatusCreated, out)
+	})
+
+	r.Post("/{id}/debug-check", func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		found := findMonitor(deps, id)
+		if found == nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "monitor not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, deps.Engine.DebugCheck(r.Context(), *found))
+	})
+
+	r.Post("/{id}/check", func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		found := findMonitor(deps, id)
+		if found == nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "monitor not found"})
+			return
+		}
+		res, err := deps.Engine.CheckNow(*found)
+		if errors.Is(err, monitor.ErrNotChecked) || errors.Is(err, monitor.ErrRemoteCheck) {
+			writeJSON(w, http.StatusConflict, map[string]any{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, res)
+	})
+
+	r.Post("/{id}/history/import", func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		found := findMonitor(deps, id)
+		if found == nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "monitor not found"})
+			return
+		}
+		var entries []model.MonitorHistoryEntry
+		if err := json.NewDecoder(r.Body).Decode(&entries); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		res, err := deps.Store.ImportMonitorHistory(id, entries, found.HistoryRetention(deps.Engine.Settings().HistoryRetentionDays))
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, res)
 	})
 
 	r.Get("/{id}/history", func(w http.ResponseWriter, r *http.Request) {
 		id := chi.URLParam(r, "id")
+		if findMonitor(deps, id) == nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "monitor not found"})
+			return
+		}
 		hist := deps.Engine.GetHistory(id)
 		writeJSON(w, http.StatusOK, hist)
 	})
 
+	r.Get("/{id}/history/export", deps.handleHistoryExport)
+
+	r.Get("/{id}/notifications", func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		attempts, err := deps.Store.GetNotificationAttempts("", id, queryLimit(r, 100))
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, attempts)
+	})
+
+	r.Get("/{id}/remediations", func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if findMonitor(deps, id) == nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "monitor not found"})
+			return
+		}
+		attempts, err := deps.Store.GetRemediationAttempts(id, queryLimit(r, 100))
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, attempts)
+	})
+
+	r.Post("/{id}/remediate", func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		found := findMonitor(deps, id)
+		if found == nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "monitor not found"})
+			return
+		}
+		attempt, err := deps.Engine.Remediate(r.Context(), *found)
+		if errors.Is(err, monitor.ErrNoRemediation) {
+			writeJSON(w, http.StatusConflict, map[string]any{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, attempt)
+	})
+
+	r.Post("/{id}/ack", func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if findMonitor(deps, id) == nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "monitor not found"})
+			return
+		}
+		var body incidentNote
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+				return
+			}
+		}
+		now := time.Now().UTC()
+		actor := actorName(r)
+		inc, err := deps.Engine.UpdateOpenIncident(id, func(inc *model.Incident) {
+			acknowledge(inc, actor, body.Note, now)
+		})
+		writeIncidentUpdate(w, inc, err, now)
+	})
+
+	r.Get("/{id}/incidents", func(w http.ResponseWriter, r *http.Request) {
+		writeIncidents(w, deps, chi.URLParam(r, "id"), false, queryLimit(r, 100))
+	})
+
+	r.Get("/{id}/stats", func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if findMonitor(deps, id) == nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "monitor not found"})
+			return
+		}
+		window := r.URL.Query().Get("window")
+		if window == "" {
+			window = "24h"
+		}
+		d, ok := statsWindows[window]
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "window must be one of 24h, 7d, 30d"})
+			return
+		}
+		stats, err := deps.Store.GetMonitorStats(id, time.Now().UTC().Add(-d))
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		stats.Window = window
+		writeJSON(w, http.StatusOK, stats)
+	})
+
+	r.Get("/{id}/latency", func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if findMonitor(deps, id) == nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "monitor not found"})
+			return
+		}
+		window := r.URL.Query().Get("window")
+		if window == "" {
+			window = "24h"
+		}
+		d, ok := statsWindows[window]
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "window must be one of 24h, 7d, 30d"})
+			return
+		}
+		resolution := defaultLatencyResolutions[window]
+		if v := r.URL.Query().Get("resolution"); v != "" {
+			var err error
+			if resolution, err = time.ParseDuration(v); err != nil || resolution < time.Minute {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"error": "resolution must be a duration of at least 1m"})
+				return
+			}
+		}
+		if d/resolution > maxLatencyBuckets {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": fmt.Sprintf("resolution too fine: at most %d buckets per window", maxLatencyBuckets)})
+			return
+		}
+		since := time.Now().UTC().Add(-d)
+		buckets, err := deps.Store.GetLatencyBuckets(id, since, resolution)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, model.LatencySeries{
+			MonitorID:  id,
+			Window:     window,
+			Resolution: resolution.String(),
+			Since:      since,
+			Buckets:    buckets,
+		})
+	})
+
 	return r
 }
 
-func normalizeMonitor(m model.Monitor) model.Monitor {
-	if m.IntervalSeconds <= 0 {
-		m.IntervalSeconds = 60
+var statsWindows = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+// defaultLatencyResolutions keeps each window at a few hundred buckets.
+var defaultLatencyResolutions = map[string]time.Duration{
+	"24h": 5 * time.Minute,
+	"7d":  time.Hour,
+	"30d": 6 * time.Hour,
+}
+
+const maxLatencyBuckets = 2000
+
+func findMonitor(deps Deps, id string) *model.Monitor {
+	st := deps.Store.GetState()
+	for _, m := range st.Monitors {
+		if m.ID == id {
+			v := m
+			return &v
+		}
+	}
+	return nil
+}
+
+// errProvisioned rejects API changes to monitors owned by the
+// provisioning file; pausing and resuming them is still allowed.
+var errProvisioned = errors.New("monitor is managed by the provisioning file")
+
+// PrepareMonitor applies the defaults and validation of the monitors API to
+// a monitor saved outside of it.
+func (d Deps) PrepareMonitor(m model.Monitor) (model.Monitor, error) {
+	m = normalizeMonitor(m, d.Engine.Settings())
+	return m, validateMonitor(d, m)
+}
+
+// validateMonitor rejects settings the engine could not use. The returned
+// error is a validationErrors listing every invalid field.
+func validateMonitor(deps Deps, m model.Monitor) error {
+	var errs validationErrors
+	if strings.TrimSpace(m.Name) == "" {
+		errs.add("name", "required")
+	}
+	if !knownMonitorType(m.Type) {
+		errs.add("type", fmt.Sprintf("unknown monitor type %q", m.Type))
+	}
+	for _, f := range m.MaskedFields() {
+		errs.add(f, errMaskedCredential)
+	}
+	for field, v := range map[string]int{
+		"intervalSeconds":      m.IntervalSeconds,
+		"timeoutSeconds":       m.TimeoutSeconds,
+		"retriesBeforeDown":    m.RetriesBeforeDown,
+		"retryIntervalSeconds": m.RetryIntervalSeconds,
+		"latencyWarnMs":        m.LatencyWarnMs,
+		"latencyCriticalMs":    m.LatencyCriticalMs,
+		"retentionDays":        m.RetentionDays,
+		"historySampleEvery":   m.HistorySampleEvery,
+		"notifyAfterFailures":  m.NotifyAfterFailures,
+		"resendEveryMinutes":   m.ResendEveryMinutes,
+		"logs.tail":            m.Logs.Tail,
+	} {
+		if v < 0 {
+			errs.add(field, "must not be negative")
+		}
+	}
+	if m.LatencyWarnMs > 0 && m.LatencyCriticalMs > 0 && m.LatencyCriticalMs <= m.LatencyWarnMs {
+		errs.add("latencyCriticalMs", "must be greater than latencyWarnMs")
+	}
+
+	notifications := map[string]bool{}
+	for _, n := range deps.Store.GetNotifications() {
+		notifications[n.ID] = true
+	}
+	for i, id := range m.NotifyWebhookIDs {
+		if !notifications[id] {
+			errs.add(fmt.Sprintf("notifyWebhookIds[%d]", i), "unknown notification channel "+id)
+		}
+	}
+	if m.RoutingPolicyID != "" {
+		known := false
+		for _, p := range deps.Store.GetRoutingPolicies() {
+			known = known || p.ID == m.RoutingPolicyID
+		}
+		if !known {
+			errs.add("routingPolicyId", "unknown routing policy "+m.RoutingPolicyID)
+		}
+	}
+
+	var agents []string
+	if m.Agent != "" {
+		agents = append(agents, m.Agent)
+	}
+	if m.Regions != nil {
+		if m.Agent != "" {
+			errs.add("regions", "agent and regions are mutually exclusive")
+		}
+		errs.addErr("regions", m.Regions.Validate())
+		agents = append(agents, m.Regions.Agents...)
+	}
+	if len(agents) > 0 && m.Type == model.MonitorTypePush {
+		errs.add("agent", "push monitors cannot be assigned to an agent")
+	}
+	for _, name := range agents {
+		known := name == model.LocalRegion && m.Regions != nil
+		for _, a := range deps.Config.Agents {
+			known = known || a.Name == name
+		}
+		if !known {
+			errs.add("agent", fmt.Sprintf("unknown agent %q", name))
+		}
+	}
+
+	switch m.IPVersion {
+	case model.IPAny, model.IPv4, model.IPv6:
+	default:
+		errs.add("ipVersion", fmt.Sprintf("unknown ip version %q", m.IPVersion))
+	}
+	if m.DNSServer != "" {
+		_, err := netdial.ServerAddr(m.DNSServer)
+		errs.addErr("dnsServer", err)
+	}
+
+	// Docker hosts are only known to the server; agents use their own.
+	checkHost := func(field, id string) {
+		if id == "" || len(agents) > 0 {
+			return
+		}
+		if _, err := deps.Docker.Get(id); errors.Is(err, docker.ErrUnknownHost) {
+			errs.add(field, "unknown docker host "+id)
+		}
+	}
+	switch {
+	case m.Type == model.MonitorTypeHTTP:
+		u, err := url.Parse(m.HTTP.URL)
+		switch {
+		case m.HTTP.URL == "":
+			errs.add("http.url", "required")
+		case len(secrets.Refs(m.HTTP.URL)) > 0:
+			// Only known once the secrets are resolved at check time.
+		case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+			errs.add("http.url", "invalid URL")
+		}
+		if m.HTTP.MaxRedirects != nil && *m.HTTP.MaxRedirects < 0 {
+			errs.add("http.maxRedirects", "must not be negative")
+		}
+		if m.HTTP.MaxIdleConns < 0 {
+			errs.add("http.maxIdleConns", "must not be negative")
+		}
+		if m.HTTP.DNSCacheSeconds < 0 {
+			errs.add("http.dnsCacheSeconds", "must not be negative")
+		}
+		if m.HTTP.MinBodyBytes < 0 {
+			errs.add("http.minBodyBytes", "must not be negative")
+		}
+		if m.HTTP.MaxBodyBytes < 0 {
+			errs.add("http.maxBodyBytes", "must not be negative")
+		} else if m.HTTP.MaxBodyBytes > 0 && m.HTTP.MaxBodyBytes < m.HTTP.MinBodyBytes {
+			errs.add("http.maxBodyBytes", "must not be less than minBodyBytes")
+		}
+		for i, expr := range m.HTTP.JSONAssertions {
+			if _, err := jsonpath.Parse(expr); err != nil {
+				errs.addErr(fmt.Sprintf("http.jsonAssertions[%d]", i), err)
+			}
+		}
+		if sum, err := hex.DecodeString(m.HTTP.BodySHA256); err != nil || (m.HTTP.BodySHA256 != "" && len(sum) != sha256.Size) {
+			errs.add("http.bodySha256", "must be a hex SHA-256 digest")
+		}
+	case m.Type == model.MonitorTypeContainer:
+		if m.Container.ContainerID == "" {
+			errs.add("container.containerId", "required")
+		}
+		checkHost("container.hostId", m.Container.HostID)
+	case m.Type == model.MonitorTypeWinService:
+		if m.WinService.ServiceName == "" && m.WinService.IISSite == "" {
+			errs.add("winService.serviceName", "required")
+		}
+	case m.Type == model.MonitorTypeLANPresence:
+		l := m.LANPresence
+		if l.IP == "" && l.MAC == "" {
+			errs.add("lanPresence", "ip or mac is required")
+		}
+		if l.IP != "" && net.ParseIP(l.IP) == nil {
+			errs.add("lanPresence.ip", "invalid IP address")
+		}
+		if l.MAC != "" {
+			if _, err := net.ParseMAC(l.MAC); err != nil {
+				errs.add("lanPresence.mac", "invalid MAC address")
+			}
+		}
+	case m.Type == model.MonitorTypeCompose:
+		if m.Compose.Project == "" {
+			errs.add("compose.project", "required")
+		}
+		checkHost("compose.hostId", m.Compose.HostID)
+	case m.Type == model.MonitorTypeKubernetes:
+		k := m.Kubernetes
+		switch k.Kind {
+		case model.KubernetesPod, "":
+			if k.Name == "" && k.LabelSelector == "" {
+				errs.add("kubernetes.name", "name or labelSelector is required")
+			}
+		case model.KubernetesDeployment:
+			if k.Name == "" {
+				errs.add("kubernetes.name", "required")
+			}
+		default:
+			errs.add("kubernetes.kind", "must be pod or deployment")
+		}
+	case m.Type.IsDatabase():
+		if m.Database.DSN == "" {
+			errs.add("database.dsn", "required")
+		}
+	case m.Type == model.MonitorTypeMail:
+		if m.Mail.Host == "" {
+			errs.add("mail.host", "required")
+		}
+		if m.Mail.Protocol != model.MailSMTP && m.Mail.Protocol != model.MailIMAP {
+			errs.add("mail.protocol", "must be smtp or imap")
+		}
+		if m.Mail.Port < 0 || m.Mail.Port > 65535 {
+			errs.add("mail.port", "must be between 0 and 65535")
+		}
+		if m.Mail.CertExpiryDays < 0 {
+			errs.add("mail.certExpiryDays", "must not be negative")
+		}
+	case m.Type == model.MonitorTypeExec:
+		if !deps.Config.AllowHostCommands {
+			errs.add("type", "exec monitors are disabled; set allow_host_commands to enable them")
+		}
+		if len(m.Exec.Command) == 0 && strings.TrimSpace(m.Exec.Script) == "" {
+			errs.add("exec.command", "command or script is required")
+		}
+	case m.Type == model.MonitorTypeSystem:
+		s := m.System
+		percent := func(field string, v float64) {
+			if v < 0 || v > 100 {
+				errs.add(field, "must be between 0 and 100")
+			}
+		}
+		percent("system.maxCpuPercent", s.MaxCPUPercent)
+		percent("system.maxMemoryPercent", s.MaxMemoryPercent)
+		if s.MaxLoadPerCPU < 0 {
+			errs.add("system.maxLoadPerCpu", "must not be negative")
+		}
+		for i, d := range s.Disks {
+			field := fmt.Sprintf("system.disks[%d]", i)
+			if !strings.HasPrefix(d.Path, "/") {
+				errs.add(field+".path", "must be an absolute path")
+			}
+			percent(field+".minFreePercent", d.MinFreePercent)
+			if d.MinFreeBytes < 0 {
+				errs.add(field+".minFreeBytes", "must not be negative")
+			}
+		}
+		for i, d := range s.SMART {
+			field := fmt.Sprintf("system.smart[%d]", i)
+			if !strings.HasPrefix(d.Device, "/dev/") {
+				errs.add(field+".device", "must be a device path such as /dev/sda")
+			}
+			if strings.HasPrefix(d.Type, "-") || strings.ContainsAny(d.Type, " \t") {
+				errs.add(field+".type", "invalid device type")
+			}
+			if d.MaxTemperature < 0 {
+				errs.add(field+".maxTemperature", "must not be negative")
+			}
+			if d.MaxReallocatedSectors < 0 {
+				errs.add(field+".maxReallocatedSectors", "must not be negative")
+			}
+		}
+	case m.Type == model.MonitorTypePush:
+		errs.addErr("push.cron", m.Push.Validate())
+		if m.Push.GraceSeconds < 0 {
+			errs.add("push.graceSeconds", "must not be negative")
+		}
+	}
+	for field, p := range remediationPolicies(m) {
+		if p.Action == model.RemediationScript && !deps.Config.AllowHostCommands {
+			errs.add(field+".action", "script remediation is disabled; set allow_host_commands to enable it")
+		}
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs.err()
+}
+
+// remediationPolicies returns the remediation policies of m by field.
+func remediationPolicies(m model.Monitor) map[string]model.RemediationPolicy {
+	out := map[string]model.RemediationPolicy{}
+	if m.Container != nil {
+		out["container.remediation"] = m.Container.Remediation
+	}
+	if m.Kubernetes != nil {
+		out["kubernetes.remediation"] = m.Kubernetes.Remediation
+	}
+	if m.Remediation != nil {
+		out["remediation"] = *m.Remediation
+	}
+	return out
+}
+
+// hostCommands returns the commands m runs on the host that checks it and
+// the requests its remediation sends from there, or "" when there are
+// none.
+func hostCommands(m model.Monitor) string {
+	var v struct {
+		Exec        *model.ExecMonitor                 `json:"exec,omitempty"`
+		Remediation map[string]model.RemediationPolicy `json:"remediation,omitempty"`
+	}
+	if m.Type == model.MonitorTypeExec {
+		v.Exec = m.Exec
+	}
+	for field, p := range remediationPolicies(m) {
+		if p.Action != model.RemediationScript && p.Action != model.RemediationWebhook {
+			continue
+		}
+		if v.Remediation == nil {
+			v.Remediation = map[string]model.RemediationPolicy{}
+		}
+		v.Remediation[field] = model.RemediationPolicy{Action: p.Action, Script: p.Script, Webhook: p.Webhook}
+	}
+	if v.Exec == nil && v.Remediation == nil {
+		return ""
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// allowHostCommands answers 403 and reports false when a caller below
+// admin sets or changes the host commands or remediation webhooks of m;
+// prev is the stored monitor, if any. What an admin saved may be kept by
+// operators.
+func allowHostCommands(w http.ResponseWriter, r *http.Request, m model.Monitor, prev *model.Monitor) bool {
+	cmd := hostCommands(m)
+	if cmd == "" || (prev != nil && hostCommands(*prev) == cmd) {
+		return true
 	}
-	if m.TimeoutSeconds <= 0 {
-		m.TimeoutSeconds = 10
+	if p := principalFrom(r.Context()); p != nil && !p.role().Allows(model.RoleAdmin) {
+		writeJSON(w, http.StatusForbidden, map[string]any{"error": "forbidden: only admins can set host commands and remediation webhooks"})
+		return false
+	}
+	return true
+}
+
+func knownMonitorType(t model.MonitorType) bool {
+	switch t {
+	case model.MonitorTypeHTTP, model.MonitorTypeContainer, model.MonitorTypeWinService,
+		model.MonitorTypeLANPresence, model.MonitorTypeCompose, model.MonitorTypeKubernetes,
+		model.MonitorTypePush, model.MonitorTypeMySQL, model.MonitorTypePostgres,
+		model.MonitorTypeRedis, model.MonitorTypeMongoDB, model.MonitorTypeMail, model.MonitorTypeExec,
+		model.MonitorTypeSystem:
+		return true
 	}
-	if m.Logs.Tail <= 0 {
+	return false
+}
+
+// applyNotifyDefaults gives a new monitor the default notification channels
+// when it does not list any, and the default alert thresholds for those it
+// leaves at zero.
+func applyNotifyDefaults(m model.Monitor, set model.Settings) model.Monitor {
+	if m.NotifyWebhookIDs == nil {
+		m.NotifyWebhookIDs = set.DefaultNotifyWebhookIDs
+	}
+	if m.NotifyAfterFailures == 0 {
+		m.NotifyAfterFailures = set.DefaultNotifyAfterFailures
+	}
+	if m.ResendEveryMinutes == 0 {
+		m.ResendEveryMinutes = set.DefaultResendEveryMinutes
+	}
+	return m
+}
+
+func normalizeMonitor(m model.Monitor, set model.Settings) model.Monitor {
+	if m.IntervalSeconds == 0 {
+		m.IntervalSeconds = set.DefaultIntervalSeconds
+	}
+	if m.TimeoutSeconds == 0 {
+		m.TimeoutSeconds = set.DefaultTimeoutSeconds
+	}
+	if m.Logs.Tail == 0 {
 		m.Logs.Tail = 200
 	}
+	m.Tags = normalizeTags(m.Tags)
 	if m.Type == model.MonitorTypeHTTP && m.HTTP == nil {
 		m.HTTP = &model.HTTPMonitor{}
 	}
 	if m.Type == model.MonitorTypeContainer && m.Container == nil {
 		m.Container = &model.ContainerMonitor{}
 	}
+	if m.Type == model.MonitorTypeWinService && m.WinService == nil {
+		m.WinService = &model.WinServiceMonitor{}
+	}
+	if m.Type == model.MonitorTypeLANPresence && m.LANPresence == nil {
+		m.LANPresence = &model.LANPresenceMonitor{}
+	}
+	if m.Type == model.MonitorTypeCompose && m.Compose == nil {
+		m.Compose = &model.ComposeMonitor{}
+	}
+	if m.Type == model.MonitorTypeKubernetes && m.Kubernetes == nil {
+		m.Kubernetes = &model.KubernetesMonitor{Kind: model.KubernetesPod}
+	}
+	if m.Type.IsDatabase() && m.Database == nil {
+		m.Database = &model.DatabaseMonitor{}
+	}
+	if m.Type == model.MonitorTypeMail && m.Mail == nil {
+		m.Mail = &model.MailMonitor{Protocol: model.MailSMTP}
+	}
+	if m.Type == model.MonitorTypeExec && m.Exec == nil {
+		m.Exec = &model.ExecMonitor{}
+	}
+	if m.Type == model.MonitorTypeSystem && m.System == nil {
+		m.System = &model.SystemMonitor{Disks: []model.DiskMonitor{{Path: "/", MinFreePercent: 10}}}
+	}
+	if m.Type == model.MonitorTypePush {
+		if m.Push == nil {
+			m.Push = &model.PushMonitor{}
+		}
+		if m.Push.Token == "" {
+			m.Push.Token = monitor.NewID()
+		}
+	}
 	return m
 }
+
+// filterMonitors returns the monitors of ms matching the list filters in q.
+func filterMonitors(deps Deps, q url.Values, ms []model.Monitor) []model.Monitor {
+	search := strings.ToLower(strings.TrimSpace(q.Get("q")))
+	types, statuses, tags := queryList(q, "type"), queryList(q, "status"), queryList(q, "tag")
+	var status map[string]model.MonitorStatusInfo
+	if statuses != nil {
+		status = deps.Engine.StatusSnapshot()
+	}
+	out := []model.Monitor{}
+	for _, m := range ms {
+		if types != nil && !types[string(m.Type)] {
+			continue
+		}
+		if statuses != nil {
+			s := status[m.ID].Status
+			if m.IsPaused {
+				s = model.StatusPaused
+			} else if s == "" {
+				s = model.StatusUnknown
+			}
+			if !statuses[string(s)] {
+				continue
+			}
+		}
+		if tags != nil && !slices.ContainsFunc(m.Tags, func(t string) bool { return tags[t] }) {
+			continue
+		}
+		if search != "" && !monitorMatches(m, search) {
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// monitorMatches reports whether the lower-cased search term occurs in the
+// name, ID, target or tags of m. Credentials in the target are masked
+// first, so that searching cannot be used to guess them.
+func monitorMatches(m model.Monitor, search string) bool {
+	fields := append([]string{m.Name, m.ID, redact.String(monitor.Target(m))}, m.Tags...)
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), search) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryList parses a comma-separated query parameter into a set, nil when
+// it is absent or empty.
+func queryList(q url.Values, name string) map[string]bool {
+	var set map[string]bool
+	for _, v := range strings.Split(q.Get(name), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			if set == nil {
+				set = map[string]bool{}
+			}
+			set[v] = true
+		}
+	}
+	return set
+}
+
+// setMonitorsPaused pauses or resumes the monitors selected by ?tag=, all
+// of them without it, and responds with the IDs of those it changed.
+// Container monitors paused because their container was removed stay
+// paused; resume them one by one.
+func setMonitorsPaused(deps Deps, w http.ResponseWriter, r *http.Request, paused bool) {
+	tag := r.URL.Query().Get("tag")
+	var changed []model.Monitor
+	for _, m := range deps.Store.GetState().Monitors {
+		if m.IsPaused == paused || (tag != "" && !slices.Contains(m.Tags, tag)) {
+			continue
+		}
+		if !paused && m.PausedReason == model.PausedReasonOrphaned {
+			continue
+		}
+		m.IsPaused = paused
+		m.PausedReason = ""
+		changed = append(changed, m)
+	}
+	ids := []string{}
+	if len(changed) > 0 {
+		out, err := deps.Store.UpsertMonitors(changed)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		event := model.EventMonitorResumed
+		if paused {
+			event = model.EventMonitorPaused
+		}
+		for _, m := range out {
+			deps.Engine.NotifyLifecycle(m, event)
+			ids = append(ids, m.ID)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"monitorIds": ids})
+}
+
+// normalizeTags trims tags and drops empty and duplicate ones, keeping
+// their order.
+func normalizeTags(tags []string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if t != "" && !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -2,16 +2,23 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/lsy88/uptime-chopper/internal/model"
 	"github.com/lsy88/uptime-chopper/internal/monitor"
+	"github.com/lsy88/uptime-chopper/internal/notify"
 )
 
 func notificationsRouter(deps Deps) http.Handler {
 	r := chi.NewRouter()
+	r.Use(guardReveal)
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		notifs := deps.Store.GetNotifications()
 
@@ -26,7 +33,7 @@ func notificationsRouter(deps Deps) http.Handler {
 		for _, n := range notifs {
 			existingNames[n.Name] = true
 			resp = append(resp, NotificationResponse{
-				Notification: n,
+				Notification: clientNotification(r, n),
 				Editable:     true,
 			})
 		}
@@ -34,14 +41,29 @@ func notificationsRouter(deps Deps) http.Handler {
 		writeJSON(w, http.StatusOK, resp)
 	})
 
+	r.Get("/providers", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, notify.Providers())
+	})
+
 	r.Post("/", func(w http.ResponseWriter, r *http.Request) {
 		var n model.Notification
 		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
 			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
 			return
 		}
+		var prev *model.Notification
 		if n.ID == "" {
 			n.ID = monitor.NewID()
+		} else if cur, ok := findNotification(deps, n.ID); ok {
+			n = n.Unredact(cur)
+			prev = &cur
+		}
+		if !allowNotificationSecretRefs(w, r, n, prev) {
+			return
+		}
+		if err := validateNotification(n); err != nil {
+			writeInvalid(w, err)
+			return
 		}
 
 		out, err := deps.Store.UpsertNotification(n)
@@ -49,7 +71,7 @@ func notificationsRouter(deps Deps) http.Handler {
 			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
 			return
 		}
-		writeJSON(w, http.StatusOK, out)
+		writeJSON(w, http.StatusOK, clientNotification(r, out))
 	})
 
 	r.Put("/{id}", func(w http.ResponseWriter, r *http.Request) {
@@ -60,12 +82,34 @@ func notificationsRouter(deps Deps) http.Handler {
 			return
 		}
 		n.ID = id
+		var prev *model.Notification
+		if cur, ok := findNotification(deps, id); ok {
+			n = n.Unredact(cur)
+			prev = &cur
+		}
+		if !allowNotificationSecretRefs(w, r, n, prev) {
+			return
+		}
+		if err := validateNotification(n); err != nil {
+			writeInvalid(w, err)
+			return
+		}
 		out, err := deps.Store.UpsertNotification(n)
 		if err != nil {
 			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
 			return
 		}
-		writeJSON(w, http.StatusOK, out)
+		writeJSON(w, http.StatusOK, clientNotification(r, out))
+	})
+
+	r.Get("/{id}/history", func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		attempts, err := deps.Store.GetNotificationAttempts(id, "", queryLimit(r, 100))
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, attempts)
 	})
 
 	r.Delete("/{id}", func(w http.ResponseWriter, r *http.Request) {
@@ -74,8 +118,104 @@ func notificationsRouter(deps Deps) http.Handler {
 			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
 			return
 		}
+		if err := deps.forgetDefaultChannel(id); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
 		writeJSON(w, http.StatusOK, map[string]any{"ok": true})
 	})
 
 	return r
 }
+
+// findNotification returns the stored channel with the given ID.
+func findNotification(deps Deps, id string) (model.Notification, bool) {
+	for _, n := range deps.Store.GetNotifications() {
+		if n.ID == id {
+			return n, true
+		}
+	}
+	return model.Notification{}, false
+}
+
+// clientNotification returns n as it is sent to the client: redacted
+// unless the request is revealing.
+func clientNotification(r *http.Request, n model.Notification) model.Notification {
+	if revealing(r) {
+		return n
+	}
+	return n.Redacted()
+}
+
+// validateNotification checks a channel against the field rules of its
+// provider in the catalog; an empty type is a generic webhook.
+func validateNotification(n model.Notification) error {
+	var errs validationErrors
+	if strings.TrimSpace(n.Name) == "" {
+		errs.add("name", "required")
+	}
+	typ := n.Type
+	if typ == "" {
+		typ = "webhook"
+	}
+	provider, ok := notify.LookupProvider(typ)
+	if !ok {
+		errs.add("type", fmt.Sprintf("unknown notification type %q", n.Type))
+		return errs
+	}
+	raw, _ := json.Marshal(n)
+	var values map[string]any
+	_ = json.Unmarshal(raw, &values)
+	for _, f := range provider.Fields {
+		v, _ := values[f.Name].(string)
+		if v == "" {
+			if f.Required {
+				errs.add(f.Name, "required")
+			}
+			continue
+		}
+		if f.Pattern != "" && !regexp.MustCompile(f.Pattern).MatchString(v) {
+			errs.add(f.Name, "invalid "+f.Label)
+		}
+	}
+	for _, f := range n.MaskedFields() {
+		errs.add(f, errMaskedCredential)
+	}
+	if n.BodyTemplate != "" {
+		errs.addErr("bodyTemplate", notify.ValidateBodyTemplate(n.BodyTemplate))
+	}
+	if n.QuietMode != "" && n.QuietMode != model.QuietModeSuppress && n.QuietMode != model.QuietModeQueue {
+		errs.add("quietMode", "must be suppress or queue")
+	}
+	for i, q := range n.QuietHours {
+		validateQuietHours(&errs, fmt.Sprintf("quietHours[%d]", i), q)
+	}
+	return errs.err()
+}
+
+// validateQuietHours records malformed clock times and unknown timezones.
+func validateQuietHours(errs *validationErrors, field string, q model.QuietHours) {
+	if _, err := time.Parse("15:04", q.Start); err != nil {
+		errs.add(field+".start", "must be HH:MM")
+	}
+	if _, err := time.Parse("15:04", q.End); err != nil {
+		errs.add(field+".end", "must be HH:MM")
+	}
+	if q.Timezone != "" {
+		if _, err := time.LoadLocation(q.Timezone); err != nil {
+			errs.add(field+".timezone", "unknown timezone "+q.Timezone)
+		}
+	}
+}
+
+// queryLimit parses the "limit" query parameter, capped at 1000.
+func queryLimit(r *http.Request, def int) int {
+	n, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || n <= 0 {
+		return def
+	}
+	if n > 1000 {
+		return 1000
+	}
+	return n
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/monitor"
+)
+
+// handlePush receives heartbeats for push monitors. The token in the path
+// identifies the monitor, so the route is exempt from authentication by
+// default. Jobs may report a failed run with ?status=down and describe it
+// with ?msg=.
+func (d Deps) handlePush(w http.ResponseWriter, r *http.Request) {
+	status := model.MonitorStatus(r.FormValue("status"))
+	m, err := d.Engine.Push(chi.URLParam(r, "token"), status, r.FormValue("msg"))
+	if errors.Is(err, monitor.ErrUnknownPushToken) {
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "monitorId": m.ID})
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// parseTrustedProxies parses IPs and CIDRs; a bare IP matches only itself.
+// Invalid entries are logged and skipped.
+func parseTrustedProxies(entries []string, logger *zap.Logger) []*net.IPNet {
+	var out []*net.IPNet
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		if _, n, err := net.ParseCIDR(e); err == nil {
+			out = append(out, n)
+			continue
+		}
+		ip := net.ParseIP(e)
+		if ip == nil {
+			if logger != nil {
+				logger.Warn("ignoring invalid trusted proxy", zap.String("proxy", e))
+			}
+			continue
+		}
+		bits := 8 * net.IPv6len
+		if v4 := ip.To4(); v4 != nil {
+			ip, bits = v4, 8*net.IPv4len
+		}
+		out = append(out, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+	}
+	return out
+}
+
+// realIP sets RemoteAddr to the client address reported by a trusted
+// reverse proxy. Forwarded headers are ignored unless the TCP peer is one
+// of the trusted proxies, so that clients cannot pick their own address.
+// X-Forwarded-For is read from the right, skipping further trusted proxies,
+// since the entries on the left are whatever the client sent.
+func realIP(trusted []*net.IPNet) func(http.Handler) http.Handler {
+	isTrusted := func(ip net.IP) bool {
+		for _, n := range trusted {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(trusted) > 0 && isTrusted(remoteIP(r.RemoteAddr)) {
+				if ip := forwardedFor(r, isTrusted); ip != "" {
+					r.RemoteAddr = ip
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedFor returns the nearest untrusted address in X-Forwarded-For,
+// falling back to X-Real-IP.
+func forwardedFor(r *http.Request, isTrusted func(net.IP) bool) string {
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			break
+		}
+		if !isTrusted(ip) || i == 0 {
+			return ip.String()
+		}
+	}
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// remoteIP parses the IP of a "host:port" or bare host address.
+func remoteIP(addr string) net.IP {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return net.ParseIP(host)
+}
@@ -8,24 +8,62 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
 )
 
 func NewRouter(deps Deps) http.Handler {
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(realIP(parseTrustedProxies(deps.Config.TrustedProxies, deps.Logger)))
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(30 * time.Second))
-	r.Use(cors(deps.Config.AllowedCORSOrigin))
+	origins := deps.CORSOrigin
+	if origins == nil {
+		origins = NewCORSOrigin(deps.Config.AllowedCORSOrigin)
+	}
+	r.Use(cors(origins))
+
+	r.With(deps.authenticate).Get("/metrics", deps.handleMetrics)
 
 	r.Route("/api", func(r chi.Router) {
+		r.Use(deps.authenticate)
 		r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 			writeJSON(w, http.StatusOK, map[string]any{"ok": true})
 		})
-		r.Mount("/monitors", monitorsRouter(deps))
-		r.Mount("/containers", containersRouter(deps))
-		r.Get("/status", deps.handleStatus)
-		r.Mount("/notifications", notificationsRouter(deps))
+		// Login, logout and "who am I" are available to every role.
+		r.Mount("/auth", authRouter(deps))
+		r.With(requireRole(model.RoleAdmin)).Mount("/users", usersRouter(deps))
+		// Heartbeats authenticate with the monitor's push token.
+		r.Get("/push/{token}", deps.handlePush)
+		r.Post("/push/{token}", deps.handlePush)
+		// Remote agents authenticate with their agent token.
+		r.Mount("/agent", agentRouter(deps))
+
+		r.Group(func(r chi.Router) {
+			r.Use(authorize)
+			r.Mount("/monitors", monitorsRouter(deps))
+			r.Mount("/containers", containersRouter(deps))
+			r.Mount("/docker", dockerRouter(deps))
+			r.Get("/agents", deps.handleAgents)
+			// Backups include notification secrets and restores rewrite the
+			// whole configuration.
+			r.With(requireRole(model.RoleAdmin)).Get("/backup", deps.handleBackup)
+			r.With(requireRole(model.RoleAdmin)).Post("/restore", deps.handleRestore)
+			r.Get("/topology", deps.handleTopology)
+			r.Get("/status", deps.handleStatus)
+			r.Get("/events", deps.handleEvents)
+			r.Get("/settings", deps.handleGetSettings)
+			r.With(requireRole(model.RoleAdmin)).Put("/settings", deps.handlePutSettings)
+			r.Mount("/secrets", secretsRouter(deps))
+			r.Mount("/notifications", notificationsRouter(deps))
+			r.Mount("/routing-policies", routingPoliciesRouter(deps))
+			r.Mount("/status-pages", statusPagesRouter(deps))
+			r.Mount("/maintenance", maintenanceRouter(deps))
+			r.Mount("/incidents", incidentsRouter(deps))
+			r.Get("/public/status/{slug}", deps.handlePublicStatus)
+			r.Mount("/badge", badgeRouter(deps))
+		})
 	})
 
 	if deps.Config.ServeFrontendFromDist {
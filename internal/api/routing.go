@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/monitor"
+)
+
+func routingPoliciesRouter(deps Deps) http.Handler {
+	r := chi.NewRouter()
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, deps.Store.GetRoutingPolicies())
+	})
+
+	r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+		var p model.RoutingPolicy
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		if p.ID == "" {
+			p.ID = monitor.NewID()
+		}
+		if err := validateRoutingPolicy(deps, p); err != nil {
+			writeInvalid(w, err)
+			return
+		}
+		out, err := deps.Store.UpsertRoutingPolicy(p)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, out)
+	})
+
+	r.Put("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		var p model.RoutingPolicy
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		p.ID = id
+		if err := validateRoutingPolicy(deps, p); err != nil {
+			writeInvalid(w, err)
+			return
+		}
+		out, err := deps.Store.UpsertRoutingPolicy(p)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, out)
+	})
+
+	r.Delete("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if err := deps.Store.DeleteRoutingPolicy(id); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+	})
+
+	return r
+}
+
+// validateRoutingPolicy rejects policies that route to unknown channels.
+func validateRoutingPolicy(deps Deps, p model.RoutingPolicy) error {
+	var errs validationErrors
+	if strings.TrimSpace(p.Name) == "" {
+		errs.add("name", "required")
+	}
+	channels := map[string]bool{}
+	for _, n := range deps.Store.GetNotifications() {
+		channels[n.ID] = true
+	}
+	for i, id := range p.ChannelIDs {
+		if !channels[id] {
+			errs.add(fmt.Sprintf("channelIds[%d]", i), "unknown notification channel "+id)
+		}
+	}
+	if p.QuietHours != nil {
+		validateQuietHours(&errs, "quietHours", *p.QuietHours)
+	}
+	for i, step := range p.Escalation {
+		field := fmt.Sprintf("escalation[%d]", i)
+		if step.AfterMinutes < 0 {
+			errs.add(field+".afterMinutes", "must not be negative")
+		}
+		for j, id := range step.ChannelIDs {
+			if !channels[id] {
+				errs.add(fmt.Sprintf("%s.channelIds[%d]", field, j), "unknown notification channel "+id)
+			}
+		}
+	}
+	return errs.err()
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/secrets"
+)
+
+// secretInfo describes a secret without its value. Source is "store" for
+// secrets saved through the API and "env" for UPTIME_SECRET_* variables,
+// which take precedence.
+type secretInfo struct {
+	Name      string    `json:"name"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"createdAt,omitzero"`
+	UpdatedAt time.Time `json:"updatedAt,omitzero"`
+}
+
+func secretsRouter(deps Deps) http.Handler {
+	r := chi.NewRouter()
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		out := []secretInfo{}
+		for _, name := range secrets.EnvNames() {
+			out = append(out, secretInfo{Name: name, Source: "env"})
+		}
+		for _, sec := range deps.Store.GetSecrets() {
+			out = append(out, secretInfo{Name: sec.Name, Source: "store", CreatedAt: sec.CreatedAt, UpdatedAt: sec.UpdatedAt})
+		}
+		writeJSON(w, http.StatusOK, out)
+	})
+	r.With(requireRole(model.RoleAdmin)).Put("/{name}", func(w http.ResponseWriter, r *http.Request) {
+		name := chi.URLParam(r, "name")
+		var body struct {
+			Value string `json:"value"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		var errs validationErrors
+		if !secrets.ValidName(name) {
+			errs.add("name", "may only contain letters, digits and underscores")
+		}
+		if body.Value == "" {
+			errs.add("value", "required")
+		}
+		if err := errs.err(); err != nil {
+			writeInvalid(w, err)
+			return
+		}
+		sealed, err := deps.Secrets.Box().Seal(body.Value)
+		if errors.Is(err, secrets.ErrNoKey) {
+			writeJSON(w, http.StatusConflict, map[string]any{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		sec, err := deps.Store.UpsertSecret(model.Secret{Name: name, Value: sealed})
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, secretInfo{Name: sec.Name, Source: "store", CreatedAt: sec.CreatedAt, UpdatedAt: sec.UpdatedAt})
+	})
+	r.With(requireRole(model.RoleAdmin)).Delete("/{name}", func(w http.ResponseWriter, r *http.Request) {
+		name := chi.URLParam(r, "name")
+		if _, ok := deps.Store.GetSecret(name); !ok {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "secret not found"})
+			return
+		}
+		if err := deps.Store.DeleteSecret(name); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+	})
+	return r
+}
+
+// monitorSecretUses returns the fields of m that secret references are
+// expanded into, with where the check sends them, or "" when none of them
+// references a secret. Comparing it with the stored monitor tells whether a
+// reference was added or pointed elsewhere.
+func monitorSecretUses(m model.Monitor) string {
+	var v struct {
+		Type      model.MonitorType      `json:"type"`
+		DNSServer string                 `json:"dnsServer,omitempty"`
+		HTTP      *model.HTTPMonitor     `json:"http,omitempty"`
+		Database  *model.DatabaseMonitor `json:"database,omitempty"`
+		Mail      *model.MailMonitor     `json:"mail,omitempty"`
+	}
+	v.Type, v.DNSServer = m.Type, m.DNSServer
+	var refs []string
+	if h := m.HTTP; h != nil {
+		v.HTTP = &model.HTTPMonitor{URL: h.URL, Headers: h.Headers, Auth: h.Auth, InsecureSkipVerify: h.InsecureSkipVerify}
+		refs = append(refs, h.URL)
+		for _, hv := range h.Headers {
+			refs = append(refs, hv)
+		}
+		if a := h.Auth; a != nil {
+			refs = append(refs, a.Username, a.Password, a.Token)
+		}
+	}
+	if d := m.Database; d != nil {
+		v.Database = &model.DatabaseMonitor{DSN: d.DSN}
+		refs = append(refs, d.DSN)
+	}
+	if ml := m.Mail; ml != nil {
+		c := *ml
+		v.Mail = &c
+		refs = append(refs, ml.Username, ml.Password)
+	}
+	return secretUses(v, refs)
+}
+
+// notificationSecretUses is monitorSecretUses for a notification channel.
+func notificationSecretUses(n model.Notification) string {
+	v := model.Notification{
+		Type: n.Type, URL: n.URL, BotToken: n.BotToken, ChatID: n.ChatID, Secret: n.Secret,
+		UserKey: n.UserKey, AppToken: n.AppToken, BearerToken: n.BearerToken,
+		Headers: n.Headers, BodyTemplate: n.BodyTemplate,
+	}
+	refs := []string{n.URL, n.BotToken, n.ChatID, n.Secret, n.UserKey, n.AppToken, n.BearerToken, n.BodyTemplate}
+	for _, hv := range n.Headers {
+		refs = append(refs, hv)
+	}
+	return secretUses(v, refs)
+}
+
+func secretUses(v any, values []string) string {
+	for _, s := range values {
+		if len(secrets.Refs(s)) > 0 {
+			b, _ := json.Marshal(v)
+			return string(b)
+		}
+	}
+	return ""
+}
+
+// allowMonitorSecretRefs applies allowSecretRefs to m and the stored
+// monitor, if any.
+func allowMonitorSecretRefs(w http.ResponseWriter, r *http.Request, m model.Monitor, prev *model.Monitor) bool {
+	var stored string
+	if prev != nil {
+		stored = monitorSecretUses(*prev)
+	}
+	return allowSecretRefs(w, r, monitorSecretUses(m), stored)
+}
+
+// allowNotificationSecretRefs applies allowSecretRefs to n and the stored
+// channel, if any.
+func allowNotificationSecretRefs(w http.ResponseWriter, r *http.Request, n model.Notification, prev *model.Notification) bool {
+	var stored string
+	if prev != nil {
+		stored = notificationSecretUses(*prev)
+	}
+	return allowSecretRefs(w, r, notificationSecretUses(n), stored)
+}
+
+// allowSecretRefs answers 403 and reports false when a caller below admin
+// adds secret references or changes where they are sent; prev is what is
+// stored. Only admins can read secrets, so only they may aim them at a
+// host. References an admin saved may be kept by operators.
+func allowSecretRefs(w http.ResponseWriter, r *http.Request, uses, prev string) bool {
+	if uses == "" || uses == prev {
+		return true
+	}
+	if p := principalFrom(r.Context()); p != nil && !p.role().Allows(model.RoleAdmin) {
+		writeJSON(w, http.StatusForbidden, map[string]any{"error": "forbidden: only admins can add or redirect secret references"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+// handleGetSettings returns the runtime settings in effect.
+func (deps Deps) handleGetSettings(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, deps.Engine.Settings())
+}
+
+// handlePutSettings saves the runtime settings and applies them without a
+// restart. Fields left out of the body keep their current value.
+func (deps Deps) handlePutSettings(w http.ResponseWriter, r *http.Request) {
+	set := deps.Engine.Settings()
+	if err := json.NewDecoder(r.Body).Decode(&set); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	}
+	if err := validateSettings(deps, set); err != nil {
+		writeInvalid(w, err)
+		return
+	}
+	out, err := deps.Store.SaveSettings(set)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+		return
+	}
+	deps.Engine.ApplySettings(out)
+	writeJSON(w, http.StatusOK, out)
+}
+
+// validateSettings rejects settings the engine could not use.
+func validateSettings(deps Deps, set model.Settings) error {
+	var errs validationErrors
+	if set.DefaultIntervalSeconds <= 0 {
+		errs.add("defaultIntervalSeconds", "must be positive")
+	}
+	if set.DefaultTimeoutSeconds <= 0 {
+		errs.add("defaultTimeoutSeconds", "must be positive")
+	}
+	for _, f := range []struct {
+		field string
+		v     int
+	}{
+		{"historyRetentionDays", set.HistoryRetentionDays},
+		{"maxDockerLogBytes", set.MaxDockerLogBytes},
+		{"historyLogBudgetBytes", set.HistoryLogBudgetBytes},
+		{"defaultNotifyAfterFailures", set.DefaultNotifyAfterFailures},
+		{"defaultResendEveryMinutes", set.DefaultResendEveryMinutes},
+	} {
+		if f.v < 0 {
+			errs.add(f.field, "must not be negative")
+		}
+	}
+	channels := map[string]bool{}
+	for _, n := range deps.Store.GetNotifications() {
+		channels[n.ID] = true
+	}
+	for i, id := range set.DefaultNotifyWebhookIDs {
+		if !channels[id] {
+			errs.add(fmt.Sprintf("defaultNotifyWebhookIds[%d]", i), "unknown notification channel "+id)
+		}
+	}
+	return errs.err()
+}
+
+// forgetDefaultChannel removes a deleted notification channel from the
+// defaults given to new monitors, which could not be created otherwise.
+func (deps Deps) forgetDefaultChannel(id string) error {
+	set, ok := deps.Store.GetSettings()
+	if !ok {
+		return nil
+	}
+	kept := set.DefaultNotifyWebhookIDs[:0]
+	for _, cid := range set.DefaultNotifyWebhookIDs {
+		if cid != id {
+			kept = append(kept, cid)
+		}
+	}
+	if len(kept) == len(set.DefaultNotifyWebhookIDs) {
+		return nil
+	}
+	set.DefaultNotifyWebhookIDs = kept
+	out, err := deps.Store.SaveSettings(set)
+	if err != nil {
+		return err
+	}
+	deps.Engine.ApplySettings(out)
+	return nil
+}
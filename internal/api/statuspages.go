@@ -0,0 +1,271 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/monitor"
+)
+
+func statusPagesRouter(deps Deps) http.Handler {
+	r := chi.NewRouter()
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		pages := deps.Store.GetStatusPages()
+		for i := range pages {
+			pages[i] = pages[i].Redacted()
+		}
+		writeJSON(w, http.StatusOK, pages)
+	})
+
+	r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+		var p model.StatusPage
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		if p.ID == "" {
+			p.ID = monitor.NewID()
+		}
+		upsertStatusPage(deps, w, p)
+	})
+
+	r.Put("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var p model.StatusPage
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		p.ID = chi.URLParam(r, "id")
+		upsertStatusPage(deps, w, p)
+	})
+
+	r.Delete("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if err := deps.Store.DeleteStatusPage(id); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+	})
+
+	// Mint a signed, expiring token for embedding the public feed.
+	r.Post("/{id}/tokens", func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		p := findStatusPage(deps, func(sp model.StatusPage) bool { return sp.ID == id })
+		if p == nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "status page not found"})
+			return
+		}
+		var body struct {
+			TTLSeconds int `json:"ttlSeconds"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		ttl := time.Duration(body.TTLSeconds) * time.Second
+		if ttl > maxStatusTokenTTL {
+			var errs validationErrors
+			errs.add("ttlSeconds", fmt.Sprintf("must be at most %d", int(maxStatusTokenTTL/time.Second)))
+			writeInvalid(w, errs)
+			return
+		}
+		if ttl <= 0 {
+			ttl = defaultStatusTokenTTL
+		}
+		exp := time.Now().Add(ttl).Unix()
+		writeJSON(w, http.StatusOK, map[string]any{
+			"token":     signStatusToken(*p, exp),
+			"expiresAt": time.Unix(exp, 0).UTC(),
+		})
+	})
+
+	return r
+}
+
+// Feed tokens cannot be revoked short of deleting the page, so they are
+// kept short-lived.
+const (
+	defaultStatusTokenTTL = 30 * 24 * time.Hour
+	maxStatusTokenTTL     = 90 * 24 * time.Hour
+)
+
+var errSlugInUse = errors.New("slug already in use")
+
+// validateStatusPage rejects pages without a unique slug or with malformed
+// CIDRs or unknown monitors. A slug taken by another page is reported as
+// errSlugInUse once everything else is valid.
+func validateStatusPage(deps Deps, p model.StatusPage) error {
+	var errs validationErrors
+	if p.Slug == "" {
+		errs.add("slug", "required")
+	}
+	for i, id := range p.MonitorIDs {
+		if findMonitor(deps, id) == nil {
+			errs.add(fmt.Sprintf("monitorIds[%d]", i), "monitor not found: "+id)
+		}
+	}
+	for i, c := range p.AllowedCIDRs {
+		if _, _, err := net.ParseCIDR(c); err != nil {
+			errs.add(fmt.Sprintf("allowedCidrs[%d]", i), "invalid CIDR "+c)
+		}
+	}
+	if p.CacheSeconds < 0 {
+		errs.add("cacheSeconds", "must not be negative")
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	if dup := findStatusPage(deps, func(sp model.StatusPage) bool { return sp.Slug == p.Slug && sp.ID != p.ID }); dup != nil {
+		return errSlugInUse
+	}
+	return nil
+}
+
+func upsertStatusPage(deps Deps, w http.ResponseWriter, p model.StatusPage) {
+	if err := validateStatusPage(deps, p); err != nil {
+		if errors.Is(err, errSlugInUse) {
+			writeJSON(w, http.StatusConflict, map[string]any{"error": err.Error()})
+			return
+		}
+		writeInvalid(w, err)
+		return
+	}
+	// The signing secret is the server's own; a page keeps it across updates.
+	p.Secret = monitor.NewID()
+	if existing := findStatusPage(deps, func(sp model.StatusPage) bool { return sp.ID == p.ID }); existing != nil && existing.Secret != "" {
+		p.Secret = existing.Secret
+	}
+	out, err := deps.Store.UpsertStatusPage(p)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, out.Redacted())
+}
+
+func findStatusPage(deps Deps, match func(model.StatusPage) bool) *model.StatusPage {
+	for _, p := range deps.Store.GetStatusPages() {
+		if match(p) {
+			v := p
+			return &v
+		}
+	}
+	return nil
+}
+
+// signStatusToken returns "<expiry>.<hex hmac>" signed with the page secret.
+func signStatusToken(p model.StatusPage, exp int64) string {
+	mac := hmac.New(sha256.New, []byte(p.Secret))
+	fmt.Fprintf(mac, "%s:%d", p.ID, exp)
+	return strconv.FormatInt(exp, 10) + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+func verifyStatusToken(p model.StatusPage, token string) bool {
+	expStr, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	exp, err := strconv.ParseInt(expStr, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return false
+	}
+	return hmac.Equal([]byte(token), []byte(signStatusToken(p, exp)))
+}
+
+func clientIPAllowed(p model.StatusPage, remoteAddr string) bool {
+	if len(p.AllowedCIDRs) == 0 {
+		return true
+	}
+	ip := remoteIP(remoteAddr)
+	if ip == nil {
+		return false
+	}
+	for _, c := range p.AllowedCIDRs {
+		if _, n, err := net.ParseCIDR(c); err == nil && n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+type publicMonitorStatus struct {
+	ID        string              `json:"id"`
+	Name      string              `json:"name"`
+	Status    model.MonitorStatus `json:"status"`
+	LastCheck time.Time           `json:"lastCheck"`
+}
+
+// handlePublicStatus serves the read-only status feed of a status page
+// without requiring API credentials.
+func (d Deps) handlePublicStatus(w http.ResponseWriter, r *http.Request) {
+	slug := chi.URLParam(r, "slug")
+	p := findStatusPage(d, func(sp model.StatusPage) bool { return sp.Slug == slug })
+	if p == nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": "status page not found"})
+		return
+	}
+	if !clientIPAllowed(*p, r.RemoteAddr) {
+		writeJSON(w, http.StatusForbidden, map[string]any{"error": "forbidden"})
+		return
+	}
+	if p.RequireToken && !verifyStatusToken(*p, r.URL.Query().Get("token")) {
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "invalid or expired token"})
+		return
+	}
+
+	status := d.Engine.StatusSnapshot()
+	byID := map[string]model.Monitor{}
+	for _, m := range d.Store.GetState().Monitors {
+		byID[m.ID] = m
+	}
+	items := make([]publicMonitorStatus, 0, len(p.MonitorIDs))
+	for _, id := range p.MonitorIDs {
+		m, ok := byID[id]
+		if !ok {
+			continue
+		}
+		st, ok := status[id]
+		if !ok {
+			st.Status = model.StatusUnknown
+		}
+		items = append(items, publicMonitorStatus{ID: m.ID, Name: m.Name, Status: st.Status, LastCheck: st.LastCheck})
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"title":    p.Title,
+		"slug":     p.Slug,
+		"monitors": items,
+	})
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+		return
+	}
+
+	maxAge := p.CacheSeconds
+	if maxAge <= 0 {
+		maxAge = 30
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d, s-maxage=%d, stale-while-revalidate=%d", maxAge, maxAge, maxAge*2))
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/lsy88/uptime-chopper/internal/docker"
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+const (
+	composeProjectLabel   = docker.ComposeProjectLabel
+	composeServiceLabel   = "com.docker.compose.service"
+	composeDependsOnLabel = "com.docker.compose.depends_on"
+)
+
+type topologyNode struct {
+	ID            string              `json:"id"`
+	Kind          string              `json:"kind"` // container, network
+	Name          string              `json:"name"`
+	Image         string              `json:"image,omitempty"`
+	State         string              `json:"state,omitempty"`
+	Project       string              `json:"project,omitempty"`
+	MonitorID     string              `json:"monitorId,omitempty"`
+	MonitorStatus model.MonitorStatus `json:"monitorStatus,omitempty"`
+	RootCause     bool                `json:"rootCause,omitempty"`
+}
+
+type topologyEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Kind   string `json:"kind"` // depends_on, link, network
+}
+
+// handleTopology derives a container dependency graph from compose labels,
+// legacy links and shared networks, annotated with monitor status. A down
+// container none of whose dependencies are down is flagged as a root cause.
+func (d Deps) handleTopology(w http.ResponseWriter, r *http.Request) {
+	host := r.URL.Query().Get("host")
+	dc, ok := dockerClient(w, r, d)
+	if !ok {
+		return
+	}
+	cs, err := dc.ListContainers(r.Context())
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": err.Error()})
+		return
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i].Name < cs[j].Name })
+
+	status := d.Engine.StatusSnapshot()
+	monitorFor := map[string]model.Monitor{}
+	for _, m := range d.Store.GetState().Monitors {
+		if m.Type == model.MonitorTypeContainer && m.Container != nil && m.Container.ContainerID != "" && m.Container.HostID == host {
+			monitorFor[m.Container.ContainerID] = m
+		}
+	}
+
+	byName := map[string]string{}
+	byService := map[string]string{} // project/service -> container id
+	for _, c := range cs {
+		byName[c.Name] = c.ID
+		if svc := c.Labels[composeServiceLabel]; svc != "" {
+			byService[c.Labels[composeProjectLabel]+"/"+svc] = c.ID
+		}
+	}
+
+	nodes := []topologyNode{}
+	edges := []topologyEdge{}
+	index := map[string]int{}
+	networks := map[string]bool{}
+
+	for _, c := range cs {
+		n := topologyNode{
+			ID:      c.ID,
+			Kind:    "container",
+			Name:    c.Name,
+			Image:   c.Image,
+			State:   c.State,
+			Project: c.Labels[composeProjectLabel],
+		}
+		m, ok := monitorFor[c.ID]
+		if !ok {
+			m, ok = monitorFor[c.Name]
+		}
+		if ok {
+			n.MonitorID = m.ID
+			n.MonitorStatus = status[m.ID].Status
+		}
+		index[c.ID] = len(nodes)
+		nodes = append(nodes, n)
+
+		for _, dep := range parseComposeDependsOn(c.Labels[composeDependsOnLabel]) {
+			if target, ok := byService[n.Project+"/"+dep]; ok {
+				edges = append(edges, topologyEdge{Source: c.ID, Target: target, Kind: "depends_on"})
+			}
+		}
+
+		if links, err := dc.Links(r.Context(), c.ID); err == nil {
+			for _, l := range links {
+				if target, ok := byName[l]; ok {
+					edges = append(edges, topologyEdge{Source: c.ID, Target: target, Kind: "link"})
+				}
+			}
+		}
+
+		for _, net := range c.Networks {
+			if net == "host" || net == "none" {
+				continue
+			}
+			networks[net] = true
+			edges = append(edges, topologyEdge{Source: c.ID, Target: "network:" + net, Kind: "network"})
+		}
+	}
+
+	netNames := make([]string, 0, len(networks))
+	for n := range networks {
+		netNames = append(netNames, n)
+	}
+	sort.Strings(netNames)
+	for _, n := range netNames {
+		nodes = append(nodes, topologyNode{ID: "network:" + n, Kind: "network", Name: n})
+	}
+
+	markRootCauses(nodes, index, edges)
+
+	writeJSON(w, http.StatusOK, map[string]any{"nodes": nodes, "edges": edges})
+}
+
+func markRootCauses(nodes []topologyNode, index map[string]int, edges []topologyEdge) {
+	down := func(n topologyNode) bool {
+		if n.MonitorStatus != "" {
+			return n.MonitorStatus == model.StatusDown
+		}
+		return n.Kind == "container" && n.State != "running"
+	}
+	for i, n := range nodes {
+		if n.Kind != "container" || !down(n) {
+			continue
+		}
+		root := true
+		for _, e := range edges {
+			if e.Source != n.ID || e.Kind == "network" {
+				continue
+			}
+			if j, ok := index[e.Target]; ok && down(nodes[j]) {
+				root = false
+				break
+			}
+		}
+		nodes[i].RootCause = root
+	}
+}
+
+// parseComposeDependsOn parses "svc:condition:restart,..." into service names.
+func parseComposeDependsOn(v string) []string {
+	if v == "" {
+		return nil
+	}
+	var out []string
+	for _, part := range strings.Split(v, ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(part), ":")
+		if name != "" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
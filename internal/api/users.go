@@ -0,0 +1,263 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/lsy88/uptime-chopper/internal/auth"
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/monitor"
+)
+
+type credentials struct {
+	Username string     `json:"username"`
+	Password string     `json:"password"`
+	Role     model.Role `json:"role,omitempty"`
+}
+
+func authRouter(deps Deps) http.Handler {
+	r := chi.NewRouter()
+	r.Post("/login", func(w http.ResponseWriter, r *http.Request) {
+		var c credentials
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		u := findUserByName(deps, c.Username)
+		hash := auth.DummyHash()
+		if u != nil {
+			hash = u.PasswordHash
+		}
+		if ok, err := auth.CheckPassword(hash, c.Password); u == nil || err != nil || !ok {
+			writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "invalid username or password"})
+			return
+		}
+
+		token, err := auth.NewToken()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		now := time.Now().UTC()
+		sess := model.Session{
+			TokenHash: auth.HashToken(token),
+			UserID:    u.ID,
+			CreatedAt: now,
+			ExpiresAt: now.Add(deps.Config.SessionTTL),
+		}
+		if err := deps.Store.CreateSession(sess); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     sessionCookie,
+			Value:    token,
+			Path:     "/",
+			Expires:  sess.ExpiresAt,
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+		})
+		writeJSON(w, http.StatusOK, map[string]any{
+			"token":     token,
+			"expiresAt": sess.ExpiresAt,
+			"user":      u.Public(),
+		})
+	})
+	r.Post("/logout", func(w http.ResponseWriter, r *http.Request) {
+		if token := requestToken(r); token != "" {
+			if err := deps.Store.DeleteSession(auth.HashToken(token)); err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+				return
+			}
+		}
+		http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: "", Path: "/", MaxAge: -1, HttpOnly: true})
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+	})
+	r.Get("/me", func(w http.ResponseWriter, r *http.Request) {
+		p := principalFrom(r.Context())
+		switch {
+		case p == nil:
+			writeJSON(w, http.StatusOK, map[string]any{"authenticated": false})
+		case p.APIKey:
+			writeJSON(w, http.StatusOK, map[string]any{"authenticated": true, "apiKey": true})
+		default:
+			writeJSON(w, http.StatusOK, map[string]any{"authenticated": true, "user": p.User.Public()})
+		}
+	})
+	return r
+}
+
+func usersRouter(deps Deps) http.Handler {
+	r := chi.NewRouter()
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		users := deps.Store.GetUsers()
+		out := make([]model.User, 0, len(users))
+		for _, u := range users {
+			out = append(out, u.Public())
+		}
+		writeJSON(w, http.StatusOK, out)
+	})
+	r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+		var c credentials
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		c.Username = strings.TrimSpace(c.Username)
+		if c.Role == "" {
+			c.Role = model.RoleViewer
+		}
+		var errs validationErrors
+		if c.Username == "" {
+			errs.add("username", "is required")
+		}
+		if c.Password == "" {
+			errs.add("password", "is required")
+		}
+		validatePassword(&errs, c.Password)
+		if !c.Role.Valid() {
+			errs.add("role", "must be viewer, operator or admin")
+		}
+		if len(errs) > 0 {
+			writeInvalid(w, errs)
+			return
+		}
+		if findUserByName(deps, c.Username) != nil {
+			writeJSON(w, http.StatusConflict, map[string]any{"error": "username already exists"})
+			return
+		}
+		u := model.User{ID: monitor.NewID(), Username: c.Username, Role: c.Role}
+		out, err := saveUser(deps, u, c.Password)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, out.Public())
+	})
+	r.Put("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		var c credentials
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		u := findUser(deps, id)
+		if u == nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "user not found"})
+			return
+		}
+		var errs validationErrors
+		validatePassword(&errs, c.Password)
+		if c.Role != "" && !c.Role.Valid() {
+			errs.add("role", "must be viewer, operator or admin")
+		}
+		if len(errs) > 0 {
+			writeInvalid(w, errs)
+			return
+		}
+		if name := strings.TrimSpace(c.Username); name != "" && name != u.Username {
+			if findUserByName(deps, name) != nil {
+				writeJSON(w, http.StatusConflict, map[string]any{"error": "username already exists"})
+				return
+			}
+			u.Username = name
+		}
+		if c.Role != "" {
+			if c.Role != model.RoleAdmin && isLastAdmin(deps, u.ID) {
+				writeJSON(w, http.StatusConflict, map[string]any{"error": "cannot demote the last admin"})
+				return
+			}
+			u.Role = c.Role
+		}
+		out, err := saveUser(deps, *u, c.Password)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		// A new password signs the user out everywhere.
+		if c.Password != "" {
+			if err := deps.Store.DeleteUserSessions(out.ID); err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+				return
+			}
+		}
+		writeJSON(w, http.StatusOK, out.Public())
+	})
+	r.Delete("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if isLastAdmin(deps, id) {
+			writeJSON(w, http.StatusConflict, map[string]any{"error": "cannot delete the last admin"})
+			return
+		}
+		if err := deps.Store.DeleteUser(id); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+	})
+	return r
+}
+
+// validatePassword rejects passwords bcrypt would refuse to hash.
+func validatePassword(errs *validationErrors, password string) {
+	if len(password) > auth.MaxPasswordBytes {
+		errs.add("password", fmt.Sprintf("must be at most %d bytes", auth.MaxPasswordBytes))
+	}
+}
+
+// saveUser hashes password, if given, and persists u.
+func saveUser(deps Deps, u model.User, password string) (model.User, error) {
+	if password != "" {
+		hash, err := auth.HashPassword(password)
+		if err != nil {
+			return model.User{}, err
+		}
+		u.PasswordHash = hash
+	}
+	if u.PasswordHash == "" {
+		return model.User{}, errors.New("password is required")
+	}
+	return deps.Store.UpsertUser(u)
+}
+
+// isLastAdmin reports whether id is the only remaining admin account.
+func isLastAdmin(deps Deps, id string) bool {
+	admins := 0
+	target := false
+	for _, u := range deps.Store.GetUsers() {
+		if u.EffectiveRole() == model.RoleAdmin {
+			admins++
+			if u.ID == id {
+				target = true
+			}
+		}
+	}
+	return target && admins == 1
+}
+
+func findUser(deps Deps, id string) *model.User {
+	for _, u := range deps.Store.GetUsers() {
+		if u.ID == id {
+			v := u
+			return &v
+		}
+	}
+	return nil
+}
+
+func findUserByName(deps Deps, username string) *model.User {
+	for _, u := range deps.Store.GetUsers() {
+		if strings.EqualFold(u.Username, username) {
+			v := u
+			return &v
+		}
+	}
+	return nil
+}
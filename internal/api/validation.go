@@ -0,0 +1,72 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// fieldError describes one invalid field of a request body. Field is the
+// JSON path of the value, e.g. "http.url", or "[2].name" in bulk requests.
+type fieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// validationErrors collects every problem with a request body so that
+// clients can show them all at once.
+type validationErrors []fieldError
+
+func (v *validationErrors) add(field, message string) {
+	*v = append(*v, fieldError{Field: field, Message: message})
+}
+
+// addErr records err, if any, as the message for field.
+func (v *validationErrors) addErr(field string, err error) {
+	if err != nil {
+		v.add(field, err.Error())
+	}
+}
+
+// prefixed returns the errors with every field nested under prefix.
+func (v validationErrors) prefixed(prefix string) validationErrors {
+	out := make(validationErrors, len(v))
+	for i, fe := range v {
+		out[i] = fieldError{Field: prefix + "." + fe.Field, Message: fe.Message}
+		if strings.HasPrefix(fe.Field, "[") {
+			out[i].Field = prefix + fe.Field
+		}
+	}
+	return out
+}
+
+// err returns v as an error, or nil when nothing was recorded.
+func (v validationErrors) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+func (v validationErrors) Error() string {
+	parts := make([]string, len(v))
+	for i, fe := range v {
+		parts[i] = fe.Field + ": " + fe.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// writeInvalid responds 422 with the field errors in err, or 400 when err
+// is not a validation error.
+func writeInvalid(w http.ResponseWriter, err error) {
+	var v validationErrors
+	if errors.As(err, &v) {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"error": v.Error(), "errors": v})
+		return
+	}
+	writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+}
+
+// errMaskedCredential explains why a masked credential was not kept: it is
+// only put back while the type and target host stay the same.
+const errMaskedCredential = "masked credential is only kept while the type and target stay the same; enter it again"
@@ -0,0 +1,95 @@
+// Package auth implements password hashing and session tokens for user
+// accounts.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/store"
+)
+
+// hashCost is the bcrypt work factor for new password hashes.
+const hashCost = 12
+
+// MaxPasswordBytes is the longest password bcrypt accepts.
+const MaxPasswordBytes = 72
+
+var ErrInvalidHash = errors.New("invalid password hash")
+
+// HashPassword returns a salted bcrypt hash of password.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), hashCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password matches hash.
+func CheckPassword(hash, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return false, nil
+	default:
+		return false, ErrInvalidHash
+	}
+}
+
+// DummyHash returns a hash no password matches. Comparing against it when
+// a login names an unknown user costs as much as checking a real account,
+// so response times do not reveal which usernames exist.
+var DummyHash = sync.OnceValue(func() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	hash, _ := HashPassword(base64.RawStdEncoding.EncodeToString(b))
+	return hash
+})
+
+// NewToken returns a random session token.
+func NewToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the form of a session token that is persisted.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// BootstrapAdmin creates the initial account from config when the store has
+// no users yet. It reports whether a user was created.
+func BootstrapAdmin(st store.Store, username, password string) (bool, error) {
+	if password == "" || len(st.GetUsers()) > 0 {
+		return false, nil
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return false, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return false, err
+	}
+	_, err = st.UpsertUser(model.User{
+		ID:           hex.EncodeToString(id),
+		Username:     username,
+		Role:         model.RoleAdmin,
+		PasswordHash: hash,
+	})
+	return err == nil, err
+}
@@ -1,48 +1,227 @@
 package config
 
 import (
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
 )
 
 type NotificationWebhook struct {
 	Name string `mapstructure:"name" yaml:"name"`
 	URL  string `mapstructure:"url" yaml:"url"`
-	Type string `mapstructure:"type" yaml:"type"` // webhook, dingtalk, wechat
+	Type string `mapstructure:"type" yaml:"type"` // webhook, dingtalk, wechat, discord, telegram, feishu, pushover
+
+	// Telegram
+	BotToken string `mapstructure:"bot_token" yaml:"bot_token"`
+	ChatID   string `mapstructure:"chat_id" yaml:"chat_id"`
+
+	// Secret signs requests for providers that support it (feishu).
+	Secret string `mapstructure:"secret" yaml:"secret"`
+
+	// Pushover
+	UserKey  string `mapstructure:"user_key" yaml:"user_key"`
+	AppToken string `mapstructure:"app_token" yaml:"app_token"`
+
+	// Generic webhook
+	Method       string            `mapstructure:"method" yaml:"method"` // default POST
+	Headers      map[string]string `mapstructure:"headers" yaml:"headers"`
+	BearerToken  string            `mapstructure:"bearer_token" yaml:"bearer_token"`
+	BodyTemplate string            `mapstructure:"body_template" yaml:"body_template"` // Go template over notify.Payload
+}
+
+// DockerHost is an additional Docker daemon that container monitors can
+// target by Name. Host is a unix://, tcp:// or ssh://user@host URL; for tcp
+// with TLS, the TLS* fields are paths to PEM files.
+type DockerHost struct {
+	Name      string `mapstructure:"name" yaml:"name"`
+	Host      string `mapstructure:"host" yaml:"host"`
+	TLSCACert string `mapstructure:"tls_ca_cert" yaml:"tls_ca_cert"`
+	TLSCert   string `mapstructure:"tls_cert" yaml:"tls_cert"`
+	TLSKey    string `mapstructure:"tls_key" yaml:"tls_key"`
+}
+
+// Agent is a remote agent allowed to run checks for monitors assigned to
+// it by Name. It authenticates with Token as a bearer token.
+type Agent struct {
+	Name  string `mapstructure:"name" yaml:"name"`
+	Token string `mapstructure:"token" yaml:"token"`
 }
 
 type Config struct {
 	HTTPAddr              string                `mapstructure:"http_addr" yaml:"http_addr"`
 	DataFilePath          string                `mapstructure:"data_file_path" yaml:"data_file_path"`
+	StoreBackend          string                `mapstructure:"store_backend" yaml:"store_backend"` // sqlite (default) or json
+	JSONDataFilePath      string                `mapstructure:"json_data_file_path" yaml:"json_data_file_path"`
 	Notifications         []NotificationWebhook `mapstructure:"notifications" yaml:"notifications"`
 	MaxDockerLogBytes     int                   `mapstructure:"max_docker_log_bytes" yaml:"max_docker_log_bytes"`
 	DefaultDockerLogSince time.Duration         `mapstructure:"default_docker_log_since" yaml:"default_docker_log_since"`
 	AllowedCORSOrigin     string                `mapstructure:"allowed_cors_origin" yaml:"allowed_cors_origin"`
 	ServeFrontendFromDist bool                  `mapstructure:"serve_frontend_from_dist" yaml:"serve_frontend_from_dist"`
 	FrontendDistDirectory string                `mapstructure:"frontend_dist_directory" yaml:"frontend_dist_directory"`
+	LatencyBucketsMs      []int                 `mapstructure:"latency_buckets_ms" yaml:"latency_buckets_ms"`
+	PersistHistograms     bool                  `mapstructure:"persist_histograms" yaml:"persist_histograms"`
+	HistoryLogBudgetBytes int                   `mapstructure:"history_log_budget_bytes" yaml:"history_log_budget_bytes"`
+	// HistoryRetentionDays is how many days of history are kept for
+	// monitors that do not set their own retentionDays. Zero keeps history
+	// forever.
+	HistoryRetentionDays int `mapstructure:"history_retention_days" yaml:"history_retention_days"`
+	// PruneInterval is how often old history is deleted. VacuumInterval is
+	// the minimum time between compactions of the SQLite file, which only
+	// run after pruning removed history.
+	PruneInterval  time.Duration `mapstructure:"prune_interval" yaml:"prune_interval"`
+	VacuumInterval time.Duration `mapstructure:"vacuum_interval" yaml:"vacuum_interval"`
+	// HistoryFlushInterval batches history writes of the engine into one
+	// transaction per interval, or per HistoryBatchSize entries when they
+	// pile up sooner. A negative interval writes every check immediately.
+	HistoryFlushInterval time.Duration `mapstructure:"history_flush_interval" yaml:"history_flush_interval"`
+	HistoryBatchSize     int           `mapstructure:"history_batch_size" yaml:"history_batch_size"`
+	// APIKeys enables API authentication when non-empty. Requests must send
+	// one of the keys as a bearer token or in the X-API-Key header.
+	APIKeys []string `mapstructure:"api_keys" yaml:"api_keys"`
+	// AuthExemptPaths lists paths served without authentication. A trailing
+	// "/*" matches any path below the prefix.
+	AuthExemptPaths []string `mapstructure:"auth_exempt_paths" yaml:"auth_exempt_paths"`
+	// AdminUsername and AdminPassword bootstrap the first user account when
+	// no users exist yet. Once any user exists, the API requires a login
+	// session (or an API key).
+	AdminUsername string        `mapstructure:"admin_username" yaml:"admin_username"`
+	AdminPassword string        `mapstructure:"admin_password" yaml:"admin_password"`
+	SessionTTL    time.Duration `mapstructure:"session_ttl" yaml:"session_ttl"`
+	// AlertDigestWindow coalesces status changes sent to the same channel
+	// within this window into a single grouped notification. Zero disables
+	// grouping.
+	AlertDigestWindow time.Duration `mapstructure:"alert_digest_window" yaml:"alert_digest_window"`
+	// DockerHosts lists remote Docker daemons in addition to the local one
+	// taken from DOCKER_HOST.
+	DockerHosts []DockerHost `mapstructure:"docker_hosts" yaml:"docker_hosts"`
+	// Kubeconfig is the kubeconfig used by kubernetes monitors. When empty,
+	// the in-cluster service account is used inside a pod, otherwise
+	// $KUBECONFIG or ~/.kube/config. KubeContext overrides its current
+	// context.
+	Kubeconfig  string `mapstructure:"kubeconfig" yaml:"kubeconfig"`
+	KubeContext string `mapstructure:"kube_context" yaml:"kube_context"`
+	// ImageUpdateInterval is how often the images of monitored containers
+	// are compared with their registry. Zero disables the check.
+	ImageUpdateInterval time.Duration `mapstructure:"image_update_interval" yaml:"image_update_interval"`
+	// DockerPingInterval is how often every Docker daemon is pinged to
+	// track its availability. Negative disables the ping.
+	DockerPingInterval time.Duration `mapstructure:"docker_ping_interval" yaml:"docker_ping_interval"`
+	// DrainTimeout is how long shutdown waits for running checks to record
+	// their results.
+	DrainTimeout time.Duration `mapstructure:"drain_timeout" yaml:"drain_timeout"`
+	// CheckJitter adds a random delay of up to this value (and at most a
+	// quarter of the interval) to every scheduled check. Checks are spread
+	// across their interval by monitor ID even without it.
+	CheckJitter time.Duration `mapstructure:"check_jitter" yaml:"check_jitter"`
+	// ProvisioningFile is a YAML file of monitors reconciled on startup and
+	// on SIGHUP. Empty disables provisioning.
+	ProvisioningFile string `mapstructure:"provisioning_file" yaml:"provisioning_file"`
+	// Agents lists the remote agents that may run checks and report their
+	// results.
+	Agents []Agent `mapstructure:"agents" yaml:"agents"`
+	// TLSCertFile and TLSKeyFile serve HTTPS with a PEM certificate and key
+	// instead of plain HTTP.
+	TLSCertFile string `mapstructure:"tls_cert_file" yaml:"tls_cert_file"`
+	TLSKeyFile  string `mapstructure:"tls_key_file" yaml:"tls_key_file"`
+	// ACMEDomains serves HTTPS with certificates obtained from Let's
+	// Encrypt (or ACMEDirectoryURL) for these domains, cached in
+	// ACMECacheDir. Certificates are issued over TLS-ALPN, which needs
+	// HTTPAddr on port 443, or over HTTP when ACMEHTTPAddr (usually ":80")
+	// is set; that listener also redirects other requests to HTTPS.
+	ACMEDomains      []string `mapstructure:"acme_domains" yaml:"acme_domains"`
+	ACMEEmail        string   `mapstructure:"acme_email" yaml:"acme_email"`
+	ACMECacheDir     string   `mapstructure:"acme_cache_dir" yaml:"acme_cache_dir"`
+	ACMEDirectoryURL string   `mapstructure:"acme_directory_url" yaml:"acme_directory_url"`
+	ACMEHTTPAddr     string   `mapstructure:"acme_http_addr" yaml:"acme_http_addr"`
+	// SecretKey encrypts the secrets saved through the API. Without it only
+	// secrets from UPTIME_SECRET_* environment variables can be used.
+	SecretKey string `mapstructure:"secret_key" yaml:"secret_key"`
+	// ContainerExecCommands lists the commands that may be run inside
+	// containers through the API, e.g. "nginx -t". A request must match an
+	// entry split on whitespace exactly; empty disables the endpoint.
+	ContainerExecCommands []string `mapstructure:"container_exec_commands" yaml:"container_exec_commands"`
+	// AllowHostCommands enables exec monitors and script remediation, which
+	// run commands on the server or agent host. Only admins can set their
+	// commands.
+	AllowHostCommands bool `mapstructure:"allow_host_commands" yaml:"allow_host_commands"`
+	// TrustedProxies lists the reverse proxies, as IPs or CIDRs, whose
+	// X-Forwarded-For and X-Real-IP headers are believed. Requests from any
+	// other peer are attributed to the peer address itself.
+	TrustedProxies []string `mapstructure:"trusted_proxies" yaml:"trusted_proxies"`
 }
 
+// Load reads config.yaml from the working directory or ./config, with
+// UPTIME_CHOPPER_* environment variables taking precedence.
 func Load() (*Config, error) {
+	v := newViper()
+	if err := v.ReadInConfig(); err != nil {
+		// It's okay if config file doesn't exist, unless explicitly specified
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return nil, err
+		}
+	}
+	return decode(v)
+}
+
+// reloadDelay lets editors finish writing the config file before it is
+// read again; they often truncate it first.
+const reloadDelay = 200 * time.Millisecond
+
+// Watch calls onChange with the reloaded config every time the config file
+// is written, or onError when it cannot be loaded; the previous config then
+// stays in effect. It reports false when there is no config file to watch.
+func Watch(onChange func(*Config), onError func(error)) bool {
+	v := newViper()
+	if err := v.ReadInConfig(); err != nil {
+		return false
+	}
+	var (
+		mu    sync.Mutex
+		timer *time.Timer
+	)
+	v.OnConfigChange(func(fsnotify.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		if timer != nil {
+			timer.Stop()
+		}
+		timer = time.AfterFunc(reloadDelay, func() {
+			cfg, err := Load()
+			if err != nil {
+				onError(err)
+				return
+			}
+			onChange(cfg)
+		})
+	})
+	v.WatchConfig()
+	return true
+}
+
+func newViper() *viper.Viper {
 	v := viper.New()
 
 	v.SetEnvPrefix("UPTIME_CHOPPER")
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
+	bindEnv(v)
 
 	v.SetConfigName("config")
 	v.SetConfigType("yaml")
 	v.AddConfigPath(".")
 	v.AddConfigPath("./config")
+	return v
+}
 
-	if err := v.ReadInConfig(); err != nil {
-		// It's okay if config file doesn't exist, unless explicitly specified
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return nil, err
-		}
-	}
-
+// decode unmarshals the config read by v and fills in the defaults.
+func decode(v *viper.Viper) (*Config, error) {
 	var cfg Config
 	if err := v.Unmarshal(&cfg); err != nil {
 		return nil, err
@@ -51,6 +230,74 @@ func Load() (*Config, error) {
 	if cfg.DataFilePath == "" {
 		cfg.DataFilePath = "data/data.db"
 	}
+	cfg.StoreBackend = strings.ToLower(strings.TrimSpace(cfg.StoreBackend))
+	if cfg.StoreBackend == "" {
+		cfg.StoreBackend = "sqlite"
+	}
+	if cfg.JSONDataFilePath == "" {
+		cfg.JSONDataFilePath = "data/data.json"
+	}
+	if len(cfg.LatencyBucketsMs) == 0 {
+		cfg.LatencyBucketsMs = []int{50, 100, 250, 500, 1000, 2500, 5000, 10000}
+	}
+	sort.Ints(cfg.LatencyBucketsMs)
+	if cfg.HistoryLogBudgetBytes == 0 {
+		cfg.HistoryLogBudgetBytes = 1 << 20
+	}
+	if cfg.AuthExemptPaths == nil {
+		cfg.AuthExemptPaths = []string{"/api/health", "/api/public/*", "/api/badge/*", "/api/auth/login", "/api/push/*", "/api/agent/*"}
+	}
+	if cfg.AdminUsername == "" {
+		cfg.AdminUsername = "admin"
+	}
+	if cfg.SessionTTL <= 0 {
+		cfg.SessionTTL = 24 * time.Hour
+	}
+	if cfg.PruneInterval <= 0 {
+		cfg.PruneInterval = time.Hour
+	}
+	if cfg.VacuumInterval <= 0 {
+		cfg.VacuumInterval = 24 * time.Hour
+	}
+	if cfg.HistoryFlushInterval == 0 {
+		cfg.HistoryFlushInterval = time.Second
+	}
+	if cfg.HistoryBatchSize <= 0 {
+		cfg.HistoryBatchSize = 500
+	}
+	if cfg.DrainTimeout <= 0 {
+		cfg.DrainTimeout = 10 * time.Second
+	}
+	if cfg.DockerPingInterval == 0 {
+		cfg.DockerPingInterval = 30 * time.Second
+	}
+	if cfg.ACMECacheDir == "" {
+		cfg.ACMECacheDir = "data/acme"
+	}
 
 	return &cfg, nil
 }
+
+// Settings returns the runtime settings given by the config file, used
+// until settings are saved through the API.
+func (c *Config) Settings() model.Settings {
+	return model.Settings{
+		DefaultIntervalSeconds: 60,
+		DefaultTimeoutSeconds:  10,
+		HistoryRetentionDays:   c.HistoryRetentionDays,
+		MaxDockerLogBytes:      c.MaxDockerLogBytes,
+		HistoryLogBudgetBytes:  c.HistoryLogBudgetBytes,
+	}
+}
+
+// bindEnv registers every config key with viper so that environment
+// variables are honoured even when the key is absent from config.yaml;
+// AutomaticEnv alone only overrides keys viper already knows about.
+func bindEnv(v *viper.Viper) {
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		if key := t.Field(i).Tag.Get("mapstructure"); key != "" {
+			_ = v.BindEnv(key)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@never",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q): want error", expr)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	// 2024-01-01 is a Monday.
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2024, time.January, day, hour, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		expr string
+		t    time.Time
+		want bool
+	}{
+		{"* * * * *", at(1, 0, 0), true},
+		{"30 2 * * *", at(1, 2, 30), true},
+		{"30 2 * * *", at(1, 2, 31), false},
+		{"*/15 * * * *", at(1, 5, 45), true},
+		{"*/15 * * * *", at(1, 5, 50), false},
+		{"5/20 * * * *", at(1, 5, 45), true},
+		{"0 9-17 * * *", at(1, 17, 0), true},
+		{"0 9-17 * * *", at(1, 18, 0), false},
+		{"0 0 1,15 * *", at(15, 0, 0), true},
+		{"0 0 * * 0", at(7, 0, 0), true},
+		{"0 0 * * 7", at(7, 0, 0), true},
+		{"0 0 * * 1-5", at(6, 0, 0), false},
+		{"@daily", at(3, 0, 0), true},
+		{"@hourly", at(3, 4, 1), false},
+		// Both day fields restricted: either may match.
+		{"0 0 13 * 5", at(5, 0, 0), true},
+		{"0 0 13 * 5", at(13, 0, 0), true},
+		{"0 0 13 * 5", at(14, 0, 0), false},
+		// A stepped "*" still leaves the field unrestricted, so the other
+		// day field must match as well.
+		{"0 0 */2 * 1", at(1, 0, 0), true},
+		{"0 0 */2 * 1", at(3, 0, 0), false},
+		{"0 0 */2 * 1", at(8, 0, 0), false},
+		{"0 0 1 * */2", at(1, 0, 0), false},
+		{"0 0 1 * */2", at(2, 0, 0), false},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.expr, err)
+		}
+		if got := s.Matches(tt.t); got != tt.want {
+			t.Errorf("%q.Matches(%s) = %v, want %v", tt.expr, tt.t.Format(time.RFC3339), got, tt.want)
+		}
+	}
+}
+
+func TestNextPrev(t *testing.T) {
+	from := time.Date(2024, time.January, 1, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		expr       string
+		next, prev time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, 1, 1, 10, 15, 0, 0, time.UTC), time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)},
+		{"0 0 * * *", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 29 2 *", time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC), time.Date(2020, 2, 29, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.expr, err)
+		}
+		if got, ok := s.Next(from); !ok || !got.Equal(tt.next) {
+			t.Errorf("%q.Next = %s, %v; want %s", tt.expr, got, ok, tt.next)
+		}
+		if got, ok := s.Prev(from); !ok || !got.Equal(tt.prev) {
+			t.Errorf("%q.Prev = %s, %v; want %s", tt.expr, got, ok, tt.prev)
+		}
+	}
+
+	never, err := Parse("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := never.Next(from); ok {
+		t.Error("Next of an impossible date: want none")
+	}
+}
+
+func TestLastBefore(t *testing.T) {
+	s, err := Parse("0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 1, 1, 10, 20, 0, 0, time.UTC)
+	if got, ok := s.LastBefore(now, 30*time.Minute); !ok || got.Minute() != 0 || got.Hour() != 10 {
+		t.Errorf("LastBefore(30m) = %s, %v", got, ok)
+	}
+	if _, ok := s.LastBefore(now, 10*time.Minute); ok {
+		t.Error("LastBefore(10m): want none")
+	}
+}
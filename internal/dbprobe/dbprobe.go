@@ -0,0 +1,135 @@
+// Package dbprobe checks that a database server accepts connections and
+// serves requests. It speaks just enough of the MySQL, PostgreSQL, Redis
+// and MongoDB wire protocols to log in and, optionally, run a trivial
+// query; it is not a general purpose client.
+package dbprobe
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/netdial"
+)
+
+type Kind string
+
+const (
+	MySQL    Kind = "mysql"
+	Postgres Kind = "postgres"
+	Redis    Kind = "redis"
+	MongoDB  Kind = "mongodb"
+)
+
+// Info describes the server reached by a probe.
+type Info struct {
+	// Version is the server version, when the handshake reports it.
+	Version string
+}
+
+// Probe connects to the server described by dsn through d, which may be
+// nil, and logs in. With query set it also runs SELECT 1 (MySQL,
+// PostgreSQL) or ping (MongoDB); Redis has no handshake of its own and is
+// always sent PING.
+func Probe(ctx context.Context, d *netdial.Dialer, kind Kind, dsn string, query bool) (Info, error) {
+	if dsn == "" {
+		return Info{}, errors.New("missing dsn")
+	}
+	switch kind {
+	case MySQL:
+		return probeMySQL(ctx, d, dsn, query)
+	case Postgres:
+		return probePostgres(ctx, d, dsn, query)
+	case Redis:
+		return probeRedis(ctx, d, dsn)
+	case MongoDB:
+		return probeMongo(ctx, d, dsn, query)
+	}
+	return Info{}, fmt.Errorf("unsupported database %q", kind)
+}
+
+// Redact returns dsn with its password replaced, for display.
+func Redact(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+	// user:pass@tcp(host)/db style MySQL DSNs.
+	if at := strings.LastIndex(dsn, "@"); at >= 0 {
+		if user, _, ok := strings.Cut(dsn[:at], ":"); ok {
+			return user + ":xxxxx" + dsn[at:]
+		}
+	}
+	return dsn
+}
+
+// dial opens a TCP connection whose reads and writes fail once ctx is done.
+func dial(ctx context.Context, d *netdial.Dialer, addr string) (net.Conn, error) {
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(dl)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	return &ctxConn{Conn: conn, stop: stop}, nil
+}
+
+type ctxConn struct {
+	net.Conn
+	stop func() bool
+}
+
+func (c *ctxConn) Close() error {
+	c.stop()
+	return c.Conn.Close()
+}
+
+// startTLS upgrades conn. The deadline set by dial carries over.
+func startTLS(ctx context.Context, conn net.Conn, host string, insecure bool) (net.Conn, error) {
+	tc := tls.Client(conn, &tls.Config{ServerName: host, InsecureSkipVerify: insecure})
+	if err := tc.HandshakeContext(ctx); err != nil {
+		return nil, fmt.Errorf("tls: %w", err)
+	}
+	return tc, nil
+}
+
+// hostPort returns the address of u, filling in the default port.
+func hostPort(u *url.URL, port string) string {
+	host := u.Hostname()
+	if host == "" {
+		host = "localhost"
+	}
+	if p := u.Port(); p != "" {
+		port = p
+	}
+	return net.JoinHostPort(host, port)
+}
+
+func parseURL(dsn string, schemes ...string) (*url.URL, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dsn: %w", err)
+	}
+	for _, s := range schemes {
+		if u.Scheme == s {
+			return u, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid dsn: scheme must be %s", strings.Join(schemes, " or "))
+}
+
+func boolParam(q url.Values, names ...string) bool {
+	for _, n := range names {
+		switch strings.ToLower(q.Get(n)) {
+		case "1", "true", "yes":
+			return true
+		}
+	}
+	return false
+}
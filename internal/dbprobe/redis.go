@@ -0,0 +1,107 @@
+package dbprobe
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/lsy88/uptime-chopper/internal/netdial"
+)
+
+// probeRedis authenticates, selects the database in the path, if any, and
+// sends PING. DSNs look like redis://:password@host:6379/0; use rediss://
+// for TLS, with ?skip_verify=true to accept any certificate.
+func probeRedis(ctx context.Context, d *netdial.Dialer, dsn string) (Info, error) {
+	u, err := parseURL(dsn, "redis", "rediss")
+	if err != nil {
+		return Info{}, err
+	}
+	conn, err := dial(ctx, d, hostPort(u, "6379"))
+	if err != nil {
+		return Info{}, err
+	}
+	defer conn.Close()
+	if u.Scheme == "rediss" {
+		if conn, err = startTLS(ctx, conn, u.Hostname(), boolParam(u.Query(), "skip_verify")); err != nil {
+			return Info{}, err
+		}
+		defer conn.Close()
+	}
+	rd := bufio.NewReader(conn)
+	call := func(args ...string) (string, error) {
+		if _, err := conn.Write(respCommand(args...)); err != nil {
+			return "", err
+		}
+		return readRESP(rd)
+	}
+
+	if u.User != nil {
+		user := u.User.Username()
+		pass, _ := u.User.Password()
+		args := []string{"AUTH", pass}
+		if user != "" {
+			args = []string{"AUTH", user, pass}
+		}
+		if _, err := call(args...); err != nil {
+			return Info{}, fmt.Errorf("auth: %w", err)
+		}
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" && db != "0" {
+		if _, err := call("SELECT", db); err != nil {
+			return Info{}, fmt.Errorf("select %s: %w", db, err)
+		}
+	}
+	reply, err := call("PING")
+	if err != nil {
+		return Info{}, err
+	}
+	if reply != "PONG" {
+		return Info{}, fmt.Errorf("unexpected PING reply %q", reply)
+	}
+	_, _ = conn.Write(respCommand("QUIT"))
+	return Info{}, nil
+}
+
+func respCommand(args ...string) []byte {
+	b := []byte("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, a := range args {
+		b = append(b, "$"+strconv.Itoa(len(a))+"\r\n"+a+"\r\n"...)
+	}
+	return b
+}
+
+// readRESP reads a simple string, error, integer or bulk string reply.
+func readRESP(rd *bufio.Reader) (string, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return "", errors.New("empty reply")
+	}
+	switch line[0] {
+	case '+', ':':
+		return line[1:], nil
+	case '-':
+		return "", errors.New(line[1:])
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return "", fmt.Errorf("malformed reply %q", line)
+		}
+		if n < 0 {
+			return "", nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(rd, buf); err != nil {
+			return "", err
+		}
+		return string(buf[:n]), nil
+	}
+	return "", fmt.Errorf("unexpected reply %q", line)
+}
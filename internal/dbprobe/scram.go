@@ -0,0 +1,106 @@
+package dbprobe
+
+import (
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+)
+
+// scram is the client side of a SCRAM exchange (RFC 5802) without channel
+// binding, as used by PostgreSQL and MongoDB.
+type scram struct {
+	newHash  func() hash.Hash
+	user     string
+	password string
+
+	nonce       string
+	clientFirst string // client-first-message-bare
+	authMessage string
+	saltedPass  []byte
+}
+
+func newSCRAM(newHash func() hash.Hash, user, password string) (*scram, error) {
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	s := &scram{newHash: newHash, user: user, password: password, nonce: base64.StdEncoding.EncodeToString(b)}
+	name := strings.NewReplacer("=", "=3D", ",", "=2C").Replace(user)
+	s.clientFirst = "n=" + name + ",r=" + s.nonce
+	return s, nil
+}
+
+// first returns the client-first-message.
+func (s *scram) first() []byte {
+	return []byte("n,," + s.clientFirst)
+}
+
+// final answers the server-first-message with the client-final-message.
+func (s *scram) final(serverFirst []byte) ([]byte, error) {
+	attrs := scramAttrs(string(serverFirst))
+	nonce, salt64, iter := attrs["r"], attrs["s"], attrs["i"]
+	if !strings.HasPrefix(nonce, s.nonce) || nonce == s.nonce {
+		return nil, errors.New("scram: server nonce mismatch")
+	}
+	salt, err := base64.StdEncoding.DecodeString(salt64)
+	if err != nil {
+		return nil, fmt.Errorf("scram: bad salt: %w", err)
+	}
+	n, err := strconv.Atoi(iter)
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("scram: bad iteration count %q", iter)
+	}
+	s.saltedPass, err = pbkdf2.Key(s.newHash, s.password, salt, n, s.newHash().Size())
+	if err != nil {
+		return nil, err
+	}
+
+	withoutProof := "c=biws,r=" + nonce
+	s.authMessage = s.clientFirst + "," + string(serverFirst) + "," + withoutProof
+	clientKey := s.hmac(s.saltedPass, "Client Key")
+	h := s.newHash()
+	h.Write(clientKey)
+	sig := s.hmac(h.Sum(nil), s.authMessage)
+	proof := make([]byte, len(clientKey))
+	for i := range clientKey {
+		proof[i] = clientKey[i] ^ sig[i]
+	}
+	return []byte(withoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof)), nil
+}
+
+// verify checks the server-final-message, proving the server knows the
+// password too.
+func (s *scram) verify(serverFinal []byte) error {
+	attrs := scramAttrs(string(serverFinal))
+	if e := attrs["e"]; e != "" {
+		return fmt.Errorf("scram: %s", e)
+	}
+	want := s.hmac(s.hmac(s.saltedPass, "Server Key"), s.authMessage)
+	got, err := base64.StdEncoding.DecodeString(attrs["v"])
+	if err != nil || !hmac.Equal(got, want) {
+		return errors.New("scram: invalid server signature")
+	}
+	return nil
+}
+
+func (s *scram) hmac(key []byte, msg string) []byte {
+	m := hmac.New(s.newHash, key)
+	m.Write([]byte(msg))
+	return m.Sum(nil)
+}
+
+func scramAttrs(msg string) map[string]string {
+	out := map[string]string{}
+	for _, part := range strings.Split(msg, ",") {
+		if k, v, ok := strings.Cut(part, "="); ok {
+			out[k] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package docker
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+const ComposeProjectLabel = "com.docker.compose.project"
+
+// Project is a Docker Compose project and its containers.
+type Project struct {
+	Name       string             `json:"name"`
+	Running    int                `json:"running"`
+	Total      int                `json:"total"`
+	Containers []ContainerSummary `json:"containers"`
+}
+
+// GroupByProject groups containers by compose project, sorted by name.
+// Containers that are not part of a project are left out.
+func GroupByProject(cs []ContainerSummary) []Project {
+	byName := map[string]*Project{}
+	for _, c := range cs {
+		if c.Project == "" {
+			continue
+		}
+		p := byName[c.Project]
+		if p == nil {
+			p = &Project{Name: c.Project}
+			byName[c.Project] = p
+		}
+		p.Total++
+		if c.State == "running" {
+			p.Running++
+		}
+		p.Containers = append(p.Containers, c)
+	}
+	out := make([]Project, 0, len(byName))
+	for _, p := range byName {
+		sort.Slice(p.Containers, func(i, j int) bool { return p.Containers[i].Name < p.Containers[j].Name })
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// ProjectContainers lists the containers of one compose project.
+func (c *Client) ProjectContainers(ctx context.Context, project string) ([]ContainerSummary, error) {
+	cs, err := c.ListContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := cs[:0]
+	for _, ct := range cs {
+		if ct.Project == project {
+			out = append(out, ct)
+		}
+	}
+	return out, nil
+}
+
+// Unhealthy reports whether the container is failing its health check,
+// judging by the status text of the container list ("Up 5 minutes (unhealthy)").
+func (s ContainerSummary) Unhealthy() bool {
+	return strings.Contains(s.Status, "(unhealthy)")
+}
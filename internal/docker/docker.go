@@ -7,14 +7,19 @@ import (
 	"fmt"
 	"io"
 	"math/rand"
+	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
 )
 
-var ErrDockerUnavailable = errors.New("docker unavailable")
+var (
+	ErrDockerUnavailable = errors.New("docker unavailable")
+	ErrContainerNotFound = errors.New("container not found")
+)
 
 type Client struct {
 	cli     *client.Client
@@ -31,13 +36,52 @@ type ContainerSummary struct {
 	Status        string            `json:"status"`
 	Labels        map[string]string `json:"labels"`
 	Names         []string          `json:"names"`
-	RestartPolicy string            `json:"restart_policy"` // For mock
+	Networks      []string          `json:"networks,omitempty"`
+	Project       string            `json:"project,omitempty"` // compose project
+	RestartPolicy string            `json:"restart_policy"`    // For mock
+	Health        string            `json:"health,omitempty"`  // starting, healthy or unhealthy; empty without HEALTHCHECK
+	Created       time.Time         `json:"created"`
+	RestartCount  int               `json:"restartCount"` // Set by FillRestartCounts
+	Stats         *Stats            `json:"stats,omitempty"`
+	Update        *ImageUpdate      `json:"update,omitempty"`
+}
+
+// StateInfo is the runtime state of a container. Health is empty when the
+// container defines no HEALTHCHECK, otherwise "starting", "healthy" or
+// "unhealthy"; HealthOutput is the output of the latest probe. RestartCount
+// counts the restarts done by the daemon's restart policy.
+type StateInfo struct {
+	Status       string
+	Health       string
+	HealthOutput string
+	OOMKilled    bool
+	RestartCount int
+}
+
+// Up reports whether the container is running and not failing its health
+// check. A container still in its health check start period counts as up.
+func (s StateInfo) Up() bool {
+	return s.Status == "running" && s.Health != "unhealthy"
+}
+
+func (s StateInfo) String() string {
+	if s.OOMKilled && s.Status != "running" {
+		return s.Status + " (out of memory)"
+	}
+	if s.Health == "" {
+		return s.Status
+	}
+	msg := s.Status + " (" + s.Health + ")"
+	if s.Health == "unhealthy" && s.HealthOutput != "" {
+		msg += ": " + s.HealthOutput
+	}
+	return msg
 }
 
 func NewClient() (*Client, error) {
 	// Try connecting to real Docker
 	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
-	
+
 	useMock := false
 	if err == nil {
 		// Verify connection
@@ -56,100 +100,116 @@ func NewClient() (*Client, error) {
 			isMock: true,
 			mockDB: map[string]*ContainerSummary{
 				"mock-1": {
-					ID:     "mock-1",
-					Name:   "mock-postgres",
-					Names:  []string{"/mock-postgres"},
-					Image:  "postgres:15",
-					State:  "running",
-					Status: "Up 2 hours",
+					ID:            "mock-1",
+					Name:          "mock-postgres",
+					Names:         []string{"/mock-postgres"},
+					Image:         "postgres:15",
+					State:         "running",
+					Status:        "Up 2 hours",
 					RestartPolicy: "always",
+					Project:       "demo",
 				},
 				"mock-2": {
-					ID:     "mock-2",
-					Name:   "mock-nginx",
-					Names:  []string{"/mock-nginx"},
-					Image:  "nginx:latest",
-					State:  "exited",
-					Status: "Exited (0) 10 minutes ago",
+					ID:            "mock-2",
+					Name:          "mock-nginx",
+					Names:         []string{"/mock-nginx"},
+					Image:         "nginx:latest",
+					State:         "exited",
+					Status:        "Exited (0) 10 minutes ago",
 					RestartPolicy: "no",
 				},
 				"mock-3": {
-					ID:     "mock-3",
-					Name:   "mock-redis",
-					Names:  []string{"/mock-redis"},
-					Image:  "redis:alpine",
-					State:  "running",
-					Status: "Up 5 days",
+					ID:            "mock-3",
+					Name:          "mock-redis",
+					Names:         []string{"/mock-redis"},
+					Image:         "redis:alpine",
+					State:         "running",
+					Status:        "Up 5 days",
 					RestartPolicy: "on-failure",
+					Project:       "demo",
 				},
 			},
 		}, nil
 	}
-	
+
 	return &Client{cli: cli}, nil
 }
 
 func (c *Client) ListContainers(ctx context.Context) ([]ContainerSummary, error) {
+	return c.FindContainers(ctx, ContainerFilter{})
+}
+
+func (c *Client) ContainerState(ctx context.Context, id string) (StateInfo, error) {
 	if c.isMock {
 		c.mockMux.Lock()
 		defer c.mockMux.Unlock()
-		out := make([]ContainerSummary, 0, len(c.mockDB))
-		for _, v := range c.mockDB {
-			out = append(out, *v)
+		if ct, ok := c.mockDB[id]; ok {
+			return StateInfo{Status: ct.State, Health: ct.Health}, nil
 		}
-		return out, nil
+		return StateInfo{}, ErrContainerNotFound
 	}
 
 	if c == nil || c.cli == nil {
-		return nil, ErrDockerUnavailable
+		return StateInfo{}, ErrDockerUnavailable
 	}
-	res, err := c.cli.ContainerList(ctx, container.ListOptions{All: true})
+	ins, err := c.cli.ContainerInspect(ctx, id)
 	if err != nil {
-		return nil, err
+		if client.IsErrNotFound(err) {
+			return StateInfo{}, fmt.Errorf("%w: %s", ErrContainerNotFound, id)
+		}
+		if client.IsErrConnectionFailed(err) {
+			return StateInfo{}, fmt.Errorf("%w: %v", ErrDockerUnavailable, err)
+		}
+		return StateInfo{}, err
 	}
-	out := make([]ContainerSummary, 0, len(res))
-	for _, r := range res {
-		name := ""
-		if len(r.Names) > 0 {
-			name = r.Names[0]
-			if len(name) > 0 && name[0] == '/' {
-				name = name[1:]
-			}
+	if ins.State == nil {
+		return StateInfo{}, nil
+	}
+	info := StateInfo{Status: ins.State.Status, OOMKilled: ins.State.OOMKilled, RestartCount: ins.RestartCount}
+	if h := ins.State.Health; h != nil && h.Status != "none" {
+		info.Health = h.Status
+		if n := len(h.Log); n > 0 && h.Log[n-1] != nil {
+			info.HealthOutput = truncate(strings.TrimSpace(h.Log[n-1].Output), 200)
 		}
-		out = append(out, ContainerSummary{
-			ID:     r.ID,
-			Name:   name,
-			Names:  r.Names,
-			Image:  r.Image,
-			State:  r.State,
-			Status: r.Status,
-			Labels: r.Labels,
-		})
 	}
-	return out, nil
+	return info, nil
 }
 
-func (c *Client) ContainerState(ctx context.Context, id string) (string, error) {
+// truncate cuts s to at most n bytes, backing off to a rune boundary so a
+// multi-byte character is never split.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "..."
+}
+
+// Links returns the legacy --link targets (container names) of a container.
+func (c *Client) Links(ctx context.Context, id string) ([]string, error) {
 	if c.isMock {
-		c.mockMux.Lock()
-		defer c.mockMux.Unlock()
-		if ct, ok := c.mockDB[id]; ok {
-			return ct.State, nil
-		}
-		return "", errors.New("container not found")
+		return nil, nil
 	}
 
 	if c == nil || c.cli == nil {
-		return "", ErrDockerUnavailable
+		return nil, ErrDockerUnavailable
 	}
 	ins, err := c.cli.ContainerInspect(ctx, id)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	if ins.State == nil {
-		return "", nil
+	if ins.HostConfig == nil {
+		return nil, nil
+	}
+	out := make([]string, 0, len(ins.HostConfig.Links))
+	for _, l := range ins.HostConfig.Links {
+		// Format is "/target:/source/alias".
+		target, _, _ := strings.Cut(l, ":")
+		out = append(out, strings.TrimPrefix(target, "/"))
 	}
-	return ins.State.Status, nil
+	return out, nil
 }
 
 func (c *Client) Start(ctx context.Context, id string) error {
@@ -161,7 +221,7 @@ func (c *Client) Start(ctx context.Context, id string) error {
 			ct.Status = "Up (Mock)"
 			return nil
 		}
-		return errors.New("container not found")
+		return ErrContainerNotFound
 	}
 
 	if c == nil || c.cli == nil {
@@ -179,7 +239,7 @@ func (c *Client) Stop(ctx context.Context, id string, timeout time.Duration) err
 			ct.Status = "Exited (Mock)"
 			return nil
 		}
-		return errors.New("container not found")
+		return ErrContainerNotFound
 	}
 
 	if c == nil || c.cli == nil {
@@ -198,7 +258,7 @@ func (c *Client) Restart(ctx context.Context, id string, timeout time.Duration)
 			ct.Status = "Up (Mock Restarted)"
 			return nil
 		}
-		return errors.New("container not found")
+		return ErrContainerNotFound
 	}
 
 	if c == nil || c.cli == nil {
@@ -216,7 +276,7 @@ func (c *Client) UpdateRestartPolicy(ctx context.Context, id string, policy cont
 			ct.RestartPolicy = string(policy.Name)
 			return nil
 		}
-		return errors.New("container not found")
+		return ErrContainerNotFound
 	}
 
 	if c == nil || c.cli == nil {
@@ -226,7 +286,15 @@ func (c *Client) UpdateRestartPolicy(ctx context.Context, id string, policy cont
 	return err
 }
 
-func (c *Client) Logs(ctx context.Context, id string, tail string, since time.Time) (io.ReadCloser, error) {
+type LogsOptions struct {
+	Tail       string
+	Since      time.Time
+	Stdout     bool
+	Stderr     bool
+	Timestamps bool
+}
+
+func (c *Client) Logs(ctx context.Context, id string, opts LogsOptions) (io.ReadCloser, error) {
 	if c.isMock {
 		// Return fake logs
 		logs := fmt.Sprintf("[%s] Mock log entry for container %s\n[%s] Another mock log entry...\n[%s] System is running fine.\n[%s] Random value: %d\n",
@@ -242,11 +310,11 @@ func (c *Client) Logs(ctx context.Context, id string, tail string, since time.Ti
 		return nil, ErrDockerUnavailable
 	}
 	return c.cli.ContainerLogs(ctx, id, container.LogsOptions{
-		ShowStdout: true,
-		ShowStderr: true,
-		Timestamps: true,
-		Tail:       tail,
-		Since:      since.UTC().Format(time.RFC3339),
+		ShowStdout: opts.Stdout,
+		ShowStderr: opts.Stderr,
+		Timestamps: opts.Timestamps,
+		Tail:       opts.Tail,
+		Since:      opts.Since.UTC().Format(time.RFC3339),
 		Details:    false,
 		Follow:     false,
 	})
@@ -262,3 +330,18 @@ func (c *Client) HasDocker(ctx context.Context) bool {
 	_, err := c.cli.Ping(ctx)
 	return err == nil
 }
+
+// Ping checks that the daemon answers; any failure is reported as
+// ErrDockerUnavailable.
+func (c *Client) Ping(ctx context.Context) error {
+	if c.isMock {
+		return nil
+	}
+	if c == nil || c.cli == nil {
+		return ErrDockerUnavailable
+	}
+	if _, err := c.cli.Ping(ctx); err != nil {
+		return fmt.Errorf("%w: %v", ErrDockerUnavailable, err)
+	}
+	return nil
+}
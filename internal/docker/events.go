@@ -0,0 +1,72 @@
+package docker
+
+import (
+	"context"
+	"strings"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// ContainerEvent is a container lifecycle event from the Docker daemon.
+type ContainerEvent struct {
+	ContainerID string
+	Name        string
+	// Project is the compose project label, empty for standalone containers.
+	Project string
+	// Action is e.g. "start", "die", "stop", "oom" or "health_status".
+	Action string
+}
+
+// watchedActions are the container events that can change a monitor's
+// status.
+var watchedActions = []string{"start", "restart", "die", "stop", "kill", "oom", "pause", "unpause", "destroy", "health_status"}
+
+// Events streams container lifecycle events until ctx is done. The error
+// channel receives one value when the stream breaks, e.g. because the daemon
+// went away; the caller is expected to resubscribe. The mock client never
+// produces events.
+func (c *Client) Events(ctx context.Context) (<-chan ContainerEvent, <-chan error) {
+	out := make(chan ContainerEvent)
+	errc := make(chan error, 1)
+	if c.isMock {
+		return out, errc
+	}
+	if c == nil || c.cli == nil {
+		errc <- ErrDockerUnavailable
+		return out, errc
+	}
+
+	args := filters.NewArgs(filters.Arg("type", string(events.ContainerEventType)))
+	for _, a := range watchedActions {
+		args.Add("event", a)
+	}
+	msgs, errs := c.cli.Events(ctx, events.ListOptions{Filters: args})
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-errs:
+				errc <- err
+				return
+			case m := <-msgs:
+				// health_status events carry the result in the action,
+				// e.g. "health_status: unhealthy".
+				action, _, _ := strings.Cut(string(m.Action), ":")
+				ev := ContainerEvent{
+					ContainerID: m.Actor.ID,
+					Name:        m.Actor.Attributes["name"],
+					Project:     m.Actor.Attributes[ComposeProjectLabel],
+					Action:      action,
+				}
+				select {
+				case out <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, errc
+}
@@ -0,0 +1,171 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os/exec"
+	"sort"
+	"time"
+
+	"github.com/docker/docker/client"
+
+	"github.com/lsy88/uptime-chopper/internal/config"
+)
+
+var ErrUnknownHost = errors.New("unknown docker host")
+
+// Hosts holds the local Docker client and any configured remote daemons.
+type Hosts struct {
+	local  *Client
+	remote map[string]*Client
+	addrs  map[string]string
+}
+
+// HostInfo describes a configured Docker daemon. ID is empty for the local
+// daemon.
+type HostInfo struct {
+	ID   string `json:"id"`
+	Host string `json:"host"`
+}
+
+// NewHosts creates clients for the configured remote daemons. Clients
+// connect lazily, so an unreachable daemon does not fail startup; its
+// monitors report docker_unreachable instead.
+func NewHosts(local *Client, hosts []config.DockerHost) (*Hosts, error) {
+	h := &Hosts{local: local, remote: map[string]*Client{}, addrs: map[string]string{}}
+	for _, cfg := range hosts {
+		if cfg.Name == "" {
+			return nil, errors.New("docker host without name")
+		}
+		if _, dup := h.remote[cfg.Name]; dup {
+			return nil, fmt.Errorf("duplicate docker host %q", cfg.Name)
+		}
+		c, err := NewRemoteClient(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("docker host %q: %w", cfg.Name, err)
+		}
+		h.remote[cfg.Name] = c
+		h.addrs[cfg.Name] = cfg.Host
+	}
+	return h, nil
+}
+
+// Get returns the client for a host ID; empty selects the local daemon.
+func (h *Hosts) Get(id string) (*Client, error) {
+	if h == nil {
+		return nil, ErrDockerUnavailable
+	}
+	if id == "" {
+		return h.local, nil
+	}
+	if c, ok := h.remote[id]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownHost, id)
+}
+
+// List returns the local daemon followed by the remote ones, sorted by ID.
+func (h *Hosts) List() []HostInfo {
+	out := []HostInfo{{ID: "", Host: "local"}}
+	ids := make([]string, 0, len(h.remote))
+	for id := range h.remote {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		out = append(out, HostInfo{ID: id, Host: h.addrs[id]})
+	}
+	return out
+}
+
+// NewRemoteClient creates a client for a unix://, tcp:// or ssh:// daemon.
+// ssh hosts are reached by running `docker system dial-stdio` on the remote
+// machine through the local ssh binary, like the docker CLI does.
+func NewRemoteClient(cfg config.DockerHost) (*Client, error) {
+	u, err := url.Parse(cfg.Host)
+	if err != nil {
+		return nil, err
+	}
+	opts := []client.Opt{client.WithAPIVersionNegotiation()}
+	switch u.Scheme {
+	case "unix", "tcp":
+		opts = append(opts, client.WithHost(cfg.Host))
+		if cfg.TLSCACert != "" || cfg.TLSCert != "" {
+			opts = append(opts, client.WithTLSClientConfig(cfg.TLSCACert, cfg.TLSCert, cfg.TLSKey))
+		}
+	case "ssh":
+		opts = append(opts,
+			client.WithHost("http://docker.example.com"),
+			client.WithDialContext(sshDialer(u)),
+		)
+	default:
+		return nil, fmt.Errorf("unsupported docker host scheme %q", u.Scheme)
+	}
+	cli, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{cli: cli}, nil
+}
+
+func sshDialer(u *url.URL) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	args := []string{"-o", "BatchMode=yes"}
+	if u.User != nil {
+		args = append(args, "-l", u.User.Username())
+	}
+	if p := u.Port(); p != "" {
+		args = append(args, "-p", p)
+	}
+	args = append(args, "--", u.Hostname(), "docker", "system", "dial-stdio")
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		// The connection outlives the dial context, so the command must not
+		// be bound to it.
+		cmd := exec.Command("ssh", args...)
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, err
+		}
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, err
+		}
+		return &commandConn{cmd: cmd, stdin: stdin, stdout: stdout}, nil
+	}
+}
+
+// commandConn is a net.Conn over the stdin and stdout of a process.
+type commandConn struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+}
+
+func (c *commandConn) Read(p []byte) (int, error)  { return c.stdout.Read(p) }
+func (c *commandConn) Write(p []byte) (int, error) { return c.stdin.Write(p) }
+
+func (c *commandConn) Close() error {
+	_ = c.stdin.Close()
+	_ = c.stdout.Close()
+	_ = c.cmd.Process.Kill()
+	_ = c.cmd.Wait()
+	return nil
+}
+
+func (c *commandConn) LocalAddr() net.Addr                { return dummyAddr{} }
+func (c *commandConn) RemoteAddr() net.Addr               { return dummyAddr{} }
+func (c *commandConn) SetDeadline(t time.Time) error      { return nil }
+func (c *commandConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *commandConn) SetWriteDeadline(t time.Time) error { return nil }
+
+type dummyAddr struct{}
+
+func (dummyAddr) Network() string { return "dummy" }
+func (dummyAddr) String() string  { return "dummy" }
@@ -0,0 +1,171 @@
+package docker
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types"
+)
+
+// DaemonInfo describes a Docker daemon and the disk space used by its
+// objects.
+type DaemonInfo struct {
+	Name              string    `json:"name"`
+	ServerVersion     string    `json:"serverVersion"`
+	APIVersion        string    `json:"apiVersion"`
+	OperatingSystem   string    `json:"operatingSystem"`
+	OSType            string    `json:"osType"`
+	Architecture      string    `json:"architecture"`
+	KernelVersion     string    `json:"kernelVersion"`
+	NCPU              int       `json:"ncpu"`
+	MemTotal          int64     `json:"memTotal"` // bytes
+	StorageDriver     string    `json:"storageDriver"`
+	DockerRootDir     string    `json:"dockerRootDir"`
+	Containers        int       `json:"containers"`
+	ContainersRunning int       `json:"containersRunning"`
+	ContainersPaused  int       `json:"containersPaused"`
+	ContainersStopped int       `json:"containersStopped"`
+	Images            int       `json:"images"`
+	Warnings          []string  `json:"warnings,omitempty"`
+	DiskUsage         DiskUsage `json:"diskUsage"`
+}
+
+// DiskUsage is the `docker system df` summary of a daemon.
+type DiskUsage struct {
+	Images     UsageSummary `json:"images"`
+	Containers UsageSummary `json:"containers"`
+	Volumes    UsageSummary `json:"volumes"`
+	BuildCache UsageSummary `json:"buildCache"`
+}
+
+// UsageSummary counts the objects of one type, those in use, and their
+// size and the part of it that pruning would free, in bytes.
+type UsageSummary struct {
+	Total       int    `json:"total"`
+	Active      int    `json:"active"`
+	Size        uint64 `json:"size"`
+	Reclaimable uint64 `json:"reclaimable"`
+}
+
+// Info returns the daemon's version, object counts and disk usage.
+func (c *Client) Info(ctx context.Context) (DaemonInfo, error) {
+	if c.isMock {
+		c.mockMux.Lock()
+		defer c.mockMux.Unlock()
+		info := DaemonInfo{Name: "mock", ServerVersion: "mock", StorageDriver: "mock"}
+		for _, ct := range c.mockDB {
+			info.Containers++
+			switch ct.State {
+			case "running":
+				info.ContainersRunning++
+			case "paused":
+				info.ContainersPaused++
+			default:
+				info.ContainersStopped++
+			}
+		}
+		info.DiskUsage.Containers = UsageSummary{Total: info.Containers, Active: info.ContainersRunning}
+		return info, nil
+	}
+
+	if c == nil || c.cli == nil {
+		return DaemonInfo{}, ErrDockerUnavailable
+	}
+	in, err := c.cli.Info(ctx)
+	if err != nil {
+		return DaemonInfo{}, err
+	}
+	du, err := c.cli.DiskUsage(ctx, types.DiskUsageOptions{})
+	if err != nil {
+		return DaemonInfo{}, err
+	}
+	return DaemonInfo{
+		Name:              in.Name,
+		ServerVersion:     in.ServerVersion,
+		APIVersion:        c.cli.ClientVersion(),
+		OperatingSystem:   in.OperatingSystem,
+		OSType:            in.OSType,
+		Architecture:      in.Architecture,
+		KernelVersion:     in.KernelVersion,
+		NCPU:              in.NCPU,
+		MemTotal:          in.MemTotal,
+		StorageDriver:     in.Driver,
+		DockerRootDir:     in.DockerRootDir,
+		Containers:        in.Containers,
+		ContainersRunning: in.ContainersRunning,
+		ContainersPaused:  in.ContainersPaused,
+		ContainersStopped: in.ContainersStopped,
+		Images:            in.Images,
+		Warnings:          in.Warnings,
+		DiskUsage:         summarizeDiskUsage(du),
+	}, nil
+}
+
+// summarizeDiskUsage computes the totals shown by `docker system df`.
+// Image layers shared with images in use are not reclaimable.
+func summarizeDiskUsage(du types.DiskUsage) DiskUsage {
+	var out DiskUsage
+
+	var used int64
+	for _, img := range du.Images {
+		if img == nil {
+			continue
+		}
+		out.Images.Total++
+		if img.Containers > 0 {
+			out.Images.Active++
+			size := img.Size
+			if img.SharedSize > 0 {
+				size -= img.SharedSize
+			}
+			used += size
+		}
+	}
+	out.Images.Size = nonNegative(du.LayersSize)
+	out.Images.Reclaimable = nonNegative(du.LayersSize - used)
+
+	for _, ct := range du.Containers {
+		if ct == nil {
+			continue
+		}
+		out.Containers.Total++
+		out.Containers.Size += nonNegative(ct.SizeRw)
+		if ct.State == "running" {
+			out.Containers.Active++
+		} else {
+			out.Containers.Reclaimable += nonNegative(ct.SizeRw)
+		}
+	}
+
+	for _, v := range du.Volumes {
+		if v == nil || v.UsageData == nil {
+			continue
+		}
+		out.Volumes.Total++
+		out.Volumes.Size += nonNegative(v.UsageData.Size)
+		if v.UsageData.RefCount > 0 {
+			out.Volumes.Active++
+		} else {
+			out.Volumes.Reclaimable += nonNegative(v.UsageData.Size)
+		}
+	}
+
+	for _, b := range du.BuildCache {
+		if b == nil {
+			continue
+		}
+		out.BuildCache.Total++
+		out.BuildCache.Size += nonNegative(b.Size)
+		if b.InUse {
+			out.BuildCache.Active++
+		} else if !b.Shared {
+			out.BuildCache.Reclaimable += nonNegative(b.Size)
+		}
+	}
+	return out
+}
+
+// nonNegative converts a size to uint64; the daemon reports -1 for sizes
+// it did not compute.
+func nonNegative(n int64) uint64 {
+	return uint64(max(n, 0))
+}
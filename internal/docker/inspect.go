@@ -0,0 +1,201 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+// ContainerDetails is the part of a container's inspect data shown in the
+// container detail view. Environment variables are listed by name only,
+// since their values often hold credentials.
+type ContainerDetails struct {
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	Image         string            `json:"image"`
+	ImageID       string            `json:"imageId"`
+	Command       []string          `json:"command"`
+	Created       time.Time         `json:"created"`
+	State         string            `json:"state"`
+	ExitCode      int               `json:"exitCode"`
+	OOMKilled     bool              `json:"oomKilled"`
+	Error         string            `json:"error,omitempty"`
+	StartedAt     *time.Time        `json:"startedAt,omitempty"`
+	FinishedAt    *time.Time        `json:"finishedAt,omitempty"`
+	RestartCount  int               `json:"restartCount"`
+	RestartPolicy RestartPolicyInfo `json:"restartPolicy"`
+	Health        *HealthInfo       `json:"health,omitempty"`
+	EnvNames      []string          `json:"envNames"`
+	Labels        map[string]string `json:"labels"`
+	Mounts        []MountInfo       `json:"mounts"`
+	Ports         []PortInfo        `json:"ports"`
+	Networks      []NetworkInfo     `json:"networks"`
+	Project       string            `json:"project,omitempty"`
+}
+
+// RestartPolicyInfo is the daemon's restart policy of a container.
+type RestartPolicyInfo struct {
+	Name              string `json:"name"`
+	MaximumRetryCount int    `json:"maximumRetryCount"`
+}
+
+// HealthInfo is the health check state of a container; Output is that of
+// the latest probe.
+type HealthInfo struct {
+	Status        string `json:"status"`
+	FailingStreak int    `json:"failingStreak"`
+	Output        string `json:"output,omitempty"`
+}
+
+// MountInfo is a volume, bind or tmpfs mount of a container.
+type MountInfo struct {
+	Type        string `json:"type"`
+	Name        string `json:"name,omitempty"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	ReadOnly    bool   `json:"readOnly"`
+}
+
+// PortInfo is an exposed container port and, when published, its host
+// address.
+type PortInfo struct {
+	ContainerPort string `json:"containerPort"` // e.g. "80/tcp"
+	HostIP        string `json:"hostIp,omitempty"`
+	HostPort      string `json:"hostPort,omitempty"`
+}
+
+// NetworkInfo is a network a container is attached to.
+type NetworkInfo struct {
+	Name      string   `json:"name"`
+	IPAddress string   `json:"ipAddress,omitempty"`
+	Gateway   string   `json:"gateway,omitempty"`
+	Aliases   []string `json:"aliases,omitempty"`
+}
+
+// Inspect returns the details of a container.
+func (c *Client) Inspect(ctx context.Context, id string) (ContainerDetails, error) {
+	if c.isMock {
+		c.mockMux.Lock()
+		defer c.mockMux.Unlock()
+		ct, ok := c.mockDB[id]
+		if !ok {
+			return ContainerDetails{}, fmt.Errorf("%w: %s", ErrContainerNotFound, id)
+		}
+		d := ContainerDetails{
+			ID:            ct.ID,
+			Name:          ct.Name,
+			Image:         ct.Image,
+			State:         ct.State,
+			RestartPolicy: RestartPolicyInfo{Name: ct.RestartPolicy},
+			EnvNames:      []string{},
+			Labels:        ct.Labels,
+			Mounts:        []MountInfo{},
+			Ports:         []PortInfo{},
+			Networks:      []NetworkInfo{},
+			Project:       ct.Project,
+		}
+		if ct.Health != "" {
+			d.Health = &HealthInfo{Status: ct.Health}
+		}
+		return d, nil
+	}
+
+	if c == nil || c.cli == nil {
+		return ContainerDetails{}, ErrDockerUnavailable
+	}
+	ins, err := c.cli.ContainerInspect(ctx, id)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return ContainerDetails{}, fmt.Errorf("%w: %s", ErrContainerNotFound, id)
+		}
+		return ContainerDetails{}, err
+	}
+	d := ContainerDetails{
+		ID:           ins.ID,
+		Name:         strings.TrimPrefix(ins.Name, "/"),
+		ImageID:      ins.Image,
+		Command:      append([]string{ins.Path}, ins.Args...),
+		RestartCount: ins.RestartCount,
+		EnvNames:     []string{},
+		Mounts:       []MountInfo{},
+		Ports:        []PortInfo{},
+		Networks:     []NetworkInfo{},
+	}
+	d.Created, _ = time.Parse(time.RFC3339Nano, ins.Created)
+	if cfg := ins.Config; cfg != nil {
+		d.Image = cfg.Image
+		d.Labels = cfg.Labels
+		d.Project = cfg.Labels[ComposeProjectLabel]
+		for _, kv := range cfg.Env {
+			name, _, _ := strings.Cut(kv, "=")
+			d.EnvNames = append(d.EnvNames, name)
+		}
+		sort.Strings(d.EnvNames)
+	}
+	if st := ins.State; st != nil {
+		d.State = string(st.Status)
+		d.ExitCode = st.ExitCode
+		d.OOMKilled = st.OOMKilled
+		d.Error = st.Error
+		d.StartedAt = inspectTime(st.StartedAt)
+		d.FinishedAt = inspectTime(st.FinishedAt)
+		if h := st.Health; h != nil && h.Status != "none" {
+			d.Health = &HealthInfo{Status: string(h.Status), FailingStreak: h.FailingStreak}
+			if n := len(h.Log); n > 0 && h.Log[n-1] != nil {
+				d.Health.Output = truncate(strings.TrimSpace(h.Log[n-1].Output), 1000)
+			}
+		}
+	}
+	if hc := ins.HostConfig; hc != nil {
+		d.RestartPolicy = RestartPolicyInfo{Name: string(hc.RestartPolicy.Name), MaximumRetryCount: hc.RestartPolicy.MaximumRetryCount}
+	}
+	for _, m := range ins.Mounts {
+		d.Mounts = append(d.Mounts, MountInfo{
+			Type:        string(m.Type),
+			Name:        m.Name,
+			Source:      m.Source,
+			Destination: m.Destination,
+			ReadOnly:    !m.RW,
+		})
+	}
+	if ns := ins.NetworkSettings; ns != nil {
+		for port, bindings := range ns.Ports {
+			if len(bindings) == 0 {
+				d.Ports = append(d.Ports, PortInfo{ContainerPort: string(port)})
+			}
+			for _, b := range bindings {
+				d.Ports = append(d.Ports, PortInfo{ContainerPort: string(port), HostIP: b.HostIP, HostPort: b.HostPort})
+			}
+		}
+		sort.Slice(d.Ports, func(i, j int) bool {
+			if d.Ports[i].ContainerPort != d.Ports[j].ContainerPort {
+				return d.Ports[i].ContainerPort < d.Ports[j].ContainerPort
+			}
+			return d.Ports[i].HostIP < d.Ports[j].HostIP
+		})
+		for name, ep := range ns.Networks {
+			n := NetworkInfo{Name: name}
+			if ep != nil {
+				n.IPAddress, n.Gateway, n.Aliases = ep.IPAddress, ep.Gateway, ep.Aliases
+			}
+			d.Networks = append(d.Networks, n)
+		}
+		sort.Slice(d.Networks, func(i, j int) bool { return d.Networks[i].Name < d.Networks[j].Name })
+	}
+	return d, nil
+}
+
+// inspectTime parses a timestamp of the inspect data, nil for the zero
+// time the daemon reports for events that did not happen yet.
+func inspectTime(s string) *time.Time {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil || t.Year() <= 1 {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
@@ -0,0 +1,160 @@
+package docker
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// ContainerFilter selects containers in FindContainers. Empty fields match
+// any container; all set fields must match.
+type ContainerFilter struct {
+	// States are the accepted states: created, restarting, running,
+	// removing, paused, exited or dead.
+	States []string
+	// Health is starting, healthy, unhealthy or none.
+	Health string
+	// Image matches containers created from this image name or ID.
+	Image string
+	// Labels are "key" or "key=value" conditions.
+	Labels []string
+	// Name matches containers whose name contains it.
+	Name string
+}
+
+func (f ContainerFilter) args() filters.Args {
+	args := filters.NewArgs()
+	for _, s := range f.States {
+		args.Add("status", s)
+	}
+	if f.Health != "" {
+		args.Add("health", f.Health)
+	}
+	if f.Image != "" {
+		args.Add("ancestor", f.Image)
+	}
+	for _, l := range f.Labels {
+		args.Add("label", l)
+	}
+	if f.Name != "" {
+		args.Add("name", regexp.QuoteMeta(f.Name))
+	}
+	return args
+}
+
+// match applies the filter to a mock container, as the daemon would.
+func (f ContainerFilter) match(ct ContainerSummary) bool {
+	if len(f.States) > 0 && !slices.Contains(f.States, ct.State) {
+		return false
+	}
+	if f.Health != "" && f.Health != ct.Health && !(f.Health == "none" && ct.Health == "") {
+		return false
+	}
+	if f.Image != "" && ct.Image != f.Image && !strings.HasPrefix(ct.Image, f.Image+":") {
+		return false
+	}
+	for _, l := range f.Labels {
+		k, v, hasValue := strings.Cut(l, "=")
+		if got, ok := ct.Labels[k]; !ok || (hasValue && got != v) {
+			return false
+		}
+	}
+	return f.Name == "" || strings.Contains(ct.Name, f.Name)
+}
+
+// FindContainers lists the containers, stopped ones included, that match
+// f. The daemon applies the filter.
+func (c *Client) FindContainers(ctx context.Context, f ContainerFilter) ([]ContainerSummary, error) {
+	if c.isMock {
+		c.mockMux.Lock()
+		defer c.mockMux.Unlock()
+		out := make([]ContainerSummary, 0, len(c.mockDB))
+		for _, v := range c.mockDB {
+			if f.match(*v) {
+				out = append(out, *v)
+			}
+		}
+		return out, nil
+	}
+
+	if c == nil || c.cli == nil {
+		return nil, ErrDockerUnavailable
+	}
+	res, err := c.cli.ContainerList(ctx, container.ListOptions{All: true, Filters: f.args()})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ContainerSummary, 0, len(res))
+	for _, r := range res {
+		name := ""
+		if len(r.Names) > 0 {
+			name = strings.TrimPrefix(r.Names[0], "/")
+		}
+		var networks []string
+		if r.NetworkSettings != nil {
+			for n := range r.NetworkSettings.Networks {
+				networks = append(networks, n)
+			}
+			sort.Strings(networks)
+		}
+		out = append(out, ContainerSummary{
+			ID:       r.ID,
+			Name:     name,
+			Names:    r.Names,
+			Image:    r.Image,
+			State:    string(r.State),
+			Status:   r.Status,
+			Labels:   r.Labels,
+			Networks: networks,
+			Project:  r.Labels[ComposeProjectLabel],
+			Health:   healthFromStatus(r.Status),
+			Created:  time.Unix(r.Created, 0).UTC(),
+		})
+	}
+	return out, nil
+}
+
+// healthFromStatus reads the health check result from a list status such
+// as "Up 2 hours (healthy)" or "Up 3 seconds (health: starting)".
+func healthFromStatus(status string) string {
+	switch {
+	case strings.HasSuffix(status, "(healthy)"):
+		return "healthy"
+	case strings.HasSuffix(status, "(unhealthy)"):
+		return "unhealthy"
+	case strings.HasSuffix(status, "(health: starting)"):
+		return "starting"
+	}
+	return ""
+}
+
+// FillRestartCounts sets the RestartCount of each container, which the
+// list does not include, by inspecting a few containers at a time.
+// Containers that cannot be inspected, e.g. because they were just
+// removed, keep a count of zero.
+func (c *Client) FillRestartCounts(ctx context.Context, cs []ContainerSummary) {
+	if c == nil || c.isMock || c.cli == nil {
+		return
+	}
+	sem := make(chan struct{}, 8)
+	var wg sync.WaitGroup
+	for i := range cs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(ct *ContainerSummary) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if ins, err := c.cli.ContainerInspect(ctx, ct.ID); err == nil {
+				ct.RestartCount = ins.RestartCount
+			}
+		}(&cs[i])
+	}
+	wg.Wait()
+}
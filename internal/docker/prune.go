@@ -0,0 +1,152 @@
+package docker
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+)
+
+// anonymousVolumeLabel marks volumes created without a name, which are the
+// only ones pruned unless PruneOptions.AllVolumes is set.
+const anonymousVolumeLabel = "com.docker.volume.anonymous"
+
+// PruneOptions selects what Prune removes: stopped containers, dangling
+// images, unused networks and unused anonymous volumes, or every unused
+// volume with AllVolumes. With DryRun nothing is removed and the report
+// lists what would be.
+type PruneOptions struct {
+	Containers bool `json:"containers"`
+	Images     bool `json:"images"`
+	Networks   bool `json:"networks"`
+	Volumes    bool `json:"volumes"`
+	AllVolumes bool `json:"allVolumes"`
+	DryRun     bool `json:"dryRun"`
+}
+
+// PruneReport lists the removed (or, for a dry run, removable) objects and
+// the disk space they take.
+type PruneReport struct {
+	DryRun         bool     `json:"dryRun"`
+	Containers     []string `json:"containers"`
+	Images         []string `json:"images"`
+	Networks       []string `json:"networks"`
+	Volumes        []string `json:"volumes"`
+	SpaceReclaimed uint64   `json:"spaceReclaimed"` // bytes
+}
+
+// Prune removes unused objects as selected by opts.
+func (c *Client) Prune(ctx context.Context, opts PruneOptions) (PruneReport, error) {
+	rep := PruneReport{DryRun: opts.DryRun, Containers: []string{}, Images: []string{}, Networks: []string{}, Volumes: []string{}}
+	if c.isMock {
+		return rep, nil
+	}
+	if c == nil || c.cli == nil {
+		return rep, ErrDockerUnavailable
+	}
+	if opts.DryRun {
+		return rep, c.prunable(ctx, opts, &rep)
+	}
+	if opts.Containers {
+		res, err := c.cli.ContainersPrune(ctx, filters.NewArgs())
+		if err != nil {
+			return rep, err
+		}
+		rep.Containers = append(rep.Containers, res.ContainersDeleted...)
+		rep.SpaceReclaimed += res.SpaceReclaimed
+	}
+	if opts.Images {
+		res, err := c.cli.ImagesPrune(ctx, filters.NewArgs(filters.Arg("dangling", "true")))
+		if err != nil {
+			return rep, err
+		}
+		for _, d := range res.ImagesDeleted {
+			if d.Deleted != "" {
+				rep.Images = append(rep.Images, d.Deleted)
+			}
+		}
+		rep.SpaceReclaimed += res.SpaceReclaimed
+	}
+	if opts.Networks {
+		res, err := c.cli.NetworksPrune(ctx, filters.NewArgs())
+		if err != nil {
+			return rep, err
+		}
+		rep.Networks = append(rep.Networks, res.NetworksDeleted...)
+	}
+	if opts.Volumes || opts.AllVolumes {
+		args := filters.NewArgs()
+		if opts.AllVolumes {
+			args.Add("all", "true")
+		}
+		res, err := c.cli.VolumesPrune(ctx, args)
+		if err != nil {
+			return rep, err
+		}
+		rep.Volumes = append(rep.Volumes, res.VolumesDeleted...)
+		rep.SpaceReclaimed += res.SpaceReclaimed
+	}
+	return rep, nil
+}
+
+// prunable fills rep with what Prune would remove, using the same rules as
+// the daemon's prune endpoints.
+func (c *Client) prunable(ctx context.Context, opts PruneOptions, rep *PruneReport) error {
+	if opts.Containers {
+		args := filters.NewArgs(filters.Arg("status", "created"), filters.Arg("status", "exited"), filters.Arg("status", "dead"))
+		cs, err := c.cli.ContainerList(ctx, container.ListOptions{All: true, Size: true, Filters: args})
+		if err != nil {
+			return err
+		}
+		for _, ct := range cs {
+			rep.Containers = append(rep.Containers, ct.ID)
+			rep.SpaceReclaimed += uint64(max(ct.SizeRw, 0))
+		}
+	}
+	if opts.Images {
+		imgs, err := c.cli.ImageList(ctx, image.ListOptions{Filters: filters.NewArgs(filters.Arg("dangling", "true"))})
+		if err != nil {
+			return err
+		}
+		for _, img := range imgs {
+			rep.Images = append(rep.Images, img.ID)
+			rep.SpaceReclaimed += uint64(max(img.Size, 0))
+		}
+	}
+	if opts.Networks {
+		nets, err := c.cli.NetworkList(ctx, network.ListOptions{Filters: filters.NewArgs(filters.Arg("dangling", "true"))})
+		if err != nil {
+			return err
+		}
+		for _, n := range nets {
+			rep.Networks = append(rep.Networks, n.Name)
+		}
+	}
+	if opts.Volumes || opts.AllVolumes {
+		// Only the disk usage endpoint reports volume sizes.
+		du, err := c.cli.DiskUsage(ctx, types.DiskUsageOptions{Types: []types.DiskUsageObject{types.VolumeObject}})
+		if err != nil {
+			return err
+		}
+		for _, v := range du.Volumes {
+			if v == nil || !prunableVolume(v, opts.AllVolumes) {
+				continue
+			}
+			rep.Volumes = append(rep.Volumes, v.Name)
+			rep.SpaceReclaimed += uint64(max(v.UsageData.Size, 0))
+		}
+	}
+	return nil
+}
+
+func prunableVolume(v *volume.Volume, all bool) bool {
+	if v.UsageData == nil || v.UsageData.RefCount != 0 {
+		return false
+	}
+	_, anonymous := v.Labels[anonymousVolumeLabel]
+	return all || anonymous
+}
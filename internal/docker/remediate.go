@@ -0,0 +1,168 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// maxExecOutput caps the output kept from an exec command.
+const maxExecOutput = 4096
+
+// ExecResult is the outcome of a command run inside a container. Output
+// holds combined stdout and stderr, truncated to its last 4 KiB.
+type ExecResult struct {
+	ExitCode int    `json:"exitCode"`
+	Output   string `json:"output"`
+}
+
+// Exec runs cmd inside a running container and waits for it to exit or for
+// ctx to be done.
+func (c *Client) Exec(ctx context.Context, id string, cmd []string) (ExecResult, error) {
+	if c.isMock {
+		c.mockMux.Lock()
+		defer c.mockMux.Unlock()
+		if _, ok := c.mockDB[id]; !ok {
+			return ExecResult{}, ErrContainerNotFound
+		}
+		return ExecResult{Output: "mock exec: " + strings.Join(cmd, " ")}, nil
+	}
+
+	if c == nil || c.cli == nil {
+		return ExecResult{}, ErrDockerUnavailable
+	}
+	created, err := c.cli.ContainerExecCreate(ctx, id, container.ExecOptions{
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return ExecResult{}, containerErr(err, id)
+	}
+	att, err := c.cli.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return ExecResult{}, err
+	}
+	defer att.Close()
+
+	// The hijacked connection ignores ctx; close it to unblock the copy.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			att.Close()
+		case <-done:
+		}
+	}()
+
+	out := &tailBuffer{max: maxExecOutput}
+	if _, err := stdcopy.StdCopy(out, out, att.Reader); err != nil && ctx.Err() == nil {
+		return ExecResult{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return ExecResult{Output: out.String()}, err
+	}
+	ins, err := c.cli.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return ExecResult{Output: out.String()}, err
+	}
+	return ExecResult{ExitCode: ins.ExitCode, Output: out.String()}, nil
+}
+
+// Recreate stops and removes a container and creates and starts a new one
+// with the same name and configuration, like `docker compose up
+// --force-recreate` does for a single service. It returns the new ID.
+func (c *Client) Recreate(ctx context.Context, id string, timeout time.Duration) (string, error) {
+	if c.isMock {
+		c.mockMux.Lock()
+		defer c.mockMux.Unlock()
+		if ct, ok := c.mockDB[id]; ok {
+			ct.State = "running"
+			ct.Status = "Up (Mock Recreated)"
+			return id, nil
+		}
+		return "", ErrContainerNotFound
+	}
+
+	if c == nil || c.cli == nil {
+		return "", ErrDockerUnavailable
+	}
+	ins, err := c.cli.ContainerInspect(ctx, id)
+	if err != nil {
+		return "", containerErr(err, id)
+	}
+	cfg := ins.Config
+	// An unset hostname defaults to the short container ID; let the new
+	// container get its own.
+	if len(ins.ID) >= 12 && cfg.Hostname == ins.ID[:12] {
+		cfg.Hostname = ""
+	}
+	netCfg := &network.NetworkingConfig{EndpointsConfig: map[string]*network.EndpointSettings{}}
+	if ins.NetworkSettings != nil {
+		for name, ep := range ins.NetworkSettings.Networks {
+			if ep == nil {
+				continue
+			}
+			// Keep the user-supplied settings only; addresses and IDs
+			// belong to the old endpoint.
+			netCfg.EndpointsConfig[name] = &network.EndpointSettings{
+				IPAMConfig: ep.IPAMConfig,
+				Links:      ep.Links,
+				Aliases:    ep.Aliases,
+				DriverOpts: ep.DriverOpts,
+			}
+		}
+	}
+
+	sec := int(timeout.Seconds())
+	if err := c.cli.ContainerStop(ctx, ins.ID, container.StopOptions{Timeout: &sec}); err != nil {
+		return "", err
+	}
+	if err := c.cli.ContainerRemove(ctx, ins.ID, container.RemoveOptions{}); err != nil {
+		return "", err
+	}
+	name := strings.TrimPrefix(ins.Name, "/")
+	created, err := c.cli.ContainerCreate(ctx, cfg, ins.HostConfig, netCfg, nil, name)
+	if err != nil {
+		return "", fmt.Errorf("create %s: %w", name, err)
+	}
+	if err := c.cli.ContainerStart(ctx, created.ID, container.StartOptions{}); err != nil {
+		return created.ID, err
+	}
+	return created.ID, nil
+}
+
+// containerErr maps Docker API errors to ErrContainerNotFound and
+// ErrDockerUnavailable.
+func containerErr(err error, id string) error {
+	if client.IsErrNotFound(err) {
+		return fmt.Errorf("%w: %s", ErrContainerNotFound, id)
+	}
+	if client.IsErrConnectionFailed(err) {
+		return fmt.Errorf("%w: %v", ErrDockerUnavailable, err)
+	}
+	return err
+}
+
+// tailBuffer keeps the last max bytes written to it.
+type tailBuffer struct {
+	max int
+	buf []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - b.max; over > 0 {
+		b.buf = b.buf[over:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string { return string(b.buf) }
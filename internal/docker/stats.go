@@ -0,0 +1,95 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// Stats is a point-in-time resource usage sample of a container.
+type Stats struct {
+	CPUPercent       float64 `json:"cpuPercent"`
+	MemoryPercent    float64 `json:"memoryPercent"`
+	MemoryUsageBytes uint64  `json:"memoryUsageBytes"`
+	MemoryLimitBytes uint64  `json:"memoryLimitBytes"`
+}
+
+// ContainerStats samples CPU and memory usage of a running container. The
+// Docker API needs two readings to compute CPU usage, so this blocks for
+// about a second.
+func (c *Client) ContainerStats(ctx context.Context, id string) (Stats, error) {
+	if c.isMock {
+		c.mockMux.Lock()
+		defer c.mockMux.Unlock()
+		ct, ok := c.mockDB[id]
+		if !ok {
+			return Stats{}, ErrContainerNotFound
+		}
+		if ct.State != "running" {
+			return Stats{}, nil
+		}
+		limit := uint64(1 << 30)
+		used := uint64(float64(limit) * (0.2 + rand.Float64()*0.5))
+		return Stats{
+			CPUPercent:       rand.Float64() * 40,
+			MemoryPercent:    float64(used) / float64(limit) * 100,
+			MemoryUsageBytes: used,
+			MemoryLimitBytes: limit,
+		}, nil
+	}
+
+	if c == nil || c.cli == nil {
+		return Stats{}, ErrDockerUnavailable
+	}
+	res, err := c.cli.ContainerStats(ctx, id, false)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return Stats{}, fmt.Errorf("%w: %s", ErrContainerNotFound, id)
+		}
+		if client.IsErrConnectionFailed(err) {
+			return Stats{}, fmt.Errorf("%w: %v", ErrDockerUnavailable, err)
+		}
+		return Stats{}, err
+	}
+	defer res.Body.Close()
+
+	var s container.StatsResponse
+	if err := json.NewDecoder(res.Body).Decode(&s); err != nil {
+		return Stats{}, err
+	}
+	return statsFrom(s), nil
+}
+
+// statsFrom computes usage percentages the same way `docker stats` does.
+func statsFrom(s container.StatsResponse) Stats {
+	var out Stats
+
+	cpuDelta := float64(s.CPUStats.CPUUsage.TotalUsage) - float64(s.PreCPUStats.CPUUsage.TotalUsage)
+	sysDelta := float64(s.CPUStats.SystemUsage) - float64(s.PreCPUStats.SystemUsage)
+	cpus := float64(s.CPUStats.OnlineCPUs)
+	if cpus == 0 {
+		cpus = float64(len(s.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if cpuDelta > 0 && sysDelta > 0 {
+		out.CPUPercent = cpuDelta / sysDelta * cpus * 100
+	}
+
+	// Page cache is reclaimable, so it is not counted as used memory.
+	used := s.MemoryStats.Usage
+	for _, key := range []string{"inactive_file", "total_inactive_file"} {
+		if v, ok := s.MemoryStats.Stats[key]; ok && v < used {
+			used -= v
+			break
+		}
+	}
+	out.MemoryUsageBytes = used
+	out.MemoryLimitBytes = s.MemoryStats.Limit
+	if out.MemoryLimitBytes > 0 {
+		out.MemoryPercent = float64(used) / float64(out.MemoryLimitBytes) * 100
+	}
+	return out
+}
@@ -0,0 +1,138 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/image"
+)
+
+// ErrNoRegistryDigest is returned for images that were built locally or
+// loaded from an archive and so cannot be compared with a registry.
+var ErrNoRegistryDigest = errors.New("image has no registry digest")
+
+// ImageUpdate compares the image a container runs with the one its tag
+// currently points to in the registry.
+type ImageUpdate struct {
+	ContainerID     string    `json:"containerId"`
+	Name            string    `json:"name"`
+	Image           string    `json:"image"`
+	LocalDigest     string    `json:"localDigest,omitempty"`
+	RemoteDigest    string    `json:"remoteDigest,omitempty"`
+	UpdateAvailable bool      `json:"updateAvailable"`
+	CheckedAt       time.Time `json:"checkedAt"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// CheckImageUpdate asks the registry, through the daemon, for the digest of
+// the container's image reference. Images pinned by digest never have
+// updates. Registries that need credentials are not supported.
+func (c *Client) CheckImageUpdate(ctx context.Context, id string) (ImageUpdate, error) {
+	if c.isMock {
+		c.mockMux.Lock()
+		defer c.mockMux.Unlock()
+		ct, ok := c.mockDB[id]
+		if !ok {
+			return ImageUpdate{}, ErrContainerNotFound
+		}
+		return ImageUpdate{
+			ContainerID:  ct.ID,
+			Name:         ct.Name,
+			Image:        ct.Image,
+			LocalDigest:  "sha256:mock",
+			RemoteDigest: "sha256:mock",
+			CheckedAt:    time.Now().UTC(),
+		}, nil
+	}
+
+	if c == nil || c.cli == nil {
+		return ImageUpdate{}, ErrDockerUnavailable
+	}
+	ins, err := c.cli.ContainerInspect(ctx, id)
+	if err != nil {
+		return ImageUpdate{}, containerErr(err, id)
+	}
+	u := ImageUpdate{
+		ContainerID: ins.ID,
+		Name:        strings.TrimPrefix(ins.Name, "/"),
+		CheckedAt:   time.Now().UTC(),
+	}
+	if ins.Config != nil {
+		u.Image = ins.Config.Image
+	}
+	if _, pinned, ok := strings.Cut(u.Image, "@"); ok {
+		u.LocalDigest, u.RemoteDigest = pinned, pinned
+		return u, nil
+	}
+
+	img, err := c.cli.ImageInspect(ctx, ins.Image)
+	if err != nil {
+		return u, err
+	}
+	local := map[string]bool{}
+	for _, rd := range img.RepoDigests {
+		if _, d, ok := strings.Cut(rd, "@"); ok {
+			local[d] = true
+			if u.LocalDigest == "" {
+				u.LocalDigest = d
+			}
+		}
+	}
+	if len(local) == 0 {
+		return u, ErrNoRegistryDigest
+	}
+
+	dist, err := c.cli.DistributionInspect(ctx, u.Image, "")
+	if err != nil {
+		return u, fmt.Errorf("registry: %w", err)
+	}
+	u.RemoteDigest = dist.Descriptor.Digest.String()
+	u.UpdateAvailable = !local[u.RemoteDigest]
+	return u, nil
+}
+
+// PullImage pulls ref and waits for the pull to finish.
+func (c *Client) PullImage(ctx context.Context, ref string) error {
+	if c.isMock {
+		return nil
+	}
+
+	if c == nil || c.cli == nil {
+		return ErrDockerUnavailable
+	}
+	rc, err := c.cli.ImagePull(ctx, ref, image.PullOptions{})
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	// The pull only completes once its progress stream is consumed.
+	_, err = io.Copy(io.Discard, rc)
+	return err
+}
+
+// UpdateImage pulls the image reference a container was created from and
+// recreates the container on top of it. It returns the new container ID.
+func (c *Client) UpdateImage(ctx context.Context, id string, timeout time.Duration) (string, error) {
+	if c.isMock {
+		return c.Recreate(ctx, id, timeout)
+	}
+
+	if c == nil || c.cli == nil {
+		return "", ErrDockerUnavailable
+	}
+	ins, err := c.cli.ContainerInspect(ctx, id)
+	if err != nil {
+		return "", containerErr(err, id)
+	}
+	if ins.Config == nil || ins.Config.Image == "" {
+		return "", errors.New("container has no image reference")
+	}
+	if err := c.PullImage(ctx, ins.Config.Image); err != nil {
+		return "", fmt.Errorf("pull %s: %w", ins.Config.Image, err)
+	}
+	return c.Recreate(ctx, ins.ID, timeout)
+}
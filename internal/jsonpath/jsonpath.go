@@ -0,0 +1,322 @@
+// Package jsonpath evaluates simple assertions on JSON documents, such as
+// `$.status == "ok"` or `$.queue.depth < 100`.
+//
+// A path starts at the root `$` and selects object members with `.name` or
+// `["name"]` and array elements with `[i]`, where a negative index counts
+// from the end. The leading `$.` may be omitted. A path may be compared
+// with ==, !=, <, <=, > or >= to a JSON literal; a bare path asserts that
+// the value exists and is neither null nor false.
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Assertion is a parsed assertion expression.
+type Assertion struct {
+	// Path is the path as written, for messages.
+	Path  string
+	steps []step
+	op    string
+	want  any
+}
+
+// step selects an object member, or an array element when key is nil.
+type step struct {
+	key   *string
+	index int
+}
+
+var operators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// Parse parses an assertion expression.
+func Parse(expr string) (Assertion, error) {
+	pathExpr, op, literal := splitOperator(expr)
+	a := Assertion{Path: strings.TrimSpace(pathExpr), op: op}
+	if a.Path == "" {
+		return a, errors.New("missing path")
+	}
+	steps, err := parsePath(a.Path)
+	if err != nil {
+		return a, err
+	}
+	a.steps = steps
+	if op == "" {
+		return a, nil
+	}
+	literal = strings.TrimSpace(literal)
+	if literal == "" {
+		return a, fmt.Errorf("missing value after %s", op)
+	}
+	if len(literal) >= 2 && literal[0] == '\'' && literal[len(literal)-1] == '\'' {
+		a.want = literal[1 : len(literal)-1]
+		return a, nil
+	}
+	if a.want, err = Decode([]byte(literal)); err != nil {
+		return a, fmt.Errorf("invalid value %s: must be a JSON literal", literal)
+	}
+	return a, nil
+}
+
+// Decode parses a JSON document, keeping numbers as json.Number.
+func Decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return v, nil
+}
+
+// Eval selects the value of the assertion's path in doc and reports
+// whether it satisfies the assertion. The error is non-nil when the path
+// does not exist or the values cannot be compared.
+func (a Assertion) Eval(doc any) (got any, ok bool, err error) {
+	got, err = a.lookup(doc)
+	if err != nil {
+		return nil, false, err
+	}
+	if a.op == "" {
+		return got, got != nil && got != false, nil
+	}
+	ok, err = compare(got, a.op, a.want)
+	return got, ok, err
+}
+
+// String returns the expected condition, e.g. `< 100`, or "" for a bare
+// path.
+func (a Assertion) String() string {
+	if a.op == "" {
+		return ""
+	}
+	return a.op + " " + Format(a.want)
+}
+
+// Format renders v as compact JSON.
+func Format(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func (a Assertion) lookup(doc any) (any, error) {
+	v := doc
+	for i, s := range a.steps {
+		switch {
+		case s.key != nil:
+			obj, ok := v.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("no value at %s: not an object", a.prefix(i))
+			}
+			if v, ok = obj[*s.key]; !ok {
+				return nil, fmt.Errorf("no value at %s", a.prefix(i+1))
+			}
+		default:
+			arr, ok := v.([]any)
+			if !ok {
+				return nil, fmt.Errorf("no value at %s: not an array", a.prefix(i))
+			}
+			idx := s.index
+			if idx < 0 {
+				idx += len(arr)
+			}
+			if idx < 0 || idx >= len(arr) {
+				return nil, fmt.Errorf("no value at %s: index out of range", a.prefix(i+1))
+			}
+			v = arr[idx]
+		}
+	}
+	return v, nil
+}
+
+// prefix renders the first n steps of the path.
+func (a Assertion) prefix(n int) string {
+	var sb strings.Builder
+	sb.WriteString("$")
+	for _, s := range a.steps[:n] {
+		if s.key != nil {
+			sb.WriteString("." + *s.key)
+		} else {
+			sb.WriteString("[" + strconv.Itoa(s.index) + "]")
+		}
+	}
+	return sb.String()
+}
+
+// splitOperator splits expr at its first comparison operator outside
+// quotes and brackets.
+func splitOperator(expr string) (path, op, literal string) {
+	var quote byte
+	depth := 0
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case depth == 0:
+			for _, o := range operators {
+				if strings.HasPrefix(expr[i:], o) {
+					return expr[:i], o, expr[i+len(o):]
+				}
+			}
+		}
+	}
+	return expr, "", ""
+}
+
+func parsePath(p string) ([]step, error) {
+	switch {
+	case p == "$":
+		return nil, nil
+	case strings.HasPrefix(p, "$.") || strings.HasPrefix(p, "$["):
+		p = p[1:]
+	default:
+		p = "." + p
+	}
+	var steps []step
+	for p != "" {
+		switch p[0] {
+		case '.':
+			end := strings.IndexAny(p[1:], ".[")
+			if end < 0 {
+				end = len(p) - 1
+			}
+			name := strings.TrimSpace(p[1 : end+1])
+			if name == "" {
+				return nil, errors.New("empty member name in path")
+			}
+			steps = append(steps, step{key: &name})
+			p = p[end+1:]
+		case '[':
+			end := closingBracket(p)
+			if end < 0 {
+				return nil, errors.New("unclosed [ in path")
+			}
+			inner := strings.TrimSpace(p[1:end])
+			switch {
+			case len(inner) >= 2 && (inner[0] == '"' || inner[0] == '\''):
+				name, err := unquote(inner)
+				if err != nil {
+					return nil, err
+				}
+				steps = append(steps, step{key: &name})
+			default:
+				idx, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index [%s] in path", inner)
+				}
+				steps = append(steps, step{index: idx})
+			}
+			p = p[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %q in path", p[0])
+		}
+	}
+	return steps, nil
+}
+
+// closingBracket returns the index of the ] closing the [ at p[0],
+// skipping quoted names.
+func closingBracket(p string) int {
+	var quote byte
+	for i := 1; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+func unquote(s string) (string, error) {
+	if s[0] == '\'' {
+		if s[len(s)-1] != '\'' {
+			return "", fmt.Errorf("invalid member name %s in path", s)
+		}
+		return s[1 : len(s)-1], nil
+	}
+	name, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid member name %s in path", s)
+	}
+	return name, nil
+}
+
+// compare applies op to got and want. Numbers and strings are ordered;
+// other values can only be tested for equality.
+func compare(got any, op string, want any) (bool, error) {
+	if g, ok := number(got); ok {
+		if w, ok := number(want); ok {
+			return ordered(g, w, op), nil
+		}
+	}
+	if g, ok := got.(string); ok {
+		if w, ok := want.(string); ok {
+			return ordered(strings.Compare(g, w), 0, op), nil
+		}
+	}
+	switch op {
+	case "==":
+		return Format(got) == Format(want), nil
+	case "!=":
+		return Format(got) != Format(want), nil
+	}
+	return false, fmt.Errorf("cannot compare %s %s %s", Format(got), op, Format(want))
+}
+
+func number(v any) (float64, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := n.Float64()
+	return f, err == nil
+}
+
+func ordered[T int | float64](a, b T, op string) bool {
+	switch op {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package jsonpath
+
+import "testing"
+
+const doc = `{
+	"status": "ok",
+	"healthy": true,
+	"degraded": false,
+	"missing": null,
+	"queue": {"depth": 42, "name": "jobs"},
+	"items": [{"id": 1}, {"id": 2}, {"id": 3}],
+	"odd.key": "dotted",
+	"version": "1.10.0"
+}`
+
+func TestEval(t *testing.T) {
+	data, err := Decode([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`$.status == "ok"`, true},
+		{`$.status != "ok"`, false},
+		{`status == 'ok'`, true},
+		{`$.healthy`, true},
+		{`$.degraded`, false},
+		{`$.missing`, false},
+		{`$.queue.depth < 100`, true},
+		{`$.queue.depth >= 42`, true},
+		{`$.queue.depth > 42`, false},
+		{`$.queue.depth == 42.0`, true},
+		{`$.queue["name"] == "jobs"`, true},
+		{`$["odd.key"] == "dotted"`, true},
+		{`$.items[0].id == 1`, true},
+		{`$.items[-1].id == 3`, true},
+		{`$.items[1] == {"id": 2}`, true},
+		{`$.version < "1.9"`, true},
+		{`$.healthy == true`, true},
+		{`$.missing == null`, true},
+	}
+	for _, tt := range tests {
+		a, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.expr, err)
+		}
+		_, ok, err := a.Eval(data)
+		if err != nil {
+			t.Errorf("%q: %v", tt.expr, err)
+			continue
+		}
+		if ok != tt.want {
+			t.Errorf("%q = %v, want %v", tt.expr, ok, tt.want)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	data, err := Decode([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expr := range []string{
+		`$.nope`,
+		`$.status.inner`,
+		`$.queue[0]`,
+		`$.items[3]`,
+		`$.items[-4]`,
+		`$.healthy < 1`,
+		`$.queue > 1`,
+	} {
+		a, err := Parse(expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", expr, err)
+		}
+		if _, _, err := a.Eval(data); err == nil {
+			t.Errorf("%q: want error", expr)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`== 1`,
+		`$.a ==`,
+		`$.a == nope`,
+		`$.a..b`,
+		`$.a[`,
+		`$.a[x]`,
+		`$.a["b]`,
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q): want error", expr)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct{ expr, want string }{
+		{`$.a`, ``},
+		{`$.a < 100`, `< 100`},
+		{`$.a == 'x'`, `== "x"`},
+		{`$.a != {"b": [1, 2]}`, `!= {"b":[1,2]}`},
+	}
+	for _, tt := range tests {
+		a, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.expr, err)
+		}
+		if got := a.String(); got != tt.want {
+			t.Errorf("%q.String() = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Package kube wraps client-go with what the kubernetes monitor needs:
+// reading pods and deployments, deleting pods and restarting deployments.
+package kube
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var (
+	ErrNotConfigured = errors.New("kubernetes not configured")
+	ErrNotFound      = errors.New("kubernetes object not found")
+)
+
+const serviceAccountNamespace = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// Client talks to one Kubernetes API server.
+type Client struct {
+	cs kubernetes.Interface
+
+	// Namespace is the namespace of the kubeconfig context, or of the pod
+	// when running in-cluster; used when a monitor leaves it empty.
+	Namespace string
+}
+
+// NewClient loads credentials from kubeconfig, or from the pod's service
+// account when kubeconfig is empty and the process runs inside a cluster.
+// Without either, the files listed in $KUBECONFIG, merged, and
+// ~/.kube/config are tried; ErrNotConfigured is returned when none exists.
+// kubeContext overrides the current context. Everything kubectl accepts
+// works, including exec credential plugins.
+func NewClient(kubeconfig, kubeContext string) (*Client, error) {
+	if kubeconfig == "" && os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return inCluster()
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+	cc := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{CurrentContext: kubeContext})
+	cfg, err := cc.ClientConfig()
+	if clientcmd.IsEmptyConfig(err) {
+		return nil, ErrNotConfigured
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load kubeconfig: %w", err)
+	}
+	ns, _, err := cc.Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("load kubeconfig: %w", err)
+	}
+	return newClient(cfg, ns)
+}
+
+func inCluster() (*Client, error) {
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, err
+	}
+	ns := "default"
+	if b, err := os.ReadFile(serviceAccountNamespace); err == nil && len(b) > 0 {
+		ns = string(b)
+	}
+	return newClient(cfg, ns)
+}
+
+func newClient(cfg *rest.Config, namespace string) (*Client, error) {
+	cfg.Timeout = 30 * time.Second
+	cs, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{cs: cs, Namespace: namespace}, nil
+}
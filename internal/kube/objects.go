@@ -0,0 +1,173 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// PodState is the readiness of a pod.
+type PodState struct {
+	Name     string `json:"name"`
+	Phase    string `json:"phase"`
+	Ready    bool   `json:"ready"`
+	Restarts int    `json:"restarts"`
+	// Reason is the waiting or termination reason of the first container
+	// that is not ready, e.g. "CrashLoopBackOff".
+	Reason string `json:"reason,omitempty"`
+}
+
+func (p PodState) String() string {
+	s := p.Name + ": " + p.Phase
+	if p.Reason != "" {
+		s += " (" + p.Reason + ")"
+	}
+	if !p.Ready && p.Phase == "Running" && p.Reason == "" {
+		s += " (not ready)"
+	}
+	if p.Restarts > 0 {
+		s += fmt.Sprintf(", %d restarts", p.Restarts)
+	}
+	return s
+}
+
+// DeploymentState is the rollout status of a deployment.
+type DeploymentState struct {
+	Name              string `json:"name"`
+	Replicas          int    `json:"replicas"`
+	ReadyReplicas     int    `json:"readyReplicas"`
+	AvailableReplicas int    `json:"availableReplicas"`
+	UpdatedReplicas   int    `json:"updatedReplicas"`
+	// Selector is the label selector of the deployment's pods.
+	Selector string `json:"selector"`
+}
+
+// Available reports whether every desired replica is available.
+func (d DeploymentState) Available() bool {
+	return d.AvailableReplicas >= d.Replicas
+}
+
+func (d DeploymentState) String() string {
+	return fmt.Sprintf("%d/%d ready, %d available, %d updated", d.ReadyReplicas, d.Replicas, d.AvailableReplicas, d.UpdatedReplicas)
+}
+
+func podState(o *corev1.Pod) PodState {
+	s := PodState{Name: o.Name, Phase: string(o.Status.Phase)}
+	for _, c := range o.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			s.Ready = c.Status == corev1.ConditionTrue
+		}
+	}
+	for _, cs := range o.Status.ContainerStatuses {
+		s.Restarts += int(cs.RestartCount)
+		if cs.Ready || s.Reason != "" {
+			continue
+		}
+		if w := cs.State.Waiting; w != nil {
+			s.Reason = w.Reason
+		} else if t := cs.State.Terminated; t != nil {
+			s.Reason = t.Reason
+		}
+	}
+	return s
+}
+
+// Pod returns the state of one pod.
+func (c *Client) Pod(ctx context.Context, namespace, name string) (PodState, error) {
+	o, err := c.cs.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return PodState{}, apiError(err)
+	}
+	return podState(o), nil
+}
+
+// Pods returns the states of the pods matching a label selector, sorted by
+// name.
+func (c *Client) Pods(ctx context.Context, namespace, selector string) ([]PodState, error) {
+	list, err := c.cs.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, apiError(err)
+	}
+	out := make([]PodState, 0, len(list.Items))
+	for i := range list.Items {
+		out = append(out, podState(&list.Items[i]))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// DeletePod deletes a pod so that its controller replaces it.
+func (c *Client) DeletePod(ctx context.Context, namespace, name string) error {
+	return apiError(c.cs.CoreV1().Pods(namespace).Delete(ctx, name, metav1.DeleteOptions{}))
+}
+
+// Deployment returns the rollout status of a deployment.
+func (c *Client) Deployment(ctx context.Context, namespace, name string) (DeploymentState, error) {
+	o, err := c.cs.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return DeploymentState{}, apiError(err)
+	}
+	d := DeploymentState{
+		Name:              o.Name,
+		Replicas:          1,
+		ReadyReplicas:     int(o.Status.ReadyReplicas),
+		AvailableReplicas: int(o.Status.AvailableReplicas),
+		UpdatedReplicas:   int(o.Status.UpdatedReplicas),
+	}
+	if o.Spec.Replicas != nil {
+		d.Replicas = int(*o.Spec.Replicas)
+	}
+	if sel := o.Spec.Selector; sel != nil {
+		labels := make([]string, 0, len(sel.MatchLabels))
+		for k, v := range sel.MatchLabels {
+			labels = append(labels, k+"="+v)
+		}
+		sort.Strings(labels)
+		d.Selector = strings.Join(labels, ",")
+	}
+	return d, nil
+}
+
+// RolloutRestart restarts a deployment the way `kubectl rollout restart`
+// does, by stamping its pod template with a restartedAt annotation.
+func (c *Client) RolloutRestart(ctx context.Context, namespace, name string, now time.Time) error {
+	patch := map[string]any{
+		"spec": map[string]any{
+			"template": map[string]any{
+				"metadata": map[string]any{
+					"annotations": map[string]string{
+						"kubectl.kubernetes.io/restartedAt": now.Format(time.RFC3339),
+					},
+				},
+			},
+		},
+	}
+	b, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	_, err = c.cs.AppsV1().Deployments(namespace).Patch(ctx, name, types.StrategicMergePatchType, b, metav1.PatchOptions{})
+	return apiError(err)
+}
+
+// apiError maps a missing object to ErrNotFound and prefixes other API
+// errors.
+func apiError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case apierrors.IsNotFound(err):
+		return ErrNotFound
+	case apierrors.ReasonForError(err) != metav1.StatusReasonUnknown:
+		return fmt.Errorf("kubernetes api: %w", err)
+	}
+	return err
+}
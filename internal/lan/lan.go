@@ -0,0 +1,28 @@
+// Package lan checks whether a device is present on the local network by
+// resolving it through the kernel's ARP table.
+package lan
+
+import (
+	"errors"
+	"net"
+	"strings"
+)
+
+var (
+	ErrUnsupported = errors.New("lan presence checks are only supported on linux")
+	ErrNotPresent  = errors.New("device did not respond to ARP")
+)
+
+// Neighbor is a resolved ARP entry.
+type Neighbor struct {
+	IP  string
+	MAC string
+}
+
+func normalizeMAC(mac string) string {
+	hw, err := net.ParseMAC(strings.TrimSpace(mac))
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(mac))
+	}
+	return hw.String()
+}
@@ -0,0 +1,92 @@
+//go:build linux
+
+package lan
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+const arpTable = "/proc/net/arp"
+
+// Probe nudges the kernel into (re)resolving ip via ARP by sending it a
+// throwaway UDP datagram, then waits for a complete ARP entry. When mac is
+// non-empty the resolved hardware address must match. With only a MAC, the
+// ARP table is searched for it without probing.
+func Probe(ctx context.Context, ip, mac string) (Neighbor, error) {
+	want := ""
+	if mac != "" {
+		want = normalizeMAC(mac)
+	}
+
+	if ip == "" {
+		if n, ok := lookup("", want); ok {
+			return n, nil
+		}
+		return Neighbor{}, ErrNotPresent
+	}
+	if net.ParseIP(ip) == nil {
+		return Neighbor{}, fmt.Errorf("invalid ip %q", ip)
+	}
+
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		poke(ip)
+		if n, ok := lookup(ip, ""); ok {
+			if want != "" && n.MAC != want {
+				return n, fmt.Errorf("%s answered from %s, expected %s", ip, n.MAC, want)
+			}
+			return n, nil
+		}
+		select {
+		case <-ctx.Done():
+			return Neighbor{}, ErrNotPresent
+		case <-ticker.C:
+		}
+	}
+}
+
+func poke(ip string) {
+	conn, err := net.Dial("udp", net.JoinHostPort(ip, "9"))
+	if err != nil {
+		return
+	}
+	_, _ = conn.Write([]byte{0})
+	_ = conn.Close()
+}
+
+// lookup scans the ARP table for a complete entry matching ip or mac.
+func lookup(ip, mac string) (Neighbor, bool) {
+	f, err := os.Open(arpTable)
+	if err != nil {
+		return Neighbor{}, false
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Scan() // header
+	for sc.Scan() {
+		// IP address  HW type  Flags  HW address  Mask  Device
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		if fields[2] != "0x2" { // ATF_COM: entry is complete
+			continue
+		}
+		n := Neighbor{IP: fields[0], MAC: normalizeMAC(fields[3])}
+		if ip != "" && n.IP == ip {
+			return n, true
+		}
+		if ip == "" && mac != "" && n.MAC == mac {
+			return n, true
+		}
+	}
+	return Neighbor{}, false
+}
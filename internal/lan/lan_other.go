@@ -0,0 +1,9 @@
+//go:build !linux
+
+package lan
+
+import "context"
+
+func Probe(ctx context.Context, ip, mac string) (Neighbor, error) {
+	return Neighbor{}, ErrUnsupported
+}
@@ -0,0 +1,87 @@
+package mailprobe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (s *session) imap(ctx context.Context, start time.Time) error {
+	banner, err := s.text().ReadLine()
+	if err != nil {
+		return fmt.Errorf("banner: %w", err)
+	}
+	if !strings.HasPrefix(banner, "* OK") && !strings.HasPrefix(banner, "* PREAUTH") {
+		return fmt.Errorf("banner: %s", banner)
+	}
+	s.res.Banner = strings.TrimPrefix(banner, "* ")
+	s.res.BannerLatency = time.Since(start)
+
+	tag := 0
+	cmd := func(format string, args ...any) ([]string, error) {
+		tag++
+		t := "a" + strconv.Itoa(tag)
+		if err := s.text().PrintfLine(t+" "+format, args...); err != nil {
+			return nil, err
+		}
+		var untagged []string
+		for {
+			line, err := s.text().ReadLine()
+			if err != nil {
+				return nil, err
+			}
+			if rest, ok := strings.CutPrefix(line, t+" "); ok {
+				if !strings.HasPrefix(rest, "OK") {
+					return nil, errors.New(rest)
+				}
+				return untagged, nil
+			}
+			untagged = append(untagged, line)
+		}
+	}
+	capabilities := func() (string, error) {
+		lines, err := cmd("CAPABILITY")
+		for _, l := range lines {
+			if c, ok := strings.CutPrefix(l, "* CAPABILITY "); ok {
+				return " " + strings.ToUpper(c) + " ", nil
+			}
+		}
+		return "", err
+	}
+
+	caps, err := capabilities()
+	if err != nil {
+		return fmt.Errorf("CAPABILITY: %w", err)
+	}
+	if s.opts.StartTLS && !s.opts.TLS {
+		if !strings.Contains(caps, " STARTTLS ") {
+			return ErrStartTLSUnsupported
+		}
+		if _, err := cmd("STARTTLS"); err != nil {
+			return fmt.Errorf("STARTTLS: %w", err)
+		}
+		if err := s.handshake(ctx); err != nil {
+			return err
+		}
+		if caps, err = capabilities(); err != nil {
+			return fmt.Errorf("CAPABILITY: %w", err)
+		}
+	}
+	if s.opts.Username != "" {
+		if strings.Contains(caps, " LOGINDISABLED ") {
+			return errors.New("server disables LOGIN on this connection; enable TLS or STARTTLS")
+		}
+		if _, err := cmd("LOGIN %s %s", imapQuote(s.opts.Username), imapQuote(s.opts.Password)); err != nil {
+			return fmt.Errorf("login: %w", err)
+		}
+	}
+	_, _ = cmd("LOGOUT")
+	return nil
+}
+
+func imapQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,129 @@
+// Package mailprobe checks SMTP and IMAP servers: it waits for the greeting
+// banner, negotiates TLS when asked to and optionally logs in.
+package mailprobe
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strconv"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/netdial"
+)
+
+type Protocol string
+
+const (
+	SMTP Protocol = "smtp"
+	IMAP Protocol = "imap"
+)
+
+// Options describes one probe. Port defaults to 25 or 143, or 465 or 993
+// with implicit TLS.
+type Options struct {
+	Protocol Protocol
+	Host     string
+	Port     int
+	// TLS connects with implicit TLS; StartTLS upgrades a plain connection
+	// and fails if the server does not offer it.
+	TLS      bool
+	StartTLS bool
+	// Username and Password log in with AUTH PLAIN or LOGIN (SMTP) or
+	// LOGIN (IMAP) when set.
+	Username           string
+	Password           string
+	InsecureSkipVerify bool
+	// Dialer connects to the server; nil dials like net.Dialer.
+	Dialer *netdial.Dialer
+}
+
+// Result describes the server reached by a probe.
+type Result struct {
+	Banner        string
+	BannerLatency time.Duration
+	// Cert is the server's leaf certificate when TLS was negotiated.
+	Cert *x509.Certificate
+}
+
+var ErrStartTLSUnsupported = errors.New("server does not offer STARTTLS")
+
+// Probe connects to the server and runs the checks described by opts.
+func Probe(ctx context.Context, opts Options) (Result, error) {
+	if opts.Host == "" {
+		return Result{}, errors.New("missing host")
+	}
+	port := opts.Port
+	if port == 0 {
+		switch {
+		case opts.Protocol == IMAP && opts.TLS:
+			port = 993
+		case opts.Protocol == IMAP:
+			port = 143
+		case opts.TLS:
+			port = 465
+		default:
+			port = 25
+		}
+	}
+
+	start := time.Now()
+	raw, err := opts.Dialer.DialContext(ctx, "tcp", net.JoinHostPort(opts.Host, strconv.Itoa(port)))
+	if err != nil {
+		return Result{}, err
+	}
+	defer raw.Close()
+	if dl, ok := ctx.Deadline(); ok {
+		_ = raw.SetDeadline(dl)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = raw.SetDeadline(time.Now()) })
+	defer stop()
+
+	p := &session{opts: opts, conn: raw}
+	if opts.TLS {
+		if err := p.handshake(ctx); err != nil {
+			return p.res, err
+		}
+	}
+	switch opts.Protocol {
+	case SMTP, "":
+		err = p.smtp(ctx, start)
+	case IMAP:
+		err = p.imap(ctx, start)
+	default:
+		err = fmt.Errorf("unsupported protocol %q", opts.Protocol)
+	}
+	return p.res, err
+}
+
+type session struct {
+	opts Options
+	conn net.Conn
+	tp   *textproto.Conn
+	res  Result
+}
+
+// handshake switches the connection to TLS and records the certificate.
+func (s *session) handshake(ctx context.Context) error {
+	tc := tls.Client(s.conn, &tls.Config{ServerName: s.opts.Host, InsecureSkipVerify: s.opts.InsecureSkipVerify})
+	if err := tc.HandshakeContext(ctx); err != nil {
+		return fmt.Errorf("tls: %w", err)
+	}
+	if certs := tc.ConnectionState().PeerCertificates; len(certs) > 0 {
+		s.res.Cert = certs[0]
+	}
+	s.conn = tc
+	s.tp = nil
+	return nil
+}
+
+func (s *session) text() *textproto.Conn {
+	if s.tp == nil {
+		s.tp = textproto.NewConn(s.conn)
+	}
+	return s.tp
+}
@@ -0,0 +1,118 @@
+package mailprobe
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func (s *session) smtp(ctx context.Context, start time.Time) error {
+	_, banner, err := s.text().ReadResponse(220)
+	if err != nil {
+		return fmt.Errorf("banner: %w", err)
+	}
+	s.res.Banner = firstLine(banner)
+	s.res.BannerLatency = time.Since(start)
+
+	ext, err := s.ehlo()
+	if err != nil {
+		return err
+	}
+	if s.opts.StartTLS && !s.opts.TLS {
+		if _, ok := ext["STARTTLS"]; !ok {
+			return ErrStartTLSUnsupported
+		}
+		if err := s.smtpCmd(220, "STARTTLS"); err != nil {
+			return err
+		}
+		if err := s.handshake(ctx); err != nil {
+			return err
+		}
+		if ext, err = s.ehlo(); err != nil {
+			return err
+		}
+	}
+	if s.opts.Username != "" {
+		if err := s.smtpAuth(ext["AUTH"]); err != nil {
+			return err
+		}
+	}
+	_ = s.smtpCmd(221, "QUIT")
+	return nil
+}
+
+// ehlo greets the server and returns its extensions and their parameters.
+func (s *session) ehlo() (map[string]string, error) {
+	id, err := s.text().Cmd("EHLO uptime-chopper")
+	if err != nil {
+		return nil, err
+	}
+	s.text().StartResponse(id)
+	defer s.text().EndResponse(id)
+	_, msg, err := s.text().ReadResponse(250)
+	if err != nil {
+		return nil, fmt.Errorf("EHLO: %w", err)
+	}
+	ext := map[string]string{}
+	lines := strings.Split(msg, "\n")
+	for _, l := range lines[1:] {
+		k, v, _ := strings.Cut(l, " ")
+		ext[strings.ToUpper(k)] = v
+	}
+	return ext, nil
+}
+
+func (s *session) smtpCmd(code int, format string, args ...any) error {
+	id, err := s.text().Cmd(format, args...)
+	if err != nil {
+		return err
+	}
+	s.text().StartResponse(id)
+	defer s.text().EndResponse(id)
+	_, _, err = s.text().ReadResponse(code)
+	return err
+}
+
+// smtpAuth logs in with PLAIN, or LOGIN when that is all the server offers.
+func (s *session) smtpAuth(mechanisms string) error {
+	if mechanisms == "" {
+		return errors.New("server does not offer AUTH")
+	}
+	offered := strings.Fields(strings.ToUpper(mechanisms))
+	has := func(m string) bool {
+		for _, o := range offered {
+			if o == m {
+				return true
+			}
+		}
+		return false
+	}
+	enc := base64.StdEncoding.EncodeToString
+	switch {
+	case has("PLAIN"):
+		if err := s.smtpCmd(235, "AUTH PLAIN %s", enc([]byte("\x00"+s.opts.Username+"\x00"+s.opts.Password))); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	case has("LOGIN"):
+		if err := s.smtpCmd(334, "AUTH LOGIN"); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+		if err := s.smtpCmd(334, "%s", enc([]byte(s.opts.Username))); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+		if err := s.smtpCmd(235, "%s", enc([]byte(s.opts.Password))); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	default:
+		return fmt.Errorf("no supported AUTH mechanism in %q", mechanisms)
+	}
+	return nil
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return strings.TrimSpace(line)
+}
@@ -1,20 +1,66 @@
 package model
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/cron"
+)
 
 type MonitorType string
 
 const (
-	MonitorTypeHTTP      MonitorType = "http"
-	MonitorTypeContainer MonitorType = "container"
+	MonitorTypeHTTP        MonitorType = "http"
+	MonitorTypeContainer   MonitorType = "container"
+	MonitorTypeWinService  MonitorType = "winservice"
+	MonitorTypeLANPresence MonitorType = "lan_presence"
+	MonitorTypeCompose     MonitorType = "compose"
+	MonitorTypeKubernetes  MonitorType = "kubernetes"
+	MonitorTypePush        MonitorType = "push"
+	MonitorTypeMySQL       MonitorType = "mysql"
+	MonitorTypePostgres    MonitorType = "postgres"
+	MonitorTypeRedis       MonitorType = "redis"
+	MonitorTypeMongoDB     MonitorType = "mongodb"
+	MonitorTypeMail        MonitorType = "mail"
+	MonitorTypeExec        MonitorType = "exec"
+	MonitorTypeSystem      MonitorType = "system"
 )
 
+// IsDatabase reports whether t is one of the database monitor types, which
+// are configured through Monitor.Database.
+func (t MonitorType) IsDatabase() bool {
+	switch t {
+	case MonitorTypeMySQL, MonitorTypePostgres, MonitorTypeRedis, MonitorTypeMongoDB:
+		return true
+	}
+	return false
+}
+
 type RemediationAction string
 
 const (
 	RemediationNone    RemediationAction = "none"
 	RemediationStart   RemediationAction = "start"
 	RemediationRestart RemediationAction = "restart"
+	RemediationStop    RemediationAction = "stop"
+	// RemediationExec runs RemediationPolicy.Command inside the container.
+	RemediationExec RemediationAction = "exec"
+	// RemediationRecreate removes the container and runs a new one with the
+	// same name and configuration.
+	RemediationRecreate RemediationAction = "recreate"
+	// RemediationWebhook calls RemediationPolicy.Webhook and
+	// RemediationScript runs RemediationPolicy.Script on this host; both
+	// work for any monitor type.
+	RemediationWebhook RemediationAction = "webhook"
+	RemediationScript  RemediationAction = "script"
+	// RemediationUpdateImage records an automatic image update; it is not a
+	// policy action.
+	RemediationUpdateImage RemediationAction = "update_image"
+	// Kubernetes monitors only.
+	RemediationDeletePod      RemediationAction = "delete_pod"
+	RemediationRolloutRestart RemediationAction = "rollout_restart"
 )
 
 type RestartPolicyName string
@@ -30,37 +76,422 @@ type RemediationPolicy struct {
 	Action          RemediationAction `json:"action"`
 	MaxAttempts     int               `json:"maxAttempts"`
 	CooldownSeconds int               `json:"cooldownSeconds"`
+	// TimeoutSeconds bounds the action: the stop grace period for stop,
+	// restart and recreate, and the run time of an exec command. Defaults
+	// to 10s, 30s for exec.
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+	// Command is the exec action's command, e.g. ["sh", "-c", "kill -HUP 1"].
+	Command []string `json:"command,omitempty"`
+	// Script is the script action's shell command. It runs with
+	// UPTIME_MONITOR_ID, UPTIME_MONITOR_NAME, UPTIME_MONITOR_TARGET and
+	// UPTIME_MESSAGE set in its environment.
+	Script  string              `json:"script,omitempty"`
+	Webhook *RemediationRequest `json:"webhook,omitempty"`
+}
+
+// RemediationRequest is the HTTP request sent by the webhook action. Method
+// defaults to POST; an empty Body sends a JSON description of the monitor
+// and its failure. Any non-2xx response counts as a failed attempt.
+type RemediationRequest struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
 }
 
 type DockerLogOptions struct {
 	Include bool `json:"include"`
 	Tail    int  `json:"tail"`
+	// Per-monitor overrides; zero values fall back to the global config.
+	SinceSeconds  int   `json:"sinceSeconds,omitempty"`
+	MaxBytes      int   `json:"maxBytes,omitempty"`
+	IncludeStdout *bool `json:"includeStdout,omitempty"`
+	IncludeStderr *bool `json:"includeStderr,omitempty"`
+	Timestamps    *bool `json:"timestamps,omitempty"`
+}
+
+// Stdout reports whether stdout should be collected (default true).
+func (o DockerLogOptions) Stdout() bool {
+	return o.IncludeStdout == nil || *o.IncludeStdout
+}
+
+// Stderr reports whether stderr should be collected (default true).
+func (o DockerLogOptions) Stderr() bool {
+	return o.IncludeStderr == nil || *o.IncludeStderr
+}
+
+// ShowTimestamps reports whether log lines should be prefixed with timestamps (default true).
+func (o DockerLogOptions) ShowTimestamps() bool {
+	return o.Timestamps == nil || *o.Timestamps
 }
 
 type Monitor struct {
-	ID               string            `json:"id"`
-	Name             string            `json:"name"`
-	Type             MonitorType       `json:"type"`
-	IsPaused         bool              `json:"isPaused"`
-	IntervalSeconds  int               `json:"intervalSeconds"`
-	TimeoutSeconds   int               `json:"timeoutSeconds"`
-	RetentionDays    int               `json:"retentionDays"` // New field: 0 means default (e.g. 30 days or forever?), user can set
-	NotifyWebhookIDs []string          `json:"notifyWebhookIds"`
-	CreatedAt        time.Time         `json:"createdAt"`
-	UpdatedAt        time.Time         `json:"updatedAt"`
-	HTTP             *HTTPMonitor      `json:"http,omitempty"`
-	Container        *ContainerMonitor `json:"container,omitempty"`
-	Logs             DockerLogOptions  `json:"logs"`
+	ID                   string              `json:"id"`
+	Name                 string              `json:"name"`
+	Type                 MonitorType         `json:"type"`
+	IsPaused             bool                `json:"isPaused"`
+	PausedReason         string              `json:"pausedReason,omitempty"`
+	Provisioned          bool                `json:"provisioned,omitempty"` // Managed by the provisioning file; read-only in the API
+	IntervalSeconds      int                 `json:"intervalSeconds"`
+	TimeoutSeconds       int                 `json:"timeoutSeconds"`
+	RetriesBeforeDown    int                 `json:"retriesBeforeDown,omitempty"`    // Re-checks before flipping to down
+	RetryIntervalSeconds int                 `json:"retryIntervalSeconds,omitempty"` // Delay between confirmation re-checks (default 2s)
+	LatencyWarnMs        int                 `json:"latencyWarnMs,omitempty"`        // Successful checks at least this slow are degraded; 0 disables
+	LatencyCriticalMs    int                 `json:"latencyCriticalMs,omitempty"`    // Successful checks at least this slow are down; 0 disables
+	RetentionDays        int                 `json:"retentionDays"`                  // Days of history to keep; 0 uses the global history retention
+	HistorySampleEvery   int                 `json:"historySampleEvery,omitempty"`   // Persist every Nth consecutive success; failures and transitions always kept
+	NotifyWebhookIDs     []string            `json:"notifyWebhookIds"`
+	RoutingPolicyID      string              `json:"routingPolicyId,omitempty"`
+	NotifyAfterFailures  int                 `json:"notifyAfterFailures,omitempty"` // Consecutive down checks before alerting (default 1)
+	ResendEveryMinutes   int                 `json:"resendEveryMinutes,omitempty"`  // Re-alert interval while still down; 0 disables
+	NotifyRecoveryOnly   bool                `json:"notifyRecoveryOnly,omitempty"`  // Suppress down alerts, only announce recovery
+	Mention              string              `json:"mention,omitempty"`             // Owner tagged in alerts: chat user ID, @username or phone number
+	Tags                 []string            `json:"tags,omitempty"`                // Free-form labels for grouping, filtering and bulk actions
+	CreatedAt            time.Time           `json:"createdAt"`
+	UpdatedAt            time.Time           `json:"updatedAt"`
+	HTTP                 *HTTPMonitor        `json:"http,omitempty"`
+	Container            *ContainerMonitor   `json:"container,omitempty"`
+	WinService           *WinServiceMonitor  `json:"winService,omitempty"`
+	LANPresence          *LANPresenceMonitor `json:"lanPresence,omitempty"`
+	Compose              *ComposeMonitor     `json:"compose,omitempty"`
+	Kubernetes           *KubernetesMonitor  `json:"kubernetes,omitempty"`
+	Push                 *PushMonitor        `json:"push,omitempty"`
+	Database             *DatabaseMonitor    `json:"database,omitempty"`
+	Mail                 *MailMonitor        `json:"mail,omitempty"`
+	Exec                 *ExecMonitor        `json:"exec,omitempty"`
+	System               *SystemMonitor      `json:"system,omitempty"`
+	Agent                string              `json:"agent,omitempty"`       // Remote agent that runs the check; empty runs it on the server
+	Regions              *RegionPolicy       `json:"regions,omitempty"`     // Check from several agents at once instead of Agent
+	IPVersion            IPVersion           `json:"ipVersion,omitempty"`   // Connect http, database and mail checks over this IP version only
+	DNSServer            string              `json:"dnsServer,omitempty"`   // Resolve their targets with this server (host or host:port)
+	Remediation          *RemediationPolicy  `json:"remediation,omitempty"` // Webhook/script healing for types without their own policy
+	Logs                 DockerLogOptions    `json:"logs"`
 }
 
+// HistoryRetention returns the days of history to keep for m, falling back
+// to the global default. Zero keeps history forever.
+func (m Monitor) HistoryRetention(defaultDays int) int {
+	if m.RetentionDays > 0 {
+		return m.RetentionDays
+	}
+	return defaultDays
+}
+
+// IPVersion restricts the addresses a network check connects to; empty
+// uses either version.
+type IPVersion string
+
+const (
+	IPAny IPVersion = ""
+	IPv4  IPVersion = "ipv4"
+	IPv6  IPVersion = "ipv6"
+)
+
 type HTTPMonitor struct {
 	URL string `json:"url"`
+	// RetryOnFailure retries a failed request once over a fresh connection;
+	// a successful retry is recorded as a transient network event, not an outage.
+	RetryOnFailure bool `json:"retryOnFailure,omitempty"`
+	// FreshConnection forces a new TCP+TLS connection on every check instead
+	// of reusing pooled keep-alive connections.
+	FreshConnection bool `json:"freshConnection,omitempty"`
+	// MaxIdleConns caps the keep-alive connections the monitor's transport
+	// keeps open per host; 0 uses the net/http default of 2.
+	MaxIdleConns int `json:"maxIdleConns,omitempty"`
+	// DNSCacheSeconds reuses resolved addresses for new connections for that
+	// long instead of looking the host up each time; 0 disables the cache.
+	DNSCacheSeconds int `json:"dnsCacheSeconds,omitempty"`
+	// MeasurePhases records how long DNS, connect, TLS and the first
+	// response byte took with every check result.
+	MeasurePhases bool `json:"measurePhases,omitempty"`
+	// PinnedSPKISHA256 lists accepted base64 SHA-256 hashes of the leaf
+	// certificate's SubjectPublicKeyInfo. Empty disables SPKI pinning.
+	PinnedSPKISHA256 []string `json:"pinnedSpkiSha256,omitempty"`
+	// ExpectedIssuer, when set, must be contained in the leaf certificate's
+	// issuer common name or organization.
+	ExpectedIssuer string `json:"expectedIssuer,omitempty"`
+	// Keyword must appear in the response body; KeywordAbsent must not.
+	Keyword       string `json:"keyword,omitempty"`
+	KeywordAbsent string `json:"keywordAbsent,omitempty"`
+	// JSONAssertions must all hold for the JSON response body, e.g.
+	// `$.status == "ok"`; the values they select are added to the message.
+	JSONAssertions []string `json:"jsonAssertions,omitempty"`
+	// MinBodyBytes and MaxBodyBytes bound the size of the response body, and
+	// BodySHA256 is the hex SHA-256 it must hash to, e.g. to catch truncated
+	// assets or a defaced page. Zero values disable the assertions.
+	MinBodyBytes int64     `json:"minBodyBytes,omitempty"`
+	MaxBodyBytes int64     `json:"maxBodyBytes,omitempty"`
+	BodySHA256   string    `json:"bodySha256,omitempty"`
+	Auth         *HTTPAuth `json:"auth,omitempty"`
+	// Headers are extra request headers, e.g. an API key.
+	Headers map[string]string `json:"headers,omitempty"`
+	// InsecureSkipVerify disables server certificate verification, e.g. for
+	// internal services with self-signed certificates.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+	// ClientCertPEM and ClientKeyPEM configure a client certificate for mTLS.
+	ClientCertPEM string `json:"clientCertPem,omitempty"`
+	ClientKeyPEM  string `json:"clientKeyPem,omitempty"`
+	// MaxRedirects limits how many redirects are followed; 0 makes a 3xx
+	// response the final status. Nil follows up to 10, like net/http.
+	MaxRedirects *int `json:"maxRedirects,omitempty"`
+}
+
+// DefaultMaxRedirects is the redirect limit used when MaxRedirects is nil.
+const DefaultMaxRedirects = 10
+
+// RedirectLimit returns MaxRedirects, or DefaultMaxRedirects when unset.
+func (h *HTTPMonitor) RedirectLimit() int {
+	if h.MaxRedirects == nil {
+		return DefaultMaxRedirects
+	}
+	return *h.MaxRedirects
 }
 
+type HTTPAuthType string
+
+const (
+	HTTPAuthNone   HTTPAuthType = ""
+	HTTPAuthBasic  HTTPAuthType = "basic"
+	HTTPAuthBearer HTTPAuthType = "bearer"
+)
+
+// HTTPAuth holds credentials sent with every check request.
+type HTTPAuth struct {
+	Type     HTTPAuthType `json:"type"`
+	Username string       `json:"username,omitempty"`
+	Password string       `json:"password,omitempty"`
+	Token    string       `json:"token,omitempty"`
+}
+
+// HTTPPhases splits the latency of an HTTP check. A phase that did not
+// happen, such as connect and TLS on a reused connection, is 0; with
+// redirects each phase sums over all requests.
+type HTTPPhases struct {
+	DNSMs     int `json:"dnsMs"`
+	ConnectMs int `json:"connectMs"`
+	TLSMs     int `json:"tlsMs"`
+	// TTFBMs is the time from sending the request to the first response byte.
+	TTFBMs int `json:"ttfbMs"`
+}
+
+type ConnectionMode string
+
+const (
+	ConnectionReused ConnectionMode = "keepalive"
+	ConnectionFresh  ConnectionMode = "fresh"
+)
+
 type ContainerMonitor struct {
+	// HostID names one of the configured docker_hosts; empty means the
+	// local daemon.
+	HostID        string            `json:"hostId,omitempty"`
 	ContainerID   string            `json:"containerId"`
 	RestartPolicy *RestartPolicy    `json:"restartPolicy,omitempty"`
 	Remediation   RemediationPolicy `json:"remediation"`
+	// AutoPauseOnRemoval pauses the monitor with an orphaned status once the
+	// container no longer exists, instead of reporting it down forever.
+	AutoPauseOnRemoval bool `json:"autoPauseOnRemoval,omitempty"`
+	// Resources reports the container down while its CPU or memory usage
+	// stays above the thresholds.
+	Resources *ResourceThresholds `json:"resources,omitempty"`
+	// CrashLoop reports the container as crash_loop, with a single alert,
+	// once it restarts too often instead of flapping between up and down.
+	CrashLoop *CrashLoopPolicy `json:"crashLoop,omitempty"`
+	// AutoUpdate pulls the container's image and recreates the container
+	// when the image update checker finds a newer image in the registry.
+	AutoUpdate bool `json:"autoUpdate,omitempty"`
+}
+
+// CrashLoopPolicy detects Restarts or more restarts within WindowMinutes
+// (defaults 3 and 5).
+type CrashLoopPolicy struct {
+	Restarts      int `json:"restarts,omitempty"`
+	WindowMinutes int `json:"windowMinutes,omitempty"`
+}
+
+// ResourceThresholds are usage limits in percent; zero disables a limit.
+// ForMinutes is how long usage must stay above a limit before the monitor
+// is reported down (0 reports it on the first sample).
+type ResourceThresholds struct {
+	CPUPercent    float64 `json:"cpuPercent,omitempty"`
+	MemoryPercent float64 `json:"memoryPercent,omitempty"`
+	ForMinutes    int     `json:"forMinutes,omitempty"`
+}
+
+// WinServiceMonitor checks a Windows service, or an IIS site when IISSite is
+// set. Only supported when running on Windows.
+type WinServiceMonitor struct {
+	ServiceName string `json:"serviceName"`
+	IISSite     string `json:"iisSite,omitempty"`
+	// RestartOnStopped starts the service again when it is found stopped.
+	RestartOnStopped bool `json:"restartOnStopped,omitempty"`
+}
+
+// LANPresenceMonitor checks that a device answers ARP on the local network.
+// At least one of IP or MAC must be set; with both, the MAC must match.
+type LANPresenceMonitor struct {
+	IP  string `json:"ip,omitempty"`
+	MAC string `json:"mac,omitempty"`
+}
+
+// ComposeMonitor checks that every container of a Docker Compose project is
+// running and not failing its health check.
+type ComposeMonitor struct {
+	// HostID names one of the configured docker_hosts; empty means the
+	// local daemon.
+	HostID  string `json:"hostId,omitempty"`
+	Project string `json:"project"`
+}
+
+type KubernetesKind string
+
+const (
+	KubernetesPod        KubernetesKind = "pod"
+	KubernetesDeployment KubernetesKind = "deployment"
+)
+
+// PushMonitor is a heartbeat monitor: instead of being polled, the job it
+// watches calls POST /api/push/{token} and the monitor goes down when no
+// heartbeat arrives within IntervalSeconds plus GraceSeconds.
+//
+// With Cron set, heartbeats are expected per run of the job instead: a run
+// is missed when no heartbeat arrives between its scheduled time and
+// GraceSeconds (default 5 minutes) after it.
+type PushMonitor struct {
+	Token        string `json:"token"`
+	GraceSeconds int    `json:"graceSeconds,omitempty"`
+	Cron         string `json:"cron,omitempty"`
+	Timezone     string `json:"timezone,omitempty"`
+}
+
+// Validate checks the cron schedule and timezone, if any.
+func (p PushMonitor) Validate() error {
+	if p.Cron == "" {
+		return nil
+	}
+	if _, err := cron.Parse(p.Cron); err != nil {
+		return err
+	}
+	if p.Timezone != "" {
+		if _, err := time.LoadLocation(p.Timezone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Schedule returns the parsed cron schedule and the location it runs in.
+func (p PushMonitor) Schedule() (*cron.Schedule, *time.Location, error) {
+	sched, err := cron.Parse(p.Cron)
+	if err != nil {
+		return nil, nil, err
+	}
+	loc := time.Local
+	if p.Timezone != "" {
+		if loc, err = time.LoadLocation(p.Timezone); err != nil {
+			return nil, nil, err
+		}
+	}
+	return sched, loc, nil
+}
+
+// Grace returns how long after a scheduled run its heartbeat may arrive.
+func (p PushMonitor) Grace() time.Duration {
+	if p.GraceSeconds > 0 {
+		return time.Duration(p.GraceSeconds) * time.Second
+	}
+	return 5 * time.Minute
+}
+
+// DatabaseMonitor logs in to a MySQL, PostgreSQL, Redis or MongoDB server,
+// as chosen by the monitor type, using a URL style DSN such as
+// postgres://user:pass@db:5432/app?sslmode=require. Query also runs
+// SELECT 1 or ping after logging in; Redis is always sent PING.
+type DatabaseMonitor struct {
+	DSN   string `json:"dsn"`
+	Query bool   `json:"query,omitempty"`
+}
+
+type MailProtocol string
+
+const (
+	MailSMTP MailProtocol = "smtp"
+	MailIMAP MailProtocol = "imap"
+)
+
+// MailMonitor waits for the banner of an SMTP or IMAP server, reported as
+// the check latency, and sends EHLO (SMTP) or CAPABILITY (IMAP). TLS uses
+// implicit TLS (ports 465 and 993 by default), StartTLS upgrades a plain
+// connection; with Username set the monitor also logs in. CertExpiryDays
+// reports the monitor down once the server certificate expires within that
+// many days.
+type MailMonitor struct {
+	Protocol           MailProtocol `json:"protocol"`
+	Host               string       `json:"host"`
+	Port               int          `json:"port,omitempty"`
+	TLS                bool         `json:"tls,omitempty"`
+	StartTLS           bool         `json:"startTls,omitempty"`
+	Username           string       `json:"username,omitempty"`
+	Password           string       `json:"password,omitempty"`
+	InsecureSkipVerify bool         `json:"insecureSkipVerify,omitempty"`
+	CertExpiryDays     int          `json:"certExpiryDays,omitempty"`
+}
+
+// ExecMonitor runs a local command under the monitor's timeout and reports
+// up when it exits 0. Command is run directly; Script, used when Command is
+// empty, goes through the platform shell. Env adds KEY=value variables.
+type ExecMonitor struct {
+	Command []string `json:"command,omitempty"`
+	Script  string   `json:"script,omitempty"`
+	Env     []string `json:"env,omitempty"`
+}
+
+// SystemMonitor checks the resources of the host that runs the check, the
+// server or the monitor's agent, and reports down when one exceeds its
+// threshold. Zero thresholds are not checked. CPU usage is measured over
+// one second of the check.
+type SystemMonitor struct {
+	MaxCPUPercent    float64 `json:"maxCpuPercent,omitempty"`
+	MaxMemoryPercent float64 `json:"maxMemoryPercent,omitempty"`
+	// MaxLoadPerCPU bounds the 5-minute load average divided by the number
+	// of CPUs.
+	MaxLoadPerCPU float64       `json:"maxLoadPerCpu,omitempty"`
+	Disks         []DiskMonitor `json:"disks,omitempty"`
+	// SMART checks the health of these disks with smartctl.
+	SMART []SMARTMonitor `json:"smart,omitempty"`
+}
+
+// DiskMonitor checks the free space of the filesystem mounted at, or
+// holding, Path.
+type DiskMonitor struct {
+	Path           string  `json:"path"`
+	MinFreePercent float64 `json:"minFreePercent,omitempty"`
+	MinFreeBytes   int64   `json:"minFreeBytes,omitempty"`
+}
+
+// SMARTMonitor checks the S.M.A.R.T. health of a disk such as /dev/sda. The
+// disk is down when its overall assessment fails, when it has pending or
+// uncorrectable sectors or an NVMe critical warning, or when it exceeds
+// one of the limits; zero limits are not checked. Type is passed to
+// smartctl as -d, e.g. "sat" for disks behind a USB bridge. Without
+// smartctl only the kernel's device state is checked.
+type SMARTMonitor struct {
+	Device                string `json:"device"`
+	Type                  string `json:"type,omitempty"`
+	MaxTemperature        int    `json:"maxTemperature,omitempty"` // Celsius
+	MaxReallocatedSectors int64  `json:"maxReallocatedSectors,omitempty"`
+}
+
+// KubernetesMonitor checks pod readiness or deployment availability. A pod
+// monitor names a single pod or selects several by label, all of which must
+// be ready. Remediation supports delete_pod (deletes the pods that are not
+// ready) and, for deployments, rollout_restart.
+type KubernetesMonitor struct {
+	Kind          KubernetesKind    `json:"kind"`
+	Namespace     string            `json:"namespace,omitempty"`
+	Name          string            `json:"name,omitempty"`
+	LabelSelector string            `json:"labelSelector,omitempty"`
+	Remediation   RemediationPolicy `json:"remediation"`
 }
 
 type RestartPolicy struct {
@@ -75,22 +506,229 @@ const (
 	StatusUp      MonitorStatus = "up"
 	StatusDown    MonitorStatus = "down"
 	StatusPaused  MonitorStatus = "paused"
+	// StatusOrphaned marks a container monitor whose container was removed.
+	StatusOrphaned MonitorStatus = "orphaned"
+	// StatusDockerUnreachable marks a container monitor whose state could not
+	// be read because the Docker daemon itself is unreachable.
+	StatusDockerUnreachable MonitorStatus = "docker_unreachable"
+	// StatusMaintenance marks a monitor inside an active maintenance window;
+	// it is not checked, remediated or alerted on.
+	StatusMaintenance MonitorStatus = "maintenance"
+	// StatusCrashLoop marks a container monitor whose container keeps being
+	// restarted; it stays in this status until the restarts stop.
+	StatusCrashLoop MonitorStatus = "crash_loop"
+	// StatusDegraded marks a monitor that responds, but slower than its
+	// latency warning threshold.
+	StatusDegraded MonitorStatus = "degraded"
 )
 
+// Available reports whether the monitor responded: up, possibly degraded.
+func (s MonitorStatus) Available() bool {
+	return s == StatusUp || s == StatusDegraded
+}
+
+const PausedReasonOrphaned = "orphaned"
+
 type CheckResult struct {
-	MonitorID string        `json:"monitorId"`
+	MonitorID string         `json:"monitorId"`
+	Status    MonitorStatus  `json:"status"`
+	CheckedAt time.Time      `json:"checkedAt"`
+	LatencyMs int            `json:"latencyMs"`
+	Message   string         `json:"message"`
+	Transient bool           `json:"transient,omitempty"`
+	ConnMode  ConnectionMode `json:"connMode,omitempty"`
+	// Container or host resource usage at check time, if sampled.
+	CPUPercent    *float64 `json:"cpuPercent,omitempty"`
+	MemoryPercent *float64 `json:"memoryPercent,omitempty"`
+	// Regions holds the per-region results behind a multi-region result.
+	Regions []RegionResult `json:"regions,omitempty"`
+	// Phases is set for HTTP checks with MeasurePhases.
+	Phases *HTTPPhases `json:"phases,omitempty"`
+}
+
+// LocalRegion names the server itself in a RegionPolicy.
+const LocalRegion = "local"
+
+type AggregationPolicy string
+
+const (
+	// AggregateMajority reports down when more than half the regions do.
+	AggregateMajority AggregationPolicy = "majority"
+	// AggregateAny reports down when any region does.
+	AggregateAny AggregationPolicy = "any"
+)
+
+// RegionPolicy checks a monitor from several agents, and optionally the
+// server itself as LocalRegion, and combines their latest results.
+type RegionPolicy struct {
+	Agents []string          `json:"agents"`
+	Policy AggregationPolicy `json:"policy,omitempty"` // default majority
+}
+
+// Validate reports settings that cannot be aggregated.
+func (p *RegionPolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	if len(p.Agents) == 0 {
+		return errors.New("regions.agents must list at least one agent")
+	}
+	seen := map[string]bool{}
+	for _, a := range p.Agents {
+		if a == "" || seen[a] {
+			return fmt.Errorf("regions.agents: empty or duplicate agent %q", a)
+		}
+		seen[a] = true
+	}
+	switch p.Policy {
+	case "", AggregateMajority, AggregateAny:
+		return nil
+	}
+	return fmt.Errorf("unknown regions.policy %q", p.Policy)
+}
+
+// RegionResult is the latest result of one region of a multi-region monitor.
+type RegionResult struct {
+	Region    string        `json:"region"`
 	Status    MonitorStatus `json:"status"`
 	CheckedAt time.Time     `json:"checkedAt"`
 	LatencyMs int           `json:"latencyMs"`
-	Message   string        `json:"message"`
+	Message   string        `json:"message,omitempty"`
+}
+
+// AgentResult is a check result reported by a remote agent, with the
+// container logs it collected, if any.
+type AgentResult struct {
+	Result        CheckResult `json:"result"`
+	Logs          string      `json:"logs,omitempty"`
+	LogsTruncated bool        `json:"logsTruncated,omitempty"`
 }
 
 type MonitorHistoryEntry struct {
-	Status    MonitorStatus `json:"status"`
-	CheckedAt time.Time     `json:"checkedAt"`
-	LatencyMs int           `json:"latencyMs"`
-	Message   string        `json:"message"`
-	Logs      string        `json:"logs,omitempty"`
+	Status        MonitorStatus  `json:"status"`
+	CheckedAt     time.Time      `json:"checkedAt"`
+	LatencyMs     int            `json:"latencyMs"`
+	Message       string         `json:"message"`
+	Logs          string         `json:"logs,omitempty"`
+	Transient     bool           `json:"transient,omitempty"`
+	ConnMode      ConnectionMode `json:"connMode,omitempty"`
+	CPUPercent    *float64       `json:"cpuPercent,omitempty"`
+	MemoryPercent *float64       `json:"memoryPercent,omitempty"`
+	// Weight is the number of checks this entry represents when history
+	// sampling is enabled; 0 is treated as 1.
+	Weight  int            `json:"weight,omitempty"`
+	Regions []RegionResult `json:"regions,omitempty"`
+	Phases  *HTTPPhases    `json:"phases,omitempty"`
+}
+
+// Checks returns the number of checks represented by the entry.
+func (h MonitorHistoryEntry) Checks() int {
+	if h.Weight <= 0 {
+		return 1
+	}
+	return h.Weight
+}
+
+// DailyUptime is the cached per-day aggregate of a monitor's history.
+type DailyUptime struct {
+	Day          string `json:"day"` // YYYY-MM-DD, UTC
+	UpChecks     int    `json:"upChecks"`
+	TotalChecks  int    `json:"totalChecks"`
+	LatencySumMs int64  `json:"latencySumMs"`
+}
+
+// HistoryImportResult summarizes a history import or backfill.
+type HistoryImportResult struct {
+	Imported       int      `json:"imported"`
+	Duplicates     int      `json:"duplicates"`
+	Expired        int      `json:"expired"`
+	RecomputedDays []string `json:"recomputedDays"`
+}
+
+// BackupVersion is the format version written into backups.
+const BackupVersion = 1
+
+// Backup is a portable snapshot of an instance's configuration and,
+// optionally, its monitor history keyed by monitor ID. Users, sessions and
+// server settings from config.yaml are not included.
+type Backup struct {
+	Version            int                              `json:"version"`
+	CreatedAt          time.Time                        `json:"createdAt"`
+	Monitors           []Monitor                        `json:"monitors"`
+	Notifications      []Notification                   `json:"notifications"`
+	RoutingPolicies    []RoutingPolicy                  `json:"routingPolicies"`
+	StatusPages        []StatusPage                     `json:"statusPages"`
+	MaintenanceWindows []MaintenanceWindow              `json:"maintenanceWindows"`
+	Settings           *Settings                        `json:"settings,omitempty"`
+	History            map[string][]MonitorHistoryEntry `json:"history,omitempty"`
+}
+
+// RestoreStrategy decides what happens to backed-up items whose ID already
+// exists: skip keeps the existing item, overwrite replaces it and merge
+// applies only the fields present in the backup on top of it.
+type RestoreStrategy string
+
+const (
+	RestoreSkip      RestoreStrategy = "skip"
+	RestoreOverwrite RestoreStrategy = "overwrite"
+	RestoreMerge     RestoreStrategy = "merge"
+)
+
+type RestoreCounts struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+}
+
+// RestoreResult reports what a restore did per collection. Items that
+// fail validation are counted as failed and described in Errors; the rest
+// of the backup is still applied.
+type RestoreResult struct {
+	Strategy           RestoreStrategy `json:"strategy"`
+	Monitors           RestoreCounts   `json:"monitors"`
+	Notifications      RestoreCounts   `json:"notifications"`
+	RoutingPolicies    RestoreCounts   `json:"routingPolicies"`
+	StatusPages        RestoreCounts   `json:"statusPages"`
+	MaintenanceWindows RestoreCounts   `json:"maintenanceWindows"`
+	Settings           RestoreCounts   `json:"settings"`
+	HistoryImported    int             `json:"historyImported"`
+	Errors             []string        `json:"errors"`
+}
+
+// MonitorStats summarizes a monitor's history over a time window.
+type MonitorStats struct {
+	MonitorID       string    `json:"monitorId"`
+	Window          string    `json:"window"`
+	Since           time.Time `json:"since"`
+	TotalChecks     int       `json:"totalChecks"`
+	UpChecks        int       `json:"upChecks"`
+	UptimePercent   float64   `json:"uptimePercent"`
+	AvgLatencyMs    float64   `json:"avgLatencyMs"`
+	MedianLatencyMs int       `json:"medianLatencyMs"`
+	P95LatencyMs    int       `json:"p95LatencyMs"`
+	Outages         int       `json:"outages"`
+}
+
+// LatencyBucket summarizes the latency of successful checks within one
+// resolution step of a latency series.
+type LatencyBucket struct {
+	Start  time.Time `json:"start"`
+	Checks int       `json:"checks"`
+	MinMs  int       `json:"minMs"`
+	AvgMs  float64   `json:"avgMs"`
+	MaxMs  int       `json:"maxMs"`
+	P95Ms  int       `json:"p95Ms"`
+}
+
+// LatencySeries is a monitor's downsampled response time history. Buckets
+// without successful checks are omitted.
+type LatencySeries struct {
+	MonitorID  string          `json:"monitorId"`
+	Window     string          `json:"window"`
+	Resolution string          `json:"resolution"`
+	Since      time.Time       `json:"since"`
+	Buckets    []LatencyBucket `json:"buckets"`
 }
 
 type EventType string
@@ -99,6 +737,19 @@ const (
 	EventStatusChanged EventType = "status_changed"
 	EventRemediated    EventType = "remediated"
 	EventError         EventType = "error"
+
+	EventDockerUnreachable EventType = "docker_unreachable"
+	EventDockerRecovered   EventType = "docker_recovered"
+	EventCrashLoop         EventType = "crash_loop"
+
+	// EventDigest groups several status changes into one notification.
+	EventDigest EventType = "digest"
+
+	EventMonitorCreated EventType = "monitor_created"
+	EventMonitorUpdated EventType = "monitor_updated"
+	EventMonitorDeleted EventType = "monitor_deleted"
+	EventMonitorPaused  EventType = "monitor_paused"
+	EventMonitorResumed EventType = "monitor_resumed"
 )
 
 type MonitorStatusInfo struct {
@@ -106,6 +757,45 @@ type MonitorStatusInfo struct {
 	LastCheck time.Time     `json:"lastCheck"`
 }
 
+// StatusCounts counts monitors by status. Degraded monitors count as up and
+// also as degraded; orphaned, unreachable and crash-looping containers
+// count as down.
+type StatusCounts struct {
+	Total       int `json:"total"`
+	Up          int `json:"up"`
+	Down        int `json:"down"`
+	Degraded    int `json:"degraded"`
+	Maintenance int `json:"maintenance"`
+	Paused      int `json:"paused"`
+	Unknown     int `json:"unknown"`
+}
+
+// Add counts a monitor with status s.
+func (c *StatusCounts) Add(s MonitorStatus) {
+	c.Total++
+	switch s {
+	case StatusUp:
+		c.Up++
+	case StatusDegraded:
+		c.Up++
+		c.Degraded++
+	case StatusMaintenance:
+		c.Maintenance++
+	case StatusPaused:
+		c.Paused++
+	case StatusUnknown, "":
+		c.Unknown++
+	default:
+		c.Down++
+	}
+}
+
+// TagStatus is the rollup of the monitors carrying Tag.
+type TagStatus struct {
+	Tag string `json:"tag"`
+	StatusCounts
+}
+
 type Event struct {
 	ID        string         `json:"id"`
 	Type      EventType      `json:"type"`
@@ -115,10 +805,396 @@ type Event struct {
 }
 
 type Notification struct {
-	ID        string    `json:"id"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"` // webhook, dingtalk, wechat, discord, telegram, feishu, pushover
+	URL      string `json:"url"`
+	BotToken string `json:"botToken,omitempty"` // telegram
+	ChatID   string `json:"chatId,omitempty"`   // telegram
+	Secret   string `json:"secret,omitempty"`   // feishu signing secret
+	UserKey  string `json:"userKey,omitempty"`  // pushover
+	AppToken string `json:"appToken,omitempty"` // pushover
+	// Generic webhook options.
+	Method       string            `json:"method,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	BearerToken  string            `json:"bearerToken,omitempty"`
+	BodyTemplate string            `json:"bodyTemplate,omitempty"`
+	// QuietHours lists do-not-disturb windows; QuietMode chooses whether
+	// non-critical alerts raised inside them are dropped or queued.
+	QuietHours []QuietHours `json:"quietHours,omitempty"`
+	QuietMode  string       `json:"quietMode,omitempty"`
+	CreatedAt  time.Time    `json:"createdAt"`
+	UpdatedAt  time.Time    `json:"updatedAt"`
+}
+
+// InQuietHours reports whether t falls inside any of the channel's quiet windows.
+func (n Notification) InQuietHours(t time.Time) bool {
+	for _, q := range n.QuietHours {
+		if q.Active(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationAttempt records one delivery attempt to a notification channel.
+// NotificationID is the stored channel ID, or the webhook name for channels
+// defined in the config file.
+type NotificationAttempt struct {
+	ID             int64     `json:"id"`
+	NotificationID string    `json:"notificationId"`
+	ChannelName    string    `json:"channelName"`
+	ChannelType    string    `json:"channelType"`
+	MonitorID      string    `json:"monitorId,omitempty"`
+	EventType      string    `json:"eventType"`
+	Summary        string    `json:"summary"`
+	Success        bool      `json:"success"`
+	Error          string    `json:"error,omitempty"`
+	At             time.Time `json:"at"`
+}
+
+// RemediationAttempt records one run of a monitor's remediation action.
+// Attempt is the automatic attempt number since the monitor last recovered;
+// manual runs do not count towards it. Result holds action specific details
+// such as an exec command's exit code and output.
+type RemediationAttempt struct {
+	ID         int64             `json:"id"`
+	MonitorID  string            `json:"monitorId"`
+	Action     RemediationAction `json:"action"`
+	Attempt    int               `json:"attempt"`
+	Manual     bool              `json:"manual,omitempty"`
+	Success    bool              `json:"success"`
+	Error      string            `json:"error,omitempty"`
+	Result     map[string]any    `json:"result,omitempty"`
+	DurationMs int64             `json:"durationMs"`
+	At         time.Time         `json:"at"`
+}
+
+// Incident records one outage of a monitor, opened when it goes down and
+// resolved when it recovers.
+type Incident struct {
+	ID          string            `json:"id"`
+	MonitorID   string            `json:"monitorId"`
+	MonitorName string            `json:"monitorName"`
+	Cause       string            `json:"cause"`
+	StartedAt   time.Time         `json:"startedAt"`
+	ResolvedAt  *time.Time        `json:"resolvedAt,omitempty"`
+	DurationSec int64             `json:"durationSeconds"`
+	AckedBy     string            `json:"ackedBy,omitempty"`
+	AckedAt     *time.Time        `json:"ackedAt,omitempty"`
+	Comments    []IncidentComment `json:"comments,omitempty"`
+}
+
+type IncidentComment struct {
+	Author string    `json:"author,omitempty"`
+	Text   string    `json:"text"`
+	At     time.Time `json:"at"`
+}
+
+// Open reports whether the incident has not been resolved yet.
+func (i Incident) Open() bool {
+	return i.ResolvedAt == nil
+}
+
+// Duration is how long the outage lasted, or has lasted so far at now.
+func (i Incident) Duration(now time.Time) time.Duration {
+	if i.ResolvedAt != nil {
+		now = *i.ResolvedAt
+	}
+	return now.Sub(i.StartedAt)
+}
+
+// RoutingPolicy groups notification channels with filters, quiet hours and
+// escalation rules so monitors can reference a single policy ID.
+type RoutingPolicy struct {
+	ID         string           `json:"id"`
+	Name       string           `json:"name"`
+	ChannelIDs []string         `json:"channelIds"`
+	EventTypes []string         `json:"eventTypes,omitempty"` // empty means all events
+	QuietHours *QuietHours      `json:"quietHours,omitempty"`
+	Escalation []EscalationStep `json:"escalation,omitempty"`
+	CreatedAt  time.Time        `json:"createdAt"`
+	UpdatedAt  time.Time        `json:"updatedAt"`
+}
+
+// QuietHours is a daily window ("HH:MM"-"HH:MM", may wrap midnight) during
+// which notifications are suppressed. Days optionally restricts the window to
+// the given weekdays ("mon".."sun"); a window that wraps midnight belongs to
+// the day it starts on.
+type QuietHours struct {
+	Start    string   `json:"start"`
+	End      string   `json:"end"`
+	Timezone string   `json:"timezone,omitempty"`
+	Days     []string `json:"days,omitempty"`
+}
+
+// Quiet-hours handling for notification channels.
+const (
+	QuietModeSuppress = "suppress" // drop non-critical alerts (default)
+	QuietModeQueue    = "queue"    // hold non-critical alerts until the window ends
+)
+
+// EscalationStep notifies extra channels once a monitor has been down for
+// AfterMinutes.
+type EscalationStep struct {
+	AfterMinutes int      `json:"afterMinutes"`
+	ChannelIDs   []string `json:"channelIds"`
+}
+
+func (p RoutingPolicy) Accepts(eventType string) bool {
+	if len(p.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range p.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Active reports whether t falls inside the quiet window.
+func (q QuietHours) Active(t time.Time) bool {
+	start, ok1 := parseClock(q.Start)
+	end, ok2 := parseClock(q.End)
+	if !ok1 || !ok2 || start == end {
+		return false
+	}
+	if q.Timezone != "" {
+		if loc, err := time.LoadLocation(q.Timezone); err == nil {
+			t = t.In(loc)
+		}
+	}
+	now := t.Hour()*60 + t.Minute()
+	if start < end {
+		return now >= start && now < end && q.onDay(t)
+	}
+	if now >= start {
+		return q.onDay(t)
+	}
+	return now < end && q.onDay(t.AddDate(0, 0, -1))
+}
+
+func (q QuietHours) onDay(t time.Time) bool {
+	if len(q.Days) == 0 {
+		return true
+	}
+	day := strings.ToLower(t.Weekday().String()[:3])
+	for _, d := range q.Days {
+		if strings.ToLower(strings.TrimSpace(d)) == day {
+			return true
+		}
+	}
+	return false
+}
+
+func parseClock(s string) (int, bool) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// MaintenanceWindow suspends checks and alerts for a set of monitors. It is
+// either one-off (StartAt/EndAt) or recurring: starting at every time
+// matched by Cron and lasting DurationMinutes.
+type MaintenanceWindow struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	MonitorIDs      []string   `json:"monitorIds"`
+	StartAt         *time.Time `json:"startAt,omitempty"`
+	EndAt           *time.Time `json:"endAt,omitempty"`
+	Cron            string     `json:"cron,omitempty"`
+	DurationMinutes int        `json:"durationMinutes,omitempty"`
+	Timezone        string     `json:"timezone,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
+// Validate checks that the window is either a valid one-off range or a
+// valid recurring schedule.
+func (w MaintenanceWindow) Validate() error {
+	if w.Cron == "" {
+		if w.StartAt == nil || w.EndAt == nil || !w.EndAt.After(*w.StartAt) {
+			return errors.New("one-off window needs startAt before endAt")
+		}
+		return nil
+	}
+	if _, err := cron.Parse(w.Cron); err != nil {
+		return err
+	}
+	if w.DurationMinutes <= 0 {
+		return errors.New("recurring window needs durationMinutes")
+	}
+	if w.Timezone != "" {
+		if _, err := time.LoadLocation(w.Timezone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Active reports whether t falls inside the window.
+func (w MaintenanceWindow) Active(t time.Time) bool {
+	if w.Cron == "" {
+		return w.StartAt != nil && w.EndAt != nil && !t.Before(*w.StartAt) && t.Before(*w.EndAt)
+	}
+	sched, err := cron.Parse(w.Cron)
+	if err != nil || w.DurationMinutes <= 0 {
+		return false
+	}
+	if w.Timezone != "" {
+		if loc, err := time.LoadLocation(w.Timezone); err == nil {
+			t = t.In(loc)
+		}
+	}
+	_, ok := sched.LastBefore(t, time.Duration(w.DurationMinutes)*time.Minute)
+	return ok
+}
+
+// StatusPage is a public, read-only view over a set of monitors. Access is
+// granted by a signed token minted from Secret and/or by client IP. Secret
+// is generated by the server and never returned by the API; see Redacted.
+type StatusPage struct {
+	ID           string    `json:"id"`
+	Slug         string    `json:"slug"`
+	Title        string    `json:"title"`
+	MonitorIDs   []string  `json:"monitorIds"`
+	Secret       string    `json:"secret,omitempty"`
+	RequireToken bool      `json:"requireToken"`
+	AllowedCIDRs []string  `json:"allowedCidrs,omitempty"`
+	CacheSeconds int       `json:"cacheSeconds,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// User is an account that can sign in to the web UI. PasswordHash is never
+// returned by the API; see Public.
+type User struct {
+	ID           string    `json:"id"`
+	Username     string    `json:"username"`
+	Role         Role      `json:"role"`
+	PasswordHash string    `json:"passwordHash,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// Role controls what a user may do. Viewers can only read; operators can
+// also change monitors and control containers; admins can delete resources
+// and manage users.
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"
+	RoleOperator Role = "operator"
+	RoleAdmin    Role = "admin"
+)
+
+// Valid reports whether r is a known role.
+func (r Role) Valid() bool {
+	return r == RoleViewer || r == RoleOperator || r == RoleAdmin
+}
+
+// Allows reports whether r grants at least the permissions of min.
+func (r Role) Allows(min Role) bool {
+	rank := map[Role]int{RoleViewer: 1, RoleOperator: 2, RoleAdmin: 3}
+	return rank[r] >= rank[min]
+}
+
+// EffectiveRole returns the user's role. Accounts created before roles were
+// introduced had full access and are treated as admins.
+func (u User) EffectiveRole() Role {
+	if u.Role == "" {
+		return RoleAdmin
+	}
+	return u.Role
+}
+
+// Public returns a copy of u that is safe to send to clients.
+func (u User) Public() User {
+	u.PasswordHash = ""
+	return u
+}
+
+// Session is a login session. Only the SHA-256 hash of the bearer token is
+// stored.
+type Session struct {
+	TokenHash string    `json:"tokenHash"`
+	UserID    string    `json:"userId"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// LatencyHistogram is a Prometheus-style latency histogram. Counts holds one
+// non-cumulative count per bucket plus a trailing +Inf bucket.
+type LatencyHistogram struct {
+	BucketsMs []int    `json:"bucketsMs"`
+	Counts    []uint64 `json:"counts"`
+	SumMs     int64    `json:"sumMs"`
+	Count     uint64   `json:"count"`
+}
+
+func NewLatencyHistogram(bucketsMs []int) LatencyHistogram {
+	b := make([]int, len(bucketsMs))
+	copy(b, bucketsMs)
+	return LatencyHistogram{BucketsMs: b, Counts: make([]uint64, len(b)+1)}
+}
+
+func (h *LatencyHistogram) Observe(ms int) {
+	i := 0
+	for i < len(h.BucketsMs) && ms > h.BucketsMs[i] {
+		i++
+	}
+	h.Counts[i]++
+	h.SumMs += int64(ms)
+	h.Count++
+}
+
+// SameBuckets reports whether h was built with the given bucket layout.
+func (h LatencyHistogram) SameBuckets(bucketsMs []int) bool {
+	if len(h.BucketsMs) != len(bucketsMs) || len(h.Counts) != len(bucketsMs)+1 {
+		return false
+	}
+	for i := range bucketsMs {
+		if h.BucketsMs[i] != bucketsMs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Secret is a named credential that monitor and notification settings
+// reference as {{secret "name"}}. Value is encrypted with the server's
+// secret key and is never returned by the API.
+type Secret struct {
 	Name      string    `json:"name"`
-	Type      string    `json:"type"` // webhook, dingtalk, wechat, discord
-	URL       string    `json:"url"`
+	Value     string    `json:"value"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
+
+// Settings are server options that can be changed at runtime from the UI.
+// Until they are first saved, their values come from the config file;
+// afterwards the saved values take precedence.
+type Settings struct {
+	// DefaultIntervalSeconds and DefaultTimeoutSeconds apply to monitors
+	// that leave their interval or timeout unset.
+	DefaultIntervalSeconds int `json:"defaultIntervalSeconds"`
+	DefaultTimeoutSeconds  int `json:"defaultTimeoutSeconds"`
+	// HistoryRetentionDays applies to monitors without their own
+	// retentionDays; zero keeps history forever.
+	HistoryRetentionDays int `json:"historyRetentionDays"`
+	// MaxDockerLogBytes caps the container logs attached to alerts and
+	// served by the API; zero uses 64 KiB.
+	MaxDockerLogBytes int `json:"maxDockerLogBytes"`
+	// HistoryLogBudgetBytes caps the compressed log attachments kept per
+	// monitor in the SQLite store; zero is unlimited.
+	HistoryLogBudgetBytes int `json:"historyLogBudgetBytes"`
+	// The notification defaults are given to new monitors that do not set
+	// notifyWebhookIds, notifyAfterFailures or resendEveryMinutes.
+	DefaultNotifyWebhookIDs    []string  `json:"defaultNotifyWebhookIds"`
+	DefaultNotifyAfterFailures int       `json:"defaultNotifyAfterFailures"`
+	DefaultResendEveryMinutes  int       `json:"defaultResendEveryMinutes"`
+	UpdatedAt                  time.Time `json:"updatedAt,omitzero"`
+}
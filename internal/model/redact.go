@@ -0,0 +1,242 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/lsy88/uptime-chopper/internal/redact"
+)
+
+// Redacted returns a copy of m that is safe to send to clients, with
+// passwords, tokens, private keys and credential headers masked.
+func (m Monitor) Redacted() Monitor {
+	if h := m.HTTP; h != nil {
+		c := *h
+		c.URL = redact.URL(c.URL)
+		c.Headers = redact.Headers(c.Headers)
+		c.ClientKeyPEM = redact.Value(c.ClientKeyPEM)
+		if a := c.Auth; a != nil {
+			ca := *a
+			ca.Password = redact.Value(ca.Password)
+			ca.Token = redact.Value(ca.Token)
+			c.Auth = &ca
+		}
+		m.HTTP = &c
+	}
+	if d := m.Database; d != nil {
+		c := *d
+		c.DSN = redact.DSN(c.DSN)
+		m.Database = &c
+	}
+	if ml := m.Mail; ml != nil {
+		c := *ml
+		c.Password = redact.Value(c.Password)
+		m.Mail = &c
+	}
+	if ex := m.Exec; ex != nil {
+		c := *ex
+		c.Env = redact.Env(c.Env)
+		m.Exec = &c
+	}
+	if ct := m.Container; ct != nil {
+		c := *ct
+		c.Remediation = c.Remediation.redacted()
+		m.Container = &c
+	}
+	if k := m.Kubernetes; k != nil {
+		c := *k
+		c.Remediation = c.Remediation.redacted()
+		m.Kubernetes = &c
+	}
+	if r := m.Remediation; r != nil {
+		c := r.redacted()
+		m.Remediation = &c
+	}
+	return m
+}
+
+// Unredact returns m with the masked values a client sent back replaced by
+// the ones stored in prev, so that saving a redacted monitor keeps them.
+// Credentials are only put back while the monitor keeps its type and
+// target, so a masked value cannot be redirected to another host; those
+// left masked are reported by MaskedFields.
+func (m Monitor) Unredact(prev Monitor) Monitor {
+	if m.Type != prev.Type {
+		return m
+	}
+	if h, p := m.HTTP, prev.HTTP; h != nil && p != nil {
+		h.URL = redact.Restore(h.URL, p.URL, redact.URL)
+		if redact.SameTarget(h.URL, p.URL) {
+			h.Headers = redact.RestoreHeaders(h.Headers, p.Headers)
+			h.ClientKeyPEM = redact.Restore(h.ClientKeyPEM, p.ClientKeyPEM, redact.Value)
+			if a, pa := h.Auth, p.Auth; a != nil && pa != nil {
+				a.Password = redact.Restore(a.Password, pa.Password, redact.Value)
+				a.Token = redact.Restore(a.Token, pa.Token, redact.Value)
+			}
+		}
+	}
+	if d, p := m.Database, prev.Database; d != nil && p != nil {
+		d.DSN = redact.Restore(d.DSN, p.DSN, redact.DSN)
+	}
+	if ml, p := m.Mail, prev.Mail; ml != nil && p != nil && ml.Protocol == p.Protocol && strings.EqualFold(ml.Host, p.Host) && ml.Port == p.Port {
+		ml.Password = redact.Restore(ml.Password, p.Password, redact.Value)
+	}
+	if ex, p := m.Exec, prev.Exec; ex != nil && p != nil && slices.Equal(ex.Command, p.Command) && ex.Script == p.Script {
+		ex.Env = redact.RestoreEnv(ex.Env, p.Env)
+	}
+	if ct, p := m.Container, prev.Container; ct != nil && p != nil {
+		ct.Remediation.unredact(p.Remediation)
+	}
+	if k, p := m.Kubernetes, prev.Kubernetes; k != nil && p != nil {
+		k.Remediation.unredact(p.Remediation)
+	}
+	if r, p := m.Remediation, prev.Remediation; r != nil && p != nil {
+		r.unredact(*p)
+	}
+	return m
+}
+
+// MaskedFields lists the credentials of m that still hold the mask, by
+// their JSON path. They have to be entered again rather than saved.
+func (m Monitor) MaskedFields() []string {
+	var out []string
+	add := func(field, v string) {
+		if redact.Masked(v) {
+			out = append(out, field)
+		}
+	}
+	if h := m.HTTP; h != nil {
+		add("http.url", h.URL)
+		for k, v := range h.Headers {
+			add("http.headers."+k, v)
+		}
+		add("http.clientKeyPem", h.ClientKeyPEM)
+		if a := h.Auth; a != nil {
+			add("http.auth.password", a.Password)
+			add("http.auth.token", a.Token)
+		}
+	}
+	if d := m.Database; d != nil {
+		add("database.dsn", d.DSN)
+	}
+	if ml := m.Mail; ml != nil {
+		add("mail.password", ml.Password)
+	}
+	if ex := m.Exec; ex != nil {
+		for i, kv := range ex.Env {
+			add(fmt.Sprintf("exec.env[%d]", i), kv)
+		}
+	}
+	if ct := m.Container; ct != nil {
+		out = append(out, ct.Remediation.maskedFields("container.remediation")...)
+	}
+	if k := m.Kubernetes; k != nil {
+		out = append(out, k.Remediation.maskedFields("kubernetes.remediation")...)
+	}
+	if r := m.Remediation; r != nil {
+		out = append(out, r.maskedFields("remediation")...)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (r RemediationPolicy) redacted() RemediationPolicy {
+	if w := r.Webhook; w != nil {
+		c := *w
+		c.URL = redact.URL(c.URL)
+		c.Headers = redact.Headers(c.Headers)
+		r.Webhook = &c
+	}
+	return r
+}
+
+func (r *RemediationPolicy) unredact(prev RemediationPolicy) {
+	if w, p := r.Webhook, prev.Webhook; w != nil && p != nil {
+		w.URL = redact.Restore(w.URL, p.URL, redact.URL)
+		if redact.SameTarget(w.URL, p.URL) {
+			w.Headers = redact.RestoreHeaders(w.Headers, p.Headers)
+		}
+	}
+}
+
+func (r RemediationPolicy) maskedFields(prefix string) []string {
+	w := r.Webhook
+	if w == nil {
+		return nil
+	}
+	var out []string
+	if redact.Masked(w.URL) {
+		out = append(out, prefix+".webhook.url")
+	}
+	for k, v := range w.Headers {
+		if redact.Masked(v) {
+			out = append(out, prefix+".webhook.headers."+k)
+		}
+	}
+	return out
+}
+
+// Redacted returns a copy of n that is safe to send to clients, with bot
+// tokens, signing secrets, credential headers and URL keys masked.
+func (n Notification) Redacted() Notification {
+	n.URL = redact.URL(n.URL)
+	n.BotToken = redact.Value(n.BotToken)
+	n.Secret = redact.Value(n.Secret)
+	n.UserKey = redact.Value(n.UserKey)
+	n.AppToken = redact.Value(n.AppToken)
+	n.BearerToken = redact.Value(n.BearerToken)
+	n.Headers = redact.Headers(n.Headers)
+	return n
+}
+
+// Unredact returns n with the masked values a client sent back replaced by
+// the ones stored in prev. As for monitors, credentials are only put back
+// while the channel keeps its type and target.
+func (n Notification) Unredact(prev Notification) Notification {
+	if n.Type != prev.Type {
+		return n
+	}
+	n.URL = redact.Restore(n.URL, prev.URL, redact.URL)
+	if !redact.SameTarget(n.URL, prev.URL) {
+		return n
+	}
+	n.BotToken = redact.Restore(n.BotToken, prev.BotToken, redact.Value)
+	n.Secret = redact.Restore(n.Secret, prev.Secret, redact.Value)
+	n.UserKey = redact.Restore(n.UserKey, prev.UserKey, redact.Value)
+	n.AppToken = redact.Restore(n.AppToken, prev.AppToken, redact.Value)
+	n.BearerToken = redact.Restore(n.BearerToken, prev.BearerToken, redact.Value)
+	n.Headers = redact.RestoreHeaders(n.Headers, prev.Headers)
+	return n
+}
+
+// MaskedFields lists the credentials of n that still hold the mask.
+func (n Notification) MaskedFields() []string {
+	var out []string
+	for field, v := range map[string]string{
+		"url":         n.URL,
+		"botToken":    n.BotToken,
+		"secret":      n.Secret,
+		"userKey":     n.UserKey,
+		"appToken":    n.AppToken,
+		"bearerToken": n.BearerToken,
+	} {
+		if redact.Masked(v) {
+			out = append(out, field)
+		}
+	}
+	for k, v := range n.Headers {
+		if redact.Masked(v) {
+			out = append(out, "headers."+k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Redacted returns p without the secret its feed tokens are signed with.
+func (p StatusPage) Redacted() StatusPage {
+	p.Secret = ""
+	return p
+}
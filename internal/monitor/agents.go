@@ -0,0 +1,177 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/notify"
+)
+
+// agentStaleAfter is the minimum time without results after which the
+// monitors of an agent are reported down.
+const agentStaleAfter = 2 * time.Minute
+
+var ErrNotAssigned = errors.New("monitor is not assigned to this agent")
+
+// AgentStatus describes a remote agent as last seen by the server.
+type AgentStatus struct {
+	Name     string    `json:"name"`
+	Hostname string    `json:"hostname,omitempty"`
+	Address  string    `json:"address,omitempty"`
+	LastSeen time.Time `json:"lastSeen,omitempty"`
+	Online   bool      `json:"online"`
+	Monitors int       `json:"monitors"`
+}
+
+type agentState struct {
+	hostname string
+	address  string
+	lastSeen time.Time
+}
+
+// schedule runs a due check: locally, or for monitors assigned to an agent,
+// by making sure the agent still reports.
+func (e *Engine) schedule(now time.Time, m model.Monitor) {
+	switch {
+	case m.Regions != nil:
+		e.checkRegions(now, m)
+	case m.Agent != "":
+		e.checkAgentMonitor(now, m)
+	default:
+		e.checkOnce(now, m)
+	}
+}
+
+// assignedTo reports whether agent runs the checks of m.
+func assignedTo(m model.Monitor, agent string) bool {
+	return m.Agent == agent || (m.Regions != nil && slices.Contains(m.Regions.Agents, agent))
+}
+
+// agentStaleAfterFor is how long the results of an agent for m stay valid.
+func agentStaleAfterFor(m model.Monitor) time.Duration {
+	return max(3*time.Duration(maxInt(5, m.IntervalSeconds))*time.Second, agentStaleAfter)
+}
+
+// checkAgentMonitor reports a monitor down when its agent has sent no
+// result for three intervals, and at least agentStaleAfter. The first call
+// only starts the clock, so a server restart gives agents time to report.
+func (e *Engine) checkAgentMonitor(now time.Time, m model.Monitor) {
+	e.mu.Lock()
+	last, ok := e.agentReports[m.ID]
+	if !ok {
+		e.agentReports[m.ID] = now
+	}
+	e.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	if now.Sub(last) < agentStaleAfterFor(m) {
+		return
+	}
+	e.handleResult(now, m, model.CheckResult{
+		MonitorID: m.ID,
+		Status:    model.StatusDown,
+		CheckedAt: now,
+		Message:   fmt.Sprintf("agent %s has not reported since %s", m.Agent, last.UTC().Format(time.RFC3339)),
+	}, nil)
+}
+
+// AgentSeen records that an agent contacted the server.
+func (e *Engine) AgentSeen(name, hostname, address string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	a := e.agents[name]
+	if hostname != "" {
+		a.hostname = hostname
+	}
+	a.address = address
+	a.lastSeen = time.Now().UTC()
+	e.agents[name] = a
+}
+
+// AgentMonitors returns the monitors assigned to an agent.
+func (e *Engine) AgentMonitors(name string) []model.Monitor {
+	out := []model.Monitor{}
+	for _, m := range e.deps.Store.GetState().Monitors {
+		if assignedTo(m, name) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// AgentStatuses describes the named agents, in order of name.
+func (e *Engine) AgentStatuses(names []string) []AgentStatus {
+	counts := map[string]int{}
+	for _, m := range e.deps.Store.GetState().Monitors {
+		for _, name := range names {
+			if assignedTo(m, name) {
+				counts[name]++
+			}
+		}
+	}
+	now := time.Now()
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	out := make([]AgentStatus, 0, len(names))
+	for _, name := range names {
+		a := e.agents[name]
+		out = append(out, AgentStatus{
+			Name:     name,
+			Hostname: a.hostname,
+			Address:  a.address,
+			LastSeen: a.lastSeen,
+			Online:   !a.lastSeen.IsZero() && now.Sub(a.lastSeen) < agentStaleAfter,
+			Monitors: counts[name],
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// IngestAgentResult processes a check result reported by an agent as if
+// the check had run here.
+func (e *Engine) IngestAgentResult(agent string, r model.AgentResult) error {
+	m := e.findMonitor(r.Result.MonitorID)
+	if m == nil || !assignedTo(*m, agent) {
+		return ErrNotAssigned
+	}
+	now := time.Now()
+	res := r.Result
+	if res.CheckedAt.IsZero() || res.CheckedAt.After(now) {
+		res.CheckedAt = now.UTC()
+	}
+	if m.Regions != nil {
+		// Combined with the other regions on the server's schedule.
+		e.recordRegion(m.ID, agent, now, res)
+		return nil
+	}
+
+	e.mu.Lock()
+	e.agentReports[m.ID] = now
+	e.mu.Unlock()
+	if m.IsPaused || e.maintenanceMonitors(now)[m.ID] {
+		return nil
+	}
+	var logs *notify.DockerLogsAttachment
+	if r.Logs != "" {
+		logs = &notify.DockerLogsAttachment{Content: r.Logs, Truncated: r.LogsTruncated}
+		if m.Container != nil {
+			logs.ContainerID = m.Container.ContainerID
+		}
+	}
+	e.deps.Logger.Debug("agent result",
+		zap.String("agent", agent),
+		zap.String("monitor_id", m.ID),
+		zap.String("status", string(res.Status)),
+	)
+	e.handleResult(now, *m, res, logs)
+	return nil
+}
@@ -0,0 +1,75 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/notify"
+)
+
+// alertState tracks an ongoing outage for the per-monitor notification rules.
+type alertState struct {
+	downChecks int
+	alerted    bool // a down alert went out for this outage
+	lastSent   time.Time
+	startedAt  time.Time
+	prev       model.MonitorStatus // status before the outage began
+	prevAt     time.Time
+}
+
+// applyAlertRules decides which notification, if any, a check result
+// triggers. Down alerts wait for NotifyAfterFailures consecutive failures and
+// repeat every ResendEveryMinutes until acknowledged; recoveries are only
+// announced for outages that were alerted (or, with NotifyRecoveryOnly, would
+// have been).
+func (e *Engine) applyAlertRules(ctx context.Context, m model.Monitor, res model.CheckResult, logs *notify.DockerLogsAttachment, prev model.MonitorStatus, prevAt time.Time) {
+	changed := prev != res.Status
+	threshold := maxInt(1, m.NotifyAfterFailures)
+	// Read from the store before taking e.mu, which every check needs.
+	acked := res.Status == model.StatusDown && m.ResendEveryMinutes > 0 && e.outageAcknowledged(m.ID)
+
+	e.mu.Lock()
+	st := e.alerts[m.ID]
+	if res.Status != model.StatusDown {
+		delete(e.alerts, m.ID)
+		e.mu.Unlock()
+		if !changed {
+			return
+		}
+		if prev == model.StatusDown && st != nil && !st.alerted && !(m.NotifyRecoveryOnly && st.downChecks >= threshold) {
+			// The outage never reached the alert threshold.
+			return
+		}
+		e.emitNotification(ctx, m, res, logs, prev, prevAt)
+		return
+	}
+
+	if changed || st == nil {
+		st = &alertState{startedAt: res.CheckedAt, prev: prev, prevAt: prevAt}
+		e.alerts[m.ID] = st
+	}
+	st.downChecks++
+
+	var payload *notify.Payload
+	switch {
+	case m.NotifyRecoveryOnly:
+	case !st.alerted && st.downChecks >= threshold:
+		p := statusPayload(m, res, logs, st.prev, st.prevAt)
+		payload = &p
+	case st.alerted && m.ResendEveryMinutes > 0 &&
+		res.CheckedAt.Sub(st.lastSent) >= time.Duration(m.ResendEveryMinutes)*time.Minute && !acked:
+		p := statusPayload(m, res, logs, model.StatusDown, st.startedAt)
+		p.Data["reminder"] = true
+		payload = &p
+	}
+	if payload != nil {
+		st.alerted = true
+		st.lastSent = res.CheckedAt
+	}
+	e.mu.Unlock()
+
+	if payload != nil {
+		e.emitWebhookBestEffort(ctx, m, *payload)
+	}
+}
@@ -0,0 +1,96 @@
+package monitor
+
+import (
+	"context"
+
+	"github.com/lsy88/uptime-chopper/internal/store"
+)
+
+// inflightCheck is a running check that can be cancelled when its monitor
+// is deleted.
+type inflightCheck struct {
+	cancel context.CancelFunc
+}
+
+// changesLoop applies monitor changes as the store reports them: changed
+// monitors are woken so edits, pauses and resumes take effect without
+// waiting for the next slot, and deleted monitors have their running check
+// cancelled and their state dropped.
+func (e *Engine) changesLoop(changes <-chan store.MonitorChange) {
+	e.wg.Add(1)
+	defer e.wg.Done()
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case c := <-changes:
+			if c.Deleted {
+				e.forget(c.ID)
+			} else {
+				e.mu.Lock()
+				delete(e.removed, c.ID)
+				e.mu.Unlock()
+			}
+			select {
+			case e.wake <- c.ID:
+			default:
+				// The scheduler is busy; the regular interval will catch up.
+			}
+		}
+	}
+}
+
+// trackCheck registers the cancel function of a running check of a monitor
+// and returns the function that unregisters it.
+func (e *Engine) trackCheck(id string, cancel context.CancelFunc) func() {
+	c := &inflightCheck{cancel: cancel}
+	e.mu.Lock()
+	e.inflight[id] = c
+	e.mu.Unlock()
+	return func() {
+		e.mu.Lock()
+		if e.inflight[id] == c {
+			delete(e.inflight, id)
+		}
+		e.mu.Unlock()
+	}
+}
+
+// forget cancels the running check of a deleted monitor and clears its
+// state, so late results are dropped and its status disappears.
+func (e *Engine) forget(id string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if c := e.inflight[id]; c != nil {
+		c.cancel()
+		delete(e.inflight, id)
+	}
+	if c, ok := e.clients[id]; ok {
+		c.client.CloseIdleConnections()
+		delete(e.clients, id)
+	}
+	e.removed[id] = true
+	delete(e.lastStatus, id)
+	delete(e.lastCheck, id)
+	delete(e.remediateAt, id)
+	delete(e.attempts, id)
+	delete(e.histograms, id)
+	delete(e.downSince, id)
+	delete(e.escalated, id)
+	delete(e.transition, id)
+	delete(e.pending, id)
+	delete(e.confirming, id)
+	delete(e.alerts, id)
+	delete(e.breachSince, id)
+	delete(e.restarts, id)
+	delete(e.heartbeats, id)
+	delete(e.agentReports, id)
+	delete(e.regions, id)
+}
+
+// isRemoved reports whether the monitor was deleted while the engine ran.
+func (e *Engine) isRemoved(id string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.removed[id]
+}
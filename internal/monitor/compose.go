@@ -0,0 +1,77 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/docker"
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+// checkCompose reports a compose project up when it has at least one
+// container and all of them are running and not unhealthy.
+func (e *Engine) checkCompose(ctx context.Context, now time.Time, m model.Monitor) model.CheckResult {
+	if m.Compose == nil || m.Compose.Project == "" {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: "missing compose project"}
+	}
+	host := m.Compose.HostID
+	dc, err := e.deps.Docker.Get(host)
+	if err != nil {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: err.Error()}
+	}
+	cs, err := dc.ProjectContainers(ctx, m.Compose.Project)
+	if err != nil {
+		if errors.Is(err, docker.ErrDockerUnavailable) {
+			e.setDockerReachable(host, false, now)
+			return model.CheckResult{MonitorID: m.ID, Status: model.StatusDockerUnreachable, CheckedAt: now, Message: err.Error()}
+		}
+		e.setDockerReachable(host, true, now)
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: err.Error()}
+	}
+	e.setDockerReachable(host, true, now)
+	return composeResult(now, m, cs)
+}
+
+// composeResult summarises the containers of m's project into a result.
+func composeResult(now time.Time, m model.Monitor, cs []docker.ContainerSummary) model.CheckResult {
+	if len(cs) == 0 {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: "no containers in project " + m.Compose.Project}
+	}
+
+	var stopped, unhealthy []string
+	for _, c := range cs {
+		switch {
+		case c.State != "running":
+			stopped = append(stopped, c.Name)
+		case c.Unhealthy():
+			unhealthy = append(unhealthy, c.Name)
+		}
+	}
+	msg := fmt.Sprintf("%d/%d running", len(cs)-len(stopped), len(cs))
+	if len(stopped) > 0 {
+		msg += "; not running: " + strings.Join(stopped, ", ")
+	}
+	if len(unhealthy) > 0 {
+		msg += "; unhealthy: " + strings.Join(unhealthy, ", ")
+	}
+	status := model.StatusUp
+	if len(stopped) > 0 || len(unhealthy) > 0 {
+		status = model.StatusDown
+	}
+	return model.CheckResult{MonitorID: m.ID, Status: status, CheckedAt: now, Message: msg}
+}
+
+// dockerHostOf returns the docker host a monitor depends on, and false for
+// monitors that do not use Docker.
+func dockerHostOf(m model.Monitor) (string, bool) {
+	switch {
+	case m.Type == model.MonitorTypeContainer && m.Container != nil:
+		return m.Container.HostID, true
+	case m.Type == model.MonitorTypeCompose && m.Compose != nil:
+		return m.Compose.HostID, true
+	}
+	return "", false
+}
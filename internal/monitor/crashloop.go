@@ -0,0 +1,84 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/docker"
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/notify"
+)
+
+// restartTracker remembers when a container was restarted, derived from
+// increases of its RestartCount between checks. Docker events wake the
+// monitor on every restart, so the timestamps are close to the real ones.
+type restartTracker struct {
+	count int // RestartCount at the previous check
+	times []time.Time
+}
+
+// crashLoop records the restarts since the previous check and reports how
+// many fall within the policy window and whether that makes a crash loop.
+func (e *Engine) crashLoop(m model.Monitor, state docker.StateInfo, now time.Time) (int, time.Duration, bool) {
+	p := m.Container.CrashLoop
+	if p == nil {
+		return 0, 0, false
+	}
+	limit := p.Restarts
+	if limit <= 0 {
+		limit = 3
+	}
+	window := time.Duration(p.WindowMinutes) * time.Minute
+	if window <= 0 {
+		window = 5 * time.Minute
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	t := e.restarts[m.ID]
+	if t == nil {
+		// The first check only establishes the baseline.
+		e.restarts[m.ID] = &restartTracker{count: state.RestartCount}
+		return 0, window, false
+	}
+	if state.RestartCount < t.count {
+		// The container was recreated and its counter started over.
+		t.count = 0
+	}
+	for i := t.count; i < state.RestartCount; i++ {
+		t.times = append(t.times, now)
+	}
+	t.count = state.RestartCount
+
+	cutoff := now.Add(-window)
+	kept := t.times[:0]
+	for _, at := range t.times {
+		if at.After(cutoff) {
+			kept = append(kept, at)
+		}
+	}
+	t.times = kept
+	return len(kept), window, len(kept) >= limit
+}
+
+// crashLoopLogs collects the container's logs when it enters a crash loop,
+// whether or not the monitor attaches logs to its other alerts.
+func (e *Engine) crashLoopLogs(ctx context.Context, m model.Monitor, now time.Time) *notify.DockerLogsAttachment {
+	if e.getLastStatus(m.ID) == model.StatusCrashLoop {
+		return nil
+	}
+	m.Logs.Include = true
+	return e.tryAttachLogs(ctx, m, now)
+}
+
+// emitCrashLoop sends the single crash_loop alert that replaces the up/down
+// notifications while a container keeps restarting.
+func (e *Engine) emitCrashLoop(ctx context.Context, m model.Monitor, res model.CheckResult, logs *notify.DockerLogsAttachment, prev model.MonitorStatus, prevAt time.Time) {
+	e.mu.Lock()
+	delete(e.alerts, m.ID)
+	e.mu.Unlock()
+
+	payload := statusPayload(m, res, logs, prev, prevAt)
+	payload.Type = string(model.EventCrashLoop)
+	e.emitWebhookBestEffort(ctx, m, payload)
+}
@@ -0,0 +1,125 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/docker"
+)
+
+// daemonPingTimeout bounds a single ping of a docker daemon.
+const daemonPingTimeout = 10 * time.Second
+
+// DaemonStatus is the availability of a docker daemon as tracked by the
+// periodic ping. Checks, Failures and Uptime count the pings since the
+// server started; outages are recorded as docker_unreachable and
+// docker_recovered events.
+type DaemonStatus struct {
+	ID        string    `json:"id"`
+	Host      string    `json:"host"`
+	Up        bool      `json:"up"`
+	Since     time.Time `json:"since,omitzero"`
+	LastPing  time.Time `json:"lastPing,omitzero"`
+	LatencyMs int       `json:"latencyMs"`
+	Message   string    `json:"message,omitempty"`
+	Checks    int       `json:"checks"`
+	Failures  int       `json:"failures"`
+	Uptime    float64   `json:"uptime"` // percent of successful pings
+}
+
+type daemonState struct {
+	since     time.Time
+	lastPing  time.Time
+	latencyMs int
+	message   string
+	checks    int
+	failures  int
+}
+
+// daemonLoop pings one docker daemon every DockerPingInterval, so that an
+// outage is noticed and reported even when no container monitor polls it.
+func (e *Engine) daemonLoop(host string, dc *docker.Client) {
+	e.wg.Add(1)
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.deps.DockerPingInterval)
+	defer ticker.Stop()
+
+	e.pingDaemon(host, dc)
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+			e.pingDaemon(host, dc)
+		}
+	}
+}
+
+func (e *Engine) pingDaemon(host string, dc *docker.Client) {
+	ctx, cancel := context.WithTimeout(e.ctx, min(e.deps.DockerPingInterval, daemonPingTimeout))
+	defer cancel()
+
+	start := time.Now()
+	err := dc.Ping(ctx)
+	if e.ctx.Err() != nil {
+		return
+	}
+	now := time.Now().UTC()
+
+	e.mu.Lock()
+	st := e.daemon(host)
+	st.checks++
+	st.lastPing = now
+	st.latencyMs = int(time.Since(start).Milliseconds())
+	st.message = ""
+	if err != nil {
+		st.failures++
+		st.message = err.Error()
+	}
+	if st.since.IsZero() {
+		st.since = now
+	}
+	e.mu.Unlock()
+
+	e.setDockerReachable(host, err == nil, now)
+}
+
+// daemon returns the state of a docker host, creating it; e.mu must be
+// held.
+func (e *Engine) daemon(host string) *daemonState {
+	st, ok := e.daemons[host]
+	if !ok {
+		st = &daemonState{}
+		e.daemons[host] = st
+	}
+	return st
+}
+
+// DaemonStatuses describes every configured docker daemon, the local one
+// first.
+func (e *Engine) DaemonStatuses() []DaemonStatus {
+	if e.deps.Docker == nil {
+		return []DaemonStatus{}
+	}
+	hosts := e.deps.Docker.List()
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	out := make([]DaemonStatus, 0, len(hosts))
+	for _, h := range hosts {
+		ds := DaemonStatus{ID: h.ID, Host: h.Host, Up: !e.dockerDown[h.ID]}
+		if st, ok := e.daemons[h.ID]; ok {
+			ds.Since = st.since
+			ds.LastPing = st.lastPing
+			ds.LatencyMs = st.latencyMs
+			ds.Message = st.message
+			ds.Checks = st.checks
+			ds.Failures = st.failures
+			if st.checks > 0 {
+				ds.Uptime = float64(st.checks-st.failures) * 100 / float64(st.checks)
+			}
+		}
+		out = append(out, ds)
+	}
+	return out
+}
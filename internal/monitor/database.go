@@ -0,0 +1,33 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/dbprobe"
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/netdial"
+)
+
+// checkDatabase logs in to the database server of a mysql, postgres, redis
+// or mongodb monitor.
+func checkDatabase(ctx context.Context, now time.Time, m model.Monitor) model.CheckResult {
+	if m.Database == nil || m.Database.DSN == "" {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: "missing dsn"}
+	}
+	d, err := netdial.New(string(m.IPVersion), m.DNSServer)
+	if err != nil {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: err.Error()}
+	}
+	start := time.Now()
+	info, err := dbprobe.Probe(ctx, d, dbprobe.Kind(m.Type), m.Database.DSN, m.Database.Query)
+	lat := int(time.Since(start).Milliseconds())
+	if err != nil {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, LatencyMs: lat, Message: err.Error()}
+	}
+	msg := "connected"
+	if info.Version != "" {
+		msg = "connected to " + info.Version
+	}
+	return model.CheckResult{MonitorID: m.ID, Status: model.StatusUp, CheckedAt: now, LatencyMs: lat, Message: msg}
+}
@@ -0,0 +1,30 @@
+package monitor
+
+import (
+	"fmt"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+// applyLatencyThresholds turns a successful but slow result into degraded,
+// or down past the critical threshold.
+func applyLatencyThresholds(m model.Monitor, res model.CheckResult) model.CheckResult {
+	if res.Status != model.StatusUp {
+		return res
+	}
+	level, limit := "", 0
+	switch {
+	case m.LatencyCriticalMs > 0 && res.LatencyMs >= m.LatencyCriticalMs:
+		res.Status, level, limit = model.StatusDown, "critical", m.LatencyCriticalMs
+	case m.LatencyWarnMs > 0 && res.LatencyMs >= m.LatencyWarnMs:
+		res.Status, level, limit = model.StatusDegraded, "warning", m.LatencyWarnMs
+	default:
+		return res
+	}
+	msg := fmt.Sprintf("latency %dms exceeds %s threshold %dms", res.LatencyMs, level, limit)
+	if res.Message != "" {
+		msg += " (" + res.Message + ")"
+	}
+	res.Message = msg
+	return res
+}
@@ -0,0 +1,131 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/config"
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/notify"
+)
+
+// digestBatch collects the status changes bound for one channel during the
+// coalescing window.
+type digestBatch struct {
+	webhook  config.NotificationWebhook
+	payloads []notify.Payload
+	flushAt  time.Time
+}
+
+// deliverOrDigest sends payload right away, or holds status changes for the
+// digest window so a burst of them reaches the channel as one notification.
+func (e *Engine) deliverOrDigest(ctx context.Context, notificationID string, w config.NotificationWebhook, payload notify.Payload) {
+	if e.deps.DigestWindow <= 0 || payload.Type != string(model.EventStatusChanged) {
+		e.deliver(ctx, notificationID, w, payload)
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	b := e.digests[notificationID]
+	if b == nil {
+		b = &digestBatch{flushAt: time.Now().Add(e.deps.DigestWindow)}
+		e.digests[notificationID] = b
+	}
+	b.webhook = w
+	b.payloads = append(b.payloads, payload)
+}
+
+func (e *Engine) digestLoop() {
+	e.wg.Add(1)
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			// Don't lose alerts still waiting for their window on shutdown.
+			e.flushDigests(context.Background(), time.Time{})
+			return
+		case now := <-ticker.C:
+			e.flushDigests(e.ctx, now)
+		}
+	}
+}
+
+// flushDigests delivers every batch whose window has closed by now; a zero
+// now flushes all batches.
+func (e *Engine) flushDigests(parent context.Context, now time.Time) {
+	due := map[string]*digestBatch{}
+	e.mu.Lock()
+	for id, b := range e.digests {
+		if now.IsZero() || !now.Before(b.flushAt) {
+			due[id] = b
+			delete(e.digests, id)
+		}
+	}
+	e.mu.Unlock()
+
+	for id, b := range due {
+		payload := b.payloads[0]
+		if len(b.payloads) > 1 {
+			payload = digestPayload(b.payloads)
+		}
+		ctx, cancel := context.WithTimeout(parent, 15*time.Second)
+		e.deliver(ctx, id, b.webhook, payload)
+		cancel()
+	}
+}
+
+// digestPayload merges several status changes into one EventDigest payload.
+// "current" is only set when every monitor ended up in the same state, so
+// providers still pick the matching colour and priority. Providers take a
+// single mention, so the first one in the batch is kept.
+func digestPayload(payloads []notify.Payload) notify.Payload {
+	names := make([]string, 0, len(payloads))
+	events := make([]string, 0, len(payloads))
+	current, same := "", true
+	mention := ""
+	for i, p := range payloads {
+		name, _ := p.Data["monitorName"].(string)
+		status, _ := p.Data["current"].(string)
+		msg, _ := p.Data["message"].(string)
+		if i == 0 {
+			current = status
+		} else if status != current {
+			same = false
+		}
+		if m, ok := p.Data["mention"].(string); ok && mention == "" {
+			mention = m
+		}
+
+		names = append(names, name)
+		line := name + ": " + status
+		if msg != "" {
+			line += " · " + msg
+		}
+		events = append(events, line)
+	}
+
+	data := map[string]any{
+		"monitorName": strings.Join(names, ", "),
+		"message":     fmt.Sprintf("%d monitors changed status", len(payloads)),
+		"monitors":    names,
+		"events":      events,
+	}
+	if same && current != "" {
+		data["current"] = current
+	}
+	if mention != "" {
+		data["mention"] = mention
+	}
+	return notify.Payload{
+		Type: string(model.EventDigest),
+		At:   payloads[len(payloads)-1].At,
+		Data: data,
+	}
+}
@@ -4,9 +4,17 @@ import (
 	"bytes"
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptrace"
+	"regexp"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/docker/docker/api/types/container"
@@ -15,19 +23,79 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/lsy88/uptime-chopper/internal/config"
+	"github.com/lsy88/uptime-chopper/internal/dbprobe"
 	"github.com/lsy88/uptime-chopper/internal/docker"
+	"github.com/lsy88/uptime-chopper/internal/jsonpath"
+	"github.com/lsy88/uptime-chopper/internal/kube"
+	"github.com/lsy88/uptime-chopper/internal/lan"
 	"github.com/lsy88/uptime-chopper/internal/model"
 	"github.com/lsy88/uptime-chopper/internal/notify"
+	"github.com/lsy88/uptime-chopper/internal/redact"
+	"github.com/lsy88/uptime-chopper/internal/secrets"
 	"github.com/lsy88/uptime-chopper/internal/store"
 )
 
 type EngineDeps struct {
 	Logger       *zap.Logger
 	Store        store.Store
-	Docker       *docker.Client
+	Docker       *docker.Hosts
+	Kube         *kube.Client // nil when kubernetes is not configured
 	Notifier     *notify.Dispatcher
-	MaxLogBytes  int
 	DefaultSince time.Duration
+
+	// Secrets resolves {{secret "name"}} references in monitor and
+	// notification settings; nil only reads the environment.
+	Secrets *secrets.Resolver
+
+	// Settings are the initial runtime settings; ApplySettings replaces
+	// them while the engine runs.
+	Settings model.Settings
+
+	LatencyBucketsMs  []int
+	PersistHistograms bool
+
+	// DigestWindow groups status changes per channel; zero sends each alert
+	// immediately.
+	DigestWindow time.Duration
+
+	// ImageUpdateInterval enables the image update checker; zero disables it.
+	ImageUpdateInterval time.Duration
+
+	// AllowHostCommands lets exec monitors and script remediation run;
+	// without it exec monitors report down and scripts fail.
+	AllowHostCommands bool
+
+	// DockerPingInterval is how often every docker daemon is pinged to
+	// track its availability; zero disables the ping.
+	DockerPingInterval time.Duration
+
+	// DrainTimeout bounds how long Stop waits for running checks; default 10s.
+	DrainTimeout time.Duration
+
+	// PruneInterval is how often history is pruned; default 1h.
+	PruneInterval time.Duration
+	// VacuumInterval is the minimum time between compactions of the store,
+	// which only run after a prune removed history; default 24h.
+	VacuumInterval time.Duration
+
+	// HistoryFlushInterval batches history writes: entries are buffered and
+	// written together at this interval, or as soon as HistoryBatchSize
+	// (default 500) are waiting. Zero writes every entry immediately.
+	HistoryFlushInterval time.Duration
+	HistoryBatchSize     int
+
+	// CheckJitter delays each scheduled check by a random amount up to this
+	// value, capped at a quarter of the monitor's interval.
+	CheckJitter time.Duration
+
+	// OnResult, when set, receives every final check result. Agents use it
+	// to report results to their server.
+	OnResult func(m model.Monitor, res model.CheckResult, logs *notify.DockerLogsAttachment)
+
+	// Checker, when set, replaces the built-in checkers for every monitor
+	// type. It is used by the simulation mode to drive the scheduler with
+	// stub checks.
+	Checker func(ctx context.Context, now time.Time, m model.Monitor) model.CheckResult
 }
 
 type Engine struct {
@@ -38,35 +106,185 @@ type Engine struct {
 	lastCheck   map[string]time.Time
 	remediateAt map[string]time.Time
 	attempts    map[string]int
+	histograms  map[string]*model.LatencyHistogram
+	dockerDown  map[string]bool // by docker host ID
+	daemons     map[string]*daemonState
+	downSince   map[string]time.Time
+	escalated   map[string]int
+	transition  map[string]time.Time
+	pending     map[string]*model.MonitorHistoryEntry
+	confirming  map[string]*downRetry
+	// history entries waiting for the next batch write
+	historyBuf  []store.HistoryRecord
+	historyFull chan struct{}
+	alerts      map[string]*alertState
+	quietQueue  map[string][]notify.Payload
+	digests     map[string]*digestBatch
+	stats       map[string]map[string]containerStats // by host, then container ID
+	breachSince map[string]time.Time
+	updates     map[string]map[string]docker.ImageUpdate // by host, then container ID
+	restarts    map[string]*restartTracker
+	heartbeats  map[string]heartbeat
+	agents      map[string]agentState
+	// last result reported by an agent, by monitor ID
+	agentReports map[string]time.Time
+	// latest result of each region, by monitor ID, then region
+	regions  map[string]map[string]regionReport
+	wake     chan string // monitor IDs to check immediately
+	clients  map[string]cachedClient
+	inflight map[string]*inflightCheck
+	removed  map[string]bool // monitors deleted since start
+	sched    SchedulerStats
+	settings model.Settings
+	// notifier sends to the webhooks of the config file; SetNotifier
+	// replaces it when the config is reloaded.
+	notifier atomic.Pointer[notify.Dispatcher]
+
+	// incidentMu serializes read-modify-writes of stored incidents.
+	incidentMu sync.Mutex
 
 	ctx    context.Context
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
+
+	// stopping is closed when Stop begins; checks counts the goroutines
+	// that run checks and are drained before ctx is cancelled.
+	stopping chan struct{}
+	checks   sync.WaitGroup
 }
 
 func NewEngine(deps EngineDeps) *Engine {
 	ctx, cancel := context.WithCancel(context.Background())
-	return &Engine{
-		deps:        deps,
-		lastStatus:  map[string]model.MonitorStatus{},
-		lastCheck:   map[string]time.Time{},
-		remediateAt: map[string]time.Time{},
-		attempts:    map[string]int{},
-		ctx:         ctx,
-		cancel:      cancel,
+	e := &Engine{
+		deps:         deps,
+		lastStatus:   map[string]model.MonitorStatus{},
+		lastCheck:    map[string]time.Time{},
+		remediateAt:  map[string]time.Time{},
+		attempts:     map[string]int{},
+		histograms:   map[string]*model.LatencyHistogram{},
+		dockerDown:   map[string]bool{},
+		daemons:      map[string]*daemonState{},
+		downSince:    map[string]time.Time{},
+		escalated:    map[string]int{},
+		transition:   map[string]time.Time{},
+		pending:      map[string]*model.MonitorHistoryEntry{},
+		confirming:   map[string]*downRetry{},
+		historyFull:  make(chan struct{}, 1),
+		alerts:       map[string]*alertState{},
+		quietQueue:   map[string][]notify.Payload{},
+		digests:      map[string]*digestBatch{},
+		stats:        map[string]map[string]containerStats{},
+		breachSince:  map[string]time.Time{},
+		restarts:     map[string]*restartTracker{},
+		updates:      map[string]map[string]docker.ImageUpdate{},
+		heartbeats:   map[string]heartbeat{},
+		agents:       map[string]agentState{},
+		agentReports: map[string]time.Time{},
+		regions:      map[string]map[string]regionReport{},
+		wake:         make(chan string, 64),
+		clients:      map[string]cachedClient{},
+		inflight:     map[string]*inflightCheck{},
+		removed:      map[string]bool{},
+		ctx:          ctx,
+		cancel:       cancel,
+		stopping:     make(chan struct{}),
 	}
+	e.notifier.Store(deps.Notifier)
+	e.ApplySettings(deps.Settings)
+	return e
 }
 
 func (e *Engine) Start() {
 	e.deps.Logger.Info("starting monitor engine")
+	if e.deps.PersistHistograms {
+		e.loadHistograms()
+		go e.histogramFlushLoop()
+	}
+	e.checks.Add(1)
 	go e.loop()
+	go e.changesLoop(e.deps.Store.MonitorChanges())
 	go e.pruneLoop()
+	if e.deps.HistoryFlushInterval > 0 {
+		go e.historyFlushLoop()
+	}
+	go e.quietQueueLoop()
+	if e.deps.DigestWindow > 0 {
+		go e.digestLoop()
+	}
+	if e.deps.Docker != nil {
+		go e.statsLoop()
+		if e.deps.ImageUpdateInterval > 0 {
+			go e.imageUpdateLoop()
+		}
+		for _, h := range e.deps.Docker.List() {
+			if c, err := e.deps.Docker.Get(h.ID); err == nil {
+				go e.eventsLoop(h.ID, c)
+				if e.deps.DockerPingInterval > 0 {
+					go e.daemonLoop(h.ID, c)
+				}
+			}
+		}
+	}
 }
 
+// Stop stops scheduling checks and waits up to DrainTimeout for the running
+// check to finish and record its result before cancelling the rest of the
+// engine. Sampled and buffered history that has not been written yet,
+// pending digests and histograms are flushed to the store.
 func (e *Engine) Stop() {
 	e.deps.Logger.Info("stopping monitor engine")
+	close(e.stopping)
+	drained := make(chan struct{})
+	go func() {
+		e.checks.Wait()
+		close(drained)
+	}()
+	timeout := e.deps.DrainTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	select {
+	case <-drained:
+	case <-time.After(timeout):
+		e.deps.Logger.Warn("in-flight checks did not finish before the drain timeout", zap.Duration("timeout", timeout))
+	}
 	e.cancel()
 	e.wg.Wait()
+	e.flushPendingHistory()
+	e.flushHistory()
+	if e.deps.PersistHistograms {
+		e.flushHistograms()
+	}
+}
+
+// SchedulerStats summarises how well the scheduler keeps up with the
+// configured intervals. Drift is how late a check started compared to its
+// planned run time.
+type SchedulerStats struct {
+	Checks        int64         `json:"checks"`
+	TotalDrift    time.Duration `json:"totalDriftNs"`
+	MaxDrift      time.Duration `json:"maxDriftNs"`
+	TotalDuration time.Duration `json:"totalDurationNs"`
+}
+
+func (e *Engine) recordSchedule(drift, took time.Duration) {
+	if drift < 0 {
+		drift = 0
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sched.Checks++
+	e.sched.TotalDrift += drift
+	e.sched.TotalDuration += took
+	if drift > e.sched.MaxDrift {
+		e.sched.MaxDrift = drift
+	}
+}
+
+func (e *Engine) SchedulerStats() SchedulerStats {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.sched
 }
 
 func (e *Engine) StatusSnapshot() map[string]model.MonitorStatusInfo {
@@ -86,36 +304,74 @@ func (e *Engine) pruneLoop() {
 	e.wg.Add(1)
 	defer e.wg.Done()
 
-	ticker := time.NewTicker(1 * time.Hour)
+	interval := e.deps.PruneInterval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	vacuumEvery := e.deps.VacuumInterval
+	if vacuumEvery <= 0 {
+		vacuumEvery = 24 * time.Hour
+	}
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	// Initial prune
-	e.pruneAll()
-
+	var lastVacuum time.Time
 	for {
+		// Deleted rows leave free pages behind; compact once enough time has
+		// passed since the last vacuum.
+		if e.pruneAll() > 0 && time.Since(lastVacuum) >= vacuumEvery {
+			start := time.Now()
+			if err := e.deps.Store.Vacuum(); err != nil {
+				e.deps.Logger.Error("failed to vacuum store", zap.Error(err))
+			} else {
+				e.deps.Logger.Info("vacuumed store", zap.Duration("took", time.Since(start)))
+			}
+			lastVacuum = time.Now()
+		}
+
 		select {
 		case <-e.ctx.Done():
 			return
 		case <-ticker.C:
-			e.pruneAll()
 		}
 	}
 }
 
-func (e *Engine) pruneAll() {
+// pruneAll applies each monitor's history retention and returns the number
+// of entries removed.
+func (e *Engine) pruneAll() int64 {
+	var total int64
 	state := e.deps.Store.GetState()
 	for _, m := range state.Monitors {
-		if m.RetentionDays > 0 {
-			if err := e.deps.Store.PruneMonitorHistory(m.ID, m.RetentionDays); err != nil {
-				e.deps.Logger.Error("failed to prune history", zap.String("monitor_id", m.ID), zap.Error(err))
-			}
+		days := m.HistoryRetention(e.Settings().HistoryRetentionDays)
+		if days <= 0 {
+			continue
+		}
+		n, err := e.deps.Store.PruneMonitorHistory(m.ID, days)
+		if err != nil {
+			e.reportError(m, "failed to prune history", err)
+			continue
+		}
+		total += n
+	}
+	if total > 0 {
+		e.deps.Logger.Info("pruned monitor history", zap.Int64("entries", total))
+	}
+	// The event log follows the global retention.
+	if days := e.Settings().HistoryRetentionDays; days > 0 {
+		n, err := e.deps.Store.PruneEvents(time.Now().UTC().AddDate(0, 0, -days))
+		if err != nil {
+			e.deps.Logger.Error("failed to prune events", zap.Error(err))
 		}
+		total += n
 	}
+	return total
 }
 
 func (e *Engine) loop() {
 	e.wg.Add(1)
 	defer e.wg.Done()
+	defer e.checks.Done()
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
@@ -123,42 +379,129 @@ func (e *Engine) loop() {
 
 	for {
 		select {
-		case <-e.ctx.Done():
+		case <-e.stopping:
 			return
+		case id := <-e.wake:
+			now := time.Now()
+			m := e.findMonitor(id)
+			switch {
+			case m == nil:
+				delete(nextRun, id)
+			case m.IsPaused:
+				// Checked as soon as it is resumed.
+				delete(nextRun, id)
+				e.setLastStatus(id, pausedStatus(*m), now)
+			case !e.maintenanceMonitors(now)[id]:
+				nextRun[id] = e.nextCheckAt(*m, now)
+				e.schedule(now, *m)
+			}
 		case now := <-ticker.C:
 			state := e.deps.Store.GetState()
+			maintenance := e.maintenanceMonitors(now)
 			for _, m := range state.Monitors {
+				if maintenance[m.ID] && !m.IsPaused {
+					// Check again shortly after the window closes.
+					e.enterMaintenance(m, now)
+					delete(nextRun, m.ID)
+					continue
+				}
 				if m.IsPaused {
-					e.setLastStatus(m.ID, model.StatusPaused, now)
+					e.setLastStatus(m.ID, pausedStatus(m), now)
 					continue
 				}
-				interval := time.Duration(maxInt(5, m.IntervalSeconds)) * time.Second
 				nr, ok := nextRun[m.ID]
-				if !ok || !now.Before(nr) {
-					nextRun[m.ID] = now.Add(interval)
-					e.checkOnce(now, m)
+				if !ok {
+					nextRun[m.ID] = firstCheckAt(m, now)
+					continue
+				}
+				if at, ok := e.retryAt(m.ID); ok && at.Before(nr) {
+					nr = at
+				}
+				if !now.Before(nr) {
+					nextRun[m.ID] = e.nextCheckAt(m, now)
+					started := time.Now()
+					e.schedule(now, m)
+					e.recordSchedule(started.Sub(nr), time.Since(started))
 				}
 			}
 		}
 	}
 }
 
-func (e *Engine) checkOnce(now time.Time, m model.Monitor) {
-	ctx, cancel := context.WithTimeout(e.ctx, time.Duration(maxInt(1, m.TimeoutSeconds))*time.Second)
-	defer cancel()
+// pausedStatus is the status shown for a paused monitor.
+func pausedStatus(m model.Monitor) model.MonitorStatus {
+	if m.PausedReason == model.PausedReasonOrphaned {
+		return model.StatusOrphaned
+	}
+	return model.StatusPaused
+}
 
-	var res model.CheckResult
-	var logs *notify.DockerLogsAttachment
-	switch m.Type {
-	case model.MonitorTypeHTTP:
-		res = checkHTTP(ctx, now, m)
-	case model.MonitorTypeContainer:
-		res, logs = e.checkContainer(ctx, now, m)
-	default:
-		res = model.CheckResult{MonitorID: m.ID, Status: model.StatusUnknown, CheckedAt: now, Message: "unknown monitor type"}
+// ErrRemoteCheck is returned by CheckNow for monitors checked by a single
+// agent, whose results arrive on the agent's own schedule.
+var ErrRemoteCheck = errors.New("monitor is checked by a remote agent")
+
+// ErrNotChecked is returned by CheckNow for monitors that are paused or in
+// a maintenance window.
+var ErrNotChecked = errors.New("monitor is paused or in maintenance")
+
+// CheckNow checks m right away, outside its schedule, and records the
+// result like a scheduled check would. Multi-region monitors check the
+// local region and combine it with the latest results of their agents.
+// A failure that RetriesBeforeDown has yet to confirm is returned marked
+// unconfirmed and left to the scheduled re-checks, so that running checks
+// by hand neither records it nor counts toward the confirmation.
+func (e *Engine) CheckNow(m model.Monitor) (model.CheckResult, error) {
+	now := time.Now()
+	switch {
+	case m.IsPaused || e.maintenanceMonitors(now)[m.ID]:
+		return model.CheckResult{}, ErrNotChecked
+	case m.Regions != nil:
+		res, ok := e.checkRegions(now, m)
+		if !ok {
+			res = model.CheckResult{MonitorID: m.ID, Status: model.StatusUnknown, CheckedAt: now, Message: "no region has reported yet"}
+		}
+		return res, nil
+	case m.Agent != "":
+		return model.CheckResult{}, ErrRemoteCheck
+	}
+	res, logs := e.runCheck(now, m)
+	if e.needsConfirmation(m, res) {
+		res.Message = fmt.Sprintf("%s (unconfirmed: down after %d failed retries)", res.Message, m.RetriesBeforeDown)
+		return res, nil
+	}
+	return e.recordCheck(now, m, res, logs), nil
+}
+
+func (e *Engine) checkOnce(now time.Time, m model.Monitor) model.CheckResult {
+	res, logs := e.runCheck(now, m)
+	return e.recordCheck(now, m, res, logs)
+}
+
+// recordCheck handles the result of a local check once confirmDown lets
+// it through and returns it as recorded.
+func (e *Engine) recordCheck(now time.Time, m model.Monitor, res model.CheckResult, logs *notify.DockerLogsAttachment) model.CheckResult {
+	if !e.confirmDown(m, &res) {
+		return res
 	}
+	if e.deps.OnResult != nil {
+		e.deps.OnResult(m, res, logs)
+	}
+	e.handleResult(now, m, res, logs)
+	return res
+}
 
+// handleResult records a check result and raises the alerts, incidents and
+// escalations it calls for, whether the check ran here or on an agent.
+func (e *Engine) handleResult(now time.Time, m model.Monitor, res model.CheckResult, logs *notify.DockerLogsAttachment) {
+	if e.isRemoved(m.ID) || e.ctx.Err() != nil {
+		// Deleted while the check ran, or cut short by shutdown.
+		return
+	}
 	prev := e.getLastStatus(m.ID)
+
+	ctx, cancel := context.WithTimeout(e.ctx, time.Duration(maxInt(1, m.TimeoutSeconds))*time.Second)
+	defer cancel()
+
 	e.setLastStatus(m.ID, res.Status, now)
 
 	logsContent := ""
@@ -166,19 +509,53 @@ func (e *Engine) checkOnce(now time.Time, m model.Monitor) {
 		logsContent = logs.Content
 	}
 
-	e.appendHistory(m.ID, model.MonitorHistoryEntry{
+	e.recordHistory(m, prev, model.MonitorHistoryEntry{
 		Status:    res.Status,
 		CheckedAt: res.CheckedAt,
 		LatencyMs: res.LatencyMs,
 		Message:   res.Message,
 		Logs:      logsContent,
+		Transient: res.Transient,
+		ConnMode:  res.ConnMode,
+
+		CPUPercent:    res.CPUPercent,
+		MemoryPercent: res.MemoryPercent,
+		Regions:       res.Regions,
+		Phases:        res.Phases,
 	})
 
-	if res.Status == model.StatusUp && prev != model.StatusUp {
+	if res.Status.Available() {
+		e.observeLatency(m.ID, res.LatencyMs)
+	}
+
+	if res.Transient {
+		e.deps.Logger.Warn("transient network error recovered on retry",
+			zap.String("monitor_id", m.ID),
+			zap.String("monitor_name", m.Name),
+			zap.String("message", res.Message),
+		)
+	}
+
+	if res.Status.Available() && !prev.Available() {
 		e.resetAttempts(m.ID)
 	}
 
-	if prev != res.Status {
+	if res.Status == model.StatusOrphaned {
+		e.pauseOrphaned(m)
+	}
+
+	e.trackEscalation(ctx, m, res, prev)
+	e.trackIncident(m, res, prev)
+
+	// Daemon outages are reported once in aggregate by setDockerReachable.
+	dockerTransition := res.Status == model.StatusDockerUnreachable ||
+		(prev == model.StatusDockerUnreachable && res.Status.Available())
+
+	changed := prev != res.Status
+	crashLoop := res.Status == model.StatusCrashLoop
+	var prevAt time.Time
+	if changed {
+		prevAt = e.swapTransition(m.ID, res.CheckedAt)
 		e.deps.Logger.Info("monitor status changed",
 			zap.String("monitor_id", m.ID),
 			zap.String("monitor_name", m.Name),
@@ -186,49 +563,485 @@ func (e *Engine) checkOnce(now time.Time, m model.Monitor) {
 			zap.String("current", string(res.Status)),
 			zap.String("message", res.Message),
 		)
-		e.emitNotification(ctx, m, res, logs, prev)
+		evt := model.EventStatusChanged
+		if crashLoop {
+			evt = model.EventCrashLoop
+		}
+		e.recordEvent(evt, m.ID, res.CheckedAt, map[string]any{
+			"monitorName": m.Name,
+			"previous":    string(prev),
+			"current":     string(res.Status),
+			"message":     res.Message,
+			"latencyMs":   res.LatencyMs,
+		})
+	}
+	// Coming back up after a maintenance window is expected, not a recovery.
+	endOfMaintenance := prev == model.StatusMaintenance && res.Status == model.StatusUp
+	if crashLoop && changed {
+		e.emitCrashLoop(ctx, m, res, logs, prev, prevAt)
+	}
+	if !dockerTransition && !endOfMaintenance && !crashLoop {
+		e.applyAlertRules(ctx, m, res, logs, prev, prevAt)
+	}
+}
+
+// runCheck performs a single check of m under the monitor's timeout.
+func (e *Engine) runCheck(now time.Time, m model.Monitor) (model.CheckResult, *notify.DockerLogsAttachment) {
+	ctx, cancel := context.WithTimeout(e.ctx, time.Duration(maxInt(1, m.TimeoutSeconds))*time.Second)
+	defer cancel()
+	defer e.trackCheck(m.ID, cancel)()
+
+	if e.deps.Checker != nil {
+		return e.deps.Checker(ctx, now, m), nil
+	}
+
+	// Remediation gets the monitor as configured, without secret values.
+	orig := m
+	m, x := e.expandMonitorSecrets(m)
+	if err := x.Err(); err != nil {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: err.Error()}, nil
+	}
+
+	var res model.CheckResult
+	var logs *notify.DockerLogsAttachment
+	switch m.Type {
+	case model.MonitorTypeHTTP:
+		res = e.checkHTTP(ctx, now, m)
+	case model.MonitorTypeContainer:
+		res, logs = e.checkContainer(ctx, now, m)
+	case model.MonitorTypeWinService:
+		res = e.checkWinService(ctx, now, m)
+	case model.MonitorTypeLANPresence:
+		res = checkLANPresence(ctx, now, m)
+	case model.MonitorTypeCompose:
+		res = e.checkCompose(ctx, now, m)
+	case model.MonitorTypeKubernetes:
+		res = e.checkKubernetes(ctx, now, m)
+	case model.MonitorTypePush:
+		res = e.checkPush(now, m)
+	case model.MonitorTypeMySQL, model.MonitorTypePostgres, model.MonitorTypeRedis, model.MonitorTypeMongoDB:
+		res = checkDatabase(ctx, now, m)
+	case model.MonitorTypeMail:
+		res = checkMail(ctx, now, m)
+	case model.MonitorTypeExec:
+		res = e.checkExec(ctx, now, m)
+	case model.MonitorTypeSystem:
+		res = checkSystem(ctx, now, m)
+	default:
+		res = model.CheckResult{MonitorID: m.ID, Status: model.StatusUnknown, CheckedAt: now, Message: "unknown monitor type"}
 	}
+	res.Message = x.Mask(res.Message)
+	res = applyLatencyThresholds(m, res)
+	if res.Status == model.StatusDown {
+		e.tryMonitorRemediation(now, orig, res)
+	}
+	return res, logs
 }
 
-func checkHTTP(ctx context.Context, now time.Time, m model.Monitor) model.CheckResult {
+// downRetry tracks a failure that is waiting to be confirmed.
+type downRetry struct {
+	first   string    // message of the first failure
+	retries int       // failed re-checks so far
+	at      time.Time // when the next re-check is due
+}
+
+// confirmDown holds back a failure of a monitor that is about to flip to
+// down until RetriesBeforeDown re-checks have failed as well. Instead of
+// sleeping, it asks the scheduler for a re-check after RetryIntervalSeconds
+// through retryAt, so other monitors keep their schedule meanwhile. It
+// reports whether res should be recorded, adding the confirmation to its
+// message.
+func (e *Engine) confirmDown(m model.Monitor, res *model.CheckResult) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	r := e.confirming[m.ID]
+	if m.RetriesBeforeDown <= 0 || e.lastStatus[m.ID] == model.StatusDown {
+		delete(e.confirming, m.ID)
+		return true
+	}
+	if res.Status != model.StatusDown {
+		if r != nil {
+			delete(e.confirming, m.ID)
+			e.deps.Logger.Info("failure not confirmed on retry",
+				zap.String("monitor_id", m.ID),
+				zap.Int("retry", r.retries+1),
+				zap.String("first_failure", r.first),
+			)
+		}
+		return true
+	}
+	if r == nil {
+		r = &downRetry{first: res.Message}
+		e.confirming[m.ID] = r
+	} else if r.retries++; r.retries >= m.RetriesBeforeDown {
+		delete(e.confirming, m.ID)
+		res.Message = fmt.Sprintf("%s (confirmed after %d retries)", res.Message, m.RetriesBeforeDown)
+		return true
+	}
+	delay := time.Duration(m.RetryIntervalSeconds) * time.Second
+	if delay <= 0 {
+		delay = 2 * time.Second
+	}
+	r.at = time.Now().Add(delay)
+	return false
+}
+
+// needsConfirmation reports whether confirmDown would hold res back.
+func (e *Engine) needsConfirmation(m model.Monitor, res model.CheckResult) bool {
+	if res.Status != model.StatusDown || m.RetriesBeforeDown <= 0 {
+		return false
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.lastStatus[m.ID] != model.StatusDown
+}
+
+// retryAt returns when the unconfirmed failure of a monitor is due to be
+// checked again.
+func (e *Engine) retryAt(id string) (time.Time, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	r, ok := e.confirming[id]
+	if !ok {
+		return time.Time{}, false
+	}
+	return r.at, true
+}
+
+func (e *Engine) checkHTTP(ctx context.Context, now time.Time, m model.Monitor) model.CheckResult {
 	if m.HTTP == nil || m.HTTP.URL == "" {
 		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: "missing url"}
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.HTTP.URL, nil)
+	mode := model.ConnectionReused
+	if m.HTTP.FreshConnection {
+		mode = model.ConnectionFresh
+	}
+	client, err := e.httpClient(m, false)
 	if err != nil {
 		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: err.Error()}
 	}
+	res, transportErr := doHTTPCheck(ctx, now, m, client)
+	res.ConnMode = mode
+	if transportErr == nil || !m.HTTP.RetryOnFailure || ctx.Err() != nil {
+		return res
+	}
+
+	// Retry once over a brand-new connection to rule out a stale pooled one.
+	freshClient, _ := e.httpClient(m, true)
+	retry, _ := doHTTPCheck(ctx, now, m, freshClient)
+	retry.ConnMode = model.ConnectionFresh
+	if retry.Status != model.StatusUp {
+		return res
+	}
+	retry.Transient = true
+	retry.Message = "transient network error (retry succeeded): " + res.Message
+	return retry
+}
+
+// doHTTPCheck performs a single request. The returned error is non-nil only
+// for transport-level failures, which are the ones worth retrying.
+func doHTTPCheck(ctx context.Context, now time.Time, m model.Monitor, client *http.Client) (res model.CheckResult, transportErr error) {
+	if m.HTTP.MeasurePhases {
+		pt := &phaseTimer{}
+		ctx = httptrace.WithClientTrace(ctx, pt.clientTrace())
+		defer func() { res.Phases = pt.result() }()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.HTTP.URL, nil)
+	if err != nil {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: err.Error()}, nil
+	}
+	for k, v := range m.HTTP.Headers {
+		if strings.EqualFold(k, "Host") {
+			req.Host = v
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+	applyHTTPAuth(req, m.HTTP.Auth)
 	start := time.Now()
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := client.Do(req)
 	lat := time.Since(start)
 	if err != nil {
-		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, LatencyMs: int(lat.Milliseconds()), Message: err.Error()}
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, LatencyMs: int(lat.Milliseconds()), Message: err.Error()}, err
 	}
+	body, bodyErr := readHTTPBody(resp.Body, m.HTTP)
 	_ = resp.Body.Close()
 
+	if reason := verifyCertificatePins(resp.TLS, m.HTTP); reason != "" {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, LatencyMs: int(lat.Milliseconds()), Message: reason}, nil
+	}
+
 	if resp.StatusCode >= 200 && resp.StatusCode < 400 {
-		return model.CheckResult{MonitorID: m.ID, Status: model.StatusUp, CheckedAt: now, LatencyMs: int(lat.Milliseconds()), Message: resp.Status}
+		if m.HTTP.Keyword != "" && !bytes.Contains(body.head, []byte(m.HTTP.Keyword)) {
+			return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, LatencyMs: int(lat.Milliseconds()), Message: fmt.Sprintf("%s, keyword %q not found", resp.Status, m.HTTP.Keyword)}, nil
+		}
+		if m.HTTP.KeywordAbsent != "" && bytes.Contains(body.head, []byte(m.HTTP.KeywordAbsent)) {
+			return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, LatencyMs: int(lat.Milliseconds()), Message: fmt.Sprintf("%s, unexpected keyword %q found", resp.Status, m.HTTP.KeywordAbsent)}, nil
+		}
+		if reason := checkHTTPBody(body, bodyErr, m.HTTP); reason != "" {
+			return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, LatencyMs: int(lat.Milliseconds()), Message: resp.Status + ", " + reason}, nil
+		}
+		values, reason := checkJSONAssertions(body.head, m.HTTP.JSONAssertions)
+		if reason != "" {
+			return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, LatencyMs: int(lat.Milliseconds()), Message: resp.Status + ", " + reason}, nil
+		}
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusUp, CheckedAt: now, LatencyMs: int(lat.Milliseconds()), Message: resp.Status + values}, nil
+	}
+	return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, LatencyMs: int(lat.Milliseconds()), Message: resp.Status}, nil
+}
+
+func applyHTTPAuth(req *http.Request, auth *model.HTTPAuth) {
+	if auth == nil {
+		return
+	}
+	switch auth.Type {
+	case model.HTTPAuthBasic:
+		req.SetBasicAuth(auth.Username, auth.Password)
+	case model.HTTPAuthBearer:
+		req.Header.Set("Authorization", "Bearer "+auth.Token)
+	}
+}
+
+// maxBodyBytes caps how much of a response body is kept for keyword
+// assertions.
+const maxBodyBytes = 1 << 20
+
+// httpBody is what the assertions of an HTTP monitor need from a response
+// body: its start for keyword and JSON assertions, and its size and hash.
+type httpBody struct {
+	head   []byte
+	size   int64
+	sha256 string
+}
+
+// readHTTPBody reads as much of r as the assertions of h need. Without
+// size or hash assertions only the prefix for keyword and JSON assertions
+// is read; with only a maximum size, reading stops just past it.
+func readHTTPBody(r io.Reader, h *model.HTTPMonitor) (httpBody, error) {
+	var b httpBody
+	if h.Keyword != "" || h.KeywordAbsent != "" || len(h.JSONAssertions) > 0 {
+		head, err := io.ReadAll(io.LimitReader(r, maxBodyBytes))
+		b.head, b.size = head, int64(len(head))
+		if err != nil {
+			return b, err
+		}
+	}
+	if h.MinBodyBytes <= 0 && h.MaxBodyBytes <= 0 && h.BodySHA256 == "" {
+		return b, nil
 	}
-	return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, LatencyMs: int(lat.Milliseconds()), Message: resp.Status}
+	if h.MaxBodyBytes > 0 && h.BodySHA256 == "" {
+		r = io.LimitReader(r, h.MaxBodyBytes+1-b.size)
+	}
+	sum := sha256.New()
+	sum.Write(b.head)
+	n, err := io.Copy(sum, r)
+	b.size += n
+	b.sha256 = hex.EncodeToString(sum.Sum(nil))
+	return b, err
+}
+
+// checkJSONAssertions evaluates the assertions against body. It returns
+// the selected values for the check message, e.g. `, $.status = "ok"`, or
+// why the body fails an assertion.
+func checkJSONAssertions(body []byte, exprs []string) (values, reason string) {
+	if len(exprs) == 0 {
+		return "", ""
+	}
+	doc, err := jsonpath.Decode(body)
+	if err != nil {
+		return "", "invalid JSON body: " + err.Error()
+	}
+	var sb strings.Builder
+	for _, expr := range exprs {
+		a, err := jsonpath.Parse(expr)
+		if err != nil {
+			return "", fmt.Sprintf("assertion %q: %v", expr, err)
+		}
+		got, ok, err := a.Eval(doc)
+		switch {
+		case err != nil:
+			return "", err.Error()
+		case !ok && a.String() == "":
+			return "", fmt.Sprintf("%s = %s, expected a value", a.Path, jsonpath.Format(got))
+		case !ok:
+			return "", fmt.Sprintf("%s = %s, expected %s", a.Path, jsonpath.Format(got), a)
+		}
+		fmt.Fprintf(&sb, ", %s = %s", a.Path, jsonpath.Format(got))
+	}
+	return sb.String(), ""
+}
+
+// checkHTTPBody returns why the body fails the size and hash assertions of
+// h, or "" when it passes.
+func checkHTTPBody(b httpBody, readErr error, h *model.HTTPMonitor) string {
+	if h.MinBodyBytes <= 0 && h.MaxBodyBytes <= 0 && h.BodySHA256 == "" {
+		return ""
+	}
+	switch {
+	case readErr != nil:
+		return fmt.Sprintf("reading body failed after %d bytes: %v", b.size, readErr)
+	case h.MinBodyBytes > 0 && b.size < h.MinBodyBytes:
+		return fmt.Sprintf("body is %d bytes, expected at least %d", b.size, h.MinBodyBytes)
+	case h.MaxBodyBytes > 0 && b.size > h.MaxBodyBytes:
+		if h.BodySHA256 == "" {
+			return fmt.Sprintf("body exceeds %d bytes", h.MaxBodyBytes)
+		}
+		return fmt.Sprintf("body is %d bytes, expected at most %d", b.size, h.MaxBodyBytes)
+	case h.BodySHA256 != "" && !strings.EqualFold(b.sha256, h.BodySHA256):
+		return "body SHA-256 mismatch: got " + b.sha256
+	}
+	return ""
+}
+
+func checkLANPresence(ctx context.Context, now time.Time, m model.Monitor) model.CheckResult {
+	if m.LANPresence == nil || (m.LANPresence.IP == "" && m.LANPresence.MAC == "") {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: "missing ip or mac"}
+	}
+	start := time.Now()
+	n, err := lan.Probe(ctx, m.LANPresence.IP, m.LANPresence.MAC)
+	lat := int(time.Since(start).Milliseconds())
+	if err != nil {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, LatencyMs: lat, Message: err.Error()}
+	}
+	return model.CheckResult{MonitorID: m.ID, Status: model.StatusUp, CheckedAt: now, LatencyMs: lat, Message: n.IP + " is at " + n.MAC}
 }
 
 func (e *Engine) checkContainer(ctx context.Context, now time.Time, m model.Monitor) (model.CheckResult, *notify.DockerLogsAttachment) {
 	if m.Container == nil || m.Container.ContainerID == "" {
 		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: "missing container id"}, nil
 	}
-	state, err := e.deps.Docker.ContainerState(ctx, m.Container.ContainerID)
+	host := m.Container.HostID
+	dc, err := e.deps.Docker.Get(host)
+	if err != nil {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: err.Error()}, nil
+	}
+	state, err := dc.ContainerState(ctx, m.Container.ContainerID)
 	if err != nil {
+		if errors.Is(err, docker.ErrDockerUnavailable) {
+			e.setDockerReachable(host, false, now)
+			return model.CheckResult{MonitorID: m.ID, Status: model.StatusDockerUnreachable, CheckedAt: now, Message: err.Error()}, nil
+		}
+		e.setDockerReachable(host, true, now)
+		if errors.Is(err, docker.ErrContainerNotFound) && m.Container.AutoPauseOnRemoval {
+			return model.CheckResult{MonitorID: m.ID, Status: model.StatusOrphaned, CheckedAt: now, Message: "container removed; monitor paused"}, nil
+		}
 		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: err.Error()}, e.tryAttachLogs(ctx, m, now)
 	}
-	if state == "running" {
-		return model.CheckResult{MonitorID: m.ID, Status: model.StatusUp, CheckedAt: now, Message: state}, nil
+	e.setDockerReachable(host, true, now)
+	if n, window, looping := e.crashLoop(m, state, now); looping {
+		// Restarting it again would not help; hold the status until the
+		// restarts stop.
+		e.clearBreach(m.ID)
+		msg := fmt.Sprintf("crash loop: %d restarts in %s (%s)", n, window, state.String())
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusCrashLoop, CheckedAt: now, Message: msg}, e.crashLoopLogs(ctx, m, now)
+	}
+	if state.Up() {
+		res := model.CheckResult{MonitorID: m.ID, Status: model.StatusUp, CheckedAt: now, Message: state.String()}
+		e.applyResourceUsage(m, &res)
+		return res, nil
 	}
+	e.clearBreach(m.ID)
 
 	e.applyRestartPolicy(ctx, m)
-	e.tryRemediate(ctx, now, m)
+	e.tryRemediate(now, m, state)
+
+	return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: state.String()}, e.tryAttachLogs(ctx, m, now)
+}
+
+// setDockerReachable records reachability of a docker host and sends a
+// single aggregated alert to the union of its container monitors' channels,
+// or the default channels when it has none, when it changes.
+func (e *Engine) setDockerReachable(host string, ok bool, now time.Time) {
+	e.mu.Lock()
+	changed := e.dockerDown[host] == ok
+	e.dockerDown[host] = !ok
+	if changed {
+		e.daemon(host).since = now
+	}
+	e.mu.Unlock()
+	if !changed {
+		return
+	}
+
+	evt := model.EventDockerRecovered
+	if !ok {
+		evt = model.EventDockerUnreachable
+		e.deps.Logger.Error("docker daemon unreachable", zap.String("host", host))
+	} else {
+		e.deps.Logger.Info("docker daemon reachable again", zap.String("host", host))
+	}
+
+	var names []string
+	var monitors []model.Monitor
+	for _, m := range e.deps.Store.GetState().Monitors {
+		if h, ok := dockerHostOf(m); !ok || m.IsPaused || h != host {
+			continue
+		}
+		names = append(names, m.Name)
+		monitors = append(monitors, m)
+	}
+
+	current := model.StatusUp
+	if !ok {
+		current = model.StatusDown
+	}
+	daemon := "Docker daemon"
+	if host != "" {
+		daemon += " (" + host + ")"
+	}
+	payload := notify.Payload{
+		Type: string(evt),
+		At:   now,
+		Data: map[string]any{
+			"monitorName": daemon,
+			"current":     string(current),
+			"message":     fmt.Sprintf("%d container monitors affected", len(names)),
+			"monitors":    names,
+		},
+	}
+	e.recordEvent(evt, "", now, map[string]any{
+		"host":     host,
+		"message":  payload.Data["message"],
+		"monitors": names,
+	})
 
-	return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: state}, e.tryAttachLogs(ctx, m, now)
+	var ids []string
+	seen := map[string]bool{}
+	for _, m := range monitors {
+		for _, id := range e.channelsFor(m, payload) {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		if ok {
+			// Bring the container monitors back without waiting for their
+			// next interval.
+			select {
+			case e.wake <- m.ID:
+			default:
+			}
+		}
+	}
+	if len(ids) == 0 {
+		ids = e.Settings().DefaultNotifyWebhookIDs
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(e.ctx, 15*time.Second)
+		defer cancel()
+		e.sendToChannels(ctx, ids, payload)
+	}()
+}
+
+func (e *Engine) pauseOrphaned(m model.Monitor) {
+	m.IsPaused = true
+	m.PausedReason = model.PausedReasonOrphaned
+	if _, err := e.deps.Store.UpsertMonitor(m); err != nil {
+		e.reportError(m, "failed to auto-pause orphaned monitor", err)
+		return
+	}
+	e.deps.Logger.Info("container removed, monitor auto-paused", zap.String("monitor_id", m.ID))
 }
 
 func (e *Engine) applyRestartPolicy(ctx context.Context, m model.Monitor) {
@@ -239,71 +1052,143 @@ func (e *Engine) applyRestartPolicy(ctx context.Context, m model.Monitor) {
 	if p.Name == "" {
 		return
 	}
-	_ = e.deps.Docker.UpdateRestartPolicy(ctx, m.Container.ContainerID, container.RestartPolicy{
+	dc, err := e.deps.Docker.Get(m.Container.HostID)
+	if err != nil {
+		return
+	}
+	_ = dc.UpdateRestartPolicy(ctx, m.Container.ContainerID, container.RestartPolicy{
 		Name:              container.RestartPolicyMode(p.Name),
 		MaximumRetryCount: p.MaximumRetryCount,
 	})
 }
 
-func (e *Engine) tryRemediate(ctx context.Context, now time.Time, m model.Monitor) {
+// tryRemediate runs the monitor's remediation action, subject to its attempt
+// limit and cooldown.
+func (e *Engine) tryRemediate(now time.Time, m model.Monitor, state docker.StateInfo) {
 	if m.Container == nil {
 		return
 	}
 	p := m.Container.Remediation
-	if p.Action == "" || p.Action == model.RemediationNone {
+	if !e.claimRemediation(m.ID, p, now) {
 		return
 	}
-	if p.MaxAttempts <= 0 {
-		return
+	if run, ok := e.containerRemediation(m, p, state); ok {
+		e.reportRemediation(now, m, run)
 	}
+}
 
-	e.mu.Lock()
-	next := e.remediateAt[m.ID]
-	if !next.IsZero() && now.Before(next) {
-		e.mu.Unlock()
-		return
+// containerRemediation runs policy p against the monitor's container. A
+// container that is running but unhealthy is restarted even if the action
+// is "start", since starting it would be a no-op. ok is false for actions
+// that do not apply to containers.
+func (e *Engine) containerRemediation(m model.Monitor, p model.RemediationPolicy, state docker.StateInfo) (run remediationRun, ok bool) {
+	action := p.Action
+	if action == model.RemediationStart && state.Status == "running" {
+		action = model.RemediationRestart
 	}
-	if e.attempts[m.ID] >= p.MaxAttempts {
-		e.mu.Unlock()
-		return
+	run = remediationRun{action: action, started: time.Now(), result: map[string]any{}}
+	timeout := 10 * time.Second
+	if action == model.RemediationExec {
+		timeout = 30 * time.Second
 	}
-	e.attempts[m.ID]++
-	e.remediateAt[m.ID] = now.Add(time.Duration(maxInt(5, p.CooldownSeconds)) * time.Second)
-	e.mu.Unlock()
+	if p.TimeoutSeconds > 0 {
+		timeout = time.Duration(p.TimeoutSeconds) * time.Second
+	}
+	// Actions get their own deadline rather than the check's; stopping a
+	// container may take the whole grace period.
+	ctx, cancel := context.WithTimeout(e.ctx, timeout+30*time.Second)
+	defer cancel()
 
-	timeout := 10 * time.Second
-	var err error
-	switch p.Action {
+	dc, err := e.deps.Docker.Get(m.Container.HostID)
+	if err != nil {
+		run.err = err
+		return run, true
+	}
+	id := m.Container.ContainerID
+	switch action {
 	case model.RemediationStart:
-		err = e.deps.Docker.Start(ctx, m.Container.ContainerID)
+		run.err = dc.Start(ctx, id)
 	case model.RemediationRestart:
-		err = e.deps.Docker.Restart(ctx, m.Container.ContainerID, timeout)
+		run.err = dc.Restart(ctx, id, timeout)
+	case model.RemediationStop:
+		run.err = dc.Stop(ctx, id, timeout)
+	case model.RemediationExec:
+		if len(p.Command) == 0 {
+			run.err = errors.New("exec remediation without command")
+			break
+		}
+		ectx, cancel := context.WithTimeout(ctx, timeout)
+		res, err := dc.Exec(ectx, id, p.Command)
+		cancel()
+		run.result["exitCode"] = res.ExitCode
+		run.result["output"] = res.Output
+		if err == nil && res.ExitCode != 0 {
+			err = fmt.Errorf("command exited with code %d", res.ExitCode)
+		}
+		run.err = err
+	case model.RemediationRecreate:
+		newID, err := dc.Recreate(ctx, id, timeout)
+		if newID != "" {
+			run.result["containerId"] = newID
+			e.retargetContainer(m.ID, id, newID)
+		}
+		run.err = err
+	case model.RemediationWebhook, model.RemediationScript:
+		run.result, run.err = e.externalRemediation(ctx, m, p, state.String())
 	default:
+		return run, false
+	}
+	return run, true
+}
+
+// retargetContainer points a monitor that refers to its container by ID at
+// the container that replaced it. Monitors referring to a name keep working
+// as is.
+func (e *Engine) retargetContainer(monitorID, oldRef, newID string) {
+	if oldRef == newID || !containerIDPattern.MatchString(oldRef) {
 		return
 	}
-	if err == nil {
-		e.deps.Logger.Info("remediation action success",
-			zap.String("monitor_id", m.ID),
-			zap.String("action", string(p.Action)),
-		)
-		e.emitWebhookBestEffort(ctx, m, notify.Payload{
-			Type:      string(model.EventRemediated),
-			MonitorID: m.ID,
-			At:        now,
-			Data: map[string]any{
-				"action":  string(p.Action),
-				"attempt": e.getAttempts(m.ID),
-			},
-		})
-	} else {
-		e.deps.Logger.Error("remediation action failed",
-			zap.String("monitor_id", m.ID),
-			zap.String("action", string(p.Action)),
+	m := e.findMonitor(monitorID)
+	if m == nil || m.Container == nil || m.Container.ContainerID != oldRef {
+		return
+	}
+	c := *m.Container
+	c.ContainerID = newID
+	m.Container = &c
+	if _, err := e.deps.Store.UpsertMonitor(*m); err != nil {
+		e.deps.Logger.Error("failed to update recreated container id",
+			zap.String("monitor_id", monitorID),
 			zap.Error(err),
 		)
 	}
 }
 
+var containerIDPattern = regexp.MustCompile(`^[0-9a-f]{12,64}$`)
+
+// claimRemediation reports whether policy p allows a remediation attempt for
+// monitor id now, and if so counts the attempt and starts its cooldown.
+func (e *Engine) claimRemediation(id string, p model.RemediationPolicy, now time.Time) bool {
+	if p.Action == "" || p.Action == model.RemediationNone {
+		return false
+	}
+	if p.MaxAttempts <= 0 {
+		return false
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	next := e.remediateAt[id]
+	if !next.IsZero() && now.Before(next) {
+		return false
+	}
+	if e.attempts[id] >= p.MaxAttempts {
+		return false
+	}
+	e.attempts[id]++
+	e.remediateAt[id] = now.Add(time.Duration(maxInt(5, p.CooldownSeconds)) * time.Second)
+	return true
+}
+
 func (e *Engine) tryAttachLogs(ctx context.Context, m model.Monitor, now time.Time) *notify.DockerLogsAttachment {
 	if m.Type != model.MonitorTypeContainer || m.Container == nil {
 		return nil
@@ -315,15 +1200,32 @@ func (e *Engine) tryAttachLogs(ctx context.Context, m model.Monitor, now time.Ti
 	if tail <= 0 {
 		tail = 200
 	}
-	since := now.Add(-e.deps.DefaultSince)
+	sinceWindow := e.deps.DefaultSince
+	if m.Logs.SinceSeconds > 0 {
+		sinceWindow = time.Duration(m.Logs.SinceSeconds) * time.Second
+	}
+	maxBytes := e.Settings().MaxDockerLogBytes
+	if m.Logs.MaxBytes > 0 {
+		maxBytes = m.Logs.MaxBytes
+	}
 
-	rc, err := e.deps.Docker.Logs(ctx, m.Container.ContainerID, intToTail(tail), since)
+	dc, err := e.deps.Docker.Get(m.Container.HostID)
+	if err != nil {
+		return nil
+	}
+	rc, err := dc.Logs(ctx, m.Container.ContainerID, docker.LogsOptions{
+		Tail:       intToTail(tail),
+		Since:      now.Add(-sinceWindow),
+		Stdout:     m.Logs.Stdout(),
+		Stderr:     m.Logs.Stderr(),
+		Timestamps: m.Logs.ShowTimestamps(),
+	})
 	if err != nil {
 		return nil
 	}
 	defer rc.Close()
 
-	lw := newLimitedWriter(e.deps.MaxLogBytes)
+	lw := newLimitedWriter(maxBytes)
 	_, _ = stdcopy.StdCopy(lw, lw, rc)
 	content := string(lw.Bytes())
 	if len(bytes.TrimSpace(lw.Bytes())) == 0 {
@@ -336,13 +1238,12 @@ func (e *Engine) tryAttachLogs(ctx context.Context, m model.Monitor, now time.Ti
 	}
 }
 
-func (e *Engine) emitNotification(ctx context.Context, m model.Monitor, res model.CheckResult, logs *notify.DockerLogsAttachment, prev model.MonitorStatus) {
-	target := ""
-	if m.Type == model.MonitorTypeHTTP && m.HTTP != nil {
-		target = m.HTTP.URL
-	} else if m.Type == model.MonitorTypeContainer && m.Container != nil {
-		target = m.Container.ContainerID
-	}
+func (e *Engine) emitNotification(ctx context.Context, m model.Monitor, res model.CheckResult, logs *notify.DockerLogsAttachment, prev model.MonitorStatus, prevAt time.Time) {
+	e.emitWebhookBestEffort(ctx, m, statusPayload(m, res, logs, prev, prevAt))
+}
+
+func statusPayload(m model.Monitor, res model.CheckResult, logs *notify.DockerLogsAttachment, prev model.MonitorStatus, prevAt time.Time) notify.Payload {
+	target := monitorTarget(m)
 
 	payload := notify.Payload{
 		Type:      string(model.EventStatusChanged),
@@ -353,18 +1254,113 @@ func (e *Engine) emitNotification(ctx context.Context, m model.Monitor, res mode
 			"target":      target,
 			"previous":    string(prev),
 			"current":     string(res.Status),
+			"severity":    notify.SeverityOf(string(res.Status)),
 			"message":     res.Message,
 			"latencyMs":   res.LatencyMs,
 		},
 		Logs: logs,
 	}
-	e.emitWebhookBestEffort(ctx, m, payload)
+	// How long the previous status lasted, e.g. "recovered after 14m32s".
+	if !prevAt.IsZero() {
+		d := res.CheckedAt.Sub(prevAt).Round(time.Second)
+		payload.Data["previousTransitionAt"] = prevAt
+		switch prev {
+		case model.StatusDown:
+			payload.Data["downFor"] = d.String()
+			payload.Data["downForSeconds"] = int64(d.Seconds())
+		case model.StatusUp:
+			payload.Data["upFor"] = d.String()
+			payload.Data["upForSeconds"] = int64(d.Seconds())
+		}
+	}
+	return payload
+}
+
+// NotifyLifecycle records a configuration lifecycle event (created, edited,
+// deleted, paused, resumed) and sends it to the monitor's notification
+// channels in the background.
+func (e *Engine) NotifyLifecycle(m model.Monitor, t model.EventType) {
+	payload := notify.Payload{
+		Type:      string(t),
+		MonitorID: m.ID,
+		At:        time.Now().UTC(),
+		Data: map[string]any{
+			"monitorName": m.Name,
+			"target":      monitorTarget(m),
+		},
+	}
+	e.recordEvent(t, m.ID, payload.At, map[string]any{"monitorName": m.Name, "target": monitorTarget(m)})
+	go func() {
+		ctx, cancel := context.WithTimeout(e.ctx, 15*time.Second)
+		defer cancel()
+		e.emitWebhookBestEffort(ctx, m, payload)
+	}()
+}
+
+// Target returns what m checks, e.g. its URL or container, for display.
+// Database DSNs come without their password.
+func Target(m model.Monitor) string {
+	return monitorTarget(m)
+}
+
+func monitorTarget(m model.Monitor) string {
+	if m.Type == model.MonitorTypeHTTP && m.HTTP != nil {
+		return m.HTTP.URL
+	} else if m.Type == model.MonitorTypeContainer && m.Container != nil {
+		return m.Container.ContainerID
+	} else if m.Type == model.MonitorTypeWinService && m.WinService != nil {
+		if m.WinService.IISSite != "" {
+			return m.WinService.IISSite
+		}
+		return m.WinService.ServiceName
+	} else if m.Type == model.MonitorTypeLANPresence && m.LANPresence != nil {
+		if m.LANPresence.IP != "" {
+			return m.LANPresence.IP
+		}
+		return m.LANPresence.MAC
+	} else if m.Type == model.MonitorTypeCompose && m.Compose != nil {
+		return m.Compose.Project
+	} else if m.Type == model.MonitorTypeKubernetes && m.Kubernetes != nil {
+		k := m.Kubernetes
+		if k.Name != "" {
+			return string(k.Kind) + "/" + k.Name
+		}
+		return string(k.Kind) + "?" + k.LabelSelector
+	} else if m.Type.IsDatabase() && m.Database != nil {
+		return dbprobe.Redact(m.Database.DSN)
+	} else if m.Type == model.MonitorTypeMail && m.Mail != nil {
+		return mailTarget(m.Mail)
+	} else if m.Type == model.MonitorTypeExec && m.Exec != nil {
+		if len(m.Exec.Command) > 0 {
+			return strings.Join(m.Exec.Command, " ")
+		}
+		return m.Exec.Script
+	} else if m.Type == model.MonitorTypeSystem && m.System != nil {
+		return systemTarget(m.System)
+	}
+	return ""
+}
+
+// SetNotifier replaces the dispatcher of the config-file webhooks. Alerts
+// already being sent finish with the previous one.
+func (e *Engine) SetNotifier(d *notify.Dispatcher) {
+	e.notifier.Store(d)
 }
 
 func (e *Engine) emitWebhookBestEffort(ctx context.Context, m model.Monitor, payload notify.Payload) {
+	if m.Mention != "" {
+		if payload.Data == nil {
+			payload.Data = map[string]any{}
+		}
+		payload.Data["mention"] = m.Mention
+	}
+	e.sendToChannels(ctx, e.channelsFor(m, payload), payload)
+}
+
+func (e *Engine) sendToChannels(ctx context.Context, ids []string, payload notify.Payload) {
 	// 1. Try to find in Store (user configured notifications)
 	allNotifs := e.deps.Store.GetNotifications()
-	for _, id := range m.NotifyWebhookIDs {
+	for _, id := range ids {
 		var found *model.Notification
 		// Try match by ID
 		for _, n := range allNotifs {
@@ -386,17 +1382,70 @@ func (e *Engine) emitWebhookBestEffort(ctx context.Context, m model.Monitor, pay
 		}
 
 		if found != nil {
-			w := config.NotificationWebhook{
-				Name: found.Name,
-				URL:  found.URL,
-				Type: found.Type,
+			if e.holdForQuietHours(*found, payload) {
+				continue
 			}
-			_ = notify.Send(ctx, e.deps.Notifier.Client(), w, payload)
+			e.deliverOrDigest(ctx, found.ID, webhookFor(*found), payload)
 			continue
 		}
 
 		// 2. Fallback to legacy Config-based notifications
-		_ = e.deps.Notifier.SendWebhook(ctx, id, payload)
+		if w, ok := e.notifier.Load().Webhook(id); ok {
+			e.deliverOrDigest(ctx, id, w, payload)
+		}
+	}
+}
+
+// deliver sends payload to one channel and records the attempt in the
+// notification log.
+func (e *Engine) deliver(ctx context.Context, notificationID string, w config.NotificationWebhook, payload notify.Payload) {
+	sw, x := e.expandWebhookSecrets(w)
+	err := x.Err()
+	if err == nil {
+		if err = notify.Send(ctx, e.notifier.Load().Client(), sw, payload); err != nil {
+			// HTTP errors quote the endpoint, which may hold the token.
+			err = errors.New(redact.String(x.Mask(err.Error())))
+		}
+	}
+	attempt := model.NotificationAttempt{
+		NotificationID: notificationID,
+		ChannelName:    w.Name,
+		ChannelType:    w.Type,
+		MonitorID:      payload.MonitorID,
+		EventType:      payload.Type,
+		Summary:        notify.Summary(payload),
+		Success:        err == nil,
+		At:             time.Now(),
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+		e.deps.Logger.Warn("notification delivery failed",
+			zap.String("notification", w.Name),
+			zap.String("monitor_id", payload.MonitorID),
+			zap.Error(err),
+		)
+	}
+	if err := e.deps.Store.AddNotificationAttempt(attempt); err != nil {
+		e.deps.Logger.Error("failed to record notification attempt", zap.Error(err))
+	}
+}
+
+// webhookFor converts a stored notification channel into the config form
+// understood by notify.Send.
+func webhookFor(n model.Notification) config.NotificationWebhook {
+	return config.NotificationWebhook{
+		Name:         n.Name,
+		URL:          n.URL,
+		Type:         n.Type,
+		BotToken:     n.BotToken,
+		ChatID:       n.ChatID,
+		Secret:       n.Secret,
+		UserKey:      n.UserKey,
+		AppToken:     n.AppToken,
+		Method:       n.Method,
+		Headers:      n.Headers,
+		BearerToken:  n.BearerToken,
+		BodyTemplate: n.BodyTemplate,
 	}
 }
 
@@ -416,6 +1465,16 @@ func (e *Engine) setLastStatus(id string, s model.MonitorStatus, t time.Time) {
 	e.lastCheck[id] = t
 }
 
+// swapTransition records t as the monitor's latest status transition and
+// returns the previous one (zero if unknown).
+func (e *Engine) swapTransition(id string, t time.Time) time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	prev := e.transition[id]
+	e.transition[id] = t
+	return prev
+}
+
 func (e *Engine) resetAttempts(id string) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -428,10 +1487,128 @@ func (e *Engine) getAttempts(id string) int {
 	return e.attempts[id]
 }
 
+// recordHistory applies the monitor's success sampling: consecutive
+// successful checks are folded into one entry whose Weight counts them, and
+// the pending entry is flushed before any failure or transition.
+func (e *Engine) recordHistory(m model.Monitor, prev model.MonitorStatus, entry model.MonitorHistoryEntry) {
+	n := m.HistorySampleEvery
+	sampled := n > 1 && entry.Status == model.StatusUp && prev == model.StatusUp
+
+	e.mu.Lock()
+	pending := e.pending[m.ID]
+	if !sampled {
+		delete(e.pending, m.ID)
+		e.mu.Unlock()
+		if pending != nil {
+			e.appendHistory(m.ID, *pending)
+		}
+		e.appendHistory(m.ID, entry)
+		return
+	}
+
+	entry.Weight = 1
+	if pending != nil {
+		entry.Weight = pending.Weight + 1
+	}
+	if entry.Weight < n {
+		e.pending[m.ID] = &entry
+		e.mu.Unlock()
+		return
+	}
+	delete(e.pending, m.ID)
+	e.mu.Unlock()
+	e.appendHistory(m.ID, entry)
+}
+
+// flushPendingHistory writes the sampled entries still being accumulated.
+func (e *Engine) flushPendingHistory() {
+	e.mu.Lock()
+	pending := e.pending
+	e.pending = map[string]*model.MonitorHistoryEntry{}
+	e.mu.Unlock()
+	for id, entry := range pending {
+		e.appendHistory(id, *entry)
+	}
+}
+
 func (e *Engine) appendHistory(id string, entry model.MonitorHistoryEntry) {
-	if err := e.deps.Store.AddMonitorHistory(id, entry); err != nil {
-		e.deps.Logger.Error("failed to append history", zap.String("monitor_id", id), zap.Error(err))
+	if e.deps.HistoryFlushInterval <= 0 {
+		if err := e.deps.Store.AddMonitorHistory(id, entry); err != nil {
+			e.deps.Logger.Error("failed to append history", zap.String("monitor_id", id), zap.Error(err))
+		}
+		return
+	}
+	size := e.historyBatchSize()
+	e.mu.Lock()
+	e.historyBuf = append(e.historyBuf, store.HistoryRecord{MonitorID: id, Entry: entry})
+	// Signal only when the buffer fills up, not on every entry while a
+	// failed batch waits in it for the next tick.
+	full := len(e.historyBuf) == size
+	e.mu.Unlock()
+	if full {
+		select {
+		case e.historyFull <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (e *Engine) historyBatchSize() int {
+	if e.deps.HistoryBatchSize <= 0 {
+		return 500
+	}
+	return e.deps.HistoryBatchSize
+}
+
+// historyFlushLoop writes the buffered history every HistoryFlushInterval
+// and whenever the buffer fills up.
+func (e *Engine) historyFlushLoop() {
+	e.wg.Add(1)
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.deps.HistoryFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-e.historyFull:
+		}
+		e.flushHistory()
+	}
+}
+
+func (e *Engine) flushHistory() {
+	e.mu.Lock()
+	batch := e.historyBuf
+	e.historyBuf = nil
+	e.mu.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+	if err := e.deps.Store.AddMonitorHistoryBatch(batch); err != nil {
+		e.deps.Logger.Error("failed to write history batch, will retry", zap.Int("entries", len(batch)), zap.Error(err))
+		e.requeueHistory(batch)
+	}
+}
+
+// maxHistoryBacklog caps the entries kept for retry while the store keeps
+// failing, in multiples of the batch size.
+const maxHistoryBacklog = 20
+
+// requeueHistory puts a batch that failed to write back in front of the
+// entries buffered since, dropping the oldest beyond the backlog cap.
+func (e *Engine) requeueHistory(batch []store.HistoryRecord) {
+	size := e.historyBatchSize()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	buf := append(batch, e.historyBuf...)
+	if over := len(buf) - maxHistoryBacklog*size; over > 0 {
+		e.deps.Logger.Warn("history backlog full, dropping oldest entries", zap.Int("dropped", over))
+		buf = buf[over:]
 	}
+	e.historyBuf = buf
 }
 
 func (e *Engine) GetHistory(id string) []model.MonitorHistoryEntry {
@@ -0,0 +1,29 @@
+package monitor
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+// recordEvent appends an event to the store's event log. Events are recorded
+// whether or not any notification goes out for them.
+func (e *Engine) recordEvent(t model.EventType, monitorID string, at time.Time, data map[string]any) {
+	ev := model.Event{ID: NewID(), Type: t, MonitorID: monitorID, At: at, Data: data}
+	if err := e.deps.Store.AddEvent(ev); err != nil {
+		e.deps.Logger.Warn("failed to record event", zap.String("type", string(t)), zap.String("monitor_id", monitorID), zap.Error(err))
+	}
+}
+
+// reportError logs an engine failure concerning a monitor and records it as
+// an error event.
+func (e *Engine) reportError(m model.Monitor, msg string, err error) {
+	e.deps.Logger.Error(msg, zap.String("monitor_id", m.ID), zap.Error(err))
+	e.recordEvent(model.EventError, m.ID, time.Now().UTC(), map[string]any{
+		"monitorName": m.Name,
+		"message":     msg,
+		"error":       err.Error(),
+	})
+}
@@ -0,0 +1,96 @@
+package monitor
+
+import (
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lsy88/uptime-chopper/internal/docker"
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+// eventsLoop follows the event stream of one docker host and asks the
+// scheduler to re-check affected container monitors right away instead of
+// waiting for their next interval. The stream is resubscribed with backoff
+// when it breaks; polling keeps working meanwhile.
+func (e *Engine) eventsLoop(host string, dc *docker.Client) {
+	e.wg.Add(1)
+	defer e.wg.Done()
+
+	backoff := time.Second
+	for {
+		events, errs := dc.Events(e.ctx)
+		started := time.Now()
+	stream:
+		for {
+			select {
+			case <-e.ctx.Done():
+				return
+			case err := <-errs:
+				if err != nil && e.ctx.Err() == nil {
+					e.deps.Logger.Debug("docker event stream ended", zap.String("host", host), zap.Error(err))
+				}
+				break stream
+			case ev := <-events:
+				e.handleContainerEvent(host, ev)
+			}
+		}
+
+		if time.Since(started) > time.Minute {
+			backoff = time.Second
+		}
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+func (e *Engine) handleContainerEvent(host string, ev docker.ContainerEvent) {
+	for _, m := range e.deps.Store.GetState().Monitors {
+		if h, ok := dockerHostOf(m); !ok || m.IsPaused || h != host {
+			continue
+		}
+		if m.Type == model.MonitorTypeCompose {
+			if ev.Project == "" || ev.Project != m.Compose.Project {
+				continue
+			}
+		} else if !matchesContainer(m.Container.ContainerID, ev) {
+			continue
+		}
+		e.deps.Logger.Debug("container event",
+			zap.String("monitor_id", m.ID),
+			zap.String("container", ev.ContainerID),
+			zap.String("action", ev.Action),
+		)
+		select {
+		case e.wake <- m.ID:
+		default:
+			// The scheduler is busy; the regular interval will catch up.
+		}
+	}
+}
+
+// matchesContainer reports whether ref, a container ID, ID prefix or name,
+// refers to the container of ev.
+func matchesContainer(ref string, ev docker.ContainerEvent) bool {
+	ref = strings.TrimPrefix(ref, "/")
+	if ref == "" {
+		return false
+	}
+	return ref == ev.ContainerID || ref == ev.Name ||
+		(len(ref) >= 12 && strings.HasPrefix(ev.ContainerID, ref))
+}
+
+func (e *Engine) findMonitor(id string) *model.Monitor {
+	for _, m := range e.deps.Store.GetState().Monitors {
+		if m.ID == id {
+			v := m
+			return &v
+		}
+	}
+	return nil
+}
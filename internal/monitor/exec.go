@@ -0,0 +1,72 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/script"
+)
+
+// maxExecMessage caps the command output kept in the check message.
+const maxExecMessage = 1024
+
+// ErrHostCommandsDisabled is reported by exec monitors and script
+// remediation unless host commands are enabled in the config.
+var ErrHostCommandsDisabled = errors.New("host commands are disabled; set allow_host_commands to enable them")
+
+// checkExec runs the command of an exec monitor; exit code 0 is up. The
+// tail of its combined output becomes the check message.
+func (e *Engine) checkExec(ctx context.Context, now time.Time, m model.Monitor) model.CheckResult {
+	if !e.deps.AllowHostCommands {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: ErrHostCommandsDisabled.Error()}
+	}
+	if m.Exec == nil || (len(m.Exec.Command) == 0 && m.Exec.Script == "") {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: "missing command"}
+	}
+	timeout := time.Duration(maxInt(1, m.TimeoutSeconds)) * time.Second
+	env := append([]string{
+		"UPTIME_MONITOR_ID=" + m.ID,
+		"UPTIME_MONITOR_NAME=" + m.Name,
+	}, m.Exec.Env...)
+
+	start := time.Now()
+	var res script.Result
+	var err error
+	if len(m.Exec.Command) > 0 {
+		res, err = script.RunCommand(ctx, m.Exec.Command, env, timeout)
+	} else {
+		res, err = script.Run(ctx, m.Exec.Script, env, timeout)
+	}
+	lat := int(time.Since(start).Milliseconds())
+
+	out := strings.TrimSpace(res.Output)
+	if len(out) > maxExecMessage {
+		out = "..." + out[len(out)-maxExecMessage:]
+	}
+	status := model.StatusUp
+	msg := out
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		status, msg = model.StatusDown, fmt.Sprintf("timed out after %s", timeout)
+		if out != "" {
+			msg += ": " + out
+		}
+	case err != nil:
+		status, msg = model.StatusDown, err.Error()
+		if out != "" {
+			msg += ": " + out
+		}
+	case res.ExitCode != 0:
+		status, msg = model.StatusDown, fmt.Sprintf("exit code %d", res.ExitCode)
+		if out != "" {
+			msg += ": " + out
+		}
+	case msg == "":
+		msg = "exit code 0"
+	}
+	return model.CheckResult{MonitorID: m.ID, Status: status, CheckedAt: now, LatencyMs: lat, Message: msg}
+}
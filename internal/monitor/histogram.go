@@ -0,0 +1,76 @@
+package monitor
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+func (e *Engine) observeLatency(id string, ms int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	h, ok := e.histograms[id]
+	if !ok {
+		v := model.NewLatencyHistogram(e.deps.LatencyBucketsMs)
+		h = &v
+		e.histograms[id] = h
+	}
+	h.Observe(ms)
+}
+
+// LatencyHistograms returns a copy of the per-monitor latency histograms.
+func (e *Engine) LatencyHistograms() map[string]model.LatencyHistogram {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	out := make(map[string]model.LatencyHistogram, len(e.histograms))
+	for id, h := range e.histograms {
+		v := *h
+		v.BucketsMs = append([]int(nil), h.BucketsMs...)
+		v.Counts = append([]uint64(nil), h.Counts...)
+		out[id] = v
+	}
+	return out
+}
+
+func (e *Engine) loadHistograms() {
+	hists, err := e.deps.Store.LoadLatencyHistograms()
+	if err != nil {
+		e.deps.Logger.Error("failed to load latency histograms", zap.Error(err))
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for id, h := range hists {
+		// Bucket layout changed since the histogram was saved; start over.
+		if !h.SameBuckets(e.deps.LatencyBucketsMs) {
+			continue
+		}
+		v := h
+		e.histograms[id] = &v
+	}
+}
+
+func (e *Engine) flushHistograms() {
+	if err := e.deps.Store.SaveLatencyHistograms(e.LatencyHistograms()); err != nil {
+		e.deps.Logger.Error("failed to persist latency histograms", zap.Error(err))
+	}
+}
+
+func (e *Engine) histogramFlushLoop() {
+	e.wg.Add(1)
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+			e.flushHistograms()
+		}
+	}
+}
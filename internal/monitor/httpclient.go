@@ -0,0 +1,174 @@
+package monitor
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/netdial"
+)
+
+type cachedClient struct {
+	fingerprint string
+	client      *http.Client
+}
+
+// httpClient returns the client used for a monitor's checks. Every
+// monitor gets a dedicated transport, cached per monitor so pooled
+// connections and cached DNS lookups are reused between checks;
+// FreshConnection turns keep-alive off. fresh returns a throwaway client
+// with a new, non-keep-alive connection instead.
+func (e *Engine) httpClient(m model.Monitor, fresh bool) (*http.Client, error) {
+	tlsCfg, err := buildTLSConfig(m.HTTP)
+	if err != nil {
+		return nil, err
+	}
+	dialer, err := netdial.New(string(m.IPVersion), m.DNSServer)
+	if err != nil {
+		return nil, err
+	}
+	if fresh {
+		c := newFreshHTTPClient(tlsCfg, dialer)
+		c.CheckRedirect = redirectPolicy(m.HTTP.RedirectLimit())
+		return c, nil
+	}
+
+	fp := clientFingerprint(m)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if c, ok := e.clients[m.ID]; ok && c.fingerprint == fp {
+		return c.client, nil
+	}
+	if c, ok := e.clients[m.ID]; ok {
+		c.client.CloseIdleConnections()
+	}
+	if m.HTTP.DNSCacheSeconds > 0 {
+		dialer = dialer.WithDNSCache(time.Duration(m.HTTP.DNSCacheSeconds) * time.Second)
+	}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.TLSClientConfig = tlsCfg
+	tr.DialContext = dialer.DialContext
+	tr.DisableKeepAlives = m.HTTP.FreshConnection
+	if m.HTTP.MaxIdleConns > 0 {
+		tr.MaxIdleConnsPerHost = m.HTTP.MaxIdleConns
+	}
+	client := &http.Client{Transport: tr, CheckRedirect: redirectPolicy(m.HTTP.RedirectLimit())}
+	e.clients[m.ID] = cachedClient{fingerprint: fp, client: client}
+	return client, nil
+}
+
+func newFreshHTTPClient(tlsCfg *tls.Config, dialer *netdial.Dialer) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:             http.ProxyFromEnvironment,
+			DialContext:       dialer.DialContext,
+			DisableKeepAlives: true,
+			TLSClientConfig:   tlsCfg,
+		},
+	}
+}
+
+// redirectPolicy follows up to max redirects. With max 0 the first response
+// is returned as is, so a 3xx becomes the check's final status; beyond max
+// the request fails, which surfaces redirect loops.
+func redirectPolicy(max int) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if max == 0 {
+			return http.ErrUseLastResponse
+		}
+		if len(via) > max {
+			return fmt.Errorf("stopped after %d redirects", max)
+		}
+		return nil
+	}
+}
+
+// buildTLSConfig returns nil when the monitor uses default TLS settings.
+func buildTLSConfig(h *model.HTTPMonitor) (*tls.Config, error) {
+	if !h.InsecureSkipVerify && h.ClientCertPEM == "" && h.ClientKeyPEM == "" {
+		return nil, nil
+	}
+	cfg := &tls.Config{InsecureSkipVerify: h.InsecureSkipVerify}
+	if h.ClientCertPEM != "" || h.ClientKeyPEM != "" {
+		cert, err := tls.X509KeyPair([]byte(h.ClientCertPEM), []byte(h.ClientKeyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// clientFingerprint identifies the settings a cached client was built
+// with, so that it is replaced when they change.
+func clientFingerprint(m model.Monitor) string {
+	h := m.HTTP
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%t|%s|%s|%s|%s|%d|%t|%d|%d", h.InsecureSkipVerify, h.ClientCertPEM, h.ClientKeyPEM, m.IPVersion, m.DNSServer,
+		h.RedirectLimit(), h.FreshConnection, h.MaxIdleConns, h.DNSCacheSeconds)))
+	return hex.EncodeToString(sum[:])
+}
+
+// phaseTimer measures the phases of a request from its httptrace hooks.
+type phaseTimer struct {
+	mu                            sync.Mutex
+	dnsStart, connStart, tlsStart time.Time
+	wroteRequest                  time.Time
+	phases                        model.HTTPPhases
+}
+
+func (p *phaseTimer) clientTrace() *httptrace.ClientTrace {
+	since := func(start time.Time) int {
+		if start.IsZero() {
+			return 0
+		}
+		return int(time.Since(start).Milliseconds())
+	}
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { p.mark(&p.dnsStart) },
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			p.mu.Lock()
+			p.phases.DNSMs += since(p.dnsStart)
+			p.mu.Unlock()
+		},
+		ConnectStart: func(string, string) { p.mark(&p.connStart) },
+		ConnectDone: func(_, _ string, err error) {
+			if err != nil {
+				return
+			}
+			p.mu.Lock()
+			p.phases.ConnectMs += since(p.connStart)
+			p.mu.Unlock()
+		},
+		TLSHandshakeStart: func() { p.mark(&p.tlsStart) },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			p.mu.Lock()
+			p.phases.TLSMs += since(p.tlsStart)
+			p.mu.Unlock()
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) { p.mark(&p.wroteRequest) },
+		GotFirstResponseByte: func() {
+			p.mu.Lock()
+			p.phases.TTFBMs += since(p.wroteRequest)
+			p.mu.Unlock()
+		},
+	}
+}
+
+func (p *phaseTimer) mark(t *time.Time) {
+	p.mu.Lock()
+	*t = time.Now()
+	p.mu.Unlock()
+}
+
+func (p *phaseTimer) result() *model.HTTPPhases {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := p.phases
+	return &out
+}
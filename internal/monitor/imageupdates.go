@@ -0,0 +1,114 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/docker"
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+func (e *Engine) imageUpdateLoop() {
+	e.wg.Add(1)
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.deps.ImageUpdateInterval)
+	defer ticker.Stop()
+
+	e.checkImageUpdates()
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+			e.checkImageUpdates()
+		}
+	}
+}
+
+// checkImageUpdates compares the image of every monitored container with
+// its registry, replaces the cached results and updates the containers
+// whose monitors opt in to AutoUpdate. Lookups run one at a time to stay
+// clear of registry rate limits.
+func (e *Engine) checkImageUpdates() {
+	out := map[string]map[string]docker.ImageUpdate{}
+	for _, m := range e.deps.Store.GetState().Monitors {
+		if m.Type != model.MonitorTypeContainer || m.Container == nil || m.IsPaused || m.Container.ContainerID == "" {
+			continue
+		}
+		host := m.Container.HostID
+		dc, err := e.deps.Docker.Get(host)
+		if err != nil {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(e.ctx, time.Minute)
+		u, err := dc.CheckImageUpdate(ctx, m.Container.ContainerID)
+		cancel()
+		if u.ContainerID == "" {
+			continue
+		}
+		if err != nil {
+			u.Error = err.Error()
+		}
+		if u.UpdateAvailable && m.Container.AutoUpdate {
+			if newID, ok := e.autoUpdateImage(m, dc, u); ok {
+				u.ContainerID = newID
+				u.LocalDigest = u.RemoteDigest
+				u.UpdateAvailable = false
+			}
+		}
+		if out[host] == nil {
+			out[host] = map[string]docker.ImageUpdate{}
+		}
+		out[host][u.ContainerID] = u
+	}
+
+	e.mu.Lock()
+	e.updates = out
+	e.mu.Unlock()
+}
+
+// autoUpdateImage pulls the newer image and recreates the container,
+// recording the outcome like a remediation attempt.
+func (e *Engine) autoUpdateImage(m model.Monitor, dc *docker.Client, u docker.ImageUpdate) (string, bool) {
+	now := time.Now().UTC()
+	run := remediationRun{
+		action:  model.RemediationUpdateImage,
+		started: time.Now(),
+		result: map[string]any{
+			"image": u.Image,
+			"from":  u.LocalDigest,
+			"to":    u.RemoteDigest,
+		},
+	}
+	ctx, cancel := context.WithTimeout(e.ctx, 10*time.Minute)
+	defer cancel()
+	newID, err := dc.UpdateImage(ctx, u.ContainerID, 10*time.Second)
+	if newID != "" {
+		run.result["containerId"] = newID
+		e.retargetContainer(m.ID, m.Container.ContainerID, newID)
+	}
+	run.err = err
+	e.reportRemediation(now, m, run)
+	return newID, err == nil && newID != ""
+}
+
+// ImageUpdates returns the latest image update check per container ID on a
+// docker host ("" for the local daemon).
+func (e *Engine) ImageUpdates(host string) map[string]docker.ImageUpdate {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	out := make(map[string]docker.ImageUpdate, len(e.updates[host]))
+	for id, u := range e.updates[host] {
+		out[id] = u
+	}
+	return out
+}
+
+// ForgetImageUpdate drops the cached result of a container that was
+// updated or removed outside the checker.
+func (e *Engine) ForgetImageUpdate(host, containerID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.updates[host], containerID)
+}
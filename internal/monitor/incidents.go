@@ -0,0 +1,87 @@
+package monitor
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+// trackIncident opens an incident when a monitor goes down or into a crash
+// loop and resolves it once the monitor is back up. The store is only
+// consulted on transitions, which also picks up incidents left open across a
+// restart.
+func (e *Engine) trackIncident(m model.Monitor, res model.CheckResult, prev model.MonitorStatus) {
+	e.incidentMu.Lock()
+	defer e.incidentMu.Unlock()
+	switch {
+	case isOutage(res.Status) && !isOutage(prev):
+		if _, ok := e.deps.Store.GetOpenIncident(m.ID); ok {
+			return
+		}
+		inc := model.Incident{
+			ID:          NewID(),
+			MonitorID:   m.ID,
+			MonitorName: m.Name,
+			Cause:       res.Message,
+			StartedAt:   res.CheckedAt,
+		}
+		if _, err := e.deps.Store.UpsertIncident(inc); err != nil {
+			e.reportError(m, "failed to open incident", err)
+		}
+
+	case res.Status.Available() && !prev.Available():
+		inc, ok := e.deps.Store.GetOpenIncident(m.ID)
+		if !ok {
+			return
+		}
+		resolved := res.CheckedAt
+		inc.ResolvedAt = &resolved
+		inc.DurationSec = int64(inc.Duration(resolved) / time.Second)
+		if _, err := e.deps.Store.UpsertIncident(inc); err != nil {
+			e.reportError(m, "failed to resolve incident", err)
+		}
+	}
+}
+
+var (
+	ErrIncidentNotFound = errors.New("incident not found")
+	ErrNoOpenIncident   = errors.New("monitor has no open incident")
+)
+
+// UpdateIncident applies update to the incident id and saves it. Updates
+// are serialized with the engine opening and resolving incidents, so that
+// e.g. an acknowledgement cannot undo a resolution saved meanwhile.
+func (e *Engine) UpdateIncident(id string, update func(*model.Incident)) (model.Incident, error) {
+	e.incidentMu.Lock()
+	defer e.incidentMu.Unlock()
+	inc, ok := e.deps.Store.GetIncident(id)
+	if !ok {
+		return model.Incident{}, ErrIncidentNotFound
+	}
+	update(&inc)
+	return e.deps.Store.UpsertIncident(inc)
+}
+
+// UpdateOpenIncident is UpdateIncident for the open incident of a monitor.
+func (e *Engine) UpdateOpenIncident(monitorID string, update func(*model.Incident)) (model.Incident, error) {
+	e.incidentMu.Lock()
+	defer e.incidentMu.Unlock()
+	inc, ok := e.deps.Store.GetOpenIncident(monitorID)
+	if !ok {
+		return model.Incident{}, ErrNoOpenIncident
+	}
+	update(&inc)
+	return e.deps.Store.UpsertIncident(inc)
+}
+
+func isOutage(s model.MonitorStatus) bool {
+	return s == model.StatusDown || s == model.StatusCrashLoop
+}
+
+// outageAcknowledged reports whether an operator acknowledged the monitor's
+// open incident, which mutes reminders and escalations until it recovers.
+func (e *Engine) outageAcknowledged(monitorID string) bool {
+	inc, ok := e.deps.Store.GetOpenIncident(monitorID)
+	return ok && inc.AckedAt != nil
+}
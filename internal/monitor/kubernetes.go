@@ -0,0 +1,208 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/kube"
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+// checkKubernetes checks a pod, the pods selected by a label selector, or a
+// deployment, and applies the monitor's remediation when it is down.
+func (e *Engine) checkKubernetes(ctx context.Context, now time.Time, m model.Monitor) model.CheckResult {
+	down := func(msg string) model.CheckResult {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: msg}
+	}
+	k := m.Kubernetes
+	if k == nil || (k.Name == "" && k.LabelSelector == "") {
+		return down("missing kubernetes object name")
+	}
+	if e.deps.Kube == nil {
+		return down(kube.ErrNotConfigured.Error())
+	}
+	ns := k.Namespace
+	if ns == "" {
+		ns = e.deps.Kube.Namespace
+	}
+
+	switch k.Kind {
+	case model.KubernetesPod, "":
+		pods, err := e.kubePods(ctx, ns, k)
+		if err != nil {
+			return down(err.Error())
+		}
+		if len(pods) == 0 {
+			return down("no pods match " + k.LabelSelector)
+		}
+		notReady := notReadyPods(pods)
+		if len(notReady) == 0 {
+			return model.CheckResult{MonitorID: m.ID, Status: model.StatusUp, CheckedAt: now, Message: podSummary(pods, notReady)}
+		}
+		msg := podSummary(pods, notReady)
+		e.remediateKubernetes(ctx, now, m, ns, notReady, msg)
+		return down(msg)
+
+	case model.KubernetesDeployment:
+		if k.Name == "" {
+			return down("missing deployment name")
+		}
+		d, err := e.deps.Kube.Deployment(ctx, ns, k.Name)
+		if err != nil {
+			return down(err.Error())
+		}
+		if d.Available() {
+			return model.CheckResult{MonitorID: m.ID, Status: model.StatusUp, CheckedAt: now, Message: d.String()}
+		}
+		var notReady []kube.PodState
+		if k.Remediation.Action == model.RemediationDeletePod && d.Selector != "" {
+			if pods, err := e.deps.Kube.Pods(ctx, ns, d.Selector); err == nil {
+				notReady = notReadyPods(pods)
+			}
+		}
+		e.remediateKubernetes(ctx, now, m, ns, notReady, d.String())
+		return down(d.String())
+	}
+	return down("unknown kubernetes kind: " + string(k.Kind))
+}
+
+func (e *Engine) kubePods(ctx context.Context, ns string, k *model.KubernetesMonitor) ([]kube.PodState, error) {
+	if k.Name == "" {
+		return e.deps.Kube.Pods(ctx, ns, k.LabelSelector)
+	}
+	p, err := e.deps.Kube.Pod(ctx, ns, k.Name)
+	if errors.Is(err, kube.ErrNotFound) {
+		return nil, fmt.Errorf("pod %s/%s not found", ns, k.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []kube.PodState{p}, nil
+}
+
+// remediateKubernetes deletes the pods that are not ready, restarts the
+// deployment or runs a webhook or script action, subject to the monitor's
+// attempt limit and cooldown.
+func (e *Engine) remediateKubernetes(ctx context.Context, now time.Time, m model.Monitor, ns string, notReady []kube.PodState, message string) {
+	p := m.Kubernetes.Remediation
+	if kubernetesActionApplies(m.Kubernetes, p.Action, notReady) != nil || !e.claimRemediation(m.ID, p, now) {
+		return
+	}
+	e.reportRemediation(now, m, e.kubernetesRemediation(ctx, now, m, ns, notReady, message))
+}
+
+// manualKubernetesRemediation looks up the monitor's pods and runs its
+// remediation action regardless of the current status.
+func (e *Engine) manualKubernetesRemediation(ctx context.Context, now time.Time, m model.Monitor) remediationRun {
+	k := m.Kubernetes
+	run := remediationRun{action: k.Remediation.Action, started: time.Now()}
+	if e.deps.Kube == nil {
+		run.err = kube.ErrNotConfigured
+		return run
+	}
+	ns := k.Namespace
+	if ns == "" {
+		ns = e.deps.Kube.Namespace
+	}
+	var notReady []kube.PodState
+	var message string
+	if k.Kind == model.KubernetesDeployment {
+		d, err := e.deps.Kube.Deployment(ctx, ns, k.Name)
+		if err != nil {
+			run.err = err
+			return run
+		}
+		message = d.String()
+		if d.Selector != "" {
+			pods, err := e.deps.Kube.Pods(ctx, ns, d.Selector)
+			if err != nil {
+				run.err = err
+				return run
+			}
+			notReady = notReadyPods(pods)
+		}
+	} else {
+		pods, err := e.kubePods(ctx, ns, k)
+		if err != nil {
+			run.err = err
+			return run
+		}
+		notReady = notReadyPods(pods)
+		message = podSummary(pods, notReady)
+	}
+	if err := kubernetesActionApplies(k, k.Remediation.Action, notReady); err != nil {
+		run.err = err
+		return run
+	}
+	return e.kubernetesRemediation(ctx, now, m, ns, notReady, message)
+}
+
+// kubernetesActionApplies returns why action has nothing to act on, or nil.
+func kubernetesActionApplies(k *model.KubernetesMonitor, action model.RemediationAction, notReady []kube.PodState) error {
+	switch action {
+	case model.RemediationDeletePod:
+		if len(notReady) == 0 {
+			return errors.New("all pods are ready")
+		}
+	case model.RemediationRolloutRestart:
+		if k.Kind != model.KubernetesDeployment {
+			return errors.New("rollout_restart needs a deployment")
+		}
+	case model.RemediationWebhook, model.RemediationScript:
+	default:
+		return fmt.Errorf("unsupported remediation action %q", action)
+	}
+	return nil
+}
+
+func (e *Engine) kubernetesRemediation(ctx context.Context, now time.Time, m model.Monitor, ns string, notReady []kube.PodState, message string) remediationRun {
+	p := m.Kubernetes.Remediation
+	run := remediationRun{action: p.Action, started: time.Now()}
+	switch p.Action {
+	case model.RemediationRolloutRestart:
+		run.err = e.deps.Kube.RolloutRestart(ctx, ns, m.Kubernetes.Name, now)
+	case model.RemediationDeletePod:
+		deleted := []string{}
+		for _, pod := range notReady {
+			err := e.deps.Kube.DeletePod(ctx, ns, pod.Name)
+			if err != nil && !errors.Is(err, kube.ErrNotFound) {
+				run.err = errors.Join(run.err, err)
+				continue
+			}
+			deleted = append(deleted, pod.Name)
+		}
+		run.result = map[string]any{"pods": deleted}
+	default:
+		run.result, run.err = e.externalRemediation(e.ctx, m, p, message)
+	}
+	return run
+}
+
+func notReadyPods(pods []kube.PodState) []kube.PodState {
+	var out []kube.PodState
+	for _, p := range pods {
+		if !p.Ready {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// podSummary renders e.g. "2/3 ready; web-7d9f: Running (CrashLoopBackOff)".
+func podSummary(pods, notReady []kube.PodState) string {
+	if len(pods) == 1 {
+		return pods[0].String()
+	}
+	msg := fmt.Sprintf("%d/%d ready", len(pods)-len(notReady), len(pods))
+	if len(notReady) > 0 {
+		parts := make([]string, len(notReady))
+		for i, p := range notReady {
+			parts[i] = p.String()
+		}
+		msg += "; " + strings.Join(parts, "; ")
+	}
+	return msg
+}
@@ -0,0 +1,65 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/mailprobe"
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/netdial"
+)
+
+// checkMail probes an SMTP or IMAP server. The latency is the time to the
+// greeting banner; the message carries the banner and, when TLS was used,
+// the certificate's expiry.
+func checkMail(ctx context.Context, now time.Time, m model.Monitor) model.CheckResult {
+	if m.Mail == nil || m.Mail.Host == "" {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: "missing host"}
+	}
+	c := m.Mail
+	d, err := netdial.New(string(m.IPVersion), m.DNSServer)
+	if err != nil {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: err.Error()}
+	}
+	res, err := mailprobe.Probe(ctx, mailprobe.Options{
+		Protocol:           mailprobe.Protocol(c.Protocol),
+		Host:               c.Host,
+		Port:               c.Port,
+		TLS:                c.TLS,
+		StartTLS:           c.StartTLS,
+		Username:           c.Username,
+		Password:           c.Password,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+		Dialer:             d,
+	})
+	out := model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, LatencyMs: int(res.BannerLatency.Milliseconds())}
+	if err != nil {
+		out.Message = err.Error()
+		return out
+	}
+	out.Message = res.Banner
+	if cert := res.Cert; cert != nil {
+		left := cert.NotAfter.Sub(now)
+		days := int(left.Hours() / 24)
+		out.Message += fmt.Sprintf("; certificate expires %s (%d days)", cert.NotAfter.UTC().Format("2006-01-02"), days)
+		if left <= 0 {
+			out.Message = "certificate expired on " + cert.NotAfter.UTC().Format("2006-01-02")
+			return out
+		}
+		if c.CertExpiryDays > 0 && days < c.CertExpiryDays {
+			return out
+		}
+	}
+	out.Status = model.StatusUp
+	return out
+}
+
+// mailTarget describes a mail monitor as protocol://host[:port].
+func mailTarget(c *model.MailMonitor) string {
+	if c.Port == 0 {
+		return string(c.Protocol) + "://" + c.Host
+	}
+	return string(c.Protocol) + "://" + c.Host + ":" + strconv.Itoa(c.Port)
+}
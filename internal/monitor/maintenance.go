@@ -0,0 +1,37 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+// maintenanceMonitors returns the IDs of monitors covered by a maintenance
+// window that is active at now.
+func (e *Engine) maintenanceMonitors(now time.Time) map[string]bool {
+	var out map[string]bool
+	for _, w := range e.deps.Store.GetMaintenanceWindows() {
+		if !w.Active(now) {
+			continue
+		}
+		if out == nil {
+			out = map[string]bool{}
+		}
+		for _, id := range w.MonitorIDs {
+			out[id] = true
+		}
+	}
+	return out
+}
+
+// enterMaintenance marks m as under maintenance and forgets any outage in
+// progress, so no reminders or escalations fire for it once the window ends.
+func (e *Engine) enterMaintenance(m model.Monitor, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lastStatus[m.ID] = model.StatusMaintenance
+	e.lastCheck[m.ID] = now
+	delete(e.alerts, m.ID)
+	delete(e.downSince, m.ID)
+	delete(e.escalated, m.ID)
+}
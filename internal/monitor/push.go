@@ -0,0 +1,131 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+// ErrUnknownPushToken is returned by Push when no push monitor uses the token.
+var ErrUnknownPushToken = errors.New("unknown push token")
+
+// heartbeat is the last push received for a monitor. Heartbeats are kept in
+// memory only, so after a restart every push monitor gets a full grace
+// period before it can go down.
+type heartbeat struct {
+	at      time.Time
+	status  model.MonitorStatus
+	message string
+	pushed  bool // false for the baseline taken before the first push
+}
+
+// Push records a heartbeat for the push monitor with the given token and
+// checks the monitor right away. A job can report its own failure with
+// status down; message becomes the check message.
+func (e *Engine) Push(token string, status model.MonitorStatus, message string) (model.Monitor, error) {
+	var found *model.Monitor
+	for _, m := range e.deps.Store.GetState().Monitors {
+		if m.Type == model.MonitorTypePush && m.Push != nil && m.Push.Token != "" && m.Push.Token == token {
+			v := m
+			found = &v
+			break
+		}
+	}
+	if found == nil {
+		return model.Monitor{}, ErrUnknownPushToken
+	}
+	if status != model.StatusDown {
+		status = model.StatusUp
+	}
+
+	e.mu.Lock()
+	e.heartbeats[found.ID] = heartbeat{at: time.Now().UTC(), status: status, message: message, pushed: true}
+	e.mu.Unlock()
+
+	select {
+	case e.wake <- found.ID:
+	default:
+		// The scheduler is busy; the regular interval will catch up.
+	}
+	return *found, nil
+}
+
+// checkPush reports the last heartbeat while it is within the deadline and
+// down once the deadline has passed without a new one.
+func (e *Engine) checkPush(now time.Time, m model.Monitor) model.CheckResult {
+	if m.Push == nil || m.Push.Token == "" {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: "missing push token"}
+	}
+
+	e.mu.Lock()
+	hb, ok := e.heartbeats[m.ID]
+	if !ok {
+		hb = heartbeat{at: now}
+		e.heartbeats[m.ID] = hb
+	}
+	e.mu.Unlock()
+
+	if m.Push.Cron != "" {
+		return checkPushSchedule(now, m, hb)
+	}
+	deadline := time.Duration(maxInt(5, m.IntervalSeconds)+maxInt(0, m.Push.GraceSeconds)) * time.Second
+	since := now.Sub(hb.at)
+	switch {
+	case since > deadline && hb.pushed:
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now,
+			Message: fmt.Sprintf("no heartbeat for %s", since.Round(time.Second))}
+	case since > deadline:
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now,
+			Message: fmt.Sprintf("no heartbeat received within %s", deadline)}
+	case !hb.pushed:
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusUnknown, CheckedAt: now, Message: "waiting for first heartbeat"}
+	}
+	return heartbeatResult(now, m, hb)
+}
+
+// checkPushSchedule reports a cron push monitor down when the latest run
+// whose grace period has passed sent no heartbeat. Runs scheduled before
+// the baseline taken on the first check are not judged.
+func checkPushSchedule(now time.Time, m model.Monitor, hb heartbeat) model.CheckResult {
+	sched, loc, err := m.Push.Schedule()
+	if err != nil {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: err.Error()}
+	}
+	due, ok := sched.Prev(now.Add(-m.Push.Grace()).In(loc))
+	switch {
+	case ok && hb.at.Before(due):
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now,
+			Message: "missed scheduled run at " + due.Format("2006-01-02 15:04 MST")}
+	case !hb.pushed:
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusUnknown, CheckedAt: now, Message: "waiting for first heartbeat"}
+	}
+	return heartbeatResult(now, m, hb)
+}
+
+func heartbeatResult(now time.Time, m model.Monitor, hb heartbeat) model.CheckResult {
+	msg := hb.message
+	if msg == "" {
+		msg = fmt.Sprintf("heartbeat %s ago", now.Sub(hb.at).Round(time.Second))
+	}
+	return model.CheckResult{MonitorID: m.ID, Status: hb.status, CheckedAt: now, Message: msg}
+}
+
+// nextPushCheck returns when the next run of a cron push monitor becomes
+// overdue, so the scheduler can check it right then.
+func nextPushCheck(m model.Monitor, now time.Time) (time.Time, bool) {
+	if m.Type != model.MonitorTypePush || m.Push == nil || m.Push.Cron == "" {
+		return time.Time{}, false
+	}
+	sched, loc, err := m.Push.Schedule()
+	if err != nil {
+		return time.Time{}, false
+	}
+	grace := m.Push.Grace()
+	next, ok := sched.Next(now.Add(-grace).In(loc))
+	if !ok {
+		return time.Time{}, false
+	}
+	return next.Add(grace), true
+}
@@ -0,0 +1,113 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/notify"
+)
+
+// maxQueuedPerChannel bounds the alerts held for one channel during quiet hours.
+const maxQueuedPerChannel = 100
+
+// isCritical reports whether an alert must bypass quiet hours: a monitor
+// going down or the Docker daemon becoming unreachable.
+func isCritical(p notify.Payload) bool {
+	switch p.Type {
+	case string(model.EventDockerUnreachable):
+		return true
+	case string(model.EventStatusChanged):
+		current, _ := p.Data["current"].(string)
+		return current == string(model.StatusDown)
+	}
+	return false
+}
+
+// holdForQuietHours reports whether payload must not be sent to n right now.
+// Non-critical alerts inside quiet hours are dropped, or queued until the
+// window ends when the channel's QuietMode is "queue".
+func (e *Engine) holdForQuietHours(n model.Notification, payload notify.Payload) bool {
+	if isCritical(payload) || !n.InQuietHours(time.Now()) {
+		return false
+	}
+	if n.QuietMode != model.QuietModeQueue {
+		e.deps.Logger.Info("notification suppressed by quiet hours",
+			zap.String("notification", n.Name),
+			zap.String("monitor_id", payload.MonitorID),
+			zap.String("event", payload.Type),
+		)
+		return true
+	}
+
+	e.mu.Lock()
+	q := append(e.quietQueue[n.ID], payload)
+	if len(q) > maxQueuedPerChannel {
+		q = q[len(q)-maxQueuedPerChannel:]
+	}
+	e.quietQueue[n.ID] = q
+	e.mu.Unlock()
+	return true
+}
+
+func (e *Engine) quietQueueLoop() {
+	e.wg.Add(1)
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+			e.flushQuietQueue()
+		}
+	}
+}
+
+// flushQuietQueue delivers queued alerts for channels whose quiet hours have
+// ended.
+func (e *Engine) flushQuietQueue() {
+	e.mu.RLock()
+	empty := len(e.quietQueue) == 0
+	e.mu.RUnlock()
+	if empty {
+		return
+	}
+
+	now := time.Now()
+	notifs := e.deps.Store.GetNotifications()
+
+	// Drop alerts queued for channels that have since been deleted.
+	known := make(map[string]bool, len(notifs))
+	for _, n := range notifs {
+		known[n.ID] = true
+	}
+	e.mu.Lock()
+	for id := range e.quietQueue {
+		if !known[id] {
+			delete(e.quietQueue, id)
+		}
+	}
+	e.mu.Unlock()
+
+	for _, n := range notifs {
+		if n.InQuietHours(now) {
+			continue
+		}
+		e.mu.Lock()
+		queued := e.quietQueue[n.ID]
+		delete(e.quietQueue, n.ID)
+		e.mu.Unlock()
+
+		for _, p := range queued {
+			ctx, cancel := context.WithTimeout(e.ctx, 15*time.Second)
+			e.deliver(ctx, n.ID, webhookFor(n), p)
+			cancel()
+		}
+	}
+}
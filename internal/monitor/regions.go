@@ -0,0 +1,134 @@
+package monitor
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/notify"
+)
+
+type regionReport struct {
+	result   model.RegionResult
+	received time.Time
+}
+
+// recordRegion keeps the latest result of one region of a multi-region
+// monitor.
+func (e *Engine) recordRegion(monitorID, region string, received time.Time, res model.CheckResult) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.regions[monitorID] == nil {
+		e.regions[monitorID] = map[string]regionReport{}
+	}
+	e.regions[monitorID][region] = regionReport{
+		result: model.RegionResult{
+			Region:    region,
+			Status:    res.Status,
+			CheckedAt: res.CheckedAt,
+			LatencyMs: res.LatencyMs,
+			Message:   res.Message,
+		},
+		received: received,
+	}
+}
+
+// checkRegions runs the local check if the server is one of the regions of
+// m, then records and returns the combination of the latest result of every
+// region. It returns false while no region counts.
+func (e *Engine) checkRegions(now time.Time, m model.Monitor) (model.CheckResult, bool) {
+	var logs *notify.DockerLogsAttachment
+	if slices.Contains(m.Regions.Agents, model.LocalRegion) {
+		var res model.CheckResult
+		res, logs = e.runCheck(now, m)
+		e.recordRegion(m.ID, model.LocalRegion, now, res)
+	}
+	res, ok := e.aggregateRegions(now, m)
+	if ok {
+		e.handleResult(now, m, res, logs)
+	}
+	return res, ok
+}
+
+// aggregateRegions combines the latest region results of m under its
+// policy. A region that has not reported for agentStaleAfterFor counts as
+// down; one that has not reported yet is left out until that long after
+// the first aggregation. It returns false while no region counts.
+func (e *Engine) aggregateRegions(now time.Time, m model.Monitor) (model.CheckResult, bool) {
+	stale := agentStaleAfterFor(m)
+	e.mu.Lock()
+	since, ok := e.agentReports[m.ID]
+	if !ok {
+		since = now
+		e.agentReports[m.ID] = now
+	}
+	reports := e.regions[m.ID]
+	var regions []model.RegionResult
+	for _, name := range m.Regions.Agents {
+		r, ok := reports[name]
+		switch {
+		case ok && now.Sub(r.received) < stale:
+			regions = append(regions, r.result)
+		case ok:
+			regions = append(regions, model.RegionResult{
+				Region:    name,
+				Status:    model.StatusDown,
+				CheckedAt: r.result.CheckedAt,
+				Message:   "no report since " + r.received.UTC().Format(time.RFC3339),
+			})
+		case now.Sub(since) >= stale:
+			regions = append(regions, model.RegionResult{
+				Region:  name,
+				Status:  model.StatusDown,
+				Message: "no report since " + since.UTC().Format(time.RFC3339),
+			})
+		}
+	}
+	e.mu.Unlock()
+	if len(regions) == 0 {
+		return model.CheckResult{}, false
+	}
+
+	var failures []string
+	latency, up, degraded := 0, 0, 0
+	for _, r := range regions {
+		if r.Status.Available() {
+			latency += r.LatencyMs
+			up++
+			if r.Status == model.StatusDegraded {
+				degraded++
+			}
+			continue
+		}
+		failures = append(failures, r.Region+": "+r.Message)
+	}
+	down := len(failures)
+	res := model.CheckResult{
+		MonitorID: m.ID,
+		Status:    model.StatusUp,
+		CheckedAt: now,
+		Regions:   regions,
+	}
+	if up > 0 {
+		res.LatencyMs = latency / up
+	}
+	failing := down*2 > len(regions)
+	if m.Regions.Policy == model.AggregateAny {
+		failing = down > 0
+	}
+	if !failing && degraded > 0 {
+		res.Status = model.StatusDegraded
+	}
+	switch {
+	case failing:
+		res.Status = model.StatusDown
+		res.Message = fmt.Sprintf("down in %d/%d regions: %s", down, len(regions), strings.Join(failures, "; "))
+	case down > 0:
+		res.Message = fmt.Sprintf("up in %d/%d regions; %s", up, len(regions), strings.Join(failures, "; "))
+	default:
+		res.Message = fmt.Sprintf("up in %d/%d regions", up, len(regions))
+	}
+	return res, true
+}
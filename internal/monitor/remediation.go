@@ -0,0 +1,267 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lsy88/uptime-chopper/internal/docker"
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/notify"
+	"github.com/lsy88/uptime-chopper/internal/redact"
+	"github.com/lsy88/uptime-chopper/internal/script"
+)
+
+// ErrNoRemediation is returned by Remediate for monitors without a
+// remediation policy.
+var ErrNoRemediation = errors.New("monitor has no remediation action")
+
+// remediationRun is the outcome of one remediation action.
+type remediationRun struct {
+	action  model.RemediationAction
+	manual  bool
+	started time.Time
+	result  map[string]any
+	err     error
+}
+
+// remediationPolicy returns the policy that applies to m: the container or
+// kubernetes policy for those types, the monitor level one otherwise.
+func remediationPolicy(m model.Monitor) (model.RemediationPolicy, bool) {
+	var p model.RemediationPolicy
+	switch {
+	case m.Type == model.MonitorTypeContainer:
+		if m.Container == nil {
+			return p, false
+		}
+		p = m.Container.Remediation
+	case m.Type == model.MonitorTypeKubernetes:
+		if m.Kubernetes == nil {
+			return p, false
+		}
+		p = m.Kubernetes.Remediation
+	case m.Remediation != nil:
+		p = *m.Remediation
+	}
+	return p, p.Action != "" && p.Action != model.RemediationNone
+}
+
+// tryMonitorRemediation applies the monitor level policy of a monitor that
+// was found down. Container and kubernetes monitors use their own policy.
+func (e *Engine) tryMonitorRemediation(now time.Time, m model.Monitor, res model.CheckResult) {
+	if m.Remediation == nil || m.Type == model.MonitorTypeContainer || m.Type == model.MonitorTypeKubernetes {
+		return
+	}
+	p := *m.Remediation
+	if p.Action != model.RemediationWebhook && p.Action != model.RemediationScript {
+		return
+	}
+	if !e.claimRemediation(m.ID, p, now) {
+		return
+	}
+	run := remediationRun{action: p.Action, started: time.Now()}
+	run.result, run.err = e.externalRemediation(e.ctx, m, p, res.Message)
+	e.reportRemediation(now, m, run)
+}
+
+// Remediate runs the monitor's remediation action right away, bypassing the
+// attempt limit and cooldown, and returns the recorded attempt.
+func (e *Engine) Remediate(ctx context.Context, m model.Monitor) (model.RemediationAttempt, error) {
+	p, ok := remediationPolicy(m)
+	if !ok {
+		return model.RemediationAttempt{}, ErrNoRemediation
+	}
+	now := time.Now().UTC()
+	var run remediationRun
+	switch m.Type {
+	case model.MonitorTypeContainer:
+		var state docker.StateInfo
+		if dc, err := e.deps.Docker.Get(m.Container.HostID); err == nil {
+			state, _ = dc.ContainerState(ctx, m.Container.ContainerID)
+		}
+		run, ok = e.containerRemediation(m, p, state)
+		if !ok {
+			return model.RemediationAttempt{}, fmt.Errorf("unsupported remediation action %q", p.Action)
+		}
+	case model.MonitorTypeKubernetes:
+		run = e.manualKubernetesRemediation(ctx, now, m)
+	default:
+		run = remediationRun{action: p.Action, started: time.Now()}
+		run.result, run.err = e.externalRemediation(ctx, m, p, "manual remediation")
+	}
+	run.manual = true
+	return e.reportRemediation(now, m, run), nil
+}
+
+// reportRemediation logs and records the outcome of a remediation action and
+// announces it on the monitor's webhooks, together with action specific
+// results such as an exec command's exit code and output.
+func (e *Engine) reportRemediation(now time.Time, m model.Monitor, run remediationRun) model.RemediationAttempt {
+	if run.err == nil {
+		e.deps.Logger.Info("remediation action success",
+			zap.String("monitor_id", m.ID),
+			zap.String("action", string(run.action)),
+			zap.Bool("manual", run.manual),
+		)
+	} else {
+		e.deps.Logger.Error("remediation action failed",
+			zap.String("monitor_id", m.ID),
+			zap.String("action", string(run.action)),
+			zap.Bool("manual", run.manual),
+			zap.Error(run.err),
+		)
+	}
+
+	a := model.RemediationAttempt{
+		MonitorID:  m.ID,
+		Action:     run.action,
+		Attempt:    e.getAttempts(m.ID),
+		Manual:     run.manual,
+		Success:    run.err == nil,
+		Result:     run.result,
+		DurationMs: time.Since(run.started).Milliseconds(),
+		At:         now,
+	}
+	if run.err != nil {
+		a.Error = run.err.Error()
+	}
+	if stored, err := e.deps.Store.AddRemediationAttempt(a); err != nil {
+		e.deps.Logger.Warn("failed to record remediation attempt", zap.String("monitor_id", m.ID), zap.Error(err))
+	} else {
+		a = stored
+	}
+
+	data := map[string]any{
+		"action":     string(a.Action),
+		"attempt":    a.Attempt,
+		"success":    a.Success,
+		"durationMs": a.DurationMs,
+	}
+	if a.Manual {
+		data["manual"] = true
+	}
+	for k, v := range a.Result {
+		data[k] = v
+	}
+	if a.Error != "" {
+		data["error"] = a.Error
+	}
+	e.recordEvent(model.EventRemediated, m.ID, now, data)
+	ctx, cancel := context.WithTimeout(e.ctx, 15*time.Second)
+	defer cancel()
+	e.emitWebhookBestEffort(ctx, m, notify.Payload{
+		Type:      string(model.EventRemediated),
+		MonitorID: m.ID,
+		At:        now,
+		Data:      data,
+	})
+	return a
+}
+
+// externalRemediation runs a webhook or script action and returns its
+// results for the remediation event.
+func (e *Engine) externalRemediation(ctx context.Context, m model.Monitor, p model.RemediationPolicy, message string) (map[string]any, error) {
+	timeout := 30 * time.Second
+	if p.TimeoutSeconds > 0 {
+		timeout = time.Duration(p.TimeoutSeconds) * time.Second
+	}
+	result := map[string]any{}
+
+	switch p.Action {
+	case model.RemediationScript:
+		if !e.deps.AllowHostCommands {
+			return result, ErrHostCommandsDisabled
+		}
+		if p.Script == "" {
+			return result, errors.New("script remediation without script")
+		}
+		env := []string{
+			"UPTIME_MONITOR_ID=" + m.ID,
+			"UPTIME_MONITOR_NAME=" + m.Name,
+			"UPTIME_MONITOR_TARGET=" + monitorTarget(m),
+			"UPTIME_MESSAGE=" + message,
+		}
+		res, err := script.Run(ctx, p.Script, env, timeout)
+		result["exitCode"] = res.ExitCode
+		result["output"] = res.Output
+		if err == nil && res.ExitCode != 0 {
+			err = fmt.Errorf("script exited with code %d", res.ExitCode)
+		}
+		return result, err
+
+	case model.RemediationWebhook:
+		if p.Webhook == nil || p.Webhook.URL == "" {
+			return result, errors.New("webhook remediation without url")
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		wh, x := e.expandRemediationSecrets(*p.Webhook)
+		if err := x.Err(); err != nil {
+			return result, err
+		}
+		status, body, err := callRemediationWebhook(ctx, e.notifier.Load().Client(), wh, m, message)
+		if status != 0 {
+			result["statusCode"] = status
+		}
+		if body != "" {
+			result["output"] = x.Mask(body)
+		}
+		if err != nil {
+			err = errors.New(redact.String(x.Mask(err.Error())))
+		}
+		return result, err
+	}
+	return result, fmt.Errorf("unsupported remediation action %q", p.Action)
+}
+
+// callRemediationWebhook sends the webhook action's request with client,
+// the notification client, whose timeout also bounds it.
+func callRemediationWebhook(ctx context.Context, client *http.Client, w model.RemediationRequest, m model.Monitor, message string) (int, string, error) {
+	method := strings.ToUpper(w.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+	body := w.Body
+	contentType := ""
+	if body == "" && method != http.MethodGet {
+		b, _ := json.Marshal(map[string]any{
+			"monitorId":   m.ID,
+			"monitorName": m.Name,
+			"target":      monitorTarget(m),
+			"message":     message,
+		})
+		body = string(b)
+		contentType = "application/json"
+	}
+	var rd io.Reader
+	if body != "" {
+		rd = strings.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, w.URL, rd)
+	if err != nil {
+		return 0, "", err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	out, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(out), fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return resp.StatusCode, string(out), nil
+}
@@ -0,0 +1,173 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/docker"
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+const (
+	// statsInterval is how often resource usage of running containers is
+	// sampled.
+	statsInterval = 15 * time.Second
+	// statsConcurrency bounds parallel stats requests; each one blocks for
+	// about a second on the daemon.
+	statsConcurrency = 4
+)
+
+func (e *Engine) statsLoop() {
+	e.wg.Add(1)
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(statsInterval)
+	defer ticker.Stop()
+
+	e.collectStats()
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+			e.collectStats()
+		}
+	}
+}
+
+// collectStats samples every running container on every docker host and
+// replaces the cached stats, so stopped or removed containers drop out.
+func (e *Engine) collectStats() {
+	ctx, cancel := context.WithTimeout(e.ctx, statsInterval)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, statsConcurrency)
+	out := map[string]map[string]containerStats{}
+	for _, h := range e.deps.Docker.List() {
+		dc, err := e.deps.Docker.Get(h.ID)
+		if err != nil {
+			continue
+		}
+		cs, err := dc.ListContainers(ctx)
+		if err != nil {
+			continue
+		}
+		out[h.ID] = map[string]containerStats{}
+		for _, c := range cs {
+			if c.State != "running" {
+				continue
+			}
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(host string, c docker.ContainerSummary) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				s, err := dc.ContainerStats(ctx, c.ID)
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				out[host][c.ID] = containerStats{name: c.Name, Stats: s}
+				mu.Unlock()
+			}(h.ID, c)
+		}
+	}
+	wg.Wait()
+
+	e.mu.Lock()
+	e.stats = out
+	e.mu.Unlock()
+}
+
+type containerStats struct {
+	docker.Stats
+	name string
+}
+
+// ContainerStats returns the latest resource usage sample per container ID
+// on a docker host ("" for the local daemon).
+func (e *Engine) ContainerStats(host string) map[string]docker.Stats {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	out := make(map[string]docker.Stats, len(e.stats[host]))
+	for id, s := range e.stats[host] {
+		out[id] = s.Stats
+	}
+	return out
+}
+
+// statsFor looks up the cached sample for a container referenced by ID, ID
+// prefix or name, as monitors may use any of them.
+func (e *Engine) statsFor(host, ref string) (docker.Stats, bool) {
+	ref = strings.TrimPrefix(ref, "/")
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	stats := e.stats[host]
+	if s, ok := stats[ref]; ok {
+		return s.Stats, true
+	}
+	for id, s := range stats {
+		if s.name == ref || (len(ref) >= 12 && strings.HasPrefix(id, ref)) {
+			return s.Stats, true
+		}
+	}
+	return docker.Stats{}, false
+}
+
+// applyResourceUsage attaches the container's latest usage to res and turns
+// an up result into down once a threshold has been exceeded for the
+// configured duration. Resource breaches do not trigger remediation.
+func (e *Engine) applyResourceUsage(m model.Monitor, res *model.CheckResult) {
+	s, ok := e.statsFor(m.Container.HostID, m.Container.ContainerID)
+	if !ok {
+		return
+	}
+	cpu, mem := s.CPUPercent, s.MemoryPercent
+	res.CPUPercent, res.MemoryPercent = &cpu, &mem
+
+	t := m.Container.Resources
+	if t == nil {
+		e.clearBreach(m.ID)
+		return
+	}
+	var reasons []string
+	if t.CPUPercent > 0 && cpu > t.CPUPercent {
+		reasons = append(reasons, fmt.Sprintf("cpu %.1f%% > %.0f%%", cpu, t.CPUPercent))
+	}
+	if t.MemoryPercent > 0 && mem > t.MemoryPercent {
+		reasons = append(reasons, fmt.Sprintf("memory %.1f%% > %.0f%%", mem, t.MemoryPercent))
+	}
+	if len(reasons) == 0 {
+		e.clearBreach(m.ID)
+		return
+	}
+
+	e.mu.Lock()
+	since, ok := e.breachSince[m.ID]
+	if !ok {
+		since = res.CheckedAt
+		e.breachSince[m.ID] = since
+	}
+	e.mu.Unlock()
+
+	sustained := time.Duration(t.ForMinutes) * time.Minute
+	if res.CheckedAt.Sub(since) < sustained {
+		return
+	}
+	res.Status = model.StatusDown
+	res.Message = strings.Join(reasons, ", ")
+	if sustained > 0 {
+		res.Message += fmt.Sprintf(" for %s", sustained)
+	}
+}
+
+func (e *Engine) clearBreach(id string) {
+	e.mu.Lock()
+	delete(e.breachSince, id)
+	e.mu.Unlock()
+}
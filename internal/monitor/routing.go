@@ -0,0 +1,119 @@
+package monitor
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/notify"
+)
+
+// channelsFor resolves the notification channels for a payload: the
+// monitor's own NotifyWebhookIDs plus the channels of its routing policy,
+// unless the policy filters the event out or is in quiet hours.
+func (e *Engine) channelsFor(m model.Monitor, payload notify.Payload) []string {
+	ids := append([]string(nil), m.NotifyWebhookIDs...)
+	p, ok := e.routingPolicy(m.RoutingPolicyID)
+	if !ok {
+		return ids
+	}
+	if !p.Accepts(payload.Type) {
+		return ids
+	}
+	if p.QuietHours != nil && p.QuietHours.Active(time.Now()) {
+		return ids
+	}
+	return appendUnique(ids, p.ChannelIDs...)
+}
+
+func (e *Engine) routingPolicy(id string) (model.RoutingPolicy, bool) {
+	if id == "" {
+		return model.RoutingPolicy{}, false
+	}
+	for _, p := range e.deps.Store.GetRoutingPolicies() {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return model.RoutingPolicy{}, false
+}
+
+// trackEscalation fires the routing policy's escalation steps, each once,
+// while a monitor stays down.
+func (e *Engine) trackEscalation(ctx context.Context, m model.Monitor, res model.CheckResult, prev model.MonitorStatus) {
+	if res.Status != model.StatusDown {
+		e.mu.Lock()
+		delete(e.downSince, m.ID)
+		delete(e.escalated, m.ID)
+		e.mu.Unlock()
+		return
+	}
+
+	e.mu.Lock()
+	since, ok := e.downSince[m.ID]
+	if !ok || prev != model.StatusDown {
+		since = res.CheckedAt
+		e.downSince[m.ID] = since
+		e.escalated[m.ID] = 0
+	}
+	fired := e.escalated[m.ID]
+	e.mu.Unlock()
+
+	p, ok := e.routingPolicy(m.RoutingPolicyID)
+	if !ok || len(p.Escalation) == 0 {
+		return
+	}
+	if p.QuietHours != nil && p.QuietHours.Active(time.Now()) {
+		return
+	}
+
+	steps := append([]model.EscalationStep(nil), p.Escalation...)
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].AfterMinutes < steps[j].AfterMinutes })
+
+	downFor := res.CheckedAt.Sub(since)
+	if fired < len(steps) && downFor >= time.Duration(steps[fired].AfterMinutes)*time.Minute && e.outageAcknowledged(m.ID) {
+		return
+	}
+	for i := fired; i < len(steps); i++ {
+		if downFor < time.Duration(steps[i].AfterMinutes)*time.Minute {
+			break
+		}
+		e.deps.Logger.Info("escalating alert",
+			zap.String("monitor_id", m.ID),
+			zap.String("policy_id", p.ID),
+			zap.Int("step", i+1),
+		)
+		e.sendToChannels(ctx, steps[i].ChannelIDs, notify.Payload{
+			Type:      string(model.EventStatusChanged),
+			MonitorID: m.ID,
+			At:        res.CheckedAt,
+			Data: map[string]any{
+				"monitorName": m.Name,
+				"target":      monitorTarget(m),
+				"current":     string(res.Status),
+				"message":     res.Message,
+				"escalation":  i + 1,
+			},
+		})
+		e.mu.Lock()
+		e.escalated[m.ID] = i + 1
+		e.mu.Unlock()
+	}
+}
+
+func appendUnique(dst []string, src ...string) []string {
+	seen := make(map[string]bool, len(dst))
+	for _, v := range dst {
+		seen[v] = true
+	}
+	for _, v := range src {
+		if !seen[v] {
+			seen[v] = true
+			dst = append(dst, v)
+		}
+	}
+	return dst
+}
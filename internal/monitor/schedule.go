@@ -0,0 +1,52 @@
+package monitor
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+// maxInitialSpread bounds how long the first check of a monitor is delayed
+// after startup, so long intervals do not leave monitors unknown for long.
+const maxInitialSpread = 30 * time.Second
+
+// checkInterval returns the interval between scheduled checks of m.
+func checkInterval(m model.Monitor) time.Duration {
+	return time.Duration(maxInt(5, m.IntervalSeconds)) * time.Second
+}
+
+// phase returns the fixed offset of the monitor's checks within period.
+// It is derived from the ID, so monitors that share an interval spread
+// evenly across it and keep their slots across restarts.
+func phase(id string, period time.Duration) time.Duration {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(id))
+	return time.Duration(h.Sum64() % uint64(period))
+}
+
+// firstCheckAt returns when a monitor new to the scheduler is first
+// checked: spread over the first interval, up to maxInitialSpread.
+func firstCheckAt(m model.Monitor, now time.Time) time.Time {
+	return now.Add(phase(m.ID, min(checkInterval(m), maxInitialSpread)))
+}
+
+// nextCheckAt returns when m is due for its next check after one at now:
+// the next slot of the monitor's phase at least half an interval away,
+// plus jitter.
+func (e *Engine) nextCheckAt(m model.Monitor, now time.Time) time.Time {
+	interval := checkInterval(m)
+	off := phase(m.ID, interval)
+	next := now.Add(-off).Truncate(interval).Add(off)
+	for next.Sub(now) < interval/2 {
+		next = next.Add(interval)
+	}
+	if j := min(e.deps.CheckJitter, interval/4); j > 0 {
+		next = next.Add(time.Duration(rand.Int63n(int64(j))))
+	}
+	if due, ok := nextPushCheck(m, now); ok && due.Before(next) {
+		return due
+	}
+	return next
+}
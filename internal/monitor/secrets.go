@@ -0,0 +1,86 @@
+package monitor
+
+import (
+	"maps"
+
+	"github.com/lsy88/uptime-chopper/internal/config"
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/secrets"
+)
+
+// expandMonitorSecrets returns a copy of m with the secret references in
+// its URL, headers and credentials replaced by their values. The expander
+// reports lookup errors and masks the values in check messages.
+func (e *Engine) expandMonitorSecrets(m model.Monitor) (model.Monitor, *secrets.Expander) {
+	x := e.deps.Secrets.Expander()
+	if h := m.HTTP; h != nil {
+		c := *h
+		c.URL = x.Expand(c.URL)
+		c.Headers = expandMap(x, c.Headers)
+		if a := c.Auth; a != nil {
+			ca := *a
+			ca.Username = x.Expand(ca.Username)
+			ca.Password = x.Expand(ca.Password)
+			ca.Token = x.Expand(ca.Token)
+			c.Auth = &ca
+		}
+		m.HTTP = &c
+	}
+	if d := m.Database; d != nil {
+		c := *d
+		c.DSN = x.Expand(c.DSN)
+		m.Database = &c
+	}
+	if ml := m.Mail; ml != nil {
+		c := *ml
+		c.Username = x.Expand(c.Username)
+		c.Password = x.Expand(c.Password)
+		m.Mail = &c
+	}
+	if ex := m.Exec; ex != nil && len(ex.Env) > 0 {
+		c := *ex
+		c.Env = make([]string, len(ex.Env))
+		for i, kv := range ex.Env {
+			c.Env[i] = x.Expand(kv)
+		}
+		m.Exec = &c
+	}
+	return m, x
+}
+
+// expandWebhookSecrets returns w with the secret references in its URL,
+// credentials, headers and body template replaced by their values.
+func (e *Engine) expandWebhookSecrets(w config.NotificationWebhook) (config.NotificationWebhook, *secrets.Expander) {
+	x := e.deps.Secrets.Expander()
+	w.URL = x.Expand(w.URL)
+	w.BotToken = x.Expand(w.BotToken)
+	w.ChatID = x.Expand(w.ChatID)
+	w.Secret = x.Expand(w.Secret)
+	w.UserKey = x.Expand(w.UserKey)
+	w.AppToken = x.Expand(w.AppToken)
+	w.BearerToken = x.Expand(w.BearerToken)
+	w.Headers = expandMap(x, w.Headers)
+	w.BodyTemplate = x.ExpandTemplate(w.BodyTemplate)
+	return w, x
+}
+
+// expandRemediationSecrets returns the remediation webhook request with
+// its secret references replaced by their values.
+func (e *Engine) expandRemediationSecrets(w model.RemediationRequest) (model.RemediationRequest, *secrets.Expander) {
+	x := e.deps.Secrets.Expander()
+	w.URL = x.Expand(w.URL)
+	w.Headers = expandMap(x, w.Headers)
+	w.Body = x.Expand(w.Body)
+	return w, x
+}
+
+func expandMap(x *secrets.Expander, in map[string]string) map[string]string {
+	if len(in) == 0 {
+		return in
+	}
+	out := maps.Clone(in)
+	for k, v := range out {
+		out[k] = x.Expand(v)
+	}
+	return out
+}
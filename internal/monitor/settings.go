@@ -0,0 +1,32 @@
+package monitor
+
+import (
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+// logBudgetSetter is implemented by stores that cap the log attachments
+// kept per monitor.
+type logBudgetSetter interface {
+	SetLogBudget(bytes int)
+}
+
+// Settings returns the runtime settings in effect.
+func (e *Engine) Settings() model.Settings {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	s := e.settings
+	s.DefaultNotifyWebhookIDs = append([]string(nil), s.DefaultNotifyWebhookIDs...)
+	return s
+}
+
+// ApplySettings puts s into effect for the following checks, prunes and
+// history writes.
+func (e *Engine) ApplySettings(s model.Settings) {
+	s.DefaultNotifyWebhookIDs = append([]string(nil), s.DefaultNotifyWebhookIDs...)
+	e.mu.Lock()
+	e.settings = s
+	e.mu.Unlock()
+	if lb, ok := e.deps.Store.(logBudgetSetter); ok {
+		lb.SetLogBudget(s.HistoryLogBudgetBytes)
+	}
+}
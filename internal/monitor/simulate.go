@@ -0,0 +1,177 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/notify"
+	"github.com/lsy88/uptime-chopper/internal/store"
+
+	"go.uber.org/zap"
+)
+
+// SimulateOptions describes a synthetic workload for capacity planning.
+type SimulateOptions struct {
+	Monitors    int
+	Interval    time.Duration
+	Duration    time.Duration
+	MinLatency  time.Duration
+	MaxLatency  time.Duration
+	FailureRate float64
+}
+
+// SimulationReport is the outcome of a simulation run.
+type SimulationReport struct {
+	Monitors        int     `json:"monitors"`
+	IntervalSeconds int     `json:"intervalSeconds"`
+	DurationSeconds float64 `json:"durationSeconds"`
+	Checks          int64   `json:"checks"`
+	ExpectedChecks  int64   `json:"expectedChecks"`
+	ChecksPerSecond float64 `json:"checksPerSecond"`
+	AvgDriftMs      float64 `json:"avgDriftMs"`
+	MaxDriftMs      float64 `json:"maxDriftMs"`
+	AvgCheckMs      float64 `json:"avgCheckMs"`
+	HeapAllocBytes  uint64  `json:"heapAllocBytes"`
+	PeakHeapBytes   uint64  `json:"peakHeapBytes"`
+	SysBytes        uint64  `json:"sysBytes"`
+	Goroutines      int     `json:"goroutines"`
+}
+
+// Simulate loads opts.Monitors synthetic monitors into a throwaway store and
+// runs the real scheduler against stub checkers for opts.Duration.
+func Simulate(ctx context.Context, logger *zap.Logger, opts SimulateOptions) (SimulationReport, error) {
+	if opts.Monitors <= 0 {
+		return SimulationReport{}, fmt.Errorf("monitors must be positive")
+	}
+	if opts.Interval < 5*time.Second {
+		opts.Interval = 5 * time.Second
+	}
+	if opts.Duration <= 0 {
+		opts.Duration = time.Minute
+	}
+	if opts.MaxLatency < opts.MinLatency {
+		opts.MaxLatency = opts.MinLatency
+	}
+
+	dir, err := os.MkdirTemp("", "uptime-chopper-sim-")
+	if err != nil {
+		return SimulationReport{}, err
+	}
+	defer os.RemoveAll(dir)
+
+	st, err := store.NewSQLiteStore(filepath.Join(dir, "sim.db"))
+	if err != nil {
+		return SimulationReport{}, err
+	}
+	defer st.Close()
+
+	for i := 0; i < opts.Monitors; i++ {
+		_, err := st.UpsertMonitor(model.Monitor{
+			ID:              fmt.Sprintf("sim-%d", i),
+			Name:            fmt.Sprintf("Simulated %d", i),
+			Type:            model.MonitorTypeHTTP,
+			IntervalSeconds: int(opts.Interval / time.Second),
+			TimeoutSeconds:  5,
+			HTTP:            &model.HTTPMonitor{URL: "http://simulated.invalid"},
+		})
+		if err != nil {
+			return SimulationReport{}, err
+		}
+	}
+
+	engine := NewEngine(EngineDeps{
+		Logger:   logger,
+		Store:    st,
+		Notifier: notify.NewDispatcher(nil),
+		Checker:  stubChecker(opts),
+		// Batch history like the server does by default.
+		HistoryFlushInterval: time.Second,
+	})
+
+	var peak uint64
+	var ms runtime.MemStats
+	start := time.Now()
+	engine.Start()
+
+	timer := time.NewTimer(opts.Duration)
+	defer timer.Stop()
+	sample := time.NewTicker(time.Second)
+	defer sample.Stop()
+wait:
+	for {
+		select {
+		case <-ctx.Done():
+			break wait
+		case <-timer.C:
+			break wait
+		case <-sample.C:
+			runtime.ReadMemStats(&ms)
+			if ms.HeapAlloc > peak {
+				peak = ms.HeapAlloc
+			}
+		}
+	}
+
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&ms)
+	if ms.HeapAlloc > peak {
+		peak = ms.HeapAlloc
+	}
+	goroutines := runtime.NumGoroutine()
+	engine.Stop()
+
+	stats := engine.SchedulerStats()
+	rep := SimulationReport{
+		Monitors:        opts.Monitors,
+		IntervalSeconds: int(opts.Interval / time.Second),
+		DurationSeconds: elapsed.Seconds(),
+		Checks:          stats.Checks,
+		ExpectedChecks:  int64(opts.Monitors) * (int64(elapsed/opts.Interval) + 1),
+		ChecksPerSecond: float64(stats.Checks) / elapsed.Seconds(),
+		MaxDriftMs:      float64(stats.MaxDrift) / float64(time.Millisecond),
+		HeapAllocBytes:  ms.HeapAlloc,
+		PeakHeapBytes:   peak,
+		SysBytes:        ms.Sys,
+		Goroutines:      goroutines,
+	}
+	if stats.Checks > 0 {
+		rep.AvgDriftMs = float64(stats.TotalDrift) / float64(stats.Checks) / float64(time.Millisecond)
+		rep.AvgCheckMs = float64(stats.TotalDuration) / float64(stats.Checks) / float64(time.Millisecond)
+	}
+	return rep, nil
+}
+
+// stubChecker sleeps for a random latency within the configured bounds and
+// reports down for roughly FailureRate of the checks.
+func stubChecker(opts SimulateOptions) func(ctx context.Context, now time.Time, m model.Monitor) model.CheckResult {
+	return func(ctx context.Context, now time.Time, m model.Monitor) model.CheckResult {
+		latency := opts.MinLatency
+		if spread := opts.MaxLatency - opts.MinLatency; spread > 0 {
+			latency += time.Duration(rand.Int63n(int64(spread)))
+		}
+		select {
+		case <-ctx.Done():
+			return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: ctx.Err().Error()}
+		case <-time.After(latency):
+		}
+		status := model.StatusUp
+		msg := "simulated"
+		if rand.Float64() < opts.FailureRate {
+			status = model.StatusDown
+			msg = "simulated failure"
+		}
+		return model.CheckResult{
+			MonitorID: m.ID,
+			Status:    status,
+			CheckedAt: now,
+			LatencyMs: int(latency.Milliseconds()),
+			Message:   msg,
+		}
+	}
+}
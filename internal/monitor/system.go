@@ -0,0 +1,152 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/sysmetrics"
+)
+
+// cpuSampleWindow is how long a system check measures CPU usage.
+const cpuSampleWindow = time.Second
+
+// checkSystem samples the host's CPU, memory, load and disks and reports
+// down with every threshold that is exceeded. The usage is always added to
+// the message; CPU and memory are also recorded with the result.
+func checkSystem(ctx context.Context, now time.Time, m model.Monitor) model.CheckResult {
+	s := m.System
+	if s == nil {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: "missing system settings"}
+	}
+	down := func(err error) model.CheckResult {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: err.Error()}
+	}
+
+	var usage, reasons []string
+	cpu, err := sysmetrics.CPUPercent(ctx, cpuSampleWindow)
+	if err != nil {
+		return down(err)
+	}
+	usage = append(usage, fmt.Sprintf("cpu %.1f%%", cpu))
+	if s.MaxCPUPercent > 0 && cpu > s.MaxCPUPercent {
+		reasons = append(reasons, fmt.Sprintf("cpu %.1f%% > %.0f%%", cpu, s.MaxCPUPercent))
+	}
+
+	vm, err := sysmetrics.VirtualMemory()
+	if err != nil {
+		return down(err)
+	}
+	mem := vm.UsedPercent()
+	usage = append(usage, fmt.Sprintf("memory %.1f%%", mem))
+	if s.MaxMemoryPercent > 0 && mem > s.MaxMemoryPercent {
+		reasons = append(reasons, fmt.Sprintf("memory %.1f%% > %.0f%%", mem, s.MaxMemoryPercent))
+	}
+
+	load, err := sysmetrics.LoadAverage()
+	if err != nil {
+		return down(err)
+	}
+	perCPU := load.Load5 / float64(runtime.NumCPU())
+	usage = append(usage, fmt.Sprintf("load %.2f", load.Load5))
+	if s.MaxLoadPerCPU > 0 && perCPU > s.MaxLoadPerCPU {
+		reasons = append(reasons, fmt.Sprintf("load %.2f per cpu > %.2f", perCPU, s.MaxLoadPerCPU))
+	}
+
+	for _, d := range s.Disks {
+		du, err := sysmetrics.DiskUsage(d.Path)
+		if err != nil {
+			reasons = append(reasons, err.Error())
+			continue
+		}
+		free := du.FreePercent()
+		usage = append(usage, fmt.Sprintf("%s %.1f%% free", d.Path, free))
+		if d.MinFreePercent > 0 && free < d.MinFreePercent {
+			reasons = append(reasons, fmt.Sprintf("%s %.1f%% free < %.0f%%", d.Path, free, d.MinFreePercent))
+		}
+		if d.MinFreeBytes > 0 && du.Free < uint64(d.MinFreeBytes) {
+			reasons = append(reasons, fmt.Sprintf("%s %s free < %s", d.Path, formatSize(du.Free), formatSize(uint64(d.MinFreeBytes))))
+		}
+	}
+
+	for _, d := range s.SMART {
+		h, err := sysmetrics.SMART(ctx, d.Device, d.Type)
+		if err != nil {
+			reasons = append(reasons, err.Error())
+			continue
+		}
+		if h.Source == "sysfs" {
+			usage = append(usage, fmt.Sprintf("%s %s", d.Device, h.State))
+		} else {
+			usage = append(usage, fmt.Sprintf("%s healthy", d.Device))
+		}
+		reasons = append(reasons, smartProblems(d, h)...)
+	}
+
+	res := model.CheckResult{MonitorID: m.ID, Status: model.StatusUp, CheckedAt: now, Message: strings.Join(usage, ", ")}
+	res.CPUPercent, res.MemoryPercent = &cpu, &mem
+	if len(reasons) > 0 {
+		res.Status = model.StatusDown
+		res.Message = strings.Join(reasons, "; ")
+	}
+	return res
+}
+
+// smartProblems lists why the disk d is considered failing.
+func smartProblems(d model.SMARTMonitor, h sysmetrics.DiskHealth) []string {
+	var out []string
+	switch {
+	case !h.Passed && h.Source == "sysfs":
+		out = append(out, fmt.Sprintf("%s is %s", d.Device, h.State))
+	case !h.Passed:
+		out = append(out, d.Device+" failed its SMART health assessment")
+	}
+	if h.Pending > 0 {
+		out = append(out, fmt.Sprintf("%s has %d pending sectors", d.Device, h.Pending))
+	}
+	if h.Uncorrectable > 0 {
+		out = append(out, fmt.Sprintf("%s has %d uncorrectable sectors", d.Device, h.Uncorrectable))
+	}
+	if h.MediaErrors > 0 {
+		out = append(out, fmt.Sprintf("%s has %d media errors", d.Device, h.MediaErrors))
+	}
+	if h.CriticalWarning != 0 {
+		out = append(out, fmt.Sprintf("%s reports NVMe critical warning 0x%02x", d.Device, h.CriticalWarning))
+	}
+	if d.MaxReallocatedSectors > 0 && h.Reallocated > d.MaxReallocatedSectors {
+		out = append(out, fmt.Sprintf("%s reallocated sectors %d > %d", d.Device, h.Reallocated, d.MaxReallocatedSectors))
+	}
+	if d.MaxTemperature > 0 && h.Temperature > d.MaxTemperature {
+		out = append(out, fmt.Sprintf("%s temperature %d°C > %d°C", d.Device, h.Temperature, d.MaxTemperature))
+	}
+	return out
+}
+
+// systemTarget lists the disks a system monitor checks.
+func systemTarget(s *model.SystemMonitor) string {
+	paths := make([]string, 0, len(s.Disks)+len(s.SMART))
+	for _, d := range s.Disks {
+		paths = append(paths, d.Path)
+	}
+	for _, d := range s.SMART {
+		paths = append(paths, d.Device)
+	}
+	return strings.Join(paths, ", ")
+}
+
+// formatSize renders a byte count with a binary unit, e.g. "1.5 GiB".
+func formatSize(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,55 @@
+package monitor
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+// verifyCertificatePins checks the presented leaf certificate against the
+// monitor's pinned SPKI hashes and expected issuer. It returns a non-empty
+// reason when the certificate does not match.
+func verifyCertificatePins(cs *tls.ConnectionState, h *model.HTTPMonitor) string {
+	if len(h.PinnedSPKISHA256) == 0 && h.ExpectedIssuer == "" {
+		return ""
+	}
+	if cs == nil || len(cs.PeerCertificates) == 0 {
+		return "certificate pinning configured but no TLS certificate was presented"
+	}
+	leaf := cs.PeerCertificates[0]
+
+	if len(h.PinnedSPKISHA256) > 0 {
+		sum := sha256.Sum256(leaf.RawSubjectPublicKeyInfo)
+		got := base64.StdEncoding.EncodeToString(sum[:])
+		matched := false
+		for _, pin := range h.PinnedSPKISHA256 {
+			if strings.TrimPrefix(strings.TrimSpace(pin), "sha256/") == got {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Sprintf("certificate SPKI pin mismatch: got sha256/%s", got)
+		}
+	}
+
+	if h.ExpectedIssuer != "" {
+		want := strings.ToLower(h.ExpectedIssuer)
+		names := append([]string{leaf.Issuer.CommonName}, leaf.Issuer.Organization...)
+		matched := false
+		for _, n := range names {
+			if strings.Contains(strings.ToLower(n), want) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Sprintf("certificate issuer mismatch: got %q", leaf.Issuer.String())
+		}
+	}
+	return ""
+}
@@ -0,0 +1,292 @@
+package monitor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/docker"
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/secrets"
+)
+
+// CheckTrace is the verbose outcome of a debug check.
+type CheckTrace struct {
+	Result          model.CheckResult   `json:"result"`
+	Events          []TraceEvent        `json:"events"`
+	ResolvedIPs     []string            `json:"resolvedIps,omitempty"`
+	RemoteAddr      string              `json:"remoteAddr,omitempty"`
+	RedirectChain   []string            `json:"redirectChain,omitempty"`
+	StatusCode      int                 `json:"statusCode,omitempty"`
+	ResponseHeaders map[string][]string `json:"responseHeaders,omitempty"`
+	TLS             *TraceTLS           `json:"tls,omitempty"`
+}
+
+type TraceEvent struct {
+	Name     string `json:"name"`
+	OffsetMs int64  `json:"offsetMs"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+type TraceTLS struct {
+	Version     string    `json:"version"`
+	CipherSuite string    `json:"cipherSuite"`
+	ServerName  string    `json:"serverName"`
+	Subject     string    `json:"subject"`
+	Issuer      string    `json:"issuer"`
+	NotBefore   time.Time `json:"notBefore"`
+	NotAfter    time.Time `json:"notAfter"`
+	DNSNames    []string  `json:"dnsNames,omitempty"`
+}
+
+type tracer struct {
+	mu    sync.Mutex
+	start time.Time
+	trace *CheckTrace
+}
+
+func (t *tracer) event(name, detail string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.trace.Events = append(t.trace.Events, TraceEvent{
+		Name:     name,
+		OffsetMs: time.Since(t.start).Milliseconds(),
+		Detail:   detail,
+	})
+}
+
+// DebugCheck runs a single check for m synchronously with verbose tracing.
+// The result is not recorded in history and does not trigger notifications.
+func (e *Engine) DebugCheck(ctx context.Context, m model.Monitor) CheckTrace {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(maxInt(1, m.TimeoutSeconds))*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	tr := &tracer{start: time.Now(), trace: &CheckTrace{Events: []TraceEvent{}}}
+
+	m, x := e.expandMonitorSecrets(m)
+	if err := x.Err(); err != nil {
+		tr.trace.Result = model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: err.Error()}
+		return *tr.trace
+	}
+
+	switch m.Type {
+	case model.MonitorTypeHTTP:
+		if m.HTTP == nil || m.HTTP.URL == "" {
+			tr.trace.Result = model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: "missing url"}
+			break
+		}
+		base, err := e.httpClient(m, true)
+		if err != nil {
+			tr.trace.Result = model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: err.Error()}
+			break
+		}
+		follow := redirectPolicy(m.HTTP.RedirectLimit())
+		client := &http.Client{
+			Transport: &tracingTransport{base: base.Transport, tr: tr},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if err := follow(req, via); err != nil {
+					return err
+				}
+				tr.mu.Lock()
+				tr.trace.RedirectChain = append(tr.trace.RedirectChain, req.URL.String())
+				tr.mu.Unlock()
+				tr.event("redirect", req.URL.String())
+				return nil
+			},
+		}
+		res, _ := doHTTPCheck(httptrace.WithClientTrace(ctx, tr.clientTrace()), now, m, client)
+		res.ConnMode = model.ConnectionFresh
+		tr.trace.Result = res
+	case model.MonitorTypeContainer:
+		if m.Container == nil || m.Container.ContainerID == "" {
+			tr.trace.Result = model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: "missing container id"}
+			break
+		}
+		// Inspect only: no restart policy or remediation side effects.
+		tr.event("container_inspect_start", m.Container.ContainerID)
+		var state docker.StateInfo
+		dc, err := e.deps.Docker.Get(m.Container.HostID)
+		if err == nil {
+			state, err = dc.ContainerState(ctx, m.Container.ContainerID)
+		}
+		res := model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: state.String()}
+		switch {
+		case err != nil:
+			res.Message = err.Error()
+			tr.event("container_inspect_done", err.Error())
+		case state.Up():
+			res.Status = model.StatusUp
+			tr.event("container_inspect_done", state.String())
+		default:
+			tr.event("container_inspect_done", state.String())
+		}
+		res.LatencyMs = int(time.Since(tr.start).Milliseconds())
+		tr.trace.Result = res
+	case model.MonitorTypeCompose:
+		if m.Compose == nil || m.Compose.Project == "" {
+			tr.trace.Result = model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: "missing compose project"}
+			break
+		}
+		tr.event("compose_list_start", m.Compose.Project)
+		var cs []docker.ContainerSummary
+		dc, err := e.deps.Docker.Get(m.Compose.HostID)
+		if err == nil {
+			cs, err = dc.ProjectContainers(ctx, m.Compose.Project)
+		}
+		var res model.CheckResult
+		if err != nil {
+			res = model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: err.Error()}
+			tr.event("compose_list_done", err.Error())
+		} else {
+			res = composeResult(now, m, cs)
+			tr.event("compose_list_done", res.Message)
+		}
+		res.LatencyMs = int(time.Since(tr.start).Milliseconds())
+		tr.trace.Result = res
+	case model.MonitorTypeKubernetes:
+		// Read only: the copy drops the remediation policy.
+		if m.Kubernetes != nil {
+			k := *m.Kubernetes
+			k.Remediation = model.RemediationPolicy{}
+			m.Kubernetes = &k
+		}
+		tr.event("kubernetes_get_start", monitorTarget(m))
+		res := e.checkKubernetes(ctx, now, m)
+		tr.event("kubernetes_get_done", res.Message)
+		res.LatencyMs = int(time.Since(tr.start).Milliseconds())
+		tr.trace.Result = res
+	case model.MonitorTypePush:
+		// Heartbeats are pushed to us; report the current state only.
+		res := e.checkPush(now, m)
+		tr.event("heartbeat", res.Message)
+		tr.trace.Result = res
+	case model.MonitorTypeMySQL, model.MonitorTypePostgres, model.MonitorTypeRedis, model.MonitorTypeMongoDB:
+		tr.event("db_connect_start", monitorTarget(m))
+		res := checkDatabase(ctx, now, m)
+		tr.event("db_connect_done", res.Message)
+		tr.trace.Result = res
+	case model.MonitorTypeMail:
+		tr.event("mail_connect_start", monitorTarget(m))
+		res := checkMail(ctx, now, m)
+		tr.event("mail_done", res.Message)
+		tr.trace.Result = res
+	case model.MonitorTypeExec:
+		tr.event("exec_start", monitorTarget(m))
+		res := e.checkExec(ctx, now, m)
+		tr.event("exec_done", res.Message)
+		tr.trace.Result = res
+	case model.MonitorTypeSystem:
+		tr.event("system_sample_start", monitorTarget(m))
+		res := checkSystem(ctx, now, m)
+		tr.event("system_done", res.Message)
+		tr.trace.Result = res
+	default:
+		tr.trace.Result = model.CheckResult{MonitorID: m.ID, Status: model.StatusUnknown, CheckedAt: now, Message: "unknown monitor type"}
+	}
+	tr.event("done", string(tr.trace.Result.Status))
+	maskTrace(tr.trace, x)
+	return *tr.trace
+}
+
+// maskTrace hides the secret values inserted by x from the parts of a
+// trace that may echo the request.
+func maskTrace(t *CheckTrace, x *secrets.Expander) {
+	t.Result.Message = x.Mask(t.Result.Message)
+	for i := range t.Events {
+		t.Events[i].Detail = x.Mask(t.Events[i].Detail)
+	}
+	for i := range t.RedirectChain {
+		t.RedirectChain[i] = x.Mask(t.RedirectChain[i])
+	}
+}
+
+func (t *tracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(info httptrace.DNSStartInfo) { t.event("dns_start", info.Host) },
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			for _, a := range info.Addrs {
+				t.trace.ResolvedIPs = append(t.trace.ResolvedIPs, a.IP.String())
+			}
+			t.mu.Unlock()
+			detail := ""
+			if info.Err != nil {
+				detail = info.Err.Error()
+			}
+			t.event("dns_done", detail)
+		},
+		ConnectStart: func(network, addr string) { t.event("connect_start", network+" "+addr) },
+		ConnectDone: func(network, addr string, err error) {
+			detail := network + " " + addr
+			if err != nil {
+				detail += ": " + err.Error()
+			}
+			t.event("connect_done", detail)
+		},
+		TLSHandshakeStart: func() { t.event("tls_handshake_start", "") },
+		TLSHandshakeDone: func(cs tls.ConnectionState, err error) {
+			detail := ""
+			if err != nil {
+				detail = err.Error()
+			} else {
+				t.mu.Lock()
+				t.trace.TLS = traceTLS(cs)
+				t.mu.Unlock()
+			}
+			t.event("tls_handshake_done", detail)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			if info.Conn != nil {
+				t.trace.RemoteAddr = info.Conn.RemoteAddr().String()
+			}
+			t.mu.Unlock()
+			t.event("got_conn", "")
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.event("wrote_request", "") },
+		GotFirstResponseByte: func() { t.event("first_response_byte", "") },
+	}
+}
+
+// tracingTransport records the status and headers of the final response.
+type tracingTransport struct {
+	base http.RoundTripper
+	tr   *tracer
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	t.tr.mu.Lock()
+	t.tr.trace.StatusCode = resp.StatusCode
+	t.tr.trace.ResponseHeaders = resp.Header.Clone()
+	t.tr.mu.Unlock()
+	return resp, nil
+}
+
+func traceTLS(cs tls.ConnectionState) *TraceTLS {
+	out := &TraceTLS{
+		Version:     tls.VersionName(cs.Version),
+		CipherSuite: tls.CipherSuiteName(cs.CipherSuite),
+		ServerName:  cs.ServerName,
+	}
+	if len(cs.PeerCertificates) > 0 {
+		fillCertInfo(out, cs.PeerCertificates[0])
+	}
+	return out
+}
+
+func fillCertInfo(out *TraceTLS, c *x509.Certificate) {
+	out.Subject = c.Subject.String()
+	out.Issuer = c.Issuer.String()
+	out.NotBefore = c.NotBefore
+	out.NotAfter = c.NotAfter
+	out.DNSNames = c.DNSNames
+}
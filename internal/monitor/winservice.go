@@ -0,0 +1,50 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/winsvc"
+)
+
+func (e *Engine) checkWinService(ctx context.Context, now time.Time, m model.Monitor) model.CheckResult {
+	w := m.WinService
+	if w == nil || (w.ServiceName == "" && w.IISSite == "") {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: "missing service name"}
+	}
+
+	if w.IISSite != "" {
+		state, err := winsvc.IISSiteState(ctx, w.IISSite)
+		if err != nil {
+			return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: err.Error()}
+		}
+		if state == "started" {
+			return model.CheckResult{MonitorID: m.ID, Status: model.StatusUp, CheckedAt: now, Message: state}
+		}
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: state}
+	}
+
+	state, err := winsvc.ServiceState(ctx, w.ServiceName)
+	if err != nil {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: err.Error()}
+	}
+	if state == winsvc.StateRunning {
+		return model.CheckResult{MonitorID: m.ID, Status: model.StatusUp, CheckedAt: now, Message: state}
+	}
+
+	if state == winsvc.StateStopped && w.RestartOnStopped {
+		if err := winsvc.StartService(ctx, w.ServiceName); err != nil {
+			e.deps.Logger.Error("failed to start windows service",
+				zap.String("monitor_id", m.ID),
+				zap.String("service", w.ServiceName),
+				zap.Error(err),
+			)
+		} else {
+			e.deps.Logger.Info("windows service started", zap.String("monitor_id", m.ID), zap.String("service", w.ServiceName))
+		}
+	}
+	return model.CheckResult{MonitorID: m.ID, Status: model.StatusDown, CheckedAt: now, Message: state}
+}
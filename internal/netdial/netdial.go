@@ -0,0 +1,170 @@
+// Package netdial connects network checks over a chosen IP version and
+// resolves their targets with a chosen DNS server, so that both halves of
+// a dual-stack service can be checked on their own. Lookups can be cached
+// per dialer.
+package netdial
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dialer opens connections like net.Dialer, restricted to one IP version
+// and resolving names with its own DNS server. A nil *Dialer uses either IP
+// version and the system resolver.
+type Dialer struct {
+	family   string // "4", "6" or empty
+	resolver *net.Resolver
+
+	cacheTTL time.Duration
+	mu       sync.Mutex
+	cache    map[string]cachedAddrs
+}
+
+type cachedAddrs struct {
+	addrs   []netip.Addr
+	expires time.Time
+}
+
+// New returns the dialer for ipVersion ("ipv4", "ipv6" or empty for either)
+// and dnsServer ("host" or "host:port", empty for the system resolver), or
+// nil when both are defaults.
+func New(ipVersion, dnsServer string) (*Dialer, error) {
+	d := &Dialer{}
+	switch ipVersion {
+	case "":
+	case "ipv4":
+		d.family = "4"
+	case "ipv6":
+		d.family = "6"
+	default:
+		return nil, fmt.Errorf("unknown ip version %q", ipVersion)
+	}
+	if dnsServer != "" {
+		server, err := ServerAddr(dnsServer)
+		if err != nil {
+			return nil, err
+		}
+		d.resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var nd net.Dialer
+				return nd.DialContext(ctx, network, server)
+			},
+		}
+	}
+	if d.family == "" && d.resolver == nil {
+		return nil, nil
+	}
+	return d, nil
+}
+
+// ServerAddr returns the address of a DNS server given as "host" or
+// "host:port", adding the default port 53.
+func ServerAddr(server string) (string, error) {
+	if net.ParseIP(server) != nil {
+		return net.JoinHostPort(server, "53"), nil
+	}
+	host, port, err := net.SplitHostPort(server)
+	if err != nil {
+		host, port = server, "53"
+	}
+	if host == "" || (strings.Contains(host, ":") && net.ParseIP(host) == nil) {
+		return "", fmt.Errorf("invalid dns server %q", server)
+	}
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return "", fmt.Errorf("invalid dns server port %q", port)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// WithDNSCache returns a dialer with the settings of d that remembers the
+// addresses a host name resolved to for ttl, so that new connections skip
+// the lookup. The cache belongs to the returned dialer.
+func (d *Dialer) WithDNSCache(ttl time.Duration) *Dialer {
+	c := &Dialer{cacheTTL: ttl, cache: map[string]cachedAddrs{}}
+	if d != nil {
+		c.family, c.resolver = d.family, d.resolver
+	}
+	return c
+}
+
+// DialContext connects to addr like net.Dialer.DialContext. TCP and UDP
+// connections of a restricted dialer only use addresses of its IP version.
+func (d *Dialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	nd := net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if d == nil {
+		return nd.DialContext(ctx, network, addr)
+	}
+	nd.Resolver = d.resolver
+	if d.family != "" && (network == "tcp" || network == "udp") {
+		network += d.family
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if d.cacheTTL <= 0 || err != nil || net.ParseIP(host) != nil {
+		return nd.DialContext(ctx, network, addr)
+	}
+	addrs, err := d.lookup(ctx, network, host)
+	if err != nil {
+		return nil, err
+	}
+	var firstErr error
+	for _, a := range addrs {
+		conn, err := nd.DialContext(ctx, network, net.JoinHostPort(a.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	// The addresses may be stale; look the host up again next time.
+	d.mu.Lock()
+	delete(d.cache, network+"|"+host)
+	d.mu.Unlock()
+	return nil, firstErr
+}
+
+// lookup resolves host for network, using the cache while it is fresh.
+func (d *Dialer) lookup(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	key := network + "|" + host
+	d.mu.Lock()
+	c, ok := d.cache[key]
+	d.mu.Unlock()
+	if ok && time.Now().Before(c.expires) {
+		return c.addrs, nil
+	}
+	ipNet := "ip"
+	switch {
+	case strings.HasSuffix(network, "4"):
+		ipNet = "ip4"
+	case strings.HasSuffix(network, "6"):
+		ipNet = "ip6"
+	}
+	addrs, err := d.Resolver().LookupNetIP(ctx, ipNet, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, errors.New("no addresses found for " + host)
+	}
+	d.mu.Lock()
+	d.cache[key] = cachedAddrs{addrs: addrs, expires: time.Now().Add(d.cacheTTL)}
+	d.mu.Unlock()
+	return addrs, nil
+}
+
+// Resolver returns the resolver that d looks names up with.
+func (d *Dialer) Resolver() *net.Resolver {
+	if d == nil || d.resolver == nil {
+		return net.DefaultResolver
+	}
+	return d.resolver
+}
@@ -3,10 +3,16 @@ package notify
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/lsy88/uptime-chopper/internal/config"
@@ -34,7 +40,7 @@ type Dispatcher struct {
 func NewDispatcher(webhooks []config.NotificationWebhook) *Dispatcher {
 	m := make(map[string]config.NotificationWebhook, len(webhooks))
 	for _, w := range webhooks {
-		if w.Name == "" || w.URL == "" {
+		if w.Name == "" || endpointURL(w) == "" {
 			continue
 		}
 		m[w.Name] = w
@@ -49,6 +55,12 @@ func (d *Dispatcher) Client() *http.Client {
 	return d.client
 }
 
+// Webhook returns the config-defined webhook with the given name.
+func (d *Dispatcher) Webhook(name string) (config.NotificationWebhook, bool) {
+	w, ok := d.webhooks[name]
+	return w, ok
+}
+
 func (d *Dispatcher) SendWebhook(ctx context.Context, webhookName string, payload Payload) error {
 	w, ok := d.webhooks[webhookName]
 	if !ok {
@@ -68,20 +80,42 @@ func Send(ctx context.Context, client *http.Client, w config.NotificationWebhook
 		body, err = buildWeChatPayload(payload)
 	case "discord":
 		body, err = buildDiscordPayload(payload)
+	case "telegram":
+		body, err = buildTelegramPayload(w.ChatID, payload)
+	case "feishu":
+		body, err = buildFeishuPayload(w.Secret, time.Now(), payload)
+	case "pushover":
+		body, err = buildPushoverPayload(w.AppToken, w.UserKey, payload)
 	default:
 		// Default to generic webhook
-		body, err = json.Marshal(payload)
+		if w.BodyTemplate != "" {
+			body, err = renderBodyTemplate(w.BodyTemplate, payload)
+		} else {
+			body, err = json.Marshal(payload)
+		}
 	}
 
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	method := http.MethodPost
+	if isGenericWebhook(w.Type) && w.Method != "" {
+		method = strings.ToUpper(w.Method)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpointURL(w), bytes.NewReader(body))
 	if err != nil {
 		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	if isGenericWebhook(w.Type) {
+		if w.BearerToken != "" {
+			req.Header.Set("Authorization", "Bearer "+w.BearerToken)
+		}
+		for k, v := range w.Headers {
+			req.Header.Set(k, v)
+		}
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -94,6 +128,19 @@ func Send(ctx context.Context, client *http.Client, w config.NotificationWebhook
 		return fmt.Errorf("webhook %s returned status %d: %s", w.Name, resp.StatusCode, string(respBody))
 	}
 
+	// Feishu reports failures in the body with HTTP 200
+	if w.Type == "feishu" {
+		var fsResp struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+		}
+		if err := json.Unmarshal(respBody, &fsResp); err == nil {
+			if fsResp.Code != 0 {
+				return fmt.Errorf("feishu error %d: %s", fsResp.Code, fsResp.Msg)
+			}
+		}
+	}
+
 	// For DingTalk, check errcode
 	if w.Type == "dingtalk" {
 		var dtResp struct {
@@ -110,6 +157,65 @@ func Send(ctx context.Context, client *http.Client, w config.NotificationWebhook
 	return nil
 }
 
+func isGenericWebhook(t string) bool {
+	switch t {
+	case "dingtalk", "wechat", "discord", "telegram", "feishu", "pushover":
+		return false
+	}
+	return true
+}
+
+// renderBodyTemplate executes a user-supplied Go template with the payload
+// as data. The "json" function encodes any value as JSON.
+func renderBodyTemplate(tmpl string, p Payload) ([]byte, error) {
+	t, err := parseBodyTemplate(tmpl, p)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, p); err != nil {
+		return nil, fmt.Errorf("render body template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ValidateBodyTemplate reports a syntax error in a webhook body template,
+// so a broken one is rejected when saved rather than on the first alert.
+func ValidateBodyTemplate(tmpl string) error {
+	_, err := parseBodyTemplate(tmpl, Payload{})
+	return err
+}
+
+func parseBodyTemplate(tmpl string, p Payload) (*template.Template, error) {
+	t, err := template.New("body").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"title": func() string { return translateEventType(p.Type) },
+	}).Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("parse body template: %w", err)
+	}
+	return t, nil
+}
+
+// endpointURL returns the URL a notification is posted to. Telegram builds it
+// from the bot token unless an explicit URL (e.g. a Bot API proxy) is set.
+func endpointURL(w config.NotificationWebhook) string {
+	if w.Type == "telegram" && w.BotToken != "" {
+		base := strings.TrimRight(w.URL, "/")
+		if base == "" {
+			base = "https://api.telegram.org"
+		}
+		return base + "/bot" + w.BotToken + "/sendMessage"
+	}
+	if w.Type == "pushover" && w.URL == "" {
+		return "https://api.pushover.net/1/messages.json"
+	}
+	return w.URL
+}
+
 func buildDingTalkPayload(p Payload) ([]byte, error) {
 	title := fmt.Sprintf("监控报警: %s", translateEventType(p.Type))
 	text := formatMarkdown(title, p)
@@ -121,6 +227,10 @@ func buildDingTalkPayload(p Payload) ([]byte, error) {
 			"text":  text,
 		},
 	}
+	// DingTalk only notifies mentioned members listed in "at".
+	if mention := mentionOf(p); isPhoneNumber(mention) {
+		payload["at"] = map[string]any{"atMobiles": []string{mention}}
+	}
 	return json.Marshal(payload)
 }
 
@@ -142,12 +252,16 @@ func buildDiscordPayload(p Payload) ([]byte, error) {
 	description := formatMarkdown(title, p)
 
 	color := 0x5cdd8b // Green
-	if s, ok := p.Data["current"].(string); ok && s == "down" {
+	switch severity(p) {
+	case SeverityCritical:
 		color = 0xdc3545 // Red
+	case SeverityWarning:
+		color = 0xffc107 // Yellow
 	}
 
 	payload := map[string]any{
 		"username": "Uptime Chopper",
+		"content":  discordMention(mentionOf(p)),
 		"embeds": []map[string]any{
 			{
 				"title":       title,
@@ -160,6 +274,215 @@ func buildDiscordPayload(p Payload) ([]byte, error) {
 	return json.Marshal(payload)
 }
 
+func buildTelegramPayload(chatID string, p Payload) ([]byte, error) {
+	title := fmt.Sprintf("监控报警: %s", translateEventType(p.Type))
+
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("%s *%s*\n\n", statusEmoji(p), escapeTelegram(title)))
+	for _, f := range eventFields(p) {
+		buf.WriteString(fmt.Sprintf("• *%s*: %s\n", escapeTelegram(f.Label), escapeTelegram(f.Value)))
+	}
+	if p.Logs != nil {
+		content, truncated := truncatedLogs(p)
+		buf.WriteString("\n*容器日志*:\n```\n")
+		if truncated {
+			buf.WriteString("...(已截断)...\n")
+		}
+		buf.WriteString(escapeTelegramCode(content))
+		buf.WriteString("\n```\n")
+	}
+
+	payload := map[string]any{
+		"chat_id":                  chatID,
+		"text":                     buf.String(),
+		"parse_mode":               "MarkdownV2",
+		"disable_web_page_preview": true,
+	}
+	return json.Marshal(payload)
+}
+
+// telegramEscaper escapes the characters reserved by Telegram's MarkdownV2.
+var telegramEscaper = strings.NewReplacer(
+	"\\", "\\\\", "_", "\\_", "*", "\\*", "[", "\\[", "]", "\\]", "(", "\\(", ")", "\\)",
+	"~", "\\~", "`", "\\`", ">", "\\>", "#", "\\#", "+", "\\+", "-", "\\-", "=", "\\=",
+	"|", "\\|", "{", "\\{", "}", "\\}", ".", "\\.", "!", "\\!",
+)
+
+func escapeTelegram(s string) string {
+	return telegramEscaper.Replace(s)
+}
+
+// escapeTelegramCode escapes text inside a MarkdownV2 code block, where only
+// backslashes and backticks are special.
+func escapeTelegramCode(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "`", "\\`").Replace(s)
+}
+
+func buildFeishuPayload(secret string, now time.Time, p Payload) ([]byte, error) {
+	title := fmt.Sprintf("监控报警: %s", translateEventType(p.Type))
+
+	var buf bytes.Buffer
+	for _, f := range eventFields(p) {
+		buf.WriteString(fmt.Sprintf("**%s**: %s\n", f.Label, f.Value))
+	}
+	if p.Logs != nil {
+		content, truncated := truncatedLogs(p)
+		buf.WriteString("\n**容器日志**:\n```\n")
+		if truncated {
+			buf.WriteString("...(已截断)...\n")
+		}
+		buf.WriteString(content)
+		buf.WriteString("\n```\n")
+	}
+
+	template := "blue"
+	if s, ok := p.Data["current"].(string); ok && s == "up" {
+		template = "green"
+	}
+	switch severity(p) {
+	case SeverityCritical:
+		template = "red"
+	case SeverityWarning:
+		template = "yellow"
+	}
+
+	payload := map[string]any{
+		"msg_type": "interactive",
+		"card": map[string]any{
+			"config": map[string]any{"wide_screen_mode": true},
+			"header": map[string]any{
+				"title":    map[string]string{"tag": "plain_text", "content": statusEmoji(p) + " " + title},
+				"template": template,
+			},
+			"elements": []map[string]any{
+				{"tag": "markdown", "content": buf.String()},
+			},
+		},
+	}
+	if secret != "" {
+		ts := strconv.FormatInt(now.Unix(), 10)
+		payload["timestamp"] = ts
+		payload["sign"] = feishuSign(ts, secret)
+	}
+	return json.Marshal(payload)
+}
+
+// feishuSign implements Feishu's custom bot signature: the HMAC-SHA256 key
+// is "timestamp\nsecret" and the message is empty.
+func feishuSign(timestamp, secret string) string {
+	mac := hmac.New(sha256.New, []byte(timestamp+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Pushover priorities. Emergency alerts repeat every pushoverRetry seconds
+// until acknowledged or pushoverExpire seconds have passed.
+const (
+	pushoverNormal    = 0
+	pushoverHigh      = 1
+	pushoverEmergency = 2
+	pushoverRetry     = 60
+	pushoverExpire    = 3600
+	pushoverMaxLen    = 1024
+)
+
+func buildPushoverPayload(appToken, userKey string, p Payload) ([]byte, error) {
+	title := fmt.Sprintf("监控报警: %s", translateEventType(p.Type))
+
+	var lines []string
+	for _, f := range eventFields(p) {
+		lines = append(lines, fmt.Sprintf("%s: %s", f.Label, f.Value))
+	}
+	message := []rune(strings.Join(lines, "\n"))
+	if len(message) > pushoverMaxLen {
+		message = message[:pushoverMaxLen]
+	}
+
+	payload := map[string]any{
+		"token":     appToken,
+		"user":      userKey,
+		"title":     statusEmoji(p) + " " + title,
+		"message":   string(message),
+		"timestamp": p.At.Unix(),
+		"priority":  pushoverNormal,
+	}
+	// Down events page on-call loudly, degraded service gets high priority;
+	// recoveries and everything else use normal priority.
+	switch severity(p) {
+	case SeverityCritical:
+		payload["priority"] = pushoverEmergency
+		payload["retry"] = pushoverRetry
+		payload["expire"] = pushoverExpire
+	case SeverityWarning:
+		payload["priority"] = pushoverHigh
+	}
+	return json.Marshal(payload)
+}
+
+// Severities of a status change, sent as the "severity" field of status
+// payloads and used by providers for colours and priorities.
+const (
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+// SeverityOf classifies a monitor status: outages are critical, degraded
+// service is a warning and everything else is informational.
+func SeverityOf(status string) string {
+	switch status {
+	case "down", "crash_loop":
+		return SeverityCritical
+	case "degraded":
+		return SeverityWarning
+	}
+	return SeverityInfo
+}
+
+func severity(p Payload) string {
+	if s, ok := p.Data["severity"].(string); ok && s != "" {
+		return s
+	}
+	s, _ := p.Data["current"].(string)
+	return SeverityOf(s)
+}
+
+func mentionOf(p Payload) string {
+	m, _ := p.Data["mention"].(string)
+	return strings.TrimSpace(m)
+}
+
+func isPhoneNumber(s string) bool {
+	s = strings.TrimPrefix(s, "+")
+	if len(s) < 6 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// formatMention renders a mention in the form chat platforms recognize.
+func formatMention(m string) string {
+	if m == "" || strings.HasPrefix(m, "@") || strings.HasPrefix(m, "<") {
+		return m
+	}
+	return "@" + m
+}
+
+func discordMention(m string) string {
+	if m == "" || strings.HasPrefix(m, "<") {
+		return m
+	}
+	// Bare numeric IDs are Discord user snowflakes.
+	if isPhoneNumber(m) {
+		return "<@" + m + ">"
+	}
+	return formatMention(m)
+}
+
 func translateEventType(t string) string {
 	switch t {
 	case "status_changed":
@@ -168,35 +491,66 @@ func translateEventType(t string) string {
 		return "自动修复"
 	case "error":
 		return "错误"
+	case "docker_unreachable":
+		return "Docker 守护进程不可达"
+	case "docker_recovered":
+		return "Docker 守护进程已恢复"
+	case "crash_loop":
+		return "容器崩溃循环"
+	case "digest":
+		return "告警汇总"
+	case "monitor_created":
+		return "监控已创建"
+	case "monitor_updated":
+		return "监控已修改"
+	case "monitor_deleted":
+		return "监控已删除"
+	case "monitor_paused":
+		return "监控已暂停"
+	case "monitor_resumed":
+		return "监控已恢复"
 	default:
 		return t
 	}
 }
 
-func formatMarkdown(title string, p Payload) string {
-	var buf bytes.Buffer
-
-	// Status Emoji
-	statusEmoji := "ℹ️"
-	if _s, ok := p.Data["current"].(string); ok {
-		if _s == "up" {
-			statusEmoji = "🟢"
-		} else if _s == "down" {
-			statusEmoji = "🔴"
+// Summary is a one-line description of a payload for delivery logs.
+func Summary(p Payload) string {
+	parts := []string{translateEventType(p.Type)}
+	for _, key := range []string{"monitorName", "current", "message"} {
+		if v, ok := p.Data[key].(string); ok && v != "" {
+			parts = append(parts, v)
 		}
 	}
+	return strings.Join(parts, " · ")
+}
+
+// eventField is one labelled line of a notification summary.
+type eventField struct {
+	Label string
+	Value string
+}
+
+// eventFields extracts the human-readable summary lines shared by all
+// markdown-style providers.
+func eventFields(p Payload) []eventField {
+	var fields []eventField
+	add := func(label, value string) {
+		fields = append(fields, eventField{Label: label, Value: value})
+	}
 
-	// Title with double newline to ensure separation
-	buf.WriteString(fmt.Sprintf("# %s %s\n\n", statusEmoji, title))
+	if mention := mentionOf(p); mention != "" {
+		add("负责人", formatMention(mention))
+	}
 
 	// Monitor Name
 	if name, ok := p.Data["monitorName"].(string); ok && name != "" {
-		buf.WriteString(fmt.Sprintf("- **监控名称**: %s\n", name))
+		add("监控名称", name)
 	}
 
 	// Target
 	if target, ok := p.Data["target"].(string); ok && target != "" {
-		buf.WriteString(fmt.Sprintf("- **监控目标**: %s\n", target))
+		add("监控目标", target)
 	}
 
 	// Status
@@ -206,35 +560,126 @@ func formatMarkdown(title string, p Payload) string {
 			statusText = "🟢 正常 (Up)"
 		} else if current == "down" {
 			statusText = "🔴 故障 (Down)"
+		} else if current == "orphaned" {
+			statusText = "⚪ 容器已移除 (Orphaned)"
+		} else if current == "crash_loop" {
+			statusText = "🟠 崩溃循环 (Crash loop)"
+		} else if current == "degraded" {
+			statusText = "🟡 性能下降 (Degraded)"
 		}
-		buf.WriteString(fmt.Sprintf("- **当前状态**: %s\n", statusText))
+		add("当前状态", statusText)
 	}
 
-	buf.WriteString(fmt.Sprintf("- **时间**: %s\n", p.At.Format("2006-01-02 15:04:05")))
+	add("时间", p.At.Format("2006-01-02 15:04:05"))
 
 	if msg, ok := p.Data["message"].(string); ok && msg != "" {
-		buf.WriteString(fmt.Sprintf("- **消息**: %s\n", msg))
+		add("消息", msg)
+	}
+
+	if r, ok := p.Data["reminder"].(bool); ok && r {
+		add("重复提醒", "故障仍未恢复")
+	}
+	if d, ok := p.Data["downFor"].(string); ok {
+		add("故障持续", d)
+	}
+	if d, ok := p.Data["upFor"].(string); ok {
+		add("正常持续", d)
+	}
+
+	if events, ok := p.Data["events"].([]string); ok {
+		for i, ev := range events {
+			add(fmt.Sprintf("事件 %d", i+1), ev)
+		}
 	}
 
 	if lat, ok := p.Data["latencyMs"]; ok {
-		buf.WriteString(fmt.Sprintf("- **延迟**: %v ms\n", lat))
+		add("延迟", fmt.Sprintf("%v ms", lat))
 	}
 
 	// Remediation info
 	if action, ok := p.Data["action"].(string); ok {
-		buf.WriteString(fmt.Sprintf("- **修复动作**: %s\n", action))
+		add("修复动作", action)
 	}
 	if attempt, ok := p.Data["attempt"]; ok {
-		buf.WriteString(fmt.Sprintf("- **尝试次数**: %v\n", attempt))
+		add("尝试次数", fmt.Sprint(attempt))
+	}
+	if manual, ok := p.Data["manual"].(bool); ok && manual {
+		add("触发方式", "手动")
+	}
+	if ok, isSet := p.Data["success"].(bool); isSet {
+		if ok {
+			add("修复结果", "成功")
+		} else {
+			add("修复结果", "失败")
+		}
+	}
+	if e, ok := p.Data["error"].(string); ok && e != "" {
+		add("错误", e)
+	}
+	if code, ok := p.Data["exitCode"]; ok {
+		add("退出码", fmt.Sprint(code))
+	}
+	if code, ok := p.Data["statusCode"]; ok {
+		add("HTTP 状态码", fmt.Sprint(code))
+	}
+	if out, ok := p.Data["output"].(string); ok && out != "" {
+		if len(out) > 500 {
+			out = "..." + out[len(out)-500:]
+		}
+		add("命令输出", out)
+	}
+	if id, ok := p.Data["containerId"].(string); ok {
+		add("新容器", id)
+	}
+
+	if level, ok := p.Data["escalation"]; ok {
+		add("升级级别", fmt.Sprint(level))
+	}
+
+	return fields
+}
+
+func statusEmoji(p Payload) string {
+	if s, ok := p.Data["current"].(string); ok {
+		if s == "up" {
+			return "🟢"
+		} else if s == "down" {
+			return "🔴"
+		} else if s == "crash_loop" {
+			return "🟠"
+		} else if s == "degraded" {
+			return "🟡"
+		}
+	}
+	return "ℹ️"
+}
+
+// truncatedLogs returns the tail of the attached logs, limited to keep chat
+// messages under provider size limits.
+func truncatedLogs(p Payload) (string, bool) {
+	content := p.Logs.Content
+	if len(content) > 1000 {
+		return content[len(content)-1000:], true
+	}
+	return content, false
+}
+
+func formatMarkdown(title string, p Payload) string {
+	var buf bytes.Buffer
+
+	// Title with double newline to ensure separation
+	buf.WriteString(fmt.Sprintf("# %s %s\n\n", statusEmoji(p), title))
+
+	for _, f := range eventFields(p) {
+		buf.WriteString(fmt.Sprintf("- **%s**: %s\n", f.Label, f.Value))
 	}
 
 	if p.Logs != nil {
 		buf.WriteString("\n> **容器日志**:\n\n")
 		buf.WriteString("```\n")
 		// Limit log length for markdown to avoid message too long errors
-		content := p.Logs.Content
-		if len(content) > 1000 {
-			content = content[len(content)-1000:]
+		content, truncated := truncatedLogs(p)
+		if truncated {
 			buf.WriteString("...(已截断)...\n")
 		}
 		buf.WriteString(content)
@@ -0,0 +1,106 @@
+package notify
+
+// ProviderField describes one configurable field of a notification provider.
+type ProviderField struct {
+	Name        string `json:"name"`
+	Label       string `json:"label"`
+	Type        string `json:"type"` // string, url, secret, number, bool, text, map
+	Required    bool   `json:"required"`
+	Pattern     string `json:"pattern,omitempty"`
+	Placeholder string `json:"placeholder,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Provider describes a notification provider type and the fields it accepts.
+type Provider struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Fields      []ProviderField `json:"fields"`
+}
+
+const urlPattern = `^https?://.+`
+
+var providers = []Provider{
+	{
+		Type:        "webhook",
+		Name:        "Generic Webhook",
+		Description: "Sends the JSON event payload, or a custom templated body, to the given URL.",
+		Fields: []ProviderField{
+			{Name: "url", Label: "Webhook URL", Type: "url", Required: true, Pattern: urlPattern, Placeholder: "https://example.com/hook"},
+			{Name: "method", Label: "HTTP Method", Type: "string", Pattern: `^(?i)(POST|PUT|PATCH|GET)$`, Placeholder: "POST"},
+			{Name: "headers", Label: "Headers", Type: "map", Description: "Extra request headers, e.g. an API key."},
+			{Name: "bearerToken", Label: "Bearer Token", Type: "secret", Description: "Sent as Authorization: Bearer <token>."},
+			{Name: "bodyTemplate", Label: "Body Template", Type: "text", Description: `Go template over the event payload, e.g. {"text": {{json (index .Data "message")}}}. Defaults to the raw JSON payload.`},
+		},
+	},
+	{
+		Type:        "dingtalk",
+		Name:        "DingTalk",
+		Description: "DingTalk custom robot, markdown message.",
+		Fields: []ProviderField{
+			{Name: "url", Label: "Robot Webhook URL", Type: "url", Required: true, Pattern: `^https://oapi\.dingtalk\.com/robot/send\?access_token=.+`, Placeholder: "https://oapi.dingtalk.com/robot/send?access_token=..."},
+		},
+	},
+	{
+		Type:        "wechat",
+		Name:        "WeCom (WeChat Work)",
+		Description: "WeCom group robot, markdown message.",
+		Fields: []ProviderField{
+			{Name: "url", Label: "Robot Webhook URL", Type: "url", Required: true, Pattern: `^https://qyapi\.weixin\.qq\.com/cgi-bin/webhook/send\?key=.+`, Placeholder: "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=..."},
+		},
+	},
+	{
+		Type:        "discord",
+		Name:        "Discord",
+		Description: "Discord channel webhook, embed message.",
+		Fields: []ProviderField{
+			{Name: "url", Label: "Webhook URL", Type: "url", Required: true, Pattern: `^https://(discord|discordapp)\.com/api/webhooks/.+`, Placeholder: "https://discord.com/api/webhooks/..."},
+		},
+	},
+	{
+		Type:        "telegram",
+		Name:        "Telegram",
+		Description: "Telegram bot message (MarkdownV2), container logs as a code block.",
+		Fields: []ProviderField{
+			{Name: "botToken", Label: "Bot Token", Type: "secret", Required: true, Pattern: `^[0-9]+:[A-Za-z0-9_-]+$`, Placeholder: "123456:ABC-DEF..."},
+			{Name: "chatId", Label: "Chat ID", Type: "string", Required: true, Pattern: `^(-?[0-9]+|@[A-Za-z0-9_]+)$`, Placeholder: "-1001234567890 or @channel"},
+			{Name: "url", Label: "Bot API URL", Type: "url", Pattern: urlPattern, Placeholder: "https://api.telegram.org", Description: "Optional, for self-hosted Bot API servers or proxies."},
+		},
+	},
+	{
+		Type:        "feishu",
+		Name:        "Feishu / Lark",
+		Description: "Feishu (Lark) custom bot, interactive card with markdown.",
+		Fields: []ProviderField{
+			{Name: "url", Label: "Bot Webhook URL", Type: "url", Required: true, Pattern: `^https://open\.(feishu\.cn|larksuite\.com)/open-apis/bot/v2/hook/.+`, Placeholder: "https://open.feishu.cn/open-apis/bot/v2/hook/..."},
+			{Name: "secret", Label: "Signing Secret", Type: "secret", Description: "Required when signature verification is enabled on the bot."},
+		},
+	},
+	{
+		Type:        "pushover",
+		Name:        "Pushover",
+		Description: "Pushover push notification; down events use emergency priority until acknowledged.",
+		Fields: []ProviderField{
+			{Name: "userKey", Label: "User Key", Type: "secret", Required: true, Pattern: `^[A-Za-z0-9]{30}$`},
+			{Name: "appToken", Label: "Application Token", Type: "secret", Required: true, Pattern: `^[A-Za-z0-9]{30}$`},
+		},
+	},
+}
+
+// Providers returns the catalog of supported notification providers.
+func Providers() []Provider {
+	out := make([]Provider, len(providers))
+	copy(out, providers)
+	return out
+}
+
+// LookupProvider returns the provider with the given type.
+func LookupProvider(t string) (Provider, bool) {
+	for _, p := range providers {
+		if p.Type == t {
+			return p, true
+		}
+	}
+	return Provider{}, false
+}
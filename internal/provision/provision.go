@@ -0,0 +1,137 @@
+// Package provision reconciles monitors declared in a YAML file with the
+// store, so that they can be managed from version control. Provisioned
+// monitors are read-only in the API; the file is their source of truth.
+package provision
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+	"github.com/lsy88/uptime-chopper/internal/store"
+)
+
+// Result counts what a reconciliation changed.
+type Result struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Deleted   int `json:"deleted"`
+	Unchanged int `json:"unchanged"`
+}
+
+// Load reads the monitors listed under the top-level "monitors" key. Keys
+// use the same names as the JSON API, e.g. intervalSeconds, and unknown
+// keys are rejected to catch typos.
+func Load(path string) ([]model.Monitor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Monitors []map[string]any `yaml:"monitors"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	out := make([]model.Monitor, 0, len(doc.Monitors))
+	for i, raw := range doc.Monitors {
+		// Round-trip through JSON so the model's json tags apply.
+		b, err := json.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("monitor %d: %w", i, err)
+		}
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		var m model.Monitor
+		if err := dec.Decode(&m); err != nil {
+			return nil, fmt.Errorf("monitor %d: %w", i, err)
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// Reconcile makes the provisioned monitors in st match declared: new ones
+// are created, changed ones updated and provisioned monitors missing from
+// declared deleted. A monitor created through the API with a declared ID is
+// taken over. prepare normalizes and validates each monitor; nothing is
+// changed unless every declared monitor passes.
+//
+// Whether a monitor is paused is operational state: it is taken from the
+// file when the monitor is created and left alone afterwards.
+func Reconcile(st store.Store, declared []model.Monitor, prepare func(model.Monitor) (model.Monitor, error)) (Result, error) {
+	var res Result
+	existing := map[string]model.Monitor{}
+	for _, m := range st.GetState().Monitors {
+		existing[m.ID] = m
+	}
+
+	var errs []error
+	var upserts []model.Monitor
+	seen := map[string]bool{}
+	for i, m := range declared {
+		if m.ID == "" {
+			errs = append(errs, fmt.Errorf("monitor %d (%s): missing id", i, m.Name))
+			continue
+		}
+		if seen[m.ID] {
+			errs = append(errs, fmt.Errorf("monitor %s: duplicate id", m.ID))
+			continue
+		}
+		seen[m.ID] = true
+		m.Provisioned = true
+		cur, ok := existing[m.ID]
+		if ok {
+			m.IsPaused, m.PausedReason = cur.IsPaused, cur.PausedReason
+			m.CreatedAt = cur.CreatedAt
+		}
+		m, err := prepare(m)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("monitor %s: %w", m.ID, err))
+			continue
+		}
+		switch {
+		case !ok:
+			res.Created++
+		case sameMonitor(cur, m):
+			res.Unchanged++
+			continue
+		default:
+			res.Updated++
+		}
+		upserts = append(upserts, m)
+	}
+	if len(errs) > 0 {
+		return Result{}, errors.Join(errs...)
+	}
+
+	if len(upserts) > 0 {
+		if _, err := st.UpsertMonitors(upserts); err != nil {
+			return Result{}, err
+		}
+	}
+	for id, m := range existing {
+		if m.Provisioned && !seen[id] {
+			if err := st.DeleteMonitor(id); err != nil {
+				return res, err
+			}
+			res.Deleted++
+		}
+	}
+	return res, nil
+}
+
+// sameMonitor compares two monitors ignoring their timestamps.
+func sameMonitor(a, b model.Monitor) bool {
+	a.CreatedAt, a.UpdatedAt = time.Time{}, time.Time{}
+	b.CreatedAt, b.UpdatedAt = time.Time{}, time.Time{}
+	ja, err1 := json.Marshal(a)
+	jb, err2 := json.Marshal(b)
+	return err1 == nil && err2 == nil && bytes.Equal(ja, jb)
+}
@@ -0,0 +1,195 @@
+// Package redact hides credentials in values shown to API clients and in
+// log output. Secret references such as {{secret "name"}} are kept, since
+// they do not reveal the value.
+package redact
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Mask replaces a redacted value. Sending it back in an update keeps the
+// stored value.
+const Mask = "********"
+
+// sensitiveWords mark header, query and log field names whose values are
+// credentials, compared case-insensitively without '-' and '_'.
+var sensitiveWords = []string{"authorization", "cookie", "password", "passwd", "secret", "token", "apikey", "accesskey", "privatekey", "signature", "credential"}
+
+// sensitiveParams are additional query parameters that carry credentials
+// in webhook URLs, e.g. the WeCom robot key.
+var sensitiveParams = map[string]bool{"key": true, "sig": true, "auth": true, "pass": true, "pwd": true}
+
+var (
+	userinfoPattern = regexp.MustCompile(`(\b[A-Za-z][A-Za-z0-9+.-]*://[^/\s:@]*):[^/\s@]+@`)
+	paramPattern    = regexp.MustCompile(`([?&;]([A-Za-z0-9_.-]+)=)[^&\s"']+`)
+	bearerPattern   = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/=-]{8,}`)
+	mysqlPattern    = regexp.MustCompile(`^([^:@/\s]*):([^@\s]+)@`)
+	kvPattern       = regexp.MustCompile(`(?i)\b(password|pwd)=('[^']*'|[^\s;]+)`)
+	secretRef       = regexp.MustCompile(`^\s*\{\{\s*secret\s+"[^"]*"\s*\}\}\s*$`)
+	// hookPathPattern matches webhook URLs whose path is the credential:
+	// Discord, Slack, Feishu/Lark, Teams and Mattermost-style /hooks/.
+	hookPathPattern = regexp.MustCompile(`(?i)(\bhttps?://(?:(?:discord|discordapp)\.com/api/webhooks/|hooks\.slack\.com/(?:services|workflows|triggers)/|open\.(?:feishu\.cn|larksuite\.com)/open-apis/bot/v2/hook/|[a-z0-9-]+\.webhook\.office\.com/|[^\s/?#"'@]+/hooks/))[^\s?#"']+`)
+	// botTokenPattern matches the bot token in Telegram Bot API paths.
+	botTokenPattern = regexp.MustCompile(`(/bot)[0-9]+:[A-Za-z0-9_-]+`)
+)
+
+// SensitiveName reports whether a header, query parameter or field called
+// name holds a credential.
+func SensitiveName(name string) bool {
+	n := strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(name))
+	for _, w := range sensitiveWords {
+		if strings.Contains(n, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// Value masks a credential, keeping empty values and secret references.
+func Value(v string) string {
+	if v == "" || secretRef.MatchString(v) {
+		return v
+	}
+	return Mask
+}
+
+// URL masks the password in the userinfo of raw, the values of query
+// parameters that carry credentials, and the path of webhook URLs whose
+// path is the credential, such as Discord, Slack and Feishu webhooks and
+// Telegram bot tokens.
+func URL(raw string) string {
+	s := userinfoPattern.ReplaceAllString(raw, "${1}:"+Mask+"@")
+	s = hookPathPattern.ReplaceAllStringFunc(s, func(p string) string {
+		m := hookPathPattern.FindStringSubmatch(p)
+		if strings.HasPrefix(p[len(m[1]):], "{{") {
+			return p
+		}
+		return m[1] + Mask
+	})
+	s = botTokenPattern.ReplaceAllString(s, "${1}"+Mask)
+	return paramPattern.ReplaceAllStringFunc(s, func(p string) string {
+		m := paramPattern.FindStringSubmatch(p)
+		if !sensitiveParam(m[2]) || strings.HasPrefix(p[len(m[1]):], "{{") {
+			return p
+		}
+		return m[1] + Mask
+	})
+}
+
+// DSN masks the password of a database DSN in URL form, the MySQL
+// user:password@tcp(host)/db form or the key=value form.
+func DSN(dsn string) string {
+	if strings.Contains(dsn, "://") {
+		return URL(dsn)
+	}
+	if m := mysqlPattern.FindStringSubmatch(dsn); m != nil && !secretRef.MatchString(m[2]) {
+		return m[1] + ":" + Mask + "@" + dsn[len(m[0]):]
+	}
+	return kvPattern.ReplaceAllStringFunc(dsn, func(s string) string {
+		k, v, _ := strings.Cut(s, "=")
+		return k + "=" + Value(v)
+	})
+}
+
+// Headers returns a copy of h with the values of credential headers masked.
+func Headers(h map[string]string) map[string]string {
+	if len(h) == 0 {
+		return h
+	}
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		if SensitiveName(k) {
+			v = Value(v)
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// Env masks the values of KEY=value pairs whose key names a credential.
+func Env(env []string) []string {
+	if len(env) == 0 {
+		return env
+	}
+	out := make([]string, len(env))
+	for i, kv := range env {
+		if k, v, ok := strings.Cut(kv, "="); ok && SensitiveName(k) {
+			kv = k + "=" + Value(v)
+		}
+		out[i] = kv
+	}
+	return out
+}
+
+// String masks credentials embedded in free text such as log messages and
+// errors: URL passwords, credential query parameters and paths, and
+// Authorization header values.
+func String(s string) string {
+	if s == "" {
+		return s
+	}
+	return bearerPattern.ReplaceAllString(URL(s), "$1 "+Mask)
+}
+
+// Restore returns in, or prev when in is the redacted form of prev, so that
+// a client can send back what it was shown without wiping the credential.
+func Restore(in, prev string, redact func(string) string) string {
+	if in != "" && in != prev && in == redact(prev) {
+		return prev
+	}
+	return in
+}
+
+// Masked reports whether v still holds a masked value.
+func Masked(v string) bool {
+	return strings.Contains(v, Mask)
+}
+
+// SameTarget reports whether two endpoint URLs have the same scheme and
+// host, so that credentials stored for one may be sent to the other.
+func SameTarget(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ua, err := url.Parse(strings.TrimSpace(a))
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(strings.TrimSpace(b))
+	if err != nil {
+		return false
+	}
+	return ua.Host != "" && strings.EqualFold(ua.Scheme, ub.Scheme) && strings.EqualFold(ua.Host, ub.Host)
+}
+
+// RestoreHeaders applies Restore to each header that is still present.
+func RestoreHeaders(in, prev map[string]string) map[string]string {
+	for k, v := range in {
+		if p, ok := prev[k]; ok {
+			in[k] = Restore(v, p, func(s string) string { return Headers(map[string]string{k: s})[k] })
+		}
+	}
+	return in
+}
+
+// RestoreEnv applies Restore to the KEY=value pairs of in by key.
+func RestoreEnv(in, prev []string) []string {
+	byKey := map[string]string{}
+	for _, kv := range prev {
+		k, _, _ := strings.Cut(kv, "=")
+		byKey[k] = kv
+	}
+	for i, kv := range in {
+		k, _, _ := strings.Cut(kv, "=")
+		if p, ok := byKey[k]; ok {
+			in[i] = Restore(kv, p, func(s string) string { return Env([]string{s})[0] })
+		}
+	}
+	return in
+}
+
+func sensitiveParam(name string) bool {
+	return sensitiveParams[strings.ToLower(name)] || SensitiveName(name)
+}
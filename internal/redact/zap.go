@@ -0,0 +1,53 @@
+package redact
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Core wraps c so that messages and string or error fields are scrubbed
+// with String, and string fields named like credentials are masked. Use it
+// with zap.WrapCore.
+func Core(c zapcore.Core) zapcore.Core {
+	return core{c}
+}
+
+type core struct {
+	zapcore.Core
+}
+
+func (c core) With(fields []zapcore.Field) zapcore.Core {
+	return core{c.Core.With(scrub(fields))}
+}
+
+func (c core) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(e.Level) {
+		return ce.AddCore(e, c)
+	}
+	return ce
+}
+
+func (c core) Write(e zapcore.Entry, fields []zapcore.Field) error {
+	e.Message = String(e.Message)
+	return c.Core.Write(e, scrub(fields))
+}
+
+func scrub(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		switch f.Type {
+		case zapcore.StringType:
+			if SensitiveName(f.Key) {
+				f.String = Value(f.String)
+			} else {
+				f.String = String(f.String)
+			}
+		case zapcore.ErrorType:
+			if err, ok := f.Interface.(error); ok && err != nil {
+				f = zap.String(f.Key, String(err.Error()))
+			}
+		}
+		out[i] = f
+	}
+	return out
+}
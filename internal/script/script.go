@@ -0,0 +1,92 @@
+// Package script runs local commands under a deadline, killing the
+// whole process tree when it expires, and captures their output.
+package script
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// maxOutput caps the output kept from a command.
+const maxOutput = 4096
+
+// Result is the outcome of a command. Output holds combined stdout and
+// stderr, truncated to its last 4 KiB.
+type Result struct {
+	ExitCode int    `json:"exitCode"`
+	Output   string `json:"output"`
+}
+
+// Run runs script with the platform shell (sh -c, or cmd /C on Windows).
+// Its environment is the search path (and on Windows the few variables
+// cmd.exe needs) plus the variables in env; the rest of the server's
+// environment, secrets included, is not passed on. The command and any
+// processes it started are killed once timeout elapses or ctx is done.
+func Run(ctx context.Context, script string, env []string, timeout time.Duration) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return run(ctx, shellCommand(ctx, script), env)
+}
+
+// RunCommand is like Run but executes argv directly, without a shell.
+func RunCommand(ctx context.Context, argv []string, env []string, timeout time.Duration) (Result, error) {
+	if len(argv) == 0 {
+		return Result{ExitCode: -1}, errors.New("empty command")
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return run(ctx, exec.CommandContext(ctx, argv[0], argv[1:]...), env)
+}
+
+func run(ctx context.Context, cmd *exec.Cmd, env []string) (Result, error) {
+	cmd.Env = append(inherit(baseEnv...), env...)
+	out := &tailBuffer{max: maxOutput}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	// Children may keep the output pipes open after the shell is killed.
+	cmd.WaitDelay = time.Second
+	isolate(cmd)
+
+	err := cmd.Run()
+	res := Result{ExitCode: cmd.ProcessState.ExitCode(), Output: out.String()}
+	if ctx.Err() != nil {
+		return res, ctx.Err()
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// A non-zero exit is reported through ExitCode.
+		return res, nil
+	}
+	return res, err
+}
+
+// inherit returns the named variables of the server's environment that are
+// set, as KEY=value.
+func inherit(names ...string) []string {
+	var out []string
+	for _, name := range names {
+		if v, ok := os.LookupEnv(name); ok {
+			out = append(out, name+"="+v)
+		}
+	}
+	return out
+}
+
+// tailBuffer keeps the last max bytes written to it.
+type tailBuffer struct {
+	max int
+	buf []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - b.max; over > 0 {
+		b.buf = b.buf[over:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string { return string(b.buf) }
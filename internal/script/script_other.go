@@ -0,0 +1,25 @@
+//go:build !windows
+
+package script
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+)
+
+// baseEnv lists the variables commands inherit from the server.
+var baseEnv = []string{"PATH"}
+
+func shellCommand(ctx context.Context, script string) *exec.Cmd {
+	return exec.CommandContext(ctx, "sh", "-c", script)
+}
+
+// isolate runs the command in its own process group so that cancellation
+// kills everything it spawned, not just the shell.
+func isolate(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+package script
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+)
+
+// baseEnv lists the variables commands inherit from the server; cmd.exe
+// and most programs fail without the system ones.
+var baseEnv = []string{"PATH", "PATHEXT", "SystemRoot", "ComSpec", "TEMP", "TMP"}
+
+func shellCommand(ctx context.Context, script string) *exec.Cmd {
+	return exec.CommandContext(ctx, "cmd", "/C", script)
+}
+
+// isolate makes cancellation kill the whole process tree, not just cmd.exe.
+func isolate(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+	}
+}
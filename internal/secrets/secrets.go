@@ -0,0 +1,223 @@
+// Package secrets resolves {{secret "name"}} references in monitor and
+// notification settings, so that credentials live in the environment or
+// encrypted in the store instead of in plain text next to the monitors.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+// EnvPrefix is prepended to the upper-cased name of a secret to find its
+// value in the environment, e.g. UPTIME_SECRET_API_TOKEN for "api_token".
+const EnvPrefix = "UPTIME_SECRET_"
+
+// ErrNoKey is returned when a secret is stored or read without a
+// configured secret key.
+var ErrNoKey = errors.New("secret_key is not configured")
+
+var (
+	refPattern  = regexp.MustCompile(`\{\{\s*secret\s+"([^"]*)"\s*\}\}`)
+	namePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+)
+
+// ValidName reports whether name can be used for a secret.
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// EnvName returns the environment variable holding the secret name.
+func EnvName(name string) string {
+	return EnvPrefix + strings.ToUpper(name)
+}
+
+// EnvNames returns the names of the secrets set in the environment.
+func EnvNames() []string {
+	var names []string
+	for _, kv := range os.Environ() {
+		k, _, _ := strings.Cut(kv, "=")
+		if name, ok := strings.CutPrefix(k, EnvPrefix); ok && ValidName(name) {
+			names = append(names, strings.ToLower(name))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Refs returns the names of the secrets referenced in s.
+func Refs(s string) []string {
+	var names []string
+	for _, m := range refPattern.FindAllStringSubmatch(s, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+// Box encrypts stored secret values with AES-256-GCM under a key derived
+// from the configured secret key.
+type Box struct {
+	aead cipher.AEAD
+}
+
+// NewBox returns the box for key, or nil when key is empty.
+func NewBox(key string) (*Box, error) {
+	if key == "" {
+		return nil, nil
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// Seal encrypts plain and returns it base64 encoded with its nonce.
+func (b *Box) Seal(plain string) (string, error) {
+	if b == nil {
+		return "", ErrNoKey
+	}
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b.aead.Seal(nonce, nonce, []byte(plain), nil)), nil
+}
+
+// Open decrypts a value produced by Seal.
+func (b *Box) Open(sealed string) (string, error) {
+	if b == nil {
+		return "", ErrNoKey
+	}
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < b.aead.NonceSize() {
+		return "", errors.New("malformed secret value")
+	}
+	n := b.aead.NonceSize()
+	plain, err := b.aead.Open(nil, data[:n], data[n:], nil)
+	if err != nil {
+		return "", errors.New("cannot decrypt secret value; was secret_key changed?")
+	}
+	return string(plain), nil
+}
+
+// Source looks up stored secrets by name.
+type Source interface {
+	GetSecret(name string) (model.Secret, bool)
+}
+
+// Resolver looks secrets up in the environment first and then in the
+// store. A nil *Resolver only uses the environment.
+type Resolver struct {
+	box *Box
+	src Source
+}
+
+// NewResolver returns a resolver reading stored secrets from src and
+// decrypting them with box. Either may be nil.
+func NewResolver(box *Box, src Source) *Resolver {
+	return &Resolver{box: box, src: src}
+}
+
+// Box returns the box stored secrets are encrypted with, nil without a
+// secret key.
+func (r *Resolver) Box() *Box {
+	if r == nil {
+		return nil
+	}
+	return r.box
+}
+
+// Lookup returns the value of the secret name.
+func (r *Resolver) Lookup(name string) (string, error) {
+	if v, ok := os.LookupEnv(EnvName(name)); ok {
+		return v, nil
+	}
+	if r != nil && r.src != nil {
+		if s, ok := r.src.GetSecret(name); ok {
+			return r.box.Open(s.Value)
+		}
+	}
+	return "", fmt.Errorf("unknown secret %q", name)
+}
+
+// Expander replaces secret references and remembers the values it
+// inserted, so that they can be masked in messages afterwards.
+type Expander struct {
+	r      *Resolver
+	values []string
+	err    error
+}
+
+// Expander returns a new expander using r.
+func (r *Resolver) Expander() *Expander {
+	return &Expander{r: r}
+}
+
+// Expand replaces the references in s with the secret values. After the
+// first failed lookup it leaves strings unchanged; see Err.
+func (x *Expander) Expand(s string) string {
+	return x.expand(s, func(v string) string { return v })
+}
+
+// ExpandTemplate is Expand for Go template source: references become
+// string constants, so values containing "{{" are not parsed as actions.
+func (x *Expander) ExpandTemplate(s string) string {
+	return x.expand(s, func(v string) string { return "{{" + strconv.Quote(v) + "}}" })
+}
+
+func (x *Expander) expand(s string, render func(string) string) string {
+	if x.err != nil || !strings.Contains(s, "{{") {
+		return s
+	}
+	return refPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		if x.err != nil {
+			return ref
+		}
+		name := refPattern.FindStringSubmatch(ref)[1]
+		v, err := x.r.Lookup(name)
+		if err != nil {
+			x.err = err
+			return ref
+		}
+		x.values = append(x.values, v)
+		return render(v)
+	})
+}
+
+// Err returns the first lookup error.
+func (x *Expander) Err() error {
+	return x.err
+}
+
+// Mask replaces the secret values inserted so far, also in their URL
+// encoded forms, with "***". Values shorter than four bytes are left
+// alone, since masking them would garble unrelated text.
+func (x *Expander) Mask(s string) string {
+	for _, v := range x.values {
+		if len(v) < 4 {
+			continue
+		}
+		for _, form := range []string{v, url.QueryEscape(v), url.PathEscape(v)} {
+			s = strings.ReplaceAll(s, form, "***")
+		}
+	}
+	return s
+}
@@ -0,0 +1,36 @@
+package store
+
+import "sync"
+
+// MonitorChange reports that a monitor was created, updated or deleted.
+type MonitorChange struct {
+	ID      string
+	Deleted bool
+}
+
+// changeFeed fans monitor changes out to subscribers. Sends never block
+// the writer: a subscriber that falls behind loses changes, which the
+// engine tolerates because its scheduler re-reads the monitors every tick.
+type changeFeed struct {
+	mu   sync.Mutex
+	subs []chan MonitorChange
+}
+
+func (f *changeFeed) MonitorChanges() <-chan MonitorChange {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ch := make(chan MonitorChange, 256)
+	f.subs = append(f.subs, ch)
+	return ch
+}
+
+func (f *changeFeed) publish(c MonitorChange) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, ch := range f.subs {
+		select {
+		case ch <- c:
+		default:
+		}
+	}
+}
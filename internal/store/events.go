@@ -0,0 +1,181 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+// jsonEventLimit caps the events kept by the JSON backend.
+const jsonEventLimit = 5000
+
+// EventFilter selects events for GetEvents. Empty fields match any event;
+// From and To bound the event time to [From, To).
+type EventFilter struct {
+	MonitorID string
+	Type      model.EventType
+	From, To  time.Time
+	Limit     int
+	Offset    int
+}
+
+func (f EventFilter) match(ev model.Event) bool {
+	return (f.MonitorID == "" || ev.MonitorID == f.MonitorID) &&
+		(f.Type == "" || ev.Type == f.Type) &&
+		inRange(ev.At, f.From, f.To)
+}
+
+func (s *SQLiteStore) AddEvent(ev model.Event) error {
+	ev.At = ev.At.UTC()
+	data, err := json.Marshal(ev.Data)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO events (id, type, monitor_id, at, data) VALUES (?, ?, ?, ?, ?)`,
+		ev.ID, string(ev.Type), ev.MonitorID, ev.At.UnixNano(), string(data))
+	return err
+}
+
+func (s *SQLiteStore) GetEvents(f EventFilter) ([]model.Event, int, error) {
+	if f.Limit <= 0 {
+		f.Limit = 100
+	}
+	where := `WHERE (? = '' OR monitor_id = ?) AND (? = '' OR type = ?) AND (? = 0 OR at >= ?) AND (? = 0 OR at < ?)`
+	from, to := unixNanoOrZero(f.From), unixNanoOrZero(f.To)
+	args := []any{f.MonitorID, f.MonitorID, string(f.Type), string(f.Type), from, from, to, to}
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM events `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := s.db.Query(`SELECT id, type, monitor_id, at, data FROM events `+where+` ORDER BY at DESC, rowid DESC LIMIT ? OFFSET ?`,
+		append(args, f.Limit, f.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	out := []model.Event{}
+	for rows.Next() {
+		var ev model.Event
+		var at int64
+		var data string
+		if err := rows.Scan(&ev.ID, &ev.Type, &ev.MonitorID, &at, &data); err != nil {
+			return nil, 0, err
+		}
+		ev.At = time.Unix(0, at).UTC()
+		_ = json.Unmarshal([]byte(data), &ev.Data)
+		out = append(out, ev)
+	}
+	return out, total, rows.Err()
+}
+
+func (s *SQLiteStore) LatestEvents() (map[string]model.Event, error) {
+	rows, err := s.db.Query(`SELECT id, type, monitor_id, at, data FROM (
+			SELECT id, type, monitor_id, at, data,
+				ROW_NUMBER() OVER (PARTITION BY monitor_id ORDER BY at DESC, rowid DESC) AS n
+			FROM events WHERE monitor_id != ''
+		) WHERE n = 1`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[string]model.Event{}
+	for rows.Next() {
+		var ev model.Event
+		var at int64
+		var data string
+		if err := rows.Scan(&ev.ID, &ev.Type, &ev.MonitorID, &at, &data); err != nil {
+			return nil, err
+		}
+		ev.At = time.Unix(0, at).UTC()
+		_ = json.Unmarshal([]byte(data), &ev.Data)
+		out[ev.MonitorID] = ev
+	}
+	return out, rows.Err()
+}
+
+func (s *SQLiteStore) PruneEvents(before time.Time) (int64, error) {
+	res, err := s.db.Exec(`DELETE FROM events WHERE at < ?`, before.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func unixNanoOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func (s *JSONStore) AddEvent(ev model.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ev.At = ev.At.UTC()
+	s.state.Events = append(s.state.Events, ev)
+	if n := len(s.state.Events); n > jsonEventLimit {
+		s.state.Events = append([]model.Event(nil), s.state.Events[n-jsonEventLimit:]...)
+	}
+	return s.persistLocked()
+}
+
+func (s *JSONStore) GetEvents(f EventFilter) ([]model.Event, int, error) {
+	if f.Limit <= 0 {
+		f.Limit = 100
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := []model.Event{}
+	total := 0
+	for i := len(s.state.Events) - 1; i >= 0; i-- {
+		ev := s.state.Events[i]
+		if !f.match(ev) {
+			continue
+		}
+		if total >= f.Offset && len(out) < f.Limit {
+			out = append(out, ev)
+		}
+		total++
+	}
+	return out, total, nil
+}
+
+func (s *JSONStore) LatestEvents() (map[string]model.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := map[string]model.Event{}
+	for _, ev := range s.state.Events {
+		if ev.MonitorID == "" {
+			continue
+		}
+		if cur, ok := out[ev.MonitorID]; !ok || !ev.At.Before(cur.At) {
+			out[ev.MonitorID] = ev
+		}
+	}
+	return out, nil
+}
+
+func (s *JSONStore) PruneEvents(before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.state.Events[:0]
+	for _, ev := range s.state.Events {
+		if !ev.At.Before(before) {
+			kept = append(kept, ev)
+		}
+	}
+	removed := int64(len(s.state.Events) - len(kept))
+	if removed == 0 {
+		return 0, nil
+	}
+	s.state.Events = kept
+	return removed, s.persistLocked()
+}
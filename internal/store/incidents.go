@@ -0,0 +1,151 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+// jsonIncidentLimit caps the incidents kept by the JSON backend.
+const jsonIncidentLimit = 1000
+
+func (s *SQLiteStore) UpsertIncident(i model.Incident) (model.Incident, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i.StartedAt = i.StartedAt.UTC()
+	data, err := json.Marshal(i)
+	if err != nil {
+		return model.Incident{}, err
+	}
+	query := `INSERT INTO incidents (id, monitor_id, started_at, resolved_at, data) VALUES (?, ?, ?, ?, ?)
+			  ON CONFLICT(id) DO UPDATE SET resolved_at=excluded.resolved_at, data=excluded.data`
+	if _, err := s.db.Exec(query, i.ID, i.MonitorID, i.StartedAt, i.ResolvedAt, string(data)); err != nil {
+		return model.Incident{}, err
+	}
+	return i, nil
+}
+
+func (s *SQLiteStore) GetIncident(id string) (model.Incident, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return scanIncident(s.db.QueryRow(`SELECT data FROM incidents WHERE id = ?`, id))
+}
+
+func (s *SQLiteStore) GetOpenIncident(monitorID string) (model.Incident, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return scanIncident(s.db.QueryRow(`SELECT data FROM incidents WHERE monitor_id = ? AND resolved_at IS NULL
+		ORDER BY started_at DESC LIMIT 1`, monitorID))
+}
+
+func scanIncident(row *sql.Row) (model.Incident, bool) {
+	var data string
+	if err := row.Scan(&data); err != nil {
+		return model.Incident{}, false
+	}
+	var i model.Incident
+	if err := json.Unmarshal([]byte(data), &i); err != nil {
+		return model.Incident{}, false
+	}
+	return i, true
+}
+
+func (s *SQLiteStore) GetIncidents(monitorID string, openOnly bool, limit int) ([]model.Incident, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	query := `SELECT data FROM incidents
+		WHERE (? = '' OR monitor_id = ?) AND (? = 0 OR resolved_at IS NULL)
+		ORDER BY started_at DESC LIMIT ?`
+	rows, err := s.db.Query(query, monitorID, monitorID, openOnly, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.Incident{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var i model.Incident
+		if err := json.Unmarshal([]byte(data), &i); err == nil {
+			out = append(out, i)
+		}
+	}
+	return out, rows.Err()
+}
+
+func (s *JSONStore) UpsertIncident(i model.Incident) (model.Incident, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i.StartedAt = i.StartedAt.UTC()
+	found := false
+	for idx := range s.state.Incidents {
+		if s.state.Incidents[idx].ID == i.ID {
+			s.state.Incidents[idx] = i
+			found = true
+			break
+		}
+	}
+	if !found {
+		s.state.Incidents = append(s.state.Incidents, i)
+		if n := len(s.state.Incidents); n > jsonIncidentLimit {
+			s.state.Incidents = append([]model.Incident(nil), s.state.Incidents[n-jsonIncidentLimit:]...)
+		}
+	}
+	if err := s.persistLocked(); err != nil {
+		return model.Incident{}, err
+	}
+	return i, nil
+}
+
+func (s *JSONStore) GetIncident(id string) (model.Incident, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, i := range s.state.Incidents {
+		if i.ID == id {
+			return i, true
+		}
+	}
+	return model.Incident{}, false
+}
+
+func (s *JSONStore) GetOpenIncident(monitorID string) (model.Incident, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for idx := len(s.state.Incidents) - 1; idx >= 0; idx-- {
+		if i := s.state.Incidents[idx]; i.MonitorID == monitorID && i.Open() {
+			return i, true
+		}
+	}
+	return model.Incident{}, false
+}
+
+func (s *JSONStore) GetIncidents(monitorID string, openOnly bool, limit int) ([]model.Incident, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := []model.Incident{}
+	for idx := len(s.state.Incidents) - 1; idx >= 0 && len(out) < limit; idx-- {
+		i := s.state.Incidents[idx]
+		if monitorID != "" && i.MonitorID != monitorID {
+			continue
+		}
+		if openOnly && !i.Open() {
+			continue
+		}
+		out = append(out, i)
+	}
+	return out, nil
+}
@@ -0,0 +1,117 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+func (s *SQLiteStore) GetMaintenanceWindows() []model.MaintenanceWindow {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	windows := []model.MaintenanceWindow{}
+	rows, err := s.db.Query("SELECT data FROM maintenance_windows")
+	if err != nil {
+		return windows
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err == nil {
+			var w model.MaintenanceWindow
+			if err := json.Unmarshal([]byte(data), &w); err == nil {
+				windows = append(windows, w)
+			}
+		}
+	}
+	return windows
+}
+
+func (s *SQLiteStore) UpsertMaintenanceWindow(w model.MaintenanceWindow) (model.MaintenanceWindow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	w.UpdatedAt = now
+	if w.CreatedAt.IsZero() {
+		w.CreatedAt = now
+	}
+
+	data, err := json.Marshal(w)
+	if err != nil {
+		return model.MaintenanceWindow{}, err
+	}
+
+	query := `INSERT INTO maintenance_windows (id, data, created_at, updated_at) VALUES (?, ?, ?, ?)
+			  ON CONFLICT(id) DO UPDATE SET data=excluded.data, updated_at=excluded.updated_at`
+
+	if _, err := s.db.Exec(query, w.ID, string(data), w.CreatedAt, w.UpdatedAt); err != nil {
+		return model.MaintenanceWindow{}, err
+	}
+	return w, nil
+}
+
+func (s *SQLiteStore) DeleteMaintenanceWindow(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec("DELETE FROM maintenance_windows WHERE id = ?", id)
+	return err
+}
+
+func (s *JSONStore) GetMaintenanceWindows() []model.MaintenanceWindow {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	dst := make([]model.MaintenanceWindow, len(s.state.MaintenanceWindows))
+	copy(dst, s.state.MaintenanceWindows)
+	return dst
+}
+
+func (s *JSONStore) UpsertMaintenanceWindow(w model.MaintenanceWindow) (model.MaintenanceWindow, error) {
+	now := time.Now().UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := false
+	for i := range s.state.MaintenanceWindows {
+		if s.state.MaintenanceWindows[i].ID == w.ID {
+			w.CreatedAt = s.state.MaintenanceWindows[i].CreatedAt
+			w.UpdatedAt = now
+			s.state.MaintenanceWindows[i] = w
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		w.CreatedAt = now
+		w.UpdatedAt = now
+		s.state.MaintenanceWindows = append(s.state.MaintenanceWindows, w)
+	}
+
+	if err := s.persistLocked(); err != nil {
+		return model.MaintenanceWindow{}, err
+	}
+
+	return w, nil
+}
+
+func (s *JSONStore) DeleteMaintenanceWindow(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dst := s.state.MaintenanceWindows[:0]
+	for _, w := range s.state.MaintenanceWindows {
+		if w.ID == id {
+			continue
+		}
+		dst = append(dst, w)
+	}
+	s.state.MaintenanceWindows = dst
+
+	return s.persistLocked()
+}
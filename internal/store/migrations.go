@@ -0,0 +1,375 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// migration is one versioned step of the SQLite schema. Steps run in order
+// inside a transaction each, and the applied versions are recorded in
+// schema_migrations. Down must undo Up so that a database can be rolled
+// back to the schema of an older release.
+type migration struct {
+	version int
+	name    string
+	up      func(tx *sql.Tx) error
+	down    func(tx *sql.Tx) error
+}
+
+// migrations lists every schema change, oldest first. Append new steps with
+// the next version number; never edit or reorder released ones.
+//
+// Databases created before versioning already hold some or all of these
+// tables and columns, so the early steps create tables only when missing
+// and add columns only when absent.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "initial schema",
+		up: execAll(
+			`CREATE TABLE IF NOT EXISTS monitors (
+				id TEXT PRIMARY KEY,
+				data TEXT NOT NULL,
+				created_at DATETIME,
+				updated_at DATETIME
+			)`,
+			`CREATE TABLE IF NOT EXISTS notifications (
+				id TEXT PRIMARY KEY,
+				data TEXT NOT NULL,
+				created_at DATETIME,
+				updated_at DATETIME
+			)`,
+			`CREATE TABLE IF NOT EXISTS routing_policies (
+				id TEXT PRIMARY KEY,
+				data TEXT NOT NULL,
+				created_at DATETIME,
+				updated_at DATETIME
+			)`,
+			`CREATE TABLE IF NOT EXISTS status_pages (
+				id TEXT PRIMARY KEY,
+				data TEXT NOT NULL,
+				created_at DATETIME,
+				updated_at DATETIME
+			)`,
+			`CREATE TABLE IF NOT EXISTS monitor_history (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				monitor_id TEXT NOT NULL,
+				status TEXT NOT NULL,
+				checked_at DATETIME NOT NULL,
+				latency_ms INTEGER NOT NULL,
+				message TEXT,
+				FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_history_monitor_id_checked_at ON monitor_history(monitor_id, checked_at DESC)`,
+			`CREATE TABLE IF NOT EXISTS uptime_daily (
+				monitor_id TEXT NOT NULL,
+				day TEXT NOT NULL,
+				up_checks INTEGER NOT NULL DEFAULT 0,
+				total_checks INTEGER NOT NULL DEFAULT 0,
+				latency_sum_ms INTEGER NOT NULL DEFAULT 0,
+				PRIMARY KEY(monitor_id, day)
+			)`,
+			`CREATE TABLE IF NOT EXISTS maintenance_windows (
+				id TEXT PRIMARY KEY,
+				data TEXT NOT NULL,
+				created_at DATETIME,
+				updated_at DATETIME
+			)`,
+			`CREATE TABLE IF NOT EXISTS notification_log (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				notification_id TEXT NOT NULL,
+				channel_name TEXT,
+				channel_type TEXT,
+				monitor_id TEXT,
+				event_type TEXT NOT NULL,
+				summary TEXT,
+				success INTEGER NOT NULL,
+				error TEXT,
+				created_at DATETIME NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_notification_log_notification ON notification_log(notification_id, id DESC)`,
+			`CREATE INDEX IF NOT EXISTS idx_notification_log_monitor ON notification_log(monitor_id, id DESC)`,
+			`CREATE TABLE IF NOT EXISTS remediation_log (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				monitor_id TEXT NOT NULL,
+				action TEXT NOT NULL,
+				attempt INTEGER NOT NULL,
+				manual INTEGER NOT NULL,
+				success INTEGER NOT NULL,
+				error TEXT,
+				result TEXT,
+				duration_ms INTEGER NOT NULL,
+				created_at DATETIME NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_remediation_log_monitor ON remediation_log(monitor_id, id DESC)`,
+			`CREATE TABLE IF NOT EXISTS incidents (
+				id TEXT PRIMARY KEY,
+				monitor_id TEXT NOT NULL,
+				started_at DATETIME NOT NULL,
+				resolved_at DATETIME,
+				data TEXT NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_incidents_monitor ON incidents(monitor_id, started_at DESC)`,
+			`CREATE TABLE IF NOT EXISTS users (
+				id TEXT PRIMARY KEY,
+				data TEXT NOT NULL,
+				created_at DATETIME,
+				updated_at DATETIME
+			)`,
+			`CREATE TABLE IF NOT EXISTS sessions (
+				token_hash TEXT PRIMARY KEY,
+				user_id TEXT NOT NULL,
+				created_at INTEGER NOT NULL,
+				expires_at INTEGER NOT NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS latency_histograms (
+				monitor_id TEXT PRIMARY KEY,
+				data TEXT NOT NULL,
+				updated_at DATETIME
+			)`,
+		),
+		down: execAll(
+			`DROP TABLE IF EXISTS latency_histograms`,
+			`DROP TABLE IF EXISTS sessions`,
+			`DROP TABLE IF EXISTS users`,
+			`DROP TABLE IF EXISTS incidents`,
+			`DROP TABLE IF EXISTS remediation_log`,
+			`DROP TABLE IF EXISTS notification_log`,
+			`DROP TABLE IF EXISTS maintenance_windows`,
+			`DROP TABLE IF EXISTS uptime_daily`,
+			`DROP TABLE IF EXISTS monitor_history`,
+			`DROP TABLE IF EXISTS status_pages`,
+			`DROP TABLE IF EXISTS routing_policies`,
+			`DROP TABLE IF EXISTS notifications`,
+			`DROP TABLE IF EXISTS monitors`,
+		),
+	},
+	{
+		version: 2,
+		name:    "monitor history check details",
+		up: addColumns("monitor_history",
+			"logs TEXT",
+			"transient INTEGER NOT NULL DEFAULT 0",
+			"conn_mode TEXT",
+			"logs_gz BLOB",
+			"weight INTEGER NOT NULL DEFAULT 1",
+			"cpu_percent REAL",
+			"mem_percent REAL",
+			"regions TEXT",
+		),
+		down: dropColumns("monitor_history", "logs", "transient", "conn_mode", "logs_gz", "weight", "cpu_percent", "mem_percent", "regions"),
+	},
+	{
+		version: 3,
+		name:    "event log",
+		up: execAll(
+			`CREATE TABLE events (
+				id TEXT PRIMARY KEY,
+				type TEXT NOT NULL,
+				monitor_id TEXT NOT NULL DEFAULT '',
+				at INTEGER NOT NULL,
+				data TEXT
+			)`,
+			`CREATE INDEX idx_events_at ON events(at DESC)`,
+			`CREATE INDEX idx_events_monitor ON events(monitor_id, at DESC)`,
+		),
+		down: execAll(`DROP TABLE IF EXISTS events`),
+	},
+	{
+		version: 4,
+		name:    "runtime settings",
+		up: execAll(
+			`CREATE TABLE settings (
+				id INTEGER PRIMARY KEY CHECK (id = 1),
+				data TEXT NOT NULL,
+				updated_at DATETIME
+			)`,
+		),
+		down: execAll(`DROP TABLE IF EXISTS settings`),
+	},
+	{
+		version: 5,
+		name:    "http phase timings",
+		up:      addColumns("monitor_history", "phases TEXT"),
+		down:    dropColumns("monitor_history", "phases"),
+	},
+	{
+		version: 6,
+		name:    "secrets",
+		up: execAll(
+			`CREATE TABLE secrets (
+				name TEXT PRIMARY KEY,
+				value TEXT NOT NULL,
+				created_at DATETIME,
+				updated_at DATETIME
+			)`,
+		),
+		down: execAll(`DROP TABLE IF EXISTS secrets`),
+	},
+	{
+		version: 7,
+		name:    "json import marker",
+		up: execAll(
+			`CREATE TABLE json_import (
+				id INTEGER PRIMARY KEY CHECK (id = 1),
+				source TEXT NOT NULL,
+				imported_at DATETIME NOT NULL
+			)`,
+		),
+		down: execAll(`DROP TABLE IF EXISTS json_import`),
+	},
+}
+
+// LatestSchemaVersion is the schema version this build migrates to.
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// SchemaVersion returns the highest applied migration, 0 for an empty
+// database.
+func (s *SQLiteStore) SchemaVersion() (int, error) {
+	if err := s.ensureMigrationsTable(); err != nil {
+		return 0, err
+	}
+	var v int
+	err := s.db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&v)
+	return v, err
+}
+
+// MigrateTo applies or reverts migrations until the schema is at version.
+// Reverting drops the tables and columns added since, with their data.
+func (s *SQLiteStore) MigrateTo(version int) error {
+	if version < 0 || version > LatestSchemaVersion() {
+		return fmt.Errorf("unknown schema version %d (latest is %d)", version, LatestSchemaVersion())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if current > LatestSchemaVersion() {
+		return fmt.Errorf("database schema version %d is newer than this build (%d)", current, LatestSchemaVersion())
+	}
+	for _, m := range migrations {
+		if m.version > current && m.version <= version {
+			if err := s.applyMigration(m, true); err != nil {
+				return err
+			}
+		}
+	}
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if m := migrations[i]; m.version <= current && m.version > version {
+			if err := s.applyMigration(m, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) ensureMigrationsTable() error {
+	_, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at DATETIME NOT NULL
+	)`)
+	return err
+}
+
+func (s *SQLiteStore) applyMigration(m migration, up bool) error {
+	direction, step := "up", m.up
+	if !up {
+		direction, step = "down", m.down
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := step(tx); err != nil {
+		return fmt.Errorf("migration %d (%s) %s: %w", m.version, m.name, direction, err)
+	}
+	if up {
+		_, err = tx.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)", m.version, m.name, time.Now().UTC())
+	} else {
+		_, err = tx.Exec("DELETE FROM schema_migrations WHERE version = ?", m.version)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func execAll(queries ...string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, q := range queries {
+			if _, err := tx.Exec(q); err != nil {
+				return fmt.Errorf("exec %q: %w", q, err)
+			}
+		}
+		return nil
+	}
+}
+
+// addColumns adds the columns, given as "name type...", that the table
+// does not have yet.
+func addColumns(table string, columns ...string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		have, err := tableColumns(tx, table)
+		if err != nil {
+			return err
+		}
+		for _, c := range columns {
+			name, _, _ := strings.Cut(c, " ")
+			if have[name] {
+				continue
+			}
+			if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", table, c)); err != nil {
+				return fmt.Errorf("add column %s.%s: %w", table, name, err)
+			}
+		}
+		return nil
+	}
+}
+
+func dropColumns(table string, columns ...string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		have, err := tableColumns(tx, table)
+		if err != nil {
+			return err
+		}
+		for _, name := range columns {
+			if !have[name] {
+				continue
+			}
+			if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, name)); err != nil {
+				return fmt.Errorf("drop column %s.%s: %w", table, name, err)
+			}
+		}
+		return nil
+	}
+}
+
+func tableColumns(tx *sql.Tx, table string) (map[string]bool, error) {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	have := map[string]bool{}
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, typ        string
+			def              sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &def, &pk); err != nil {
+			return nil, err
+		}
+		have[name] = true
+	}
+	return have, rows.Err()
+}
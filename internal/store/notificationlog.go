@@ -0,0 +1,91 @@
+package store
+
+import (
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+const (
+	// notificationLogRetention bounds how long delivery attempts are kept.
+	notificationLogRetention = 30 * 24 * time.Hour
+	// jsonNotificationLogLimit caps the attempts kept by the JSON backend.
+	jsonNotificationLogLimit = 500
+)
+
+func (s *SQLiteStore) AddNotificationAttempt(a model.NotificationAttempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a.At = a.At.UTC()
+	query := `INSERT INTO notification_log (notification_id, channel_name, channel_type, monitor_id, event_type, summary, success, error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	if _, err := s.db.Exec(query, a.NotificationID, a.ChannelName, a.ChannelType, a.MonitorID, a.EventType, a.Summary, a.Success, a.Error, a.At); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`DELETE FROM notification_log WHERE created_at < ?`, time.Now().UTC().Add(-notificationLogRetention))
+	return err
+}
+
+func (s *SQLiteStore) GetNotificationAttempts(notificationID, monitorID string, limit int) ([]model.NotificationAttempt, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	query := `SELECT id, notification_id, channel_name, channel_type, monitor_id, event_type, summary, success, error, created_at
+		FROM notification_log
+		WHERE (? = '' OR notification_id = ?) AND (? = '' OR monitor_id = ?)
+		ORDER BY id DESC LIMIT ?`
+	rows, err := s.db.Query(query, notificationID, notificationID, monitorID, monitorID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.NotificationAttempt{}
+	for rows.Next() {
+		var a model.NotificationAttempt
+		if err := rows.Scan(&a.ID, &a.NotificationID, &a.ChannelName, &a.ChannelType, &a.MonitorID, &a.EventType, &a.Summary, &a.Success, &a.Error, &a.At); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (s *JSONStore) AddNotificationAttempt(a model.NotificationAttempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var last int64
+	if n := len(s.state.NotificationLog); n > 0 {
+		last = s.state.NotificationLog[n-1].ID
+	}
+	a.ID = last + 1
+	a.At = a.At.UTC()
+	s.state.NotificationLog = append(s.state.NotificationLog, a)
+	if n := len(s.state.NotificationLog); n > jsonNotificationLogLimit {
+		s.state.NotificationLog = append([]model.NotificationAttempt(nil), s.state.NotificationLog[n-jsonNotificationLogLimit:]...)
+	}
+	return s.persistLocked()
+}
+
+func (s *JSONStore) GetNotificationAttempts(notificationID, monitorID string, limit int) ([]model.NotificationAttempt, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := []model.NotificationAttempt{}
+	for i := len(s.state.NotificationLog) - 1; i >= 0 && len(out) < limit; i-- {
+		a := s.state.NotificationLog[i]
+		if notificationID != "" && a.NotificationID != notificationID {
+			continue
+		}
+		if monitorID != "" && a.MonitorID != monitorID {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
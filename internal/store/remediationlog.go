@@ -0,0 +1,103 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+const (
+	// remediationLogRetention bounds how long remediation attempts are kept.
+	remediationLogRetention = 90 * 24 * time.Hour
+	// jsonRemediationLogLimit caps the attempts kept by the JSON backend.
+	jsonRemediationLogLimit = 500
+)
+
+func (s *SQLiteStore) AddRemediationAttempt(a model.RemediationAttempt) (model.RemediationAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result sql.NullString
+	if len(a.Result) > 0 {
+		b, err := json.Marshal(a.Result)
+		if err != nil {
+			return a, err
+		}
+		result = sql.NullString{String: string(b), Valid: true}
+	}
+	a.At = a.At.UTC()
+	query := `INSERT INTO remediation_log (monitor_id, action, attempt, manual, success, error, result, duration_ms, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := s.db.Exec(query, a.MonitorID, string(a.Action), a.Attempt, a.Manual, a.Success, a.Error, result, a.DurationMs, a.At)
+	if err != nil {
+		return a, err
+	}
+	a.ID, _ = res.LastInsertId()
+	_, err = s.db.Exec(`DELETE FROM remediation_log WHERE created_at < ?`, time.Now().UTC().Add(-remediationLogRetention))
+	return a, err
+}
+
+func (s *SQLiteStore) GetRemediationAttempts(monitorID string, limit int) ([]model.RemediationAttempt, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	query := `SELECT id, monitor_id, action, attempt, manual, success, error, result, duration_ms, created_at
+		FROM remediation_log WHERE monitor_id = ? ORDER BY id DESC LIMIT ?`
+	rows, err := s.db.Query(query, monitorID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.RemediationAttempt{}
+	for rows.Next() {
+		var a model.RemediationAttempt
+		var action string
+		var errText, result sql.NullString
+		if err := rows.Scan(&a.ID, &a.MonitorID, &action, &a.Attempt, &a.Manual, &a.Success, &errText, &result, &a.DurationMs, &a.At); err != nil {
+			return nil, err
+		}
+		a.Action = model.RemediationAction(action)
+		a.Error = errText.String
+		if result.Valid {
+			_ = json.Unmarshal([]byte(result.String), &a.Result)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (s *JSONStore) AddRemediationAttempt(a model.RemediationAttempt) (model.RemediationAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var last int64
+	if n := len(s.state.RemediationLog); n > 0 {
+		last = s.state.RemediationLog[n-1].ID
+	}
+	a.ID = last + 1
+	a.At = a.At.UTC()
+	s.state.RemediationLog = append(s.state.RemediationLog, a)
+	if n := len(s.state.RemediationLog); n > jsonRemediationLogLimit {
+		s.state.RemediationLog = append([]model.RemediationAttempt(nil), s.state.RemediationLog[n-jsonRemediationLogLimit:]...)
+	}
+	return a, s.persistLocked()
+}
+
+func (s *JSONStore) GetRemediationAttempts(monitorID string, limit int) ([]model.RemediationAttempt, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := []model.RemediationAttempt{}
+	for i := len(s.state.RemediationLog) - 1; i >= 0 && len(out) < limit; i-- {
+		if a := s.state.RemediationLog[i]; a.MonitorID == monitorID {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
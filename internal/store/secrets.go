@@ -0,0 +1,125 @@
+package store
+
+import (
+	"sort"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+func (s *SQLiteStore) GetSecrets() []model.Secret {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	secrets := []model.Secret{}
+	rows, err := s.db.Query("SELECT name, value, created_at, updated_at FROM secrets ORDER BY name")
+	if err != nil {
+		return secrets
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sec model.Secret
+		if err := rows.Scan(&sec.Name, &sec.Value, &sec.CreatedAt, &sec.UpdatedAt); err == nil {
+			secrets = append(secrets, sec)
+		}
+	}
+	return secrets
+}
+
+func (s *SQLiteStore) GetSecret(name string) (model.Secret, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var sec model.Secret
+	err := s.db.QueryRow("SELECT name, value, created_at, updated_at FROM secrets WHERE name = ?", name).
+		Scan(&sec.Name, &sec.Value, &sec.CreatedAt, &sec.UpdatedAt)
+	if err != nil {
+		return model.Secret{}, false
+	}
+	return sec, true
+}
+
+func (s *SQLiteStore) UpsertSecret(sec model.Secret) (model.Secret, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	sec.UpdatedAt = now
+	if err := s.db.QueryRow("SELECT created_at FROM secrets WHERE name = ?", sec.Name).Scan(&sec.CreatedAt); err != nil {
+		sec.CreatedAt = now
+	}
+	query := `INSERT INTO secrets (name, value, created_at, updated_at) VALUES (?, ?, ?, ?)
+			  ON CONFLICT(name) DO UPDATE SET value=excluded.value, updated_at=excluded.updated_at`
+	if _, err := s.db.Exec(query, sec.Name, sec.Value, sec.CreatedAt, sec.UpdatedAt); err != nil {
+		return model.Secret{}, err
+	}
+	return sec, nil
+}
+
+func (s *SQLiteStore) DeleteSecret(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec("DELETE FROM secrets WHERE name = ?", name)
+	return err
+}
+
+func (s *JSONStore) GetSecrets() []model.Secret {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	dst := make([]model.Secret, len(s.state.Secrets))
+	copy(dst, s.state.Secrets)
+	sort.Slice(dst, func(i, j int) bool { return dst[i].Name < dst[j].Name })
+	return dst
+}
+
+func (s *JSONStore) GetSecret(name string) (model.Secret, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, sec := range s.state.Secrets {
+		if sec.Name == name {
+			return sec, true
+		}
+	}
+	return model.Secret{}, false
+}
+
+func (s *JSONStore) UpsertSecret(sec model.Secret) (model.Secret, error) {
+	now := time.Now().UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sec.CreatedAt, sec.UpdatedAt = now, now
+	found := false
+	for i := range s.state.Secrets {
+		if s.state.Secrets[i].Name == sec.Name {
+			sec.CreatedAt = s.state.Secrets[i].CreatedAt
+			s.state.Secrets[i] = sec
+			found = true
+			break
+		}
+	}
+	if !found {
+		s.state.Secrets = append(s.state.Secrets, sec)
+	}
+	if err := s.persistLocked(); err != nil {
+		return model.Secret{}, err
+	}
+	return sec, nil
+}
+
+func (s *JSONStore) DeleteSecret(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dst := s.state.Secrets[:0]
+	for _, sec := range s.state.Secrets {
+		if sec.Name != name {
+			dst = append(dst, sec)
+		}
+	}
+	s.state.Secrets = dst
+	return s.persistLocked()
+}
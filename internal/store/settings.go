@@ -0,0 +1,63 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+func (s *SQLiteStore) GetSettings() (model.Settings, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var data string
+	if err := s.db.QueryRow("SELECT data FROM settings WHERE id = 1").Scan(&data); err != nil {
+		return model.Settings{}, false
+	}
+	var set model.Settings
+	if err := json.Unmarshal([]byte(data), &set); err != nil {
+		return model.Settings{}, false
+	}
+	return set, true
+}
+
+func (s *SQLiteStore) SaveSettings(set model.Settings) (model.Settings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set.UpdatedAt = time.Now().UTC()
+	data, err := json.Marshal(set)
+	if err != nil {
+		return model.Settings{}, err
+	}
+	query := `INSERT INTO settings (id, data, updated_at) VALUES (1, ?, ?)
+			  ON CONFLICT(id) DO UPDATE SET data=excluded.data, updated_at=excluded.updated_at`
+	if _, err := s.db.Exec(query, string(data), set.UpdatedAt); err != nil {
+		return model.Settings{}, err
+	}
+	return set, nil
+}
+
+func (s *JSONStore) GetSettings() (model.Settings, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.state.Settings == nil {
+		return model.Settings{}, false
+	}
+	set := *s.state.Settings
+	set.DefaultNotifyWebhookIDs = append([]string(nil), set.DefaultNotifyWebhookIDs...)
+	return set, true
+}
+
+func (s *JSONStore) SaveSettings(set model.Settings) (model.Settings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set.UpdatedAt = time.Now().UTC()
+	stored := set
+	stored.DefaultNotifyWebhookIDs = append([]string(nil), set.DefaultNotifyWebhookIDs...)
+	s.state.Settings = &stored
+	return set, s.persistLocked()
+}
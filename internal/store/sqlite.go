@@ -1,9 +1,14 @@
 package store
 
 import (
+	"bytes"
+	"compress/gzip"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,10 +19,36 @@ import (
 type SQLiteStore struct {
 	db *sql.DB
 	mu sync.RWMutex
+
+	// insertHistory is prepared once for the per-check history insert.
+	insertHistory *sql.Stmt
+
+	// logBudget caps the compressed log bytes kept per monitor; older
+	// attachments are dropped first. Zero disables the cap.
+	logBudget int
+
+	changeFeed
+}
+
+// sqlitePragmas are applied to every connection of the pool. WAL lets the
+// API read while the engine writes history, and busy_timeout makes
+// concurrent writers wait for the lock instead of failing with SQLITE_BUSY.
+var sqlitePragmas = []string{
+	"journal_mode(WAL)",
+	"busy_timeout(5000)",
+	"synchronous(NORMAL)",
 }
 
 func NewSQLiteStore(filePath string) (*SQLiteStore, error) {
-	db, err := sql.Open("sqlite", filePath)
+	dsn := filePath
+	for i, p := range sqlitePragmas {
+		sep := "&"
+		if i == 0 && !strings.Contains(filePath, "?") {
+			sep = "?"
+		}
+		dsn += sep + "_pragma=" + p
+	}
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open sqlite db: %w", err)
 	}
@@ -27,64 +58,32 @@ func NewSQLiteStore(filePath string) (*SQLiteStore, error) {
 	}
 
 	s := &SQLiteStore{db: db}
-	if err := s.initSchema(); err != nil {
+	if err := s.MigrateTo(LatestSchemaVersion()); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
+	}
+	if err := s.backfillDailyUptime(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to backfill daily uptime: %w", err)
+	}
+	s.insertHistory, err = db.Prepare(`INSERT INTO monitor_history (monitor_id, status, checked_at, latency_ms, message, logs_gz, transient, conn_mode, weight, cpu_percent, mem_percent, regions, phases) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
 		db.Close()
-		return nil, fmt.Errorf("failed to init schema: %w", err)
+		return nil, fmt.Errorf("failed to prepare statements: %w", err)
 	}
 
 	return s, nil
 }
 
-func (s *SQLiteStore) initSchema() error {
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS monitors (
-			id TEXT PRIMARY KEY,
-			data TEXT NOT NULL,
-			created_at DATETIME,
-			updated_at DATETIME
-		);`,
-		`CREATE TABLE IF NOT EXISTS notifications (
-			id TEXT PRIMARY KEY,
-			data TEXT NOT NULL,
-			created_at DATETIME,
-			updated_at DATETIME
-		);`,
-		`CREATE TABLE IF NOT EXISTS monitor_history (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			monitor_id TEXT NOT NULL,
-			status TEXT NOT NULL,
-			checked_at DATETIME NOT NULL,
-			latency_ms INTEGER NOT NULL,
-			message TEXT,
-			logs TEXT,
-			FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
-		);`,
-		`CREATE INDEX IF NOT EXISTS idx_history_monitor_id_checked_at ON monitor_history(monitor_id, checked_at DESC);`,
-	}
-
-	for _, query := range queries {
-		if _, err := s.db.Exec(query); err != nil {
-			return fmt.Errorf("failed to exec query %q: %w", query, err)
-		}
-	}
-
-	// Migration for existing tables
-	s.ensureColumns()
-
-	return nil
-}
-
-func (s *SQLiteStore) ensureColumns() {
-	// Add logs column to monitor_history if it doesn't exist
-	// We can try to query it, if fails, add it.
-	// Or just try to add it and ignore error "duplicate column name"
-	_, err := s.db.Exec("ALTER TABLE monitor_history ADD COLUMN logs TEXT")
-	if err != nil {
-		// Ignore error, likely column already exists
-	}
+// SetLogBudget sets the per-monitor budget for compressed log attachments.
+func (s *SQLiteStore) SetLogBudget(bytes int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logBudget = bytes
 }
 
 func (s *SQLiteStore) Close() error {
+	_ = s.insertHistory.Close()
 	return s.db.Close()
 }
 
@@ -157,16 +156,57 @@ func (s *SQLiteStore) UpsertMonitor(m model.Monitor) (model.Monitor, error) {
 	if err != nil {
 		return model.Monitor{}, err
 	}
+	s.publish(MonitorChange{ID: m.ID})
 
 	return m, nil
 }
 
+func (s *SQLiteStore) UpsertMonitors(ms []model.Monitor) ([]model.Monitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	query := `INSERT INTO monitors (id, data, created_at, updated_at) VALUES (?, ?, ?, ?)
+			  ON CONFLICT(id) DO UPDATE SET data=excluded.data, updated_at=excluded.updated_at`
+	out := make([]model.Monitor, 0, len(ms))
+	for _, m := range ms {
+		m.UpdatedAt = now
+		if m.CreatedAt.IsZero() {
+			m.CreatedAt = now
+		}
+		data, err := json.Marshal(m)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(query, m.ID, string(data), m.CreatedAt, m.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	for _, m := range out {
+		s.publish(MonitorChange{ID: m.ID})
+	}
+	return out, nil
+}
+
 func (s *SQLiteStore) DeleteMonitor(id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	_, err := s.db.Exec("DELETE FROM monitors WHERE id = ?", id)
-	return err
+	if _, err := s.db.Exec("DELETE FROM monitors WHERE id = ?", id); err != nil {
+		return err
+	}
+	s.publish(MonitorChange{ID: id, Deleted: true})
+	return nil
 }
 
 func (s *SQLiteStore) GetNotifications() []model.Notification {
@@ -226,22 +266,273 @@ func (s *SQLiteStore) DeleteNotification(id string) error {
 	return err
 }
 
-func (s *SQLiteStore) AddMonitorHistory(id string, entry model.MonitorHistoryEntry) error {
-	// Not locking strictly needed for INSERT, but let's keep it safe if we add logic later
-	// s.mu.Lock()
-	// defer s.mu.Unlock()
+func (s *SQLiteStore) GetRoutingPolicies() []model.RoutingPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	policies := []model.RoutingPolicy{}
+	rows, err := s.db.Query("SELECT data FROM routing_policies")
+	if err != nil {
+		return policies
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err == nil {
+			var p model.RoutingPolicy
+			if err := json.Unmarshal([]byte(data), &p); err == nil {
+				policies = append(policies, p)
+			}
+		}
+	}
+	return policies
+}
+
+func (s *SQLiteStore) UpsertRoutingPolicy(p model.RoutingPolicy) (model.RoutingPolicy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	p.UpdatedAt = now
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = now
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		return model.RoutingPolicy{}, err
+	}
+
+	query := `INSERT INTO routing_policies (id, data, created_at, updated_at) VALUES (?, ?, ?, ?)
+			  ON CONFLICT(id) DO UPDATE SET data=excluded.data, updated_at=excluded.updated_at`
+
+	if _, err := s.db.Exec(query, p.ID, string(data), p.CreatedAt, p.UpdatedAt); err != nil {
+		return model.RoutingPolicy{}, err
+	}
+	return p, nil
+}
+
+func (s *SQLiteStore) DeleteRoutingPolicy(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec("DELETE FROM routing_policies WHERE id = ?", id)
+	return err
+}
 
-	query := `INSERT INTO monitor_history (monitor_id, status, checked_at, latency_ms, message, logs) VALUES (?, ?, ?, ?, ?, ?)`
-	_, err := s.db.Exec(query, id, string(entry.Status), entry.CheckedAt, entry.LatencyMs, entry.Message, entry.Logs)
+func (s *SQLiteStore) GetStatusPages() []model.StatusPage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pages := []model.StatusPage{}
+	rows, err := s.db.Query("SELECT data FROM status_pages")
+	if err != nil {
+		return pages
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err == nil {
+			var p model.StatusPage
+			if err := json.Unmarshal([]byte(data), &p); err == nil {
+				pages = append(pages, p)
+			}
+		}
+	}
+	return pages
+}
+
+func (s *SQLiteStore) UpsertStatusPage(p model.StatusPage) (model.StatusPage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	p.UpdatedAt = now
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = now
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		return model.StatusPage{}, err
+	}
+
+	query := `INSERT INTO status_pages (id, data, created_at, updated_at) VALUES (?, ?, ?, ?)
+			  ON CONFLICT(id) DO UPDATE SET data=excluded.data, updated_at=excluded.updated_at`
+
+	if _, err := s.db.Exec(query, p.ID, string(data), p.CreatedAt, p.UpdatedAt); err != nil {
+		return model.StatusPage{}, err
+	}
+	return p, nil
+}
+
+func (s *SQLiteStore) DeleteStatusPage(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec("DELETE FROM status_pages WHERE id = ?", id)
 	return err
 }
 
+func (s *SQLiteStore) AddMonitorHistory(id string, entry model.MonitorHistoryEntry) error {
+	return s.AddMonitorHistoryBatch([]HistoryRecord{{MonitorID: id, Entry: entry}})
+}
+
+// AddMonitorHistoryBatch inserts the entries and their daily uptime in one
+// transaction.
+func (s *SQLiteStore) AddMonitorHistoryBatch(records []HistoryRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	insert := tx.Stmt(s.insertHistory)
+	defer insert.Close()
+
+	withLogs := map[string]bool{}
+	for _, r := range records {
+		if err := s.insertHistoryRecord(tx, insert, r); err != nil {
+			return err
+		}
+		if r.Entry.Logs != "" {
+			withLogs[r.MonitorID] = true
+		}
+	}
+	for id := range withLogs {
+		if err := s.enforceLogBudget(tx, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// insertHistoryRecord inserts one history entry with insert, a statement
+// of tx, and adds it to the daily uptime.
+func (s *SQLiteStore) insertHistoryRecord(tx *sql.Tx, insert *sql.Stmt, r HistoryRecord) error {
+	entry := r.Entry
+	entry.CheckedAt = entry.CheckedAt.UTC()
+
+	var logsGz []byte
+	if entry.Logs != "" {
+		var err error
+		if logsGz, err = gzipString(entry.Logs); err != nil {
+			return err
+		}
+	}
+	regions, err := regionsColumn(entry.Regions)
+	if err != nil {
+		return err
+	}
+	phases, err := phasesColumn(entry.Phases)
+	if err != nil {
+		return err
+	}
+	_, err = insert.Exec(r.MonitorID, string(entry.Status), entry.CheckedAt, entry.LatencyMs, entry.Message, logsGz, entry.Transient, string(entry.ConnMode), entry.Checks(), entry.CPUPercent, entry.MemoryPercent, regions, phases)
+	if err != nil {
+		return err
+	}
+	return s.addDailyUptime(tx, r.MonitorID, entry)
+}
+
+// regionsColumn encodes per-region results, or NULL for single-region
+// monitors.
+func regionsColumn(regions []model.RegionResult) (any, error) {
+	if len(regions) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(regions)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// phasesColumn encodes HTTP phase timings, or NULL when not measured.
+func phasesColumn(phases *model.HTTPPhases) (any, error) {
+	if phases == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(phases)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// enforceLogBudget drops the oldest log attachments of a monitor once their
+// total stored size exceeds the budget.
+func (s *SQLiteStore) enforceLogBudget(db queryExecer, id string) error {
+	s.mu.RLock()
+	budget := s.logBudget
+	s.mu.RUnlock()
+	if budget <= 0 {
+		return nil
+	}
+
+	rows, err := db.Query(`SELECT id, COALESCE(LENGTH(logs_gz), 0) + COALESCE(LENGTH(logs), 0) FROM monitor_history
+		WHERE monitor_id = ? AND (logs_gz IS NOT NULL OR logs IS NOT NULL) ORDER BY checked_at DESC`, id)
+	if err != nil {
+		return err
+	}
+	var drop []int64
+	total := 0
+	for rows.Next() {
+		var rowID int64
+		var size int
+		if err := rows.Scan(&rowID, &size); err != nil {
+			continue
+		}
+		total += size
+		if total > budget {
+			drop = append(drop, rowID)
+		}
+	}
+	rows.Close()
+
+	for _, rowID := range drop {
+		if _, err := db.Exec(`UPDATE monitor_history SET logs = NULL, logs_gz = NULL WHERE id = ?`, rowID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func gzipString(v string) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(v)); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gunzipString(b []byte) (string, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return "", err
+	}
+	defer zr.Close()
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
 func (s *SQLiteStore) GetMonitorHistory(id string) ([]model.MonitorHistoryEntry, error) {
 	// s.mu.RLock()
 	// defer s.mu.RUnlock()
 
 	// Get last 50 entries
-	query := `SELECT status, checked_at, latency_ms, message, logs FROM monitor_history WHERE monitor_id = ? ORDER BY checked_at DESC LIMIT 50`
+	query := `SELECT status, checked_at, latency_ms, message, logs, logs_gz, transient, conn_mode, weight, cpu_percent, mem_percent, regions, phases FROM monitor_history WHERE monitor_id = ? ORDER BY checked_at DESC LIMIT 50`
 	rows, err := s.db.Query(query, id)
 	if err != nil {
 		return []model.MonitorHistoryEntry{}, err
@@ -250,16 +541,10 @@ func (s *SQLiteStore) GetMonitorHistory(id string) ([]model.MonitorHistoryEntry,
 
 	var history []model.MonitorHistoryEntry
 	for rows.Next() {
-		var entry model.MonitorHistoryEntry
-		var status string
-		var logs sql.NullString
-		if err := rows.Scan(&status, &entry.CheckedAt, &entry.LatencyMs, &entry.Message, &logs); err != nil {
+		entry, err := scanHistoryRow(rows)
+		if err != nil {
 			continue
 		}
-		entry.Status = model.MonitorStatus(status)
-		if logs.Valid {
-			entry.Logs = logs.String
-		}
 		history = append(history, entry)
 	}
 
@@ -274,40 +559,261 @@ func (s *SQLiteStore) GetMonitorHistory(id string) ([]model.MonitorHistoryEntry,
 	return history, nil
 }
 
-func (s *SQLiteStore) PruneMonitorHistory(id string, days int) error {
+// scanHistoryRow reads a row selected with the columns status, checked_at,
+// latency_ms, message, logs, logs_gz, transient, conn_mode, weight,
+// cpu_percent, mem_percent, regions and phases.
+func scanHistoryRow(rows *sql.Rows) (model.MonitorHistoryEntry, error) {
+	var entry model.MonitorHistoryEntry
+	var status string
+	var logs, connMode, regions, phases sql.NullString
+	var logsGz []byte
+	var cpu, mem sql.NullFloat64
+	if err := rows.Scan(&status, &entry.CheckedAt, &entry.LatencyMs, &entry.Message, &logs, &logsGz, &entry.Transient, &connMode, &entry.Weight, &cpu, &mem, &regions, &phases); err != nil {
+		return entry, err
+	}
+	if regions.Valid {
+		_ = json.Unmarshal([]byte(regions.String), &entry.Regions)
+	}
+	if phases.Valid {
+		entry.Phases = &model.HTTPPhases{}
+		_ = json.Unmarshal([]byte(phases.String), entry.Phases)
+	}
+	entry.Status = model.MonitorStatus(status)
+	entry.ConnMode = model.ConnectionMode(connMode.String)
+	if cpu.Valid {
+		entry.CPUPercent = &cpu.Float64
+	}
+	if mem.Valid {
+		entry.MemoryPercent = &mem.Float64
+	}
+	if len(logsGz) > 0 {
+		if v, err := gunzipString(logsGz); err == nil {
+			entry.Logs = v
+		}
+	} else if logs.Valid {
+		// Rows written before compression was introduced.
+		entry.Logs = logs.String
+	}
+	return entry, nil
+}
+
+// ExportMonitorHistory streams history oldest first without attached logs.
+// The range is applied to checked_at as text with a day of slack, since
+// rows keep the zone the check ran in, and then exactly in Go.
+func (s *SQLiteStore) ExportMonitorHistory(id string, from, to time.Time, fn func(model.MonitorHistoryEntry) error) error {
+	query := `SELECT status, checked_at, latency_ms, message, NULL, NULL, transient, conn_mode, weight, cpu_percent, mem_percent, regions, phases
+		FROM monitor_history WHERE monitor_id = ?`
+	args := []any{id}
+	if !from.IsZero() {
+		query += ` AND checked_at >= ?`
+		args = append(args, from.UTC().Add(-24*time.Hour))
+	}
+	if !to.IsZero() {
+		query += ` AND checked_at < ?`
+		args = append(args, to.UTC().Add(24*time.Hour))
+	}
+	rows, err := s.db.Query(query+` ORDER BY checked_at ASC`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanHistoryRow(rows)
+		if err != nil {
+			return err
+		}
+		if !inRange(entry.CheckedAt, from, to) {
+			continue
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// PruneMonitorHistory also drops the daily uptime of the days that were
+// removed entirely and rebuilds the one the cutoff falls in, so the cached
+// aggregates keep matching the raw history.
+func (s *SQLiteStore) PruneMonitorHistory(id string, days int) (int64, error) {
 	if days <= 0 {
-		return nil
+		return 0, nil
+	}
+	cutoff := time.Now().UTC().AddDate(0, 0, -days)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM monitor_history WHERE monitor_id = ? AND checked_at < ?`, id, cutoff)
+	if err != nil {
+		return 0, err
 	}
-	cutoff := time.Now().AddDate(0, 0, -days)
-	query := `DELETE FROM monitor_history WHERE monitor_id = ? AND checked_at < ?`
-	_, err := s.db.Exec(query, id, cutoff)
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	day := cutoff.Format(dayLayout)
+	if _, err := tx.Exec(`DELETE FROM uptime_daily WHERE monitor_id = ? AND day < ?`, id, day); err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		if err := s.recomputeDay(tx, id, day); err != nil {
+			return 0, err
+		}
+	}
+	return n, tx.Commit()
+}
+
+// Vacuum rebuilds the database file so pages freed by pruning are returned
+// to the filesystem. Writers are blocked while it runs.
+func (s *SQLiteStore) Vacuum() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.db.Exec("VACUUM")
 	return err
 }
 
-func (s *SQLiteStore) MigrateFromJSON(jsonPath string) error {
-	js, err := NewJSONStore(jsonPath)
+func (s *SQLiteStore) SaveLatencyHistograms(hists map[string]model.LatencyHistogram) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
 	if err != nil {
-		// If file doesn't exist, nothing to migrate
-		return nil
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	query := `INSERT INTO latency_histograms (monitor_id, data, updated_at) VALUES (?, ?, ?)
+			  ON CONFLICT(monitor_id) DO UPDATE SET data=excluded.data, updated_at=excluded.updated_at`
+	for id, h := range hists {
+		data, err := json.Marshal(h)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(query, id, string(data), now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) LoadLatencyHistograms() (map[string]model.LatencyHistogram, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := map[string]model.LatencyHistogram{}
+	rows, err := s.db.Query("SELECT monitor_id, data FROM latency_histograms")
+	if err != nil {
+		return out, err
 	}
+	defer rows.Close()
 
-	state := js.GetState()
+	for rows.Next() {
+		var id, data string
+		if err := rows.Scan(&id, &data); err != nil {
+			continue
+		}
+		var h model.LatencyHistogram
+		if err := json.Unmarshal([]byte(data), &h); err == nil {
+			out[id] = h
+		}
+	}
+	return out, rows.Err()
+}
 
+// MigrateFromJSON imports the JSON store at jsonPath, with its history,
+// into an empty database in one transaction. The import is recorded, so it
+// runs at most once even when the JSON file stays in place.
+func (s *SQLiteStore) MigrateFromJSON(jsonPath string) error {
+	// NewJSONStore creates an empty file when none exists; don't leave one
+	// behind just for probing.
+	if _, err := os.Stat(jsonPath); err != nil {
+		return nil
+	}
+	withLogs, err := s.importJSONOnce(jsonPath)
+	if err != nil {
+		return err
+	}
+	for _, id := range withLogs {
+		if err := s.enforceLogBudget(s.db, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importJSONOnce runs the import unless it was recorded before and returns
+// the monitors whose imported history has logs attached.
+func (s *SQLiteStore) importJSONOnce(jsonPath string) ([]string, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Check if we already have data
-	var count int
-	if err := s.db.QueryRow("SELECT COUNT(*) FROM monitors").Scan(&count); err == nil && count > 0 {
-		return nil // Already have data, skip migration
+	var imported int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM json_import").Scan(&imported); err != nil {
+		return nil, err
+	}
+	if imported > 0 {
+		return nil, nil
 	}
 
+	js, err := NewJSONStore(jsonPath)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", jsonPath, err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Databases that were in use before the import was recorded are
+	// considered migrated.
+	empty, err := isEmptyDatabase(tx)
+	if err != nil {
+		return nil, err
+	}
+	var withLogs []string
+	if empty {
+		if withLogs, err = s.importJSON(tx, js.GetState(), js.history); err != nil {
+			return nil, fmt.Errorf("import %s: %w", jsonPath, err)
+		}
+	}
+	if _, err := tx.Exec("INSERT INTO json_import (id, source, imported_at) VALUES (1, ?, ?)", jsonPath, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return withLogs, tx.Commit()
+}
+
+// isEmptyDatabase reports whether none of the tables filled from the JSON
+// store holds a row.
+func isEmptyDatabase(tx *sql.Tx) (bool, error) {
+	for _, table := range []string{"monitors", "notifications", "routing_policies", "status_pages", "maintenance_windows", "incidents", "users", "settings", "secrets", "monitor_history"} {
+		var n int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM (SELECT 1 FROM " + table + " LIMIT 1)").Scan(&n); err != nil {
+			return false, err
+		}
+		if n > 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// importJSON copies the state and history of the JSON store into tx and
+// returns the monitors whose history has logs attached. History is newest
+// first, as the JSON store keeps it.
+func (s *SQLiteStore) importJSON(tx *sql.Tx, state State, history map[string][]model.MonitorHistoryEntry) ([]string, error) {
+	now := time.Now().UTC()
 	for _, m := range state.Monitors {
-		// Upsert logic duplicated here or call UpsertMonitor (but UpsertMonitor locks)
-		// Since we hold lock, we should use internal method or just do it here.
-		// Calling UpsertMonitor would deadlock.
-		// Let's copy logic.
-		now := time.Now().UTC()
 		if m.CreatedAt.IsZero() {
 			m.CreatedAt = now
 		}
@@ -316,13 +822,12 @@ func (s *SQLiteStore) MigrateFromJSON(jsonPath string) error {
 		}
 		data, _ := json.Marshal(m)
 		query := `INSERT INTO monitors (id, data, created_at, updated_at) VALUES (?, ?, ?, ?)`
-		if _, err := s.db.Exec(query, m.ID, string(data), m.CreatedAt, m.UpdatedAt); err != nil {
-			return err
+		if _, err := tx.Exec(query, m.ID, string(data), m.CreatedAt, m.UpdatedAt); err != nil {
+			return nil, err
 		}
 	}
 
 	for _, n := range state.Notifications {
-		now := time.Now().UTC()
 		if n.CreatedAt.IsZero() {
 			n.CreatedAt = now
 		}
@@ -331,10 +836,80 @@ func (s *SQLiteStore) MigrateFromJSON(jsonPath string) error {
 		}
 		data, _ := json.Marshal(n)
 		query := `INSERT INTO notifications (id, data, created_at, updated_at) VALUES (?, ?, ?, ?)`
-		if _, err := s.db.Exec(query, n.ID, string(data), n.CreatedAt, n.UpdatedAt); err != nil {
-			return err
+		if _, err := tx.Exec(query, n.ID, string(data), n.CreatedAt, n.UpdatedAt); err != nil {
+			return nil, err
 		}
 	}
 
-	return nil
+	for _, p := range state.RoutingPolicies {
+		data, _ := json.Marshal(p)
+		query := `INSERT INTO routing_policies (id, data, created_at, updated_at) VALUES (?, ?, ?, ?)`
+		if _, err := tx.Exec(query, p.ID, string(data), p.CreatedAt, p.UpdatedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, p := range state.StatusPages {
+		data, _ := json.Marshal(p)
+		query := `INSERT INTO status_pages (id, data, created_at, updated_at) VALUES (?, ?, ?, ?)`
+		if _, err := tx.Exec(query, p.ID, string(data), p.CreatedAt, p.UpdatedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, mw := range state.MaintenanceWindows {
+		data, _ := json.Marshal(mw)
+		query := `INSERT INTO maintenance_windows (id, data, created_at, updated_at) VALUES (?, ?, ?, ?)`
+		if _, err := tx.Exec(query, mw.ID, string(data), mw.CreatedAt, mw.UpdatedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, inc := range state.Incidents {
+		data, _ := json.Marshal(inc)
+		query := `INSERT INTO incidents (id, monitor_id, started_at, resolved_at, data) VALUES (?, ?, ?, ?, ?)`
+		if _, err := tx.Exec(query, inc.ID, inc.MonitorID, inc.StartedAt, inc.ResolvedAt, string(data)); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, u := range state.Users {
+		data, _ := json.Marshal(u)
+		query := `INSERT INTO users (id, data, created_at, updated_at) VALUES (?, ?, ?, ?)`
+		if _, err := tx.Exec(query, u.ID, string(data), u.CreatedAt, u.UpdatedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	if set := state.Settings; set != nil {
+		data, _ := json.Marshal(set)
+		query := `INSERT INTO settings (id, data, updated_at) VALUES (1, ?, ?)`
+		if _, err := tx.Exec(query, string(data), set.UpdatedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, sec := range state.Secrets {
+		query := `INSERT INTO secrets (name, value, created_at, updated_at) VALUES (?, ?, ?, ?)`
+		if _, err := tx.Exec(query, sec.Name, sec.Value, sec.CreatedAt, sec.UpdatedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	insert := tx.Stmt(s.insertHistory)
+	defer insert.Close()
+	var withLogs []string
+	for id, hist := range history {
+		logs := false
+		for i := len(hist) - 1; i >= 0; i-- {
+			if err := s.insertHistoryRecord(tx, insert, HistoryRecord{MonitorID: id, Entry: hist[i]}); err != nil {
+				return nil, err
+			}
+			logs = logs || hist[i].Logs != ""
+		}
+		if logs {
+			withLogs = append(withLogs, id)
+		}
+	}
+	return withLogs, nil
 }
@@ -0,0 +1,202 @@
+package store
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+type statSample struct {
+	status    model.MonitorStatus
+	latencyMs int
+	weight    int
+}
+
+// GetMonitorStats computes the same figures as aggregateStats in SQL, so
+// that a long window is summarized without loading its history.
+func (s *SQLiteStore) GetMonitorStats(id string, since time.Time) (model.MonitorStats, error) {
+	query := `WITH counted AS (
+			SELECT status, latency_ms, MAX(weight, 1) AS weight,
+				LAG(status) OVER (ORDER BY checked_at) AS prev
+			FROM monitor_history
+			WHERE monitor_id = ? AND checked_at >= ? AND status IN (?, ?, ?)
+		), ranked AS (
+			SELECT latency_ms,
+				SUM(weight) OVER (ORDER BY latency_ms ROWS UNBOUNDED PRECEDING) AS seen,
+				SUM(weight) OVER () AS total
+			FROM counted WHERE status <> ?3
+		)
+		SELECT
+			(SELECT COALESCE(SUM(weight), 0) FROM counted),
+			(SELECT COALESCE(SUM(weight), 0) FROM counted WHERE status <> ?3),
+			(SELECT SUM(latency_ms * weight) * 1.0 / SUM(weight) FROM counted WHERE status <> ?3),
+			(SELECT COUNT(*) FROM counted WHERE status = ?3 AND (prev IS NULL OR prev <> ?3)),
+			(SELECT MIN(CASE WHEN seen >= MAX(CAST(total * 0.5 + 0.5 AS INTEGER), 1) THEN latency_ms END) FROM ranked),
+			(SELECT MIN(CASE WHEN seen >= MAX(CAST(total * 0.95 + 0.5 AS INTEGER), 1) THEN latency_ms END) FROM ranked)`
+	var avg sql.NullFloat64
+	var median, p95 sql.NullInt64
+	out := model.MonitorStats{MonitorID: id, Since: since}
+	err := s.db.QueryRow(query, id, since.UTC(), string(model.StatusDown), string(model.StatusUp), string(model.StatusDegraded)).
+		Scan(&out.TotalChecks, &out.UpChecks, &avg, &out.Outages, &median, &p95)
+	if err != nil {
+		return model.MonitorStats{}, err
+	}
+	if out.TotalChecks > 0 {
+		out.UptimePercent = float64(out.UpChecks) * 100 / float64(out.TotalChecks)
+	}
+	out.AvgLatencyMs = avg.Float64
+	out.MedianLatencyMs = int(median.Int64)
+	out.P95LatencyMs = int(p95.Int64)
+	return out, nil
+}
+
+// aggregateStats computes uptime, latency percentiles and outage count from
+// samples in chronological order. Sampled entries count with their weight;
+// latency only considers up and degraded checks. Paused and unknown results
+// are ignored.
+func aggregateStats(samples []statSample) model.MonitorStats {
+	var out model.MonitorStats
+	var latencies []statSample
+	var latencySum, latencyWeight int64
+	wasDown := false
+
+	for _, sm := range samples {
+		switch sm.status {
+		case model.StatusUp, model.StatusDegraded:
+			out.UpChecks += sm.weight
+			out.TotalChecks += sm.weight
+			latencies = append(latencies, sm)
+			latencySum += int64(sm.latencyMs) * int64(sm.weight)
+			latencyWeight += int64(sm.weight)
+			wasDown = false
+		case model.StatusDown:
+			out.TotalChecks += sm.weight
+			if !wasDown {
+				out.Outages++
+			}
+			wasDown = true
+		}
+	}
+
+	if out.TotalChecks > 0 {
+		out.UptimePercent = float64(out.UpChecks) * 100 / float64(out.TotalChecks)
+	}
+	if latencyWeight > 0 {
+		out.AvgLatencyMs = float64(latencySum) / float64(latencyWeight)
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i].latencyMs < latencies[j].latencyMs })
+		out.MedianLatencyMs = weightedPercentile(latencies, latencyWeight, 0.5)
+		out.P95LatencyMs = weightedPercentile(latencies, latencyWeight, 0.95)
+	}
+	return out
+}
+
+// weightedPercentile expects sorted samples whose weights sum to total.
+func weightedPercentile(sorted []statSample, total int64, p float64) int {
+	rank := int64(float64(total)*p + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+	var seen int64
+	for _, sm := range sorted {
+		seen += int64(sm.weight)
+		if seen >= rank {
+			return sm.latencyMs
+		}
+	}
+	return sorted[len(sorted)-1].latencyMs
+}
+
+// checkedAtUnix converts checked_at to Unix seconds in SQL. The driver
+// stores times as Go's time.String() text ("2006-01-02 15:04:05.999999999
+// -0700 MST") in whatever zone the check ran in, so the zone offset that
+// follows the optional fraction is applied by hand.
+const checkedAtUnix = `(CAST(strftime('%s', substr(checked_at, 1, 19)) AS INTEGER) - (
+	CAST(substr(checked_at, 20 + instr(substr(checked_at, 20), ' '), 3) AS INTEGER) * 3600 +
+	CAST(substr(checked_at, 20 + instr(substr(checked_at, 20), ' '), 1) || substr(checked_at, 23 + instr(substr(checked_at, 20), ' '), 2) AS INTEGER) * 60))`
+
+// GetLatencyBuckets groups history into buckets aligned to multiples of
+// resolution since the Unix epoch, so repeated requests line up. The p95
+// is weighted by sample weight like aggregateStats.
+func (s *SQLiteStore) GetLatencyBuckets(id string, since time.Time, resolution time.Duration) ([]model.LatencyBucket, error) {
+	step := int64(resolution / time.Second)
+	if step <= 0 {
+		step = 1
+	}
+	query := `WITH samples AS (
+			SELECT ` + checkedAtUnix + ` AS ts, latency_ms, MAX(weight, 1) AS weight
+			FROM monitor_history
+			WHERE monitor_id = ? AND checked_at >= ? AND status IN (?, ?)
+		), ranked AS (
+			SELECT ts / ? AS bucket, latency_ms, weight,
+				SUM(weight) OVER (PARTITION BY ts / ? ORDER BY latency_ms ROWS UNBOUNDED PRECEDING) AS seen,
+				SUM(weight) OVER (PARTITION BY ts / ?) AS total
+			FROM samples WHERE ts >= ?
+		)
+		SELECT bucket, SUM(weight), MIN(latency_ms), SUM(latency_ms * weight) * 1.0 / SUM(weight), MAX(latency_ms),
+			MIN(CASE WHEN seen >= MAX(CAST(total * 0.95 + 0.5 AS INTEGER), 1) THEN latency_ms END)
+		FROM ranked GROUP BY bucket ORDER BY bucket`
+	// The index on checked_at compares text, which is only ordered within
+	// one zone; the day of slack covers any offset and ts does the rest.
+	rows, err := s.db.Query(query, id, since.UTC().Add(-24*time.Hour), string(model.StatusUp), string(model.StatusDegraded),
+		step, step, step, since.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.LatencyBucket{}
+	for rows.Next() {
+		var bucket int64
+		var b model.LatencyBucket
+		if err := rows.Scan(&bucket, &b.Checks, &b.MinMs, &b.AvgMs, &b.MaxMs, &b.P95Ms); err != nil {
+			return nil, err
+		}
+		b.Start = time.Unix(bucket*step, 0).UTC()
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+// latencyBuckets is the in-memory counterpart of the SQLite query for
+// samples of up and degraded checks.
+func latencyBuckets(samples []timedSample, resolution time.Duration) []model.LatencyBucket {
+	step := int64(resolution / time.Second)
+	if step <= 0 {
+		step = 1
+	}
+	groups := map[int64][]statSample{}
+	for _, sm := range samples {
+		if !sm.status.Available() {
+			continue
+		}
+		bucket := sm.at.Unix() / step
+		groups[bucket] = append(groups[bucket], sm.statSample)
+	}
+
+	out := make([]model.LatencyBucket, 0, len(groups))
+	for bucket, group := range groups {
+		sort.Slice(group, func(i, j int) bool { return group[i].latencyMs < group[j].latencyMs })
+		var sum, weight int64
+		for _, sm := range group {
+			sum += int64(sm.latencyMs) * int64(sm.weight)
+			weight += int64(sm.weight)
+		}
+		out = append(out, model.LatencyBucket{
+			Start:  time.Unix(bucket*step, 0).UTC(),
+			Checks: int(weight),
+			MinMs:  group[0].latencyMs,
+			AvgMs:  float64(sum) / float64(weight),
+			MaxMs:  group[len(group)-1].latencyMs,
+			P95Ms:  weightedPercentile(group, weight, 0.95),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out
+}
+
+type timedSample struct {
+	statSample
+	at time.Time
+}
@@ -1,9 +1,17 @@
 package store
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,20 +21,130 @@ import (
 type State struct {
 	Monitors      []model.Monitor      `json:"monitors"`
 	Notifications []model.Notification `json:"notifications"`
+
+	// The fields below are only persisted by the JSON backend; the SQLite
+	// backend keeps them in dedicated tables and leaves them empty here.
+	RoutingPolicies    []model.RoutingPolicy             `json:"routingPolicies,omitempty"`
+	StatusPages        []model.StatusPage                `json:"statusPages,omitempty"`
+	MaintenanceWindows []model.MaintenanceWindow         `json:"maintenanceWindows,omitempty"`
+	LatencyHistograms  map[string]model.LatencyHistogram `json:"latencyHistograms,omitempty"`
+	Users              []model.User                      `json:"users,omitempty"`
+	Sessions           []model.Session                   `json:"sessions,omitempty"`
+	NotificationLog    []model.NotificationAttempt       `json:"notificationLog,omitempty"`
+	Incidents          []model.Incident                  `json:"incidents,omitempty"`
+	RemediationLog     []model.RemediationAttempt        `json:"remediationLog,omitempty"`
+	Events             []model.Event                     `json:"events,omitempty"`
+	Settings           *model.Settings                   `json:"settings,omitempty"`
+	Secrets            []model.Secret                    `json:"secrets,omitempty"`
 }
 
 type Store interface {
 	GetState() State
 	UpsertMonitor(m model.Monitor) (model.Monitor, error)
+	// UpsertMonitors saves all monitors or none of them.
+	UpsertMonitors(ms []model.Monitor) ([]model.Monitor, error)
 	DeleteMonitor(id string) error
+	// MonitorChanges subscribes to monitor upserts and deletes.
+	MonitorChanges() <-chan MonitorChange
 
 	GetNotifications() []model.Notification
 	UpsertNotification(n model.Notification) (model.Notification, error)
 	DeleteNotification(id string) error
 
+	GetRoutingPolicies() []model.RoutingPolicy
+	UpsertRoutingPolicy(p model.RoutingPolicy) (model.RoutingPolicy, error)
+	DeleteRoutingPolicy(id string) error
+
+	GetStatusPages() []model.StatusPage
+	UpsertStatusPage(p model.StatusPage) (model.StatusPage, error)
+	DeleteStatusPage(id string) error
+
+	GetMaintenanceWindows() []model.MaintenanceWindow
+	UpsertMaintenanceWindow(w model.MaintenanceWindow) (model.MaintenanceWindow, error)
+	DeleteMaintenanceWindow(id string) error
+
 	AddMonitorHistory(id string, entry model.MonitorHistoryEntry) error
+	// AddMonitorHistoryBatch appends the entries of several checks at once.
+	// On error none of them is stored, so the caller may retry the batch.
+	AddMonitorHistoryBatch(records []HistoryRecord) error
 	GetMonitorHistory(id string) ([]model.MonitorHistoryEntry, error)
-	PruneMonitorHistory(id string, days int) error
+	// ExportMonitorHistory calls fn for every persisted entry checked in
+	// [from, to), oldest first; zero times leave the range open. Logs are
+	// not included. Iteration stops at the first error from fn.
+	ExportMonitorHistory(id string, from, to time.Time, fn func(model.MonitorHistoryEntry) error) error
+	// PruneMonitorHistory deletes history older than days and returns the
+	// number of entries removed.
+	PruneMonitorHistory(id string, days int) (int64, error)
+	// Vacuum reclaims the space left behind by pruned history.
+	Vacuum() error
+	ImportMonitorHistory(id string, entries []model.MonitorHistoryEntry, retentionDays int) (model.HistoryImportResult, error)
+	GetDailyUptime(id string, since time.Time) ([]model.DailyUptime, error)
+	GetMonitorStats(id string, since time.Time) (model.MonitorStats, error)
+	// GetLatencyBuckets downsamples the latency of up and degraded checks
+	// since the given time into buckets of the given width.
+	GetLatencyBuckets(id string, since time.Time, resolution time.Duration) ([]model.LatencyBucket, error)
+
+	SaveLatencyHistograms(hists map[string]model.LatencyHistogram) error
+	LoadLatencyHistograms() (map[string]model.LatencyHistogram, error)
+
+	AddNotificationAttempt(a model.NotificationAttempt) error
+	// GetNotificationAttempts returns the newest attempts first; empty
+	// notificationID or monitorID match any.
+	GetNotificationAttempts(notificationID, monitorID string, limit int) ([]model.NotificationAttempt, error)
+
+	// AddRemediationAttempt stores a and returns it with its ID set.
+	AddRemediationAttempt(a model.RemediationAttempt) (model.RemediationAttempt, error)
+	// GetRemediationAttempts returns the newest attempts of a monitor first.
+	GetRemediationAttempts(monitorID string, limit int) ([]model.RemediationAttempt, error)
+
+	// AddEvent appends an event, whose ID is set by the caller, to the
+	// event log.
+	AddEvent(ev model.Event) error
+	// GetEvents returns the events matching f, newest first, and the total
+	// number of matches.
+	GetEvents(f EventFilter) ([]model.Event, int, error)
+	// LatestEvents returns the newest event of each monitor that has one,
+	// keyed by monitor ID.
+	LatestEvents() (map[string]model.Event, error)
+	// PruneEvents deletes events older than before and returns how many.
+	PruneEvents(before time.Time) (int64, error)
+
+	UpsertIncident(i model.Incident) (model.Incident, error)
+	GetIncident(id string) (model.Incident, bool)
+	// GetOpenIncident returns the unresolved incident of a monitor, if any.
+	GetOpenIncident(monitorID string) (model.Incident, bool)
+	// GetIncidents returns the newest incidents first; an empty monitorID
+	// matches any.
+	GetIncidents(monitorID string, openOnly bool, limit int) ([]model.Incident, error)
+
+	// GetSettings returns the saved runtime settings, or false when they
+	// have never been saved.
+	GetSettings() (model.Settings, bool)
+	// SaveSettings replaces the runtime settings and returns them with
+	// UpdatedAt set.
+	SaveSettings(set model.Settings) (model.Settings, error)
+
+	// GetSecrets returns the stored secrets sorted by name.
+	GetSecrets() []model.Secret
+	GetSecret(name string) (model.Secret, bool)
+	UpsertSecret(sec model.Secret) (model.Secret, error)
+	DeleteSecret(name string) error
+
+	GetUsers() []model.User
+	UpsertUser(u model.User) (model.User, error)
+	DeleteUser(id string) error
+	CreateSession(sess model.Session) error
+	GetSession(tokenHash string) (model.Session, bool)
+	DeleteSession(tokenHash string) error
+	// DeleteUserSessions signs a user out everywhere.
+	DeleteUserSessions(userID string) error
+}
+
+// HistoryRecord is a history entry of the given monitor, as written by
+// AddMonitorHistoryBatch.
+type HistoryRecord struct {
+	MonitorID string
+	Entry     model.MonitorHistoryEntry
 }
 
 type JSONStore struct {
@@ -34,12 +152,28 @@ type JSONStore struct {
 	mu       sync.RWMutex
 	state    State
 	history  map[string][]model.MonitorHistoryEntry
+	// lines counts the entries in each monitor's history file, including
+	// those dropped from memory since it was last compacted.
+	lines map[string]int
+	// stale marks history files that may hold part of a failed write and
+	// must be rewritten from memory before anything is appended to them.
+	stale map[string]bool
+	changeFeed
 }
 
+// jsonHistoryLimit caps the entries kept per monitor by the JSON backend,
+// which holds all history in memory.
+const jsonHistoryLimit = 1000
+
 func NewJSONStore(filePath string) (*JSONStore, error) {
 	s := &JSONStore{
 		filePath: filePath,
 		history:  make(map[string][]model.MonitorHistoryEntry),
+		lines:    make(map[string]int),
+		stale:    make(map[string]bool),
+	}
+	if err := s.loadHistory(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
 	}
 	if err := s.load(); err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -93,10 +227,48 @@ func (s *JSONStore) UpsertMonitor(m model.Monitor) (model.Monitor, error) {
 	if err := s.persistLocked(); err != nil {
 		return model.Monitor{}, err
 	}
+	s.publish(MonitorChange{ID: m.ID})
 
 	return m, nil
 }
 
+func (s *JSONStore) UpsertMonitors(ms []model.Monitor) ([]model.Monitor, error) {
+	now := time.Now().UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev := s.state.Monitors
+	monitors := append([]model.Monitor(nil), prev...)
+	index := make(map[string]int, len(monitors))
+	for i, m := range monitors {
+		index[m.ID] = i
+	}
+	out := make([]model.Monitor, 0, len(ms))
+	for _, m := range ms {
+		m.UpdatedAt = now
+		if i, ok := index[m.ID]; ok {
+			m.CreatedAt = monitors[i].CreatedAt
+			monitors[i] = m
+		} else {
+			m.CreatedAt = now
+			index[m.ID] = len(monitors)
+			monitors = append(monitors, m)
+		}
+		out = append(out, m)
+	}
+
+	s.state.Monitors = monitors
+	if err := s.persistLocked(); err != nil {
+		s.state.Monitors = prev
+		return nil, err
+	}
+	for _, m := range out {
+		s.publish(MonitorChange{ID: m.ID})
+	}
+	return out, nil
+}
+
 func (s *JSONStore) DeleteMonitor(id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -110,7 +282,11 @@ func (s *JSONStore) DeleteMonitor(id string) error {
 	}
 	s.state.Monitors = dst
 
-	return s.persistLocked()
+	if err := s.persistLocked(); err != nil {
+		return err
+	}
+	s.publish(MonitorChange{ID: id, Deleted: true})
+	return nil
 }
 
 func (s *JSONStore) GetNotifications() []model.Notification {
@@ -168,21 +344,152 @@ func (s *JSONStore) DeleteNotification(id string) error {
 	return s.persistLocked()
 }
 
+func (s *JSONStore) GetRoutingPolicies() []model.RoutingPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	dst := make([]model.RoutingPolicy, len(s.state.RoutingPolicies))
+	copy(dst, s.state.RoutingPolicies)
+	return dst
+}
+
+func (s *JSONStore) UpsertRoutingPolicy(p model.RoutingPolicy) (model.RoutingPolicy, error) {
+	now := time.Now().UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := false
+	for i := range s.state.RoutingPolicies {
+		if s.state.RoutingPolicies[i].ID == p.ID {
+			p.CreatedAt = s.state.RoutingPolicies[i].CreatedAt
+			p.UpdatedAt = now
+			s.state.RoutingPolicies[i] = p
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		p.CreatedAt = now
+		p.UpdatedAt = now
+		s.state.RoutingPolicies = append(s.state.RoutingPolicies, p)
+	}
+
+	if err := s.persistLocked(); err != nil {
+		return model.RoutingPolicy{}, err
+	}
+
+	return p, nil
+}
+
+func (s *JSONStore) DeleteRoutingPolicy(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dst := s.state.RoutingPolicies[:0]
+	for _, p := range s.state.RoutingPolicies {
+		if p.ID == id {
+			continue
+		}
+		dst = append(dst, p)
+	}
+	s.state.RoutingPolicies = dst
+
+	return s.persistLocked()
+}
+
+func (s *JSONStore) GetStatusPages() []model.StatusPage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	dst := make([]model.StatusPage, len(s.state.StatusPages))
+	copy(dst, s.state.StatusPages)
+	return dst
+}
+
+func (s *JSONStore) UpsertStatusPage(p model.StatusPage) (model.StatusPage, error) {
+	now := time.Now().UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := false
+	for i := range s.state.StatusPages {
+		if s.state.StatusPages[i].ID == p.ID {
+			p.CreatedAt = s.state.StatusPages[i].CreatedAt
+			p.UpdatedAt = now
+			s.state.StatusPages[i] = p
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		p.CreatedAt = now
+		p.UpdatedAt = now
+		s.state.StatusPages = append(s.state.StatusPages, p)
+	}
+
+	if err := s.persistLocked(); err != nil {
+		return model.StatusPage{}, err
+	}
+
+	return p, nil
+}
+
+func (s *JSONStore) DeleteStatusPage(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dst := s.state.StatusPages[:0]
+	for _, p := range s.state.StatusPages {
+		if p.ID == id {
+			continue
+		}
+		dst = append(dst, p)
+	}
+	s.state.StatusPages = dst
+
+	return s.persistLocked()
+}
+
 func (s *JSONStore) AddMonitorHistory(id string, entry model.MonitorHistoryEntry) error {
+	return s.AddMonitorHistoryBatch([]HistoryRecord{{MonitorID: id, Entry: entry}})
+}
+
+// AddMonitorHistoryBatch appends the entries to the history files of their
+// monitors. On error none of them is kept, so the batch can be retried.
+func (s *JSONStore) AddMonitorHistoryBatch(records []HistoryRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	hist := s.history[id]
-	// Prepend
-	hist = append([]model.MonitorHistoryEntry{entry}, hist...)
-	// Keep last 50
-	if len(hist) > 50 {
-		hist = hist[:50]
+	saved := map[string][]model.MonitorHistoryEntry{}
+	for _, r := range records {
+		if _, ok := saved[r.MonitorID]; !ok {
+			saved[r.MonitorID] = s.history[r.MonitorID]
+		}
+		s.prependHistoryLocked(r.MonitorID, r.Entry)
+	}
+	if err := s.appendHistoryLocked(records); err != nil {
+		for id, hist := range saved {
+			s.history[id] = hist
+			s.stale[id] = true
+		}
+		return err
 	}
-	s.history[id] = hist
 	return nil
 }
 
+func (s *JSONStore) prependHistoryLocked(id string, entry model.MonitorHistoryEntry) {
+	hist := append([]model.MonitorHistoryEntry{entry}, s.history[id]...)
+	if len(hist) > jsonHistoryLimit {
+		hist = hist[:jsonHistoryLimit]
+	}
+	s.history[id] = hist
+}
+
 func (s *JSONStore) GetMonitorHistory(id string) ([]model.MonitorHistoryEntry, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -197,6 +504,197 @@ func (s *JSONStore) GetMonitorHistory(id string) ([]model.MonitorHistoryEntry, e
 	return out, nil
 }
 
+func (s *JSONStore) ExportMonitorHistory(id string, from, to time.Time, fn func(model.MonitorHistoryEntry) error) error {
+	s.mu.RLock()
+	hist := s.history[id]
+	out := make([]model.MonitorHistoryEntry, 0, len(hist))
+	// history is newest first; export oldest first.
+	for i := len(hist) - 1; i >= 0; i-- {
+		if e := hist[i]; inRange(e.CheckedAt, from, to) {
+			e.Logs = ""
+			out = append(out, e)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, e := range out {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inRange reports whether t lies in [from, to); zero bounds are open.
+func inRange(t, from, to time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || t.Before(to))
+}
+
+func (s *JSONStore) PruneMonitorHistory(id string, days int) (int64, error) {
+	if days <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().UTC().AddDate(0, 0, -days)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hist := s.history[id]
+	dst := hist[:0]
+	for _, e := range hist {
+		if e.CheckedAt.Before(cutoff) {
+			continue
+		}
+		dst = append(dst, e)
+	}
+	removed := int64(len(hist) - len(dst))
+	if removed == 0 {
+		return 0, nil
+	}
+	s.history[id] = dst
+	return removed, s.rewriteHistoryLocked(id)
+}
+
+// Vacuum is a no-op: the history file is rewritten on every change.
+func (s *JSONStore) Vacuum() error {
+	return nil
+}
+
+func (s *JSONStore) ImportMonitorHistory(id string, entries []model.MonitorHistoryEntry, retentionDays int) (model.HistoryImportResult, error) {
+	res := model.HistoryImportResult{RecomputedDays: []string{}}
+
+	var cutoff time.Time
+	if retentionDays > 0 {
+		cutoff = time.Now().UTC().AddDate(0, 0, -retentionDays)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hist := s.history[id]
+	seen := make(map[int64]bool, len(hist))
+	for _, e := range hist {
+		seen[e.CheckedAt.Unix()] = true
+	}
+
+	days := map[string]bool{}
+	for _, e := range entries {
+		e.CheckedAt = e.CheckedAt.UTC()
+		if e.CheckedAt.IsZero() || (!cutoff.IsZero() && e.CheckedAt.Before(cutoff)) {
+			res.Expired++
+			continue
+		}
+		if seen[e.CheckedAt.Unix()] {
+			res.Duplicates++
+			continue
+		}
+		seen[e.CheckedAt.Unix()] = true
+		hist = append(hist, e)
+		days[e.CheckedAt.Format(dayLayout)] = true
+		res.Imported++
+	}
+	// Newest first, matching AddMonitorHistory.
+	sort.SliceStable(hist, func(i, j int) bool { return hist[i].CheckedAt.After(hist[j].CheckedAt) })
+	if len(hist) > jsonHistoryLimit {
+		hist = hist[:jsonHistoryLimit]
+	}
+	s.history[id] = hist
+	if err := s.rewriteHistoryLocked(id); err != nil {
+		return res, err
+	}
+
+	for day := range days {
+		res.RecomputedDays = append(res.RecomputedDays, day)
+	}
+	sort.Strings(res.RecomputedDays)
+	return res, nil
+}
+
+// GetDailyUptime aggregates the in-memory history on the fly; the JSON
+// backend has no cached daily table.
+func (s *JSONStore) GetDailyUptime(id string, since time.Time) ([]model.DailyUptime, error) {
+	sinceDay := since.UTC().Format(dayLayout)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	byDay := map[string]*model.DailyUptime{}
+	for _, e := range s.history[id] {
+		day := e.CheckedAt.UTC().Format(dayLayout)
+		if day < sinceDay {
+			continue
+		}
+		d := byDay[day]
+		if d == nil {
+			d = &model.DailyUptime{Day: day}
+			byDay[day] = d
+		}
+		if e.Status.Available() {
+			d.UpChecks += e.Checks()
+		}
+		d.TotalChecks += e.Checks()
+		d.LatencySumMs += int64(e.LatencyMs) * int64(e.Checks())
+	}
+
+	out := make([]model.DailyUptime, 0, len(byDay))
+	for _, d := range byDay {
+		out = append(out, *d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Day < out[j].Day })
+	return out, nil
+}
+
+func (s *JSONStore) GetMonitorStats(id string, since time.Time) (model.MonitorStats, error) {
+	s.mu.RLock()
+	hist := s.history[id]
+	samples := make([]statSample, 0, len(hist))
+	// history is newest first; aggregate oldest first.
+	for i := len(hist) - 1; i >= 0; i-- {
+		e := hist[i]
+		if e.CheckedAt.Before(since) {
+			continue
+		}
+		samples = append(samples, statSample{status: e.Status, latencyMs: e.LatencyMs, weight: e.Checks()})
+	}
+	s.mu.RUnlock()
+
+	out := aggregateStats(samples)
+	out.MonitorID = id
+	out.Since = since
+	return out, nil
+}
+
+func (s *JSONStore) GetLatencyBuckets(id string, since time.Time, resolution time.Duration) ([]model.LatencyBucket, error) {
+	s.mu.RLock()
+	hist := s.history[id]
+	samples := make([]timedSample, 0, len(hist))
+	for _, e := range hist {
+		if e.CheckedAt.Before(since) {
+			continue
+		}
+		samples = append(samples, timedSample{statSample{status: e.Status, latencyMs: e.LatencyMs, weight: e.Checks()}, e.CheckedAt})
+	}
+	s.mu.RUnlock()
+	return latencyBuckets(samples, resolution), nil
+}
+
+func (s *JSONStore) SaveLatencyHistograms(hists map[string]model.LatencyHistogram) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.LatencyHistograms = hists
+	return s.persistLocked()
+}
+
+func (s *JSONStore) LoadLatencyHistograms() (map[string]model.LatencyHistogram, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]model.LatencyHistogram, len(s.state.LatencyHistograms))
+	for k, v := range s.state.LatencyHistograms {
+		out[k] = v
+	}
+	return out, nil
+}
+
 func (s *JSONStore) load() error {
 	b, err := os.ReadFile(s.filePath)
 	if err != nil {
@@ -229,3 +727,175 @@ func (s *JSONStore) persistLocked() error {
 	}
 	return os.Rename(tmp, s.filePath)
 }
+
+// legacyHistoryPath is the single history file of earlier releases, which
+// was rewritten on every check.
+func (s *JSONStore) legacyHistoryPath() string {
+	return s.filePath + ".history"
+}
+
+// historyDir holds one history file per monitor, with one JSON entry per
+// line, oldest first.
+func (s *JSONStore) historyDir() string {
+	return s.filePath + ".history.d"
+}
+
+func (s *JSONStore) historyFile(id string) string {
+	return filepath.Join(s.historyDir(), url.PathEscape(id)+".jsonl")
+}
+
+func (s *JSONStore) loadHistory() error {
+	if err := s.convertLegacyHistory(); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(s.historyDir())
+	if err != nil {
+		return err
+	}
+	for _, de := range entries {
+		name, ok := strings.CutSuffix(de.Name(), ".jsonl")
+		if !ok || de.IsDir() {
+			continue
+		}
+		id, err := url.PathUnescape(name)
+		if err != nil {
+			continue
+		}
+		hist, n, err := readHistoryFile(filepath.Join(s.historyDir(), de.Name()))
+		if err != nil {
+			return err
+		}
+		s.history[id] = hist
+		s.lines[id] = n
+	}
+	return nil
+}
+
+// readHistoryFile returns the newest jsonHistoryLimit entries of a history
+// file, newest first, and the number of lines it has. A line cut short by a
+// crash is skipped.
+func readHistoryFile(path string) ([]model.MonitorHistoryEntry, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	var hist []model.MonitorHistoryEntry
+	lines := 0
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 16<<20)
+	for sc.Scan() {
+		lines++
+		var e model.MonitorHistoryEntry
+		if json.Unmarshal(sc.Bytes(), &e) == nil {
+			hist = append(hist, e)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, 0, fmt.Errorf("read %s: %w", path, err)
+	}
+	hist = hist[max(len(hist)-jsonHistoryLimit, 0):]
+	slices.Reverse(hist)
+	return hist, lines, nil
+}
+
+// convertLegacyHistory splits the history file of earlier releases into
+// per-monitor files and removes it.
+func (s *JSONStore) convertLegacyHistory() error {
+	b, err := os.ReadFile(s.legacyHistoryPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	hist := map[string][]model.MonitorHistoryEntry{}
+	if err := json.Unmarshal(b, &hist); err != nil {
+		return err
+	}
+	s.history = hist
+	for id := range hist {
+		if err := s.rewriteHistoryLocked(id); err != nil {
+			return err
+		}
+	}
+	return os.Remove(s.legacyHistoryPath())
+}
+
+// appendHistoryLocked appends the records to the history files of their
+// monitors, so that a check only writes its own entry. A file is compacted
+// once it holds twice as many entries as are kept.
+func (s *JSONStore) appendHistoryLocked(records []HistoryRecord) error {
+	byMonitor := map[string][]byte{}
+	var order []string
+	for _, r := range records {
+		b, err := json.Marshal(r.Entry)
+		if err != nil {
+			return err
+		}
+		if _, ok := byMonitor[r.MonitorID]; !ok {
+			order = append(order, r.MonitorID)
+		}
+		byMonitor[r.MonitorID] = append(append(byMonitor[r.MonitorID], b...), '\n')
+		s.lines[r.MonitorID]++
+	}
+	if err := os.MkdirAll(s.historyDir(), 0o700); err != nil {
+		return err
+	}
+	for _, id := range order {
+		if s.stale[id] || s.lines[id] > 2*jsonHistoryLimit {
+			if err := s.rewriteHistoryLocked(id); err != nil {
+				return err
+			}
+			continue
+		}
+		f, err := os.OpenFile(s.historyFile(id), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(byMonitor[id])
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewriteHistoryLocked replaces the history file of a monitor with the
+// entries held in memory, or removes it when there are none.
+func (s *JSONStore) rewriteHistoryLocked(id string) error {
+	hist := s.history[id]
+	if len(hist) == 0 {
+		if err := os.Remove(s.historyFile(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		delete(s.lines, id)
+		delete(s.stale, id)
+		return nil
+	}
+	var buf bytes.Buffer
+	for i := len(hist) - 1; i >= 0; i-- {
+		b, err := json.Marshal(hist[i])
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+		buf.WriteByte('\n')
+	}
+	if err := os.MkdirAll(s.historyDir(), 0o700); err != nil {
+		return err
+	}
+	tmp := s.historyFile(id) + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.historyFile(id)); err != nil {
+		return err
+	}
+	s.lines[id] = len(hist)
+	delete(s.stale, id)
+	return nil
+}
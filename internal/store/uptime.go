@@ -0,0 +1,238 @@
+package store
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+const dayLayout = "2006-01-02"
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// addDailyUptime folds a single history entry into the cached daily aggregate.
+func (s *SQLiteStore) addDailyUptime(db execer, id string, entry model.MonitorHistoryEntry) error {
+	up := 0
+	if entry.Status.Available() {
+		up = entry.Checks()
+	}
+	query := `INSERT INTO uptime_daily (monitor_id, day, up_checks, total_checks, latency_sum_ms) VALUES (?, ?, ?, ?, ?)
+			  ON CONFLICT(monitor_id, day) DO UPDATE SET
+			  up_checks = up_checks + excluded.up_checks,
+			  total_checks = total_checks + excluded.total_checks,
+			  latency_sum_ms = latency_sum_ms + excluded.latency_sum_ms`
+	_, err := db.Exec(query, id, entry.CheckedAt.UTC().Format(dayLayout), up, entry.Checks(), int64(entry.LatencyMs)*int64(entry.Checks()))
+	return err
+}
+
+func (s *SQLiteStore) GetDailyUptime(id string, since time.Time) ([]model.DailyUptime, error) {
+	rows, err := s.db.Query(`SELECT day, up_checks, total_checks, latency_sum_ms FROM uptime_daily
+		WHERE monitor_id = ? AND day >= ? ORDER BY day`, id, since.UTC().Format(dayLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.DailyUptime{}
+	for rows.Next() {
+		var d model.DailyUptime
+		if err := rows.Scan(&d.Day, &d.UpChecks, &d.TotalChecks, &d.LatencySumMs); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// ImportMonitorHistory backfills history for a monitor. Entries older than
+// the retention window are dropped, entries overlapping existing history
+// (same second) are de-duplicated, and the daily aggregates of every touched
+// day are recomputed from scratch.
+func (s *SQLiteStore) ImportMonitorHistory(id string, entries []model.MonitorHistoryEntry, retentionDays int) (model.HistoryImportResult, error) {
+	res := model.HistoryImportResult{RecomputedDays: []string{}}
+	if len(entries) == 0 {
+		return res, nil
+	}
+
+	var cutoff time.Time
+	if retentionDays > 0 {
+		cutoff = time.Now().UTC().AddDate(0, 0, -retentionDays)
+	}
+
+	keep := make([]model.MonitorHistoryEntry, 0, len(entries))
+	for _, e := range entries {
+		e.CheckedAt = e.CheckedAt.UTC()
+		if e.CheckedAt.IsZero() || (!cutoff.IsZero() && e.CheckedAt.Before(cutoff)) {
+			res.Expired++
+			continue
+		}
+		keep = append(keep, e)
+	}
+	if len(keep) == 0 {
+		return res, nil
+	}
+	sort.Slice(keep, func(i, j int) bool { return keep[i].CheckedAt.Before(keep[j].CheckedAt) })
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen, err := s.historySeconds(id, keep[0].CheckedAt.Add(-time.Second), keep[len(keep)-1].CheckedAt.Add(time.Second))
+	if err != nil {
+		return res, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	days := map[string]bool{}
+	query := `INSERT INTO monitor_history (monitor_id, status, checked_at, latency_ms, message, logs_gz, transient, conn_mode, weight, cpu_percent, mem_percent, regions, phases) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	for _, e := range keep {
+		sec := e.CheckedAt.Unix()
+		if seen[sec] {
+			res.Duplicates++
+			continue
+		}
+		seen[sec] = true
+
+		var logsGz []byte
+		if e.Logs != "" {
+			if logsGz, err = gzipString(e.Logs); err != nil {
+				return res, err
+			}
+		}
+		regions, err := regionsColumn(e.Regions)
+		if err != nil {
+			return res, err
+		}
+		phases, err := phasesColumn(e.Phases)
+		if err != nil {
+			return res, err
+		}
+		if _, err := tx.Exec(query, id, string(e.Status), e.CheckedAt, e.LatencyMs, e.Message, logsGz, e.Transient, string(e.ConnMode), e.Checks(), e.CPUPercent, e.MemoryPercent, regions, phases); err != nil {
+			return res, err
+		}
+		days[e.CheckedAt.Format(dayLayout)] = true
+		res.Imported++
+	}
+
+	for day := range days {
+		if err := s.recomputeDay(tx, id, day); err != nil {
+			return res, err
+		}
+		res.RecomputedDays = append(res.RecomputedDays, day)
+	}
+	sort.Strings(res.RecomputedDays)
+
+	return res, tx.Commit()
+}
+
+func (s *SQLiteStore) historySeconds(id string, from, to time.Time) (map[int64]bool, error) {
+	rows, err := s.db.Query(`SELECT checked_at FROM monitor_history WHERE monitor_id = ? AND checked_at >= ? AND checked_at <= ?`, id, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seen := map[int64]bool{}
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			continue
+		}
+		seen[t.Unix()] = true
+	}
+	return seen, rows.Err()
+}
+
+type queryExecer interface {
+	execer
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// recomputeDay rebuilds the cached aggregate of one UTC day from raw history.
+func (s *SQLiteStore) recomputeDay(db queryExecer, id, day string) error {
+	start, err := time.Parse(dayLayout, day)
+	if err != nil {
+		return err
+	}
+	end := start.AddDate(0, 0, 1)
+
+	rows, err := db.Query(`SELECT status, latency_ms, weight FROM monitor_history WHERE monitor_id = ? AND checked_at >= ? AND checked_at < ?`, id, start, end)
+	if err != nil {
+		return err
+	}
+	var agg model.DailyUptime
+	for rows.Next() {
+		var status string
+		var e model.MonitorHistoryEntry
+		if err := rows.Scan(&status, &e.LatencyMs, &e.Weight); err != nil {
+			continue
+		}
+		if model.MonitorStatus(status).Available() {
+			agg.UpChecks += e.Checks()
+		}
+		agg.TotalChecks += e.Checks()
+		agg.LatencySumMs += int64(e.LatencyMs) * int64(e.Checks())
+	}
+	rows.Close()
+
+	_, err = db.Exec(`INSERT INTO uptime_daily (monitor_id, day, up_checks, total_checks, latency_sum_ms) VALUES (?, ?, ?, ?, ?)
+			  ON CONFLICT(monitor_id, day) DO UPDATE SET
+			  up_checks = excluded.up_checks,
+			  total_checks = excluded.total_checks,
+			  latency_sum_ms = excluded.latency_sum_ms`, id, day, agg.UpChecks, agg.TotalChecks, agg.LatencySumMs)
+	return err
+}
+
+// backfillDailyUptime populates the aggregate cache from existing history
+// the first time the table is created on an existing database.
+func (s *SQLiteStore) backfillDailyUptime() error {
+	var cached, raw int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM uptime_daily").Scan(&cached); err != nil || cached > 0 {
+		return err
+	}
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM monitor_history").Scan(&raw); err != nil || raw == 0 {
+		return err
+	}
+
+	rows, err := s.db.Query("SELECT monitor_id, status, checked_at, latency_ms, weight FROM monitor_history")
+	if err != nil {
+		return err
+	}
+	type key struct{ id, day string }
+	aggs := map[key]*model.DailyUptime{}
+	for rows.Next() {
+		var id, status string
+		var e model.MonitorHistoryEntry
+		if err := rows.Scan(&id, &status, &e.CheckedAt, &e.LatencyMs, &e.Weight); err != nil {
+			continue
+		}
+		k := key{id, e.CheckedAt.UTC().Format(dayLayout)}
+		a, ok := aggs[k]
+		if !ok {
+			a = &model.DailyUptime{Day: k.day}
+			aggs[k] = a
+		}
+		if model.MonitorStatus(status).Available() {
+			a.UpChecks += e.Checks()
+		}
+		a.TotalChecks += e.Checks()
+		a.LatencySumMs += int64(e.LatencyMs) * int64(e.Checks())
+	}
+	rows.Close()
+
+	for k, a := range aggs {
+		if _, err := s.db.Exec(`INSERT OR REPLACE INTO uptime_daily (monitor_id, day, up_checks, total_checks, latency_sum_ms) VALUES (?, ?, ?, ?, ?)`,
+			k.id, k.day, a.UpChecks, a.TotalChecks, a.LatencySumMs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lsy88/uptime-chopper/internal/model"
+)
+
+func (s *SQLiteStore) GetUsers() []model.User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := []model.User{}
+	rows, err := s.db.Query("SELECT data FROM users")
+	if err != nil {
+		return users
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err == nil {
+			var u model.User
+			if err := json.Unmarshal([]byte(data), &u); err == nil {
+				users = append(users, u)
+			}
+		}
+	}
+	return users
+}
+
+func (s *SQLiteStore) UpsertUser(u model.User) (model.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	u.UpdatedAt = now
+	if u.CreatedAt.IsZero() {
+		u.CreatedAt = now
+	}
+
+	data, err := json.Marshal(u)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	query := `INSERT INTO users (id, data, created_at, updated_at) VALUES (?, ?, ?, ?)
+			  ON CONFLICT(id) DO UPDATE SET data=excluded.data, updated_at=excluded.updated_at`
+
+	if _, err := s.db.Exec(query, u.ID, string(data), u.CreatedAt, u.UpdatedAt); err != nil {
+		return model.User{}, err
+	}
+	return u, nil
+}
+
+func (s *SQLiteStore) DeleteUser(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.db.Exec("DELETE FROM sessions WHERE user_id = ?", id); err != nil {
+		return err
+	}
+	_, err := s.db.Exec("DELETE FROM users WHERE id = ?", id)
+	return err
+}
+
+// CreateSession stores sess and drops sessions that have already expired.
+func (s *SQLiteStore) CreateSession(sess model.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.db.Exec("DELETE FROM sessions WHERE expires_at < ?", time.Now().Unix()); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`INSERT INTO sessions (token_hash, user_id, created_at, expires_at) VALUES (?, ?, ?, ?)`,
+		sess.TokenHash, sess.UserID, sess.CreatedAt.Unix(), sess.ExpiresAt.Unix())
+	return err
+}
+
+// GetSession returns the session for tokenHash unless it is unknown or expired.
+func (s *SQLiteStore) GetSession(tokenHash string) (model.Session, bool) {
+	var sess model.Session
+	var created, expires int64
+	err := s.db.QueryRow(`SELECT token_hash, user_id, created_at, expires_at FROM sessions WHERE token_hash = ?`, tokenHash).
+		Scan(&sess.TokenHash, &sess.UserID, &created, &expires)
+	if err != nil {
+		return model.Session{}, false
+	}
+	sess.CreatedAt = time.Unix(created, 0).UTC()
+	sess.ExpiresAt = time.Unix(expires, 0).UTC()
+	if !time.Now().Before(sess.ExpiresAt) {
+		return model.Session{}, false
+	}
+	return sess, true
+}
+
+func (s *SQLiteStore) DeleteUserSessions(userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec("DELETE FROM sessions WHERE user_id = ?", userID)
+	return err
+}
+
+func (s *SQLiteStore) DeleteSession(tokenHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec("DELETE FROM sessions WHERE token_hash = ?", tokenHash)
+	return err
+}
+
+func (s *JSONStore) GetUsers() []model.User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	dst := make([]model.User, len(s.state.Users))
+	copy(dst, s.state.Users)
+	return dst
+}
+
+func (s *JSONStore) UpsertUser(u model.User) (model.User, error) {
+	now := time.Now().UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := false
+	for i := range s.state.Users {
+		if s.state.Users[i].ID == u.ID {
+			u.CreatedAt = s.state.Users[i].CreatedAt
+			u.UpdatedAt = now
+			s.state.Users[i] = u
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		u.CreatedAt = now
+		u.UpdatedAt = now
+		s.state.Users = append(s.state.Users, u)
+	}
+
+	if err := s.persistLocked(); err != nil {
+		return model.User{}, err
+	}
+
+	return u, nil
+}
+
+func (s *JSONStore) DeleteUser(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dst := s.state.Users[:0]
+	for _, u := range s.state.Users {
+		if u.ID == id {
+			continue
+		}
+		dst = append(dst, u)
+	}
+	s.state.Users = dst
+	s.deleteUserSessionsLocked(id)
+
+	return s.persistLocked()
+}
+
+func (s *JSONStore) DeleteUserSessions(userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteUserSessionsLocked(userID)
+	return s.persistLocked()
+}
+
+func (s *JSONStore) deleteUserSessionsLocked(userID string) {
+	sessions := s.state.Sessions[:0]
+	for _, sess := range s.state.Sessions {
+		if sess.UserID == userID {
+			continue
+		}
+		sessions = append(sessions, sess)
+	}
+	s.state.Sessions = sessions
+}
+
+func (s *JSONStore) CreateSession(sess model.Session) error {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dst := s.state.Sessions[:0]
+	for _, v := range s.state.Sessions {
+		if now.Before(v.ExpiresAt) {
+			dst = append(dst, v)
+		}
+	}
+	s.state.Sessions = append(dst, sess)
+
+	return s.persistLocked()
+}
+
+func (s *JSONStore) GetSession(tokenHash string) (model.Session, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, v := range s.state.Sessions {
+		if v.TokenHash == tokenHash && time.Now().Before(v.ExpiresAt) {
+			return v, true
+		}
+	}
+	return model.Session{}, false
+}
+
+func (s *JSONStore) DeleteSession(tokenHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dst := s.state.Sessions[:0]
+	for _, v := range s.state.Sessions {
+		if v.TokenHash == tokenHash {
+			continue
+		}
+		dst = append(dst, v)
+	}
+	s.state.Sessions = dst
+
+	return s.persistLocked()
+}
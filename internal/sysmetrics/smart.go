@@ -0,0 +1,136 @@
+package sysmetrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// DiskHealth is the S.M.A.R.T. health of a disk. The attribute counts are
+// those of ATA disks and MediaErrors and CriticalWarning those of NVMe
+// disks; the others stay zero. Source is "smartctl", or "sysfs" when
+// smartctl is not installed and only the kernel's device State is known.
+type DiskHealth struct {
+	Device          string
+	Model           string
+	Source          string
+	State           string
+	Passed          bool
+	Temperature     int // Celsius, 0 when not reported
+	Reallocated     int64
+	Pending         int64
+	Uncorrectable   int64
+	MediaErrors     int64
+	CriticalWarning int
+}
+
+// smartctl exit status bits that mean no data was read: the command line
+// did not parse, or the device could not be opened.
+const smartctlFatal = 1<<0 | 1<<1
+
+// SMART reads the health of device, e.g. /dev/sda, with smartctl. devType
+// is passed as -d when set, e.g. "sat" for disks behind USB bridges.
+// smartctl needs root to open the device.
+func SMART(ctx context.Context, device, devType string) (DiskHealth, error) {
+	args := []string{"--json", "-H", "-A", "-i"}
+	if devType != "" {
+		args = append(args, "-d", devType)
+	}
+	out, err := exec.CommandContext(ctx, "smartctl", append(args, device)...).Output()
+	if errors.Is(err, exec.ErrNotFound) {
+		return sysfsHealth(device)
+	}
+	var exit *exec.ExitError
+	if err != nil && !errors.As(err, &exit) {
+		return DiskHealth{}, err
+	}
+	h, status, perr := parseSmartctl(out)
+	if perr != nil {
+		if err != nil {
+			return DiskHealth{}, fmt.Errorf("smartctl %s: %w", device, err)
+		}
+		return DiskHealth{}, perr
+	}
+	if status&smartctlFatal != 0 {
+		return DiskHealth{}, fmt.Errorf("smartctl %s: %s", device, h.errMsg)
+	}
+	if !h.hasStatus {
+		return DiskHealth{}, fmt.Errorf("smartctl %s: SMART status not available", device)
+	}
+	h.Device = device
+	return h.DiskHealth, nil
+}
+
+type smartctlHealth struct {
+	DiskHealth
+	hasStatus bool
+	errMsg    string
+}
+
+// smartctlOutput is the part of `smartctl --json` output SMART uses.
+type smartctlOutput struct {
+	Smartctl struct {
+		ExitStatus int `json:"exit_status"`
+		Messages   []struct {
+			String   string `json:"string"`
+			Severity string `json:"severity"`
+		} `json:"messages"`
+	} `json:"smartctl"`
+	ModelName   string `json:"model_name"`
+	SmartStatus *struct {
+		Passed bool `json:"passed"`
+	} `json:"smart_status"`
+	Temperature struct {
+		Current int `json:"current"`
+	} `json:"temperature"`
+	ATAAttributes struct {
+		Table []struct {
+			ID  int `json:"id"`
+			Raw struct {
+				Value int64 `json:"value"`
+			} `json:"raw"`
+		} `json:"table"`
+	} `json:"ata_smart_attributes"`
+	NVMeLog *struct {
+		CriticalWarning int   `json:"critical_warning"`
+		MediaErrors     int64 `json:"media_errors"`
+	} `json:"nvme_smart_health_information_log"`
+}
+
+func parseSmartctl(b []byte) (smartctlHealth, int, error) {
+	var o smartctlOutput
+	if err := json.Unmarshal(b, &o); err != nil {
+		return smartctlHealth{}, 0, fmt.Errorf("parse smartctl output: %w", err)
+	}
+	h := smartctlHealth{DiskHealth: DiskHealth{Model: o.ModelName, Source: "smartctl", Temperature: o.Temperature.Current}}
+	var msgs []string
+	for _, m := range o.Smartctl.Messages {
+		msgs = append(msgs, m.String)
+	}
+	h.errMsg = strings.Join(msgs, "; ")
+	if h.errMsg == "" {
+		h.errMsg = fmt.Sprintf("exit status %d", o.Smartctl.ExitStatus)
+	}
+	if o.SmartStatus != nil {
+		h.hasStatus = true
+		h.Passed = o.SmartStatus.Passed
+	}
+	for _, a := range o.ATAAttributes.Table {
+		switch a.ID {
+		case 5:
+			h.Reallocated = a.Raw.Value
+		case 197:
+			h.Pending = a.Raw.Value
+		case 198:
+			h.Uncorrectable = a.Raw.Value
+		}
+	}
+	if n := o.NVMeLog; n != nil {
+		h.MediaErrors = n.MediaErrors
+		h.CriticalWarning = n.CriticalWarning
+	}
+	return h, o.Smartctl.ExitStatus, nil
+}
@@ -0,0 +1,92 @@
+// Package sysmetrics reads CPU, memory, load, disk usage and disk health
+// of the host the process runs on. Usage is read with gopsutil, which
+// covers linux, windows, macOS and the BSDs; disk health comes from
+// smartctl.
+package sysmetrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/shirou/gopsutil/v4/load"
+	"github.com/shirou/gopsutil/v4/mem"
+)
+
+// Memory is the physical memory of the host, in bytes. Available is what
+// can be allocated without swapping, including reclaimable caches.
+type Memory struct {
+	Total     uint64
+	Available uint64
+}
+
+// UsedPercent returns the share of memory that is not available.
+func (m Memory) UsedPercent() float64 {
+	if m.Total == 0 {
+		return 0
+	}
+	return float64(m.Total-min(m.Available, m.Total)) * 100 / float64(m.Total)
+}
+
+// Load is the 1, 5 and 15 minute load average.
+type Load struct {
+	Load1, Load5, Load15 float64
+}
+
+// Disk is the size and free space of the filesystem holding Path, in
+// bytes. Free counts the blocks available to unprivileged users.
+type Disk struct {
+	Path  string
+	Total uint64
+	Free  uint64
+}
+
+// FreePercent returns the share of the filesystem that is free.
+func (d Disk) FreePercent() float64 {
+	if d.Total == 0 {
+		return 0
+	}
+	return float64(d.Free) * 100 / float64(d.Total)
+}
+
+// CPUPercent returns the CPU usage of all cores over window.
+func CPUPercent(ctx context.Context, window time.Duration) (float64, error) {
+	p, err := cpu.PercentWithContext(ctx, window, false)
+	if err != nil {
+		return 0, err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return p[0], nil
+}
+
+// VirtualMemory returns the memory size and availability.
+func VirtualMemory() (Memory, error) {
+	vm, err := mem.VirtualMemory()
+	if err != nil {
+		return Memory{}, err
+	}
+	return Memory{Total: vm.Total, Available: vm.Available}, nil
+}
+
+// LoadAverage returns the load average. Windows has none; gopsutil
+// estimates it from the processor queue length, starting at zero on the
+// first call.
+func LoadAverage() (Load, error) {
+	a, err := load.Avg()
+	if err != nil {
+		return Load{}, err
+	}
+	return Load{Load1: a.Load1, Load5: a.Load5, Load15: a.Load15}, nil
+}
+
+// DiskUsage returns the size and free space of the filesystem holding path.
+func DiskUsage(path string) (Disk, error) {
+	u, err := disk.Usage(path)
+	if err != nil {
+		return Disk{}, err
+	}
+	return Disk{Path: path, Total: u.Total, Free: u.Free}, nil
+}
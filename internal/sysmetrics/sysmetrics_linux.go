@@ -0,0 +1,30 @@
+//go:build linux
+
+package sysmetrics
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// sysfsHealth reports a disk as healthy while the kernel considers it
+// online, for hosts without smartctl. Disks without a device state, such
+// as virtio disks, are healthy while they exist.
+func sysfsHealth(device string) (DiskHealth, error) {
+	dir := "/sys/block/" + strings.TrimPrefix(device, "/dev/")
+	if _, err := os.Stat(dir); err != nil {
+		return DiskHealth{}, fmt.Errorf("smartctl not found and %s is not a disk", device)
+	}
+	b, err := os.ReadFile(dir + "/device/state")
+	if errors.Is(err, os.ErrNotExist) {
+		return DiskHealth{Device: device, Source: "sysfs", State: "present", Passed: true}, nil
+	}
+	if err != nil {
+		return DiskHealth{}, err
+	}
+	state := strings.TrimSpace(string(b))
+	// SCSI and ATA disks report "running", NVMe controllers "live".
+	return DiskHealth{Device: device, Source: "sysfs", State: state, Passed: state == "running" || state == "live"}, nil
+}
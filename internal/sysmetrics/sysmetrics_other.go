@@ -0,0 +1,9 @@
+//go:build !linux
+
+package sysmetrics
+
+import "errors"
+
+func sysfsHealth(device string) (DiskHealth, error) {
+	return DiskHealth{}, errors.New("smartctl not found")
+}
@@ -0,0 +1,16 @@
+// Package winsvc queries and controls Windows services and IIS sites. On
+// non-Windows builds every call returns ErrUnsupported.
+package winsvc
+
+import "errors"
+
+var ErrUnsupported = errors.New("windows service monitoring is only supported on windows")
+
+// Service states as reported by the service control manager.
+const (
+	StateRunning = "running"
+	StateStopped = "stopped"
+	StatePending = "pending"
+	StatePaused  = "paused"
+	StateUnknown = "unknown"
+)
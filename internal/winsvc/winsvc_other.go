@@ -0,0 +1,17 @@
+//go:build !windows
+
+package winsvc
+
+import "context"
+
+func ServiceState(ctx context.Context, name string) (string, error) {
+	return "", ErrUnsupported
+}
+
+func StartService(ctx context.Context, name string) error {
+	return ErrUnsupported
+}
+
+func IISSiteState(ctx context.Context, site string) (string, error) {
+	return "", ErrUnsupported
+}
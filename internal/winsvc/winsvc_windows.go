@@ -0,0 +1,72 @@
+//go:build windows
+
+package winsvc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+func ServiceState(ctx context.Context, name string) (string, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return "", fmt.Errorf("connect service manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(name)
+	if err != nil {
+		return "", fmt.Errorf("open service %s: %w", name, err)
+	}
+	defer s.Close()
+
+	st, err := s.Query()
+	if err != nil {
+		return "", fmt.Errorf("query service %s: %w", name, err)
+	}
+	switch st.State {
+	case svc.Running:
+		return StateRunning, nil
+	case svc.Stopped:
+		return StateStopped, nil
+	case svc.Paused:
+		return StatePaused, nil
+	case svc.StartPending, svc.StopPending, svc.ContinuePending, svc.PausePending:
+		return StatePending, nil
+	default:
+		return StateUnknown, nil
+	}
+}
+
+func StartService(ctx context.Context, name string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connect service manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(name)
+	if err != nil {
+		return fmt.Errorf("open service %s: %w", name, err)
+	}
+	defer s.Close()
+	return s.Start()
+}
+
+// IISSiteState returns the state of an IIS site ("started", "stopped", ...)
+// as reported by appcmd.
+func IISSiteState(ctx context.Context, site string) (string, error) {
+	appcmd := filepath.Join(os.Getenv("windir"), "system32", "inetsrv", "appcmd.exe")
+	out, err := exec.CommandContext(ctx, appcmd, "list", "site", site, "/text:state").Output()
+	if err != nil {
+		return "", fmt.Errorf("appcmd list site %s: %w", site, err)
+	}
+	return strings.ToLower(strings.TrimSpace(string(out))), nil
+}